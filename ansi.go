@@ -0,0 +1,173 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// VisibleLength returns the number of terminal columns used to display
+// s, as with DisplayWidth, ignoring escape sequences such as colors,
+// cursor movement and hyperlinks. This is the width used by the package
+// when laying out tables and the live region.
+func VisibleLength(s string) int {
+	var w int
+
+	for s != "" {
+		if _, rest, ok := cutEscape(s); ok {
+			s = rest
+
+			continue
+		}
+
+		var c string
+
+		c, s = nextCluster(s)
+		w += clusterWidth(c)
+	}
+
+	return w
+}
+
+// WrapANSI wraps each line of s to at most width columns, as measured
+// by VisibleLength, breaking lines at spaces where possible and within
+// words longer than width otherwise. The space at each break is
+// removed. Escape sequences are kept in place, so colors continue
+// across the inserted line breaks as they do on a terminal. If width is
+// less than one, s is returned unchanged.
+func WrapANSI(s string, width int) string {
+	if width < 1 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.Join(wrapLine(line, width), "\n")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// wrapLine wraps line, which contains no newlines, to width columns.
+func wrapLine(line string, width int) []string {
+	var (
+		out []string
+		cur strings.Builder
+		n   int
+
+		// the position of the last space in cur and the width of cur
+		// up to and including it, or -1 if there is none
+		space  = -1
+		spaceN int
+	)
+
+	for line != "" {
+		if seq, rest, ok := cutEscape(line); ok {
+			cur.WriteString(seq)
+			line = rest
+
+			continue
+		}
+
+		var c string
+
+		c, line = nextCluster(line)
+		cw := clusterWidth(c)
+
+		if n+cw > width && n > 0 {
+			s := cur.String()
+			cur.Reset()
+
+			switch {
+			case c == " ":
+				// break at this space, which is dropped
+				out = append(out, s)
+				n, space = 0, -1
+
+				continue
+			case space >= 0:
+				out = append(out, s[:space])
+				cur.WriteString(s[space+1:])
+				n -= spaceN
+			default:
+				out = append(out, s)
+				n = 0
+			}
+
+			space = -1
+		}
+
+		if c == " " {
+			space, spaceN = cur.Len(), n+cw
+		}
+
+		cur.WriteString(c)
+		n += cw
+	}
+
+	return append(out, cur.String())
+}
+
+// cutEscape splits an escape sequence from the start of s, reporting
+// whether s starts with one. Control sequences (ESC [) end with a final
+// byte, operating system commands (ESC ]) such as hyperlinks end with
+// BEL or ESC \, and other escapes are two bytes long. An unterminated
+// sequence extends to the end of s.
+func cutEscape(s string) (string, string, bool) {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return "", s, false
+	}
+
+	switch s[1] {
+	case '[':
+		// parameter and intermediate bytes precede the final byte
+		end := strings.IndexFunc(s[2:], func(r rune) bool {
+			return r < 0x20 || r > 0x3f
+		})
+		if end < 0 {
+			return s, "", true
+		}
+
+		_, size := utf8.DecodeRuneInString(s[2+end:])
+		end += 2 + size
+
+		return s[:end], s[end:], true
+	case ']':
+		for i := 2; i < len(s); i++ {
+			switch {
+			case s[i] == '\a':
+				return s[:i+1], s[i+1:], true
+			case s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\':
+				return s[:i+2], s[i+2:], true
+			}
+		}
+
+		return s, "", true
+	default:
+		_, size := utf8.DecodeRuneInString(s[1:])
+
+		return s[:1+size], s[1+size:], true
+	}
+}
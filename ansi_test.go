@@ -0,0 +1,86 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func ExampleWrapANSI() {
+	s := "\x1b[32mgreen text\x1b[0m wraps without counting the color codes"
+
+	fmt.Printf("%q\n", cli.WrapANSI(s, 16))
+
+	// Output:
+	// "\x1b[32mgreen text\x1b[0m wraps\nwithout counting\nthe color codes"
+}
+
+func TestVisibleLength(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"\x1b[31mred\x1b[0m", 3},
+		{"\x1b[1;38;5;208m日本\x1b[m", 4},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 4},
+		{"\x1b]0;title\atext", 4},
+		{"\x1b7saved\x1b8", 5},
+		{"\x1b[31", 0},
+	}
+
+	for _, tc := range tests {
+		w := cli.VisibleLength(tc.in)
+		if w != tc.exp {
+			t.Errorf("%q: expected %d, received %d", tc.in, tc.exp, w)
+		}
+	}
+}
+
+func TestWrapANSI(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		exp   string
+	}{
+		{"the quick brown fox", 10, "the quick\nbrown fox"},
+		{"the quick brown fox", 0, "the quick brown fox"},
+		{"short", 10, "short"},
+		{"abcdefghij", 4, "abcd\nefgh\nij"},
+		{"one two\nthree four", 5, "one\ntwo\nthree\nfour"},
+		{"日本語のテキスト", 6, "日本語\nのテキ\nスト"},
+		{"\x1b[1mbold words\x1b[0m here", 5, "\x1b[1mbold\nwords\x1b[0m\nhere"},
+		{"a  b", 1, "a\n\nb"},
+	}
+
+	for _, tc := range tests {
+		s := cli.WrapANSI(tc.in, tc.width)
+		if s != tc.exp {
+			t.Errorf("%q: expected %q, received %q", tc.in, tc.exp, s)
+		}
+	}
+}
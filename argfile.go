@@ -0,0 +1,93 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// WithArgFiles makes Parse expand arguments of the form "@filename"
+// into the arguments listed in the named file. See ExpandArgFiles.
+func WithArgFiles() Option {
+	return func(c *Cmd) {
+		c.argFiles = true
+	}
+}
+
+// ExpandArgFiles returns args with each argument of the form
+// "@filename" replaced by the arguments read from the named file, one
+// per line. Leading and trailing space is removed from each line, and
+// blank lines and lines beginning with "#" are skipped. An argument
+// beginning with "@@" is passed through with the first "@" removed, and
+// arguments following "--" are not expanded.
+func ExpandArgFiles(args []string) ([]string, error) {
+	var out []string
+
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return append(out, args[i:]...), nil
+		case strings.HasPrefix(arg, "@@"):
+			out = append(out, arg[1:])
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			lines, err := readArgFile(arg[1:])
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, lines...)
+		default:
+			out = append(out, arg)
+		}
+	}
+
+	return out, nil
+}
+
+// readArgFile returns the arguments listed in the named file.
+func readArgFile(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var args []string
+
+	s := bufio.NewScanner(f)
+
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		args = append(args, line)
+	}
+
+	return args, s.Err()
+}
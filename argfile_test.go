@@ -0,0 +1,70 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestExpandArgFiles(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "args")
+
+	err := os.WriteFile(name, []byte("# generated\n-v\n\n  two words  \r\nlast\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	args, err := cli.ExpandArgFiles([]string{"first", "@" + name, "@@literal", "@", "--", "@" + name})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := []string{"first", "-v", "two words", "last", "@literal", "@", "--", "@" + name}
+	if !reflect.DeepEqual(args, exp) {
+		t.Errorf("expected %q, received %q", exp, args)
+	}
+
+	_, err = cli.ExpandArgFiles([]string{"@" + name + ".missing"})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Error("expected ErrNotExist, received", err)
+	}
+
+	cmd := cli.NewCmd(cli.WithArgFiles())
+	v := cmd.FlagSet.Bool("v", false, "verbose")
+
+	err = cmd.Parse([]string{"@" + name})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !*v || !reflect.DeepEqual(cmd.FlagSet.Args(), []string{"two words", "last"}) {
+		t.Errorf("unexpected result %v %q", *v, cmd.FlagSet.Args())
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// AtomicFile is an io.WriteCloser which replaces a file atomically.
+// Data is written to a temporary file in the same directory, which is
+// synced and renamed over the target by Close, so readers see either
+// the previous contents or the new contents, never a partial write.
+type AtomicFile struct {
+	f    *os.File
+	path string
+	err  error
+	done bool
+}
+
+// CreateAtomic returns an AtomicFile which replaces the file at path
+// when closed. If the file exists, its mode and, where supported, its
+// owner are carried over to the replacement; otherwise the file is
+// created with perm, before the umask.
+//
+// If path is a symbolic link, the file it refers to is replaced and the
+// link is left in place. A link whose target does not exist is replaced
+// by a regular file.
+func CreateAtomic(path string, perm os.FileMode) (*AtomicFile, error) {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+
+	fi, serr := os.Stat(path)
+	if serr == nil {
+		perm = fi.Mode()
+	}
+
+	f, err := createTemp(path, perm.Perm())
+	if err != nil {
+		return nil, err
+	}
+
+	if serr == nil {
+		// the umask may have removed bits from the existing mode
+		err = f.Chmod(fi.Mode().Perm())
+		if err == nil {
+			chownLike(f, fi)
+		}
+	}
+
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return nil, err
+	}
+
+	return &AtomicFile{f: f, path: path}, nil
+}
+
+// createTemp creates a new file with the given permissions, subject to
+// the umask, in the directory of path.
+func createTemp(path string, perm os.FileMode) (*os.File, error) {
+	prefix := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".")
+
+	for {
+		//nolint:gosec // the name only needs to be unique
+		f, err := os.OpenFile(prefix+strconv.FormatUint(uint64(rand.Uint32()), 10),
+			os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if !os.IsExist(err) {
+			return f, err
+		}
+	}
+}
+
+// Write writes b to the temporary file. After a write fails, Close
+// discards the file instead of replacing the target.
+func (af *AtomicFile) Write(b []byte) (int, error) {
+	if af.done {
+		return 0, os.ErrClosed
+	}
+
+	n, err := af.f.Write(b)
+	if err != nil && af.err == nil {
+		af.err = err
+	}
+
+	return n, err
+}
+
+// Close syncs the temporary file and renames it over the target. If a
+// previous write failed, the temporary file is removed and the error
+// of the write is returned.
+func (af *AtomicFile) Close() error {
+	if af.done {
+		return os.ErrClosed
+	}
+
+	af.done = true
+
+	err := af.err
+	if err == nil {
+		err = af.f.Sync()
+	}
+
+	if cerr := af.f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(af.f.Name(), af.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(af.f.Name())
+
+		return err
+	}
+
+	syncDir(filepath.Dir(af.path))
+
+	return nil
+}
+
+// Abort removes the temporary file, leaving the target unchanged. It
+// does nothing if the file has already been closed.
+func (af *AtomicFile) Abort() {
+	if af.done {
+		return
+	}
+
+	af.done = true
+
+	_ = af.f.Close()
+	_ = os.Remove(af.f.Name())
+}
+
+// WriteFileAtomic writes data to the file at path, replacing it
+// atomically as described for AtomicFile.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	af, err := CreateAtomic(path, perm)
+	if err != nil {
+		return err
+	}
+
+	_, _ = af.Write(data)
+
+	return af.Close()
+}
+
+// writeFileAtomic writes the output of wt to the file at path, replacing
+// it atomically. New files are only accessible by the owner.
+func writeFileAtomic(path string, wt io.WriterTo) error {
+	af, err := CreateAtomic(path, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = wt.WriteTo(af)
+	if err != nil {
+		af.Abort()
+
+		return err
+	}
+
+	return af.Close()
+}
@@ -0,0 +1,172 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+
+	err := cli.WriteFileAtomic(path, []byte("first"), 0o640)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, path, []byte("first"))
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if fi.Mode().Perm()&^0o640 != 0 {
+		t.Errorf("expected mode within 0640, received %v", fi.Mode())
+	}
+
+	err = os.Chmod(path, 0o604)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cli.WriteFileAtomic(path, []byte("second"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, path, []byte("second"))
+
+	fi, err = os.Stat(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if fi.Mode().Perm() != 0o604 {
+		t.Errorf("expected mode 0604, received %v", fi.Mode())
+	}
+
+	checkDirEntries(t, filepath.Dir(path), 1)
+}
+
+func TestCreateAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+
+	err := os.WriteFile(path, []byte("old"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	af, err := cli.CreateAtomic(path, 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	_, err = af.Write([]byte("new"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, path, []byte("old"))
+
+	af.Abort()
+
+	checkFile(t, path, []byte("old"))
+	checkDirEntries(t, filepath.Dir(path), 1)
+
+	err = af.Close()
+	if !errors.Is(err, os.ErrClosed) {
+		t.Error("expected os.ErrClosed, received", err)
+	}
+
+	af, err = cli.CreateAtomic(path, 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	_, err = af.Write([]byte("new"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = af.Close()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, path, []byte("new"))
+	checkDirEntries(t, filepath.Dir(path), 1)
+}
+
+func TestWriteFileAtomicSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	link := filepath.Join(dir, "link")
+
+	err := os.WriteFile(target, []byte("old"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = os.Symlink("target", link)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cli.WriteFileAtomic(link, []byte("new"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	fi, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected symlink to be kept, received mode %v", fi.Mode())
+	}
+
+	checkFile(t, target, []byte("new"))
+	checkDirEntries(t, dir, 2)
+}
+
+// checkDirEntries checks that dir holds n entries, so no temporary
+// files were left behind.
+func checkDirEntries(t *testing.T, dir string, n int) {
+	t.Helper()
+
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if len(ents) != n {
+		t.Errorf("expected %d entries, received %d", n, len(ents))
+	}
+}
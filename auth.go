@@ -0,0 +1,236 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// ErrAuthDenied is returned by BrowserAuth when the authorization
+// server reports an error, such as the user denying access.
+var ErrAuthDenied = errors.New("authentication denied")
+
+// authPage is the page shown in the browser once the callback has been
+// received.
+const authPage = `<!DOCTYPE html>
+<html><head><title>%[1]s</title></head>
+<body><p>%[1]s</p><p>You may close this window and return to the terminal.</p></body></html>
+`
+
+// BrowserAuthOptions configures BrowserAuth.
+type BrowserAuthOptions struct {
+	// URL returns the URL to open in the browser, given the URL of the
+	// local callback listener to use as the redirect URL.
+	URL func(redirect string) string
+
+	// Param is the query parameter of the callback holding the code or
+	// token. The default is "code".
+	Param string
+
+	// State, if set, must equal the "state" query parameter of the
+	// callback. Callbacks with a different state are rejected.
+	State string
+
+	// Addr is the address of the callback listener. The default is
+	// "127.0.0.1:0", which listens on a random port.
+	Addr string
+
+	// Path is the path of the callback. The default is "/callback".
+	Path string
+
+	// Open opens url in the browser. The default opens the system
+	// browser.
+	Open func(url string) error
+}
+
+// authResult is the outcome of a callback.
+type authResult struct {
+	v   string
+	err error
+}
+
+// BrowserAuth performs the browser step of an OAuth style login. It
+// starts a callback listener on localhost, opens the URL returned by
+// opts.URL in the browser, and shows a live status line while waiting
+// for the browser to be redirected to the listener. The URL is also
+// printed, in case the browser cannot be opened. The value of the
+// callback parameter named by opts.Param is returned.
+//
+// Waiting stops with ErrInterrupted if the exit channel closes, or with
+// the error of ctx if it is done.
+func (c *Cmd) BrowserAuth(ctx context.Context, opts BrowserAuthOptions) (string, error) {
+	if opts.Param == "" {
+		opts.Param = "code"
+	}
+
+	if opts.Addr == "" {
+		opts.Addr = "127.0.0.1:0"
+	}
+
+	if opts.Path == "" {
+		opts.Path = "/callback"
+	}
+
+	if opts.Open == nil {
+		opts.Open = openBrowser
+	}
+
+	ln, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return "", err
+	}
+
+	results := make(chan authResult, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(opts.Path, opts.callback(results))
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), statusShutdownTimeout)
+		defer cancel()
+
+		_ = srv.Shutdown(ctx)
+	}()
+
+	url := opts.URL(fmt.Sprintf("http://%s%s", ln.Addr(), opts.Path))
+
+	c.Println("Opening your browser to authenticate. If it does not open, visit:")
+	c.Println(url)
+
+	err = opts.Open(url)
+	if err != nil {
+		c.Eprintf("unable to open browser: %v\n", err)
+	}
+
+	res := c.waitAuth(ctx, results)
+
+	if res.err != nil {
+		c.FinalizeLive("authentication failed")
+	} else {
+		c.FinalizeLive("authenticated")
+	}
+
+	return res.v, res.err
+}
+
+// callback returns the handler of the callback, which sends the result
+// to results.
+func (opts BrowserAuthOptions) callback(results chan<- authResult) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		if opts.State != "" && q.Get("state") != opts.State {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+
+			return
+		}
+
+		var res authResult
+
+		switch {
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("%w: %s", ErrAuthDenied, q.Get("error"))
+
+			if d := q.Get("error_description"); d != "" {
+				res.err = fmt.Errorf("%w: %s", res.err, d)
+			}
+
+			fmt.Fprintf(w, authPage, "Authentication failed.")
+		case q.Get(opts.Param) == "":
+			http.Error(w, "missing "+opts.Param, http.StatusBadRequest)
+
+			return
+		default:
+			res.v = q.Get(opts.Param)
+
+			fmt.Fprintf(w, authPage, "Authentication complete.")
+		}
+
+		select {
+		case results <- res:
+		default:
+		}
+	}
+}
+
+// waitAuth shows a live status line until a result is received, the
+// exit channel closes or ctx is done.
+func (c *Cmd) waitAuth(ctx context.Context, results <-chan authResult) authResult {
+	start := c.now()
+
+	tick, stop := c.frames(time.Second)
+	defer stop()
+
+	for {
+		c.Lprintf("waiting for authentication... (%s)\n",
+			c.now().Sub(start).Round(time.Second))
+
+		select {
+		case res := <-results:
+			return res
+		case <-c.C:
+			return authResult{err: ErrInterrupted}
+		case <-ctx.Done():
+			return authResult{err: ctx.Err()}
+		case <-tick:
+		}
+	}
+}
+
+// openBrowser opens url in the system browser.
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
+}
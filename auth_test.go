@@ -0,0 +1,114 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+// authOpener returns a function which follows the redirect of an
+// authorization URL with the given query.
+func authOpener(t *testing.T, query string) func(string) error {
+	t.Helper()
+
+	return func(s string) error {
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			resp, err := http.Get(u.Query().Get("redirect_uri") + "?" + query)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		return nil
+	}
+}
+
+func authURL(redirect string) string {
+	return "https://auth.example.com/authorize?redirect_uri=" + url.QueryEscape(redirect)
+}
+
+func TestBrowserAuth(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	code, err := cmd.BrowserAuth(context.Background(), cli.BrowserAuthOptions{
+		URL:   authURL,
+		State: "xyz",
+		Open:  authOpener(t, "state=xyz&code=abc123"),
+	})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if code != "abc123" {
+		t.Errorf("expected %q, received %q", "abc123", code)
+	}
+
+	if !strings.Contains(outbuf.String(), "https://auth.example.com/authorize?redirect_uri=http%3A%2F%2F127.0.0.1%3A") {
+		t.Errorf("expected URL to be printed, received %q", outbuf)
+	}
+}
+
+func TestBrowserAuthDenied(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	_, err := cmd.BrowserAuth(context.Background(), cli.BrowserAuthOptions{
+		URL:  authURL,
+		Open: authOpener(t, "error=access_denied"),
+	})
+	if !errors.Is(err, cli.ErrAuthDenied) {
+		t.Error("expected ErrAuthDenied, received", err)
+	}
+}
+
+func TestBrowserAuthInterrupted(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	cmd.After(50*time.Millisecond, func() { cmd.Exit(nil) })
+
+	_, err := cmd.BrowserAuth(context.Background(), cli.BrowserAuthOptions{
+		URL:  authURL,
+		Open: func(string) error { return nil },
+	})
+	if !errors.Is(err, cli.ErrInterrupted) {
+		t.Error("expected ErrInterrupted, received", err)
+	}
+}
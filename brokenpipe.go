@@ -0,0 +1,128 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// BrokenPipe determines what happens when Stdout or Stderr is a pipe
+// whose reader has gone away, as when output is piped to head.
+type BrokenPipe int32
+
+// Broken pipe policies.
+const (
+	// BrokenPipeKill keeps the default behavior of Go programs, which
+	// are killed by SIGPIPE when writing to a broken pipe on Stdout or
+	// Stderr.
+	BrokenPipeKill BrokenPipe = iota
+
+	// BrokenPipeDiscard discards later output to the broken stream, so
+	// the Print functions succeed without writing anything.
+	BrokenPipeDiscard
+
+	// BrokenPipeExit discards later output to the broken stream and
+	// calls the exit func with an error wrapping ErrBrokenPipe. A Cmd
+	// then shuts down as on a signal.
+	BrokenPipeExit
+)
+
+// BrokenPipeExitCode is the exit status returned by Run when output
+// stops because of a broken pipe, matching the status shells report
+// for a process killed by SIGPIPE.
+const BrokenPipeExitCode = 141
+
+// ErrBrokenPipe is passed to the exit func when BrokenPipeExit is set
+// and a write fails because of a broken pipe.
+var ErrBrokenPipe = errors.New("broken pipe")
+
+// brokenPipeState holds the broken pipe policy of a TermPrinter.
+type brokenPipeState struct {
+	policy   atomic.Int32
+	exitOnce sync.Once
+
+	m  sync.Mutex
+	sc chan os.Signal
+}
+
+// SetBrokenPipe sets the policy for writes to a broken pipe. With any
+// policy other than BrokenPipeKill, SIGPIPE is received by the program
+// rather than killing it, so that the failed write can be detected.
+//
+// If no exit func has been set with SetExitFunc, BrokenPipeExit exits
+// the program with BrokenPipeExitCode.
+func (tp *TermPrinter) SetBrokenPipe(p BrokenPipe) {
+	bp := &tp.brokenPipe
+
+	bp.m.Lock()
+	defer bp.m.Unlock()
+
+	bp.policy.Store(int32(p))
+
+	switch {
+	case p == BrokenPipeKill && bp.sc != nil:
+		signal.Stop(bp.sc)
+		bp.sc = nil
+	case p != BrokenPipeKill && bp.sc == nil:
+		// the signals are only received so that writes fail with
+		// EPIPE, so the channel is never read
+		bp.sc = make(chan os.Signal, 1)
+		signal.Notify(bp.sc, syscall.SIGPIPE)
+	}
+}
+
+// writeFailed applies the broken pipe policy after a write of stream s
+// to lw failed with err.
+func (tp *TermPrinter) writeFailed(lw *lockingWriter, s Stream, err error) {
+	p := BrokenPipe(tp.brokenPipe.policy.Load())
+	if p == BrokenPipeKill || !isBrokenPipe(err) {
+		return
+	}
+
+	lw.broken.Store(true)
+
+	if p != BrokenPipeExit {
+		return
+	}
+
+	tp.brokenPipe.exitOnce.Do(func() {
+		if tp.exitFunc == nil {
+			os.Exit(BrokenPipeExitCode)
+		}
+
+		tp.exitFunc(fmt.Errorf("%w: %s", ErrBrokenPipe, s))
+	})
+}
+
+// isBrokenPipe reports whether err results from writing to a pipe with
+// no reader.
+func isBrokenPipe(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrClosedPipe)
+}
@@ -0,0 +1,134 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestBrokenPipeDiscard(t *testing.T) {
+	r, w := io.Pipe()
+	r.Close()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(w)
+	p.SetBrokenPipe(cli.BrokenPipeDiscard)
+
+	defer p.SetBrokenPipe(cli.BrokenPipeKill)
+
+	_, err := p.Println("first")
+	if !errors.Is(err, io.ErrClosedPipe) {
+		t.Error("unexpected error:", err)
+	}
+
+	n, err := p.Println("second")
+	if err != nil || n != 7 {
+		t.Errorf("expected 7 bytes discarded, received %d, %v", n, err)
+	}
+}
+
+func TestBrokenPipeExit(t *testing.T) {
+	r, w := io.Pipe()
+	r.Close()
+
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(w)
+	cmd.SetStderr(errbuf)
+	cmd.SetBrokenPipe(cli.BrokenPipeExit)
+
+	defer cmd.SetBrokenPipe(cli.BrokenPipeKill)
+
+	code := cmd.Run(nil, func(ctx context.Context, _ []string) error {
+		for ctx.Err() == nil {
+			cmd.Println("y")
+		}
+
+		return nil
+	})
+
+	if code != cli.BrokenPipeExitCode {
+		t.Error("unexpected exit code:", code)
+	}
+
+	if errbuf.Len() > 0 {
+		t.Errorf("unexpected output: %q", errbuf)
+	}
+}
+
+func TestBrokenPipeSignal(t *testing.T) {
+	if os.Getenv("CLI_TEST_BROKEN_PIPE") != "" {
+		cmd := cli.NewCmd()
+		cmd.SetBrokenPipe(cli.BrokenPipeExit)
+
+		os.Exit(cmd.Run(nil, func(ctx context.Context, _ []string) error {
+			for ctx.Err() == nil {
+				cmd.Println("y")
+			}
+
+			return nil
+		}))
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("no SIGPIPE on windows")
+	}
+
+	proc := exec.Command(os.Args[0], "-test.run=^TestBrokenPipeSignal$")
+	proc.Env = append(os.Environ(), "CLI_TEST_BROKEN_PIPE=1")
+
+	out, err := proc.StdoutPipe()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = proc.Start()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil || line != "y\n" {
+		t.Errorf("unexpected output %q, %v", line, err)
+	}
+
+	out.Close()
+
+	err = proc.Wait()
+
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) || ee.ExitCode() != cli.BrokenPipeExitCode {
+		t.Error("expected exit status 141, received", err)
+	}
+}
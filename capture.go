@@ -0,0 +1,125 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sync"
+)
+
+// stdioCapture routes writes to os.Stdout and os.Stderr through a
+// TermPrinter.
+type stdioCapture struct {
+	stdout *os.File
+	stderr *os.File
+
+	outW *os.File
+	errW *os.File
+
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// CaptureGlobalStdio replaces os.Stdout and os.Stderr with pipes whose
+// output is printed with Print and Eprint a line at a time, so output
+// from packages which write to os.Stdout directly, such as with
+// fmt.Println, does not corrupt the live region.
+//
+// The original files are restored when the returned io.Closer is
+// closed, or when Wait returns, after any remaining output has been
+// printed. Only writes made through the os.Stdout and os.Stderr
+// variables are captured: loggers created earlier, such as the default
+// logger of the log package, keep the file they were given, and child
+// processes write to the original file descriptors.
+func (c *Cmd) CaptureGlobalStdio() (io.Closer, error) {
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		_ = outR.Close()
+		_ = outW.Close()
+
+		return nil, err
+	}
+
+	sc := &stdioCapture{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		outW:   outW,
+		errW:   errW,
+	}
+
+	sc.wg.Add(2)
+
+	go sc.forward(outR, func(s string) { c.Print(s) })
+	go sc.forward(errR, func(s string) { c.Eprint(s) })
+
+	os.Stdout = outW
+	os.Stderr = errW
+
+	c.OnExit(func(error) {
+		_ = sc.Close()
+	})
+
+	return sc, nil
+}
+
+// forward passes each line read from r to fn until r is closed.
+func (sc *stdioCapture) forward(r *os.File, fn func(string)) {
+	defer sc.wg.Done()
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			fn(line)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Close restores os.Stdout and os.Stderr, then waits for the captured
+// output to be printed.
+func (sc *stdioCapture) Close() error {
+	sc.once.Do(func() {
+		os.Stdout = sc.stdout
+		os.Stderr = sc.stderr
+
+		_ = sc.outW.Close()
+		_ = sc.errW.Close()
+
+		sc.wg.Wait()
+	})
+
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestCaptureGlobalStdio(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+	errbuf := new(bytes.Buffer)
+
+	stdout, stderr := os.Stdout, os.Stderr
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetStderr(errbuf)
+
+	closer, err := cmd.CaptureGlobalStdio()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	fmt.Println("first line")
+	fmt.Print("second ")
+	fmt.Println("line")
+	fmt.Fprint(os.Stderr, "partial")
+
+	err = closer.Close()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if os.Stdout != stdout || os.Stderr != stderr {
+		t.Error("expected os.Stdout and os.Stderr to be restored")
+	}
+
+	if outbuf.String() != "first line\nsecond line\n" {
+		t.Errorf("unexpected stdout %q", outbuf)
+	}
+
+	if errbuf.String() != "partial" {
+		t.Errorf("unexpected stderr %q", errbuf)
+	}
+
+	cmd.Exit(nil)
+	_ = cmd.Wait()
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpointer persists the state of a long-running job, so that an
+// interrupted run can resume where it left off. State passed to Save is
+// held in memory and written to a file in the StateDir of the
+// application when Flush is called, and when the Cmd exits, including
+// by a forced exit. Once the job completes, Clear removes the file.
+//
+// A typical job calls Load at the start of the run if the user asked to
+// resume, Save as each unit of work completes, and Clear at the end.
+// A Checkpointer is safe for concurrent use.
+type Checkpointer struct {
+	path string
+
+	m     sync.Mutex
+	state []byte
+	dirty bool
+}
+
+// NewCheckpointer returns a Checkpointer storing state in a file named
+// for name in the StateDir of the application, which is flushed when
+// the Cmd exits. Each job of the application should use its own name.
+func (c *Cmd) NewCheckpointer(name string) (*Checkpointer, error) {
+	dir, err := StateDir(filepath.Base(c.FlagSet.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &Checkpointer{path: filepath.Join(dir, name+".checkpoint.json")}
+
+	c.OnExit(func(error) {
+		err := cp.Flush()
+		if err != nil {
+			_, _ = c.Eprintln("unable to save checkpoint:", err)
+		}
+	})
+
+	return cp, nil
+}
+
+// Path returns the path of the checkpoint file.
+func (cp *Checkpointer) Path() string {
+	return cp.path
+}
+
+// Save records state, which must be encodable as JSON, as the latest
+// checkpoint. The state is encoded immediately, so it may be modified
+// once Save returns. It is written to the file by Flush.
+func (cp *Checkpointer) Save(state any) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	cp.m.Lock()
+	cp.state = b
+	cp.dirty = true
+	cp.m.Unlock()
+
+	return nil
+}
+
+// Load decodes the latest checkpoint into state, which should be a
+// pointer as with json.Unmarshal. It reports whether a checkpoint was
+// found, either saved during this run or left in the file by a
+// previous one.
+func (cp *Checkpointer) Load(state any) (bool, error) {
+	cp.m.Lock()
+	b := cp.state
+	cp.m.Unlock()
+
+	if b == nil {
+		var err error
+
+		b, err = os.ReadFile(cp.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, json.Unmarshal(b, state)
+}
+
+// Flush writes the latest checkpoint to the file, replacing it
+// atomically. Flush does nothing if nothing was saved since the last
+// Flush or Clear.
+func (cp *Checkpointer) Flush() error {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+
+	if !cp.dirty {
+		return nil
+	}
+
+	err := writeFileAtomic(cp.path, bytes.NewReader(cp.state))
+	if err != nil {
+		return err
+	}
+
+	cp.dirty = false
+
+	return nil
+}
+
+// Clear discards the checkpoint and removes the file, for when the job
+// has completed and there is nothing left to resume.
+func (cp *Checkpointer) Clear() error {
+	cp.m.Lock()
+	defer cp.m.Unlock()
+
+	cp.state = nil
+	cp.dirty = false
+
+	err := os.Remove(cp.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
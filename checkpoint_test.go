@@ -0,0 +1,106 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+type batchState struct {
+	Next  int      `json:"next"`
+	Files []string `json:"files"`
+}
+
+func TestCheckpointer(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(io.Discard)
+
+	cp, err := cmd.NewCheckpointer("batch")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var state batchState
+
+	found, err := cp.Load(&state)
+	if err != nil || found {
+		t.Fatal("expected no checkpoint, received", found, err)
+	}
+
+	cmd.Run(nil, func(context.Context, []string) error {
+		state.Files = []string{"a", "b", "c"}
+
+		for state.Next = 0; state.Next < 2; state.Next++ {
+			err := cp.Save(state)
+			if err != nil {
+				return err
+			}
+		}
+
+		return errTest
+	})
+
+	_, err = os.Stat(cp.Path())
+	if err != nil {
+		t.Fatal("expected checkpoint file, received", err)
+	}
+
+	cmd = cli.NewCmd()
+
+	cp, err = cmd.NewCheckpointer("batch")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var resumed batchState
+
+	found, err = cp.Load(&resumed)
+	if err != nil || !found {
+		t.Fatal("expected checkpoint, received", found, err)
+	}
+
+	if resumed.Next != 1 || len(resumed.Files) != 3 {
+		t.Errorf("unexpected state %+v", resumed)
+	}
+
+	err = cp.Clear()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Exit(nil)
+	_ = cmd.Wait()
+
+	_, err = os.Stat(cp.Path())
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected checkpoint removed, received", err)
+	}
+}
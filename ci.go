@@ -0,0 +1,129 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultCIInterval is the minimum time between live updates printed
+// in CI environments if SetCIInterval has not been called.
+const defaultCIInterval = 10 * time.Second
+
+// ciEnv lists environment variables set by common CI providers, with
+// the name of the provider.
+//
+//nolint:gochecknoglobals // read-only list of CI variables
+var ciEnv = []struct {
+	env  string
+	name string
+}{
+	{"GITHUB_ACTIONS", "GitHub Actions"},
+	{"GITLAB_CI", "GitLab CI"},
+	{"BUILDKITE", "Buildkite"},
+	{"CIRCLECI", "CircleCI"},
+	{"TRAVIS", "Travis CI"},
+	{"JENKINS_URL", "Jenkins"},
+	{"TEAMCITY_VERSION", "TeamCity"},
+	{"TF_BUILD", "Azure Pipelines"},
+	{"BITBUCKET_BUILD_NUMBER", "Bitbucket Pipelines"},
+	{"DRONE", "Drone"},
+	{"APPVEYOR", "AppVeyor"},
+	{"CODEBUILD_BUILD_ID", "AWS CodeBuild"},
+}
+
+// IsCI reports whether the process appears to be running in a
+// continuous integration environment, based on the CI variable and the
+// variables set by common CI providers.
+func IsCI() bool {
+	if v, ok := os.LookupEnv("CI"); ok {
+		return v != "false" && v != "0"
+	}
+
+	return ciProvider() != ""
+}
+
+// ciProvider returns the name of the CI provider detected from the
+// environment, or an empty string if none is detected.
+func ciProvider() string {
+	for _, p := range ciEnv {
+		if os.Getenv(p.env) != "" {
+			return p.name
+		}
+	}
+
+	return ""
+}
+
+// ciState holds the state of append-only live output in CI.
+type ciState struct {
+	m        sync.Mutex
+	enabled  bool
+	interval time.Duration
+	last     time.Time
+}
+
+// SetCIInterval sets the minimum time between live updates printed when
+// running in a CI environment. In CI, Lprintf does not redraw the live
+// region even if Stdout is a terminal, since the escape sequences make
+// logs unreadable. Instead, updates are appended as regular output, and
+// updates received within d of the previous one are dropped. A zero
+// value prints every update, a negative value restores the default of
+// 10 seconds.
+func (tp *TermPrinter) SetCIInterval(d time.Duration) {
+	tp.ci.m.Lock()
+	tp.ci.interval = d
+	tp.ci.m.Unlock()
+}
+
+// ciEnabled reports whether live updates are in append-only mode.
+func (tp *TermPrinter) ciEnabled() bool {
+	tp.ci.m.Lock()
+	defer tp.ci.m.Unlock()
+
+	return tp.ci.enabled
+}
+
+// ciThrottle reports whether a live update should be dropped. If force
+// is true, the update is never dropped.
+func (tp *TermPrinter) ciThrottle(force bool) bool {
+	tp.ci.m.Lock()
+	defer tp.ci.m.Unlock()
+
+	d := tp.ci.interval
+	if d < 0 {
+		d = defaultCIInterval
+	}
+
+	now := tp.now()
+
+	if !force && !tp.ci.last.IsZero() && now.Sub(tp.ci.last) < d {
+		return true
+	}
+
+	tp.ci.last = now
+
+	return false
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestCI(t *testing.T) {
+	t.Run("Detect", testCIDetect)
+	t.Run("Console", testCIConsole)
+}
+
+func testCIDetect(t *testing.T) {
+	t.Setenv("CI", "true")
+
+	if !cli.IsCI() {
+		t.Error("expected CI detected")
+	}
+
+	t.Setenv("CI", "false")
+	t.Setenv("GITHUB_ACTIONS", "true")
+
+	if cli.IsCI() {
+		t.Error("expected CI=false to take precedence")
+	}
+}
+
+func testCIConsole(t *testing.T) {
+	t.Setenv("CI", "true")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	var outstr string
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		outstr, err = cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetCIInterval(time.Hour)
+
+	p.Lprintf("status 1\n")
+	p.Lprintf("status 2\n")
+	p.SetLiveRenderer(func() string { return "status 3\n" })
+	p.Refresh()
+	p.Print("END")
+
+	wg.Wait()
+
+	if outstr != "status 1\r\nstatus 3\r\nEND" {
+		t.Errorf("unexpected output %q", outstr)
+	}
+}
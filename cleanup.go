@@ -0,0 +1,84 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// cleanupState holds the state of exit hooks run in the background.
+type cleanupState struct {
+	background atomic.Bool
+	once       sync.Once
+	done       chan bool
+}
+
+// SetBackgroundCleanup sets whether Wait returns as soon as all the
+// goroutines being awaited call Done, leaving the exit hooks added with
+// OnExit to run in the background. This allows results to be printed
+// promptly while slower clean up, such as flushing a cache or removing
+// temporary files, finishes. WaitCleanup must then be called before the
+// application exits to wait for the hooks.
+//
+// If a timeout has been set, it also limits the background clean up, as
+// it runs after Exit has been called.
+func (e *ExitHandler) SetBackgroundCleanup(background bool) {
+	e.cleanup.background.Store(background)
+}
+
+// WaitCleanup blocks until all the goroutines being awaited call Done
+// and the exit hooks have returned, starting the hooks if Wait has not
+// already done so. The return value is the first error value passed to
+// Exit. If SetBackgroundCleanup is not enabled, WaitCleanup behaves the
+// same as Wait.
+func (e *ExitHandler) WaitCleanup() error {
+	if !e.cleanup.background.Load() {
+		return e.Wait()
+	}
+
+	e.wg.Wait()
+
+	e.restoreTerminal()
+
+	<-e.startCleanup()
+
+	return e.err
+}
+
+// startCleanup calls the exit hooks in a new goroutine, unless already
+// started, returning a channel which is closed once they return.
+func (e *ExitHandler) startCleanup() <-chan bool {
+	e.cleanup.once.Do(func() {
+		e.cleanup.done = make(chan bool)
+
+		go func() {
+			defer close(e.cleanup.done)
+
+			e.runHooks(e.err, false)
+			e.finish()
+		}()
+	})
+
+	return e.cleanup.done
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestBackgroundCleanup(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	eh.SetBackgroundCleanup(true)
+
+	release := make(chan bool)
+
+	var cleaned atomic.Bool
+
+	eh.OnExit(func(err error) {
+		<-release
+		cleaned.Store(errors.Is(err, errTest))
+	})
+
+	eh.Add(1)
+
+	go func() {
+		defer eh.Done()
+
+		eh.Exit(errTest)
+	}()
+
+	err := eh.Wait()
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error", err)
+	}
+
+	if cleaned.Load() {
+		t.Error("Wait waited for exit hook")
+	}
+
+	close(release)
+
+	err = eh.WaitCleanup()
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error", err)
+	}
+
+	if !cleaned.Load() {
+		t.Error("WaitCleanup did not wait for exit hook")
+	}
+}
+
+func TestWaitCleanupDisabled(t *testing.T) {
+	eh := new(cli.ExitHandler)
+
+	var calls int
+
+	eh.OnExit(func(error) {
+		calls++
+	})
+
+	eh.Add(1)
+	eh.Exit(nil)
+	eh.Done()
+
+	if err := eh.WaitCleanup(); err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, received %d", calls)
+	}
+}
+
+// signalWriter closes printed once the text has been written to it.
+type signalWriter struct {
+	m       sync.Mutex
+	b       strings.Builder
+	text    string
+	once    sync.Once
+	printed chan bool
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	w.m.Lock()
+	defer w.m.Unlock()
+
+	n, err := w.b.Write(p)
+
+	if strings.Contains(w.b.String(), w.text) {
+		w.once.Do(func() { close(w.printed) })
+	}
+
+	return n, err
+}
+
+func TestRunBackgroundCleanup(t *testing.T) {
+	errbuf := &signalWriter{text: "testing error", printed: make(chan bool)}
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(errbuf)
+	cmd.SetBackgroundCleanup(true)
+
+	var cleaned atomic.Bool
+
+	cmd.OnExit(func(error) {
+		select {
+		case <-errbuf.printed:
+			cleaned.Store(true)
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	code := cmd.Run(nil, func(context.Context, []string) error {
+		return errTest
+	})
+
+	if code != 1 {
+		t.Errorf("expected code 1, received %d", code)
+	}
+
+	if !cleaned.Load() {
+		t.Error("error not printed before exit hook finished")
+	}
+}
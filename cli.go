@@ -26,6 +26,9 @@
 // ability to coordinate the shutdown of multiple goroutines in
 // long-running processes.
 //
+// WorkerPool runs tasks on a fixed number of goroutines which stop
+// accepting work and shut down along with an ExitHandler.
+//
 // TermPrinter provides convenience functions to print output to Stdout
 // and Stderr, including a simple "live writer" for status output.
 package cli
@@ -0,0 +1,223 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ErrNoClipboard is returned by WriteClipboard and ReadClipboard when
+// no means of accessing the clipboard is available.
+var ErrNoClipboard = errors.New("no clipboard available")
+
+// osc52Terms lists prefixes of TERM values of terminals known to
+// support setting the clipboard with OSC 52.
+//
+//nolint:gochecknoglobals // constant list
+var osc52Terms = []string{"xterm-kitty", "alacritty", "foot", "wezterm", "xterm-ghostty"}
+
+// osc52Programs lists TERM_PROGRAM values of terminals known to
+// support setting the clipboard with OSC 52.
+//
+//nolint:gochecknoglobals // constant list
+var osc52Programs = []string{"iTerm.app", "WezTerm", "ghostty"}
+
+// clipTool is an external program which accesses the clipboard.
+type clipTool struct {
+	name string
+	args []string
+}
+
+// WriteClipboard copies s to the system clipboard. When running in a
+// terminal known to support it, or in an SSH session where the local
+// clipboard is out of reach, the OSC 52 escape sequence asks the
+// terminal to set the clipboard. Otherwise a platform utility such as
+// pbcopy, wl-copy, xclip, xsel or clip.exe is used, with OSC 52 as a
+// last resort if none is found.
+func WriteClipboard(s string) error {
+	term := clipboardTerm()
+
+	if term != nil && osc52Supported() {
+		return writeOSC52(term, s)
+	}
+
+	for _, t := range copyTools() {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			continue
+		}
+
+		cmd := exec.Command(path, t.args...)
+		cmd.Stdin = strings.NewReader(s)
+
+		err = cmd.Run()
+		if err != nil {
+			return fmt.Errorf("%s: %w", t.name, err)
+		}
+
+		return nil
+	}
+
+	if term != nil {
+		return writeOSC52(term, s)
+	}
+
+	return ErrNoClipboard
+}
+
+// ReadClipboard returns the contents of the system clipboard, using a
+// platform utility such as pbpaste, wl-paste, xclip, xsel or PowerShell.
+// Reading with OSC 52 is disabled by most terminals, so it is not
+// attempted.
+func ReadClipboard() (string, error) {
+	for _, t := range pasteTools() {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			continue
+		}
+
+		var out bytes.Buffer
+
+		cmd := exec.Command(path, t.args...)
+		cmd.Stdout = &out
+
+		err = cmd.Run()
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", t.name, err)
+		}
+
+		if t.name == "powershell.exe" {
+			return strings.TrimSuffix(out.String(), "\r\n"), nil
+		}
+
+		return out.String(), nil
+	}
+
+	return "", ErrNoClipboard
+}
+
+// copyTools returns the utilities which may set the clipboard, in
+// order of preference.
+func copyTools() []clipTool {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipTool{{"pbcopy", nil}}
+	case "windows":
+		return []clipTool{{"clip.exe", nil}}
+	}
+
+	var tools []clipTool
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, clipTool{"wl-copy", nil})
+	}
+
+	return append(tools,
+		clipTool{"xclip", []string{"-selection", "clipboard"}},
+		clipTool{"xsel", []string{"--clipboard", "--input"}},
+		clipTool{"clip.exe", nil})
+}
+
+// pasteTools returns the utilities which may read the clipboard, in
+// order of preference.
+func pasteTools() []clipTool {
+	ps := clipTool{"powershell.exe", []string{"-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipTool{{"pbpaste", nil}}
+	case "windows":
+		return []clipTool{ps}
+	}
+
+	var tools []clipTool
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		tools = append(tools, clipTool{"wl-paste", []string{"--no-newline"}})
+	}
+
+	return append(tools,
+		clipTool{"xclip", []string{"-selection", "clipboard", "-out"}},
+		clipTool{"xsel", []string{"--clipboard", "--output"}},
+		ps)
+}
+
+// clipboardTerm returns Stdout or Stderr if either is a terminal, or
+// nil otherwise.
+func clipboardTerm() *os.File {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if isatty.IsTerminal(f.Fd()) {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// osc52Supported reports whether the terminal should be asked to set
+// the clipboard with OSC 52.
+func osc52Supported() bool {
+	if isSSH() {
+		return true
+	}
+
+	term, prog := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+
+	for _, t := range osc52Terms {
+		if strings.HasPrefix(term, t) {
+			return true
+		}
+	}
+
+	for _, p := range osc52Programs {
+		if prog == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeOSC52 writes the OSC 52 sequence setting the clipboard to s.
+// Within tmux, the sequence is wrapped so that it is passed through to
+// the outer terminal.
+func writeOSC52(f *os.File, s string) error {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(s)) + "\a"
+
+	if os.Getenv("TMUX") != "" {
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+
+	_, err := f.WriteString(seq)
+
+	return err
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestClipboard(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("xclip is not used on", runtime.GOOS)
+	}
+
+	dir := t.TempDir()
+	clip := filepath.Join(dir, "clip")
+
+	script := "#!/bin/sh\n" +
+		"case \"$*\" in\n" +
+		"  *-out*) cat '" + clip + "' ;;\n" +
+		"  *) cat > '" + clip + "' ;;\n" +
+		"esac\n"
+
+	err := os.WriteFile(filepath.Join(dir, "xclip"), []byte(script), 0o700)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("WAYLAND_DISPLAY", "")
+	t.Setenv("SSH_TTY", "")
+	t.Setenv("SSH_CONNECTION", "")
+	t.Setenv("TERM", "dumb")
+	t.Setenv("TERM_PROGRAM", "")
+
+	err = cli.WriteClipboard("copied text")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	s, err := cli.ReadClipboard()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if s != "copied text" {
+		t.Errorf("expected %q, received %q", "copied text", s)
+	}
+}
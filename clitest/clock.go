@@ -0,0 +1,194 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package clitest provides helpers for testing programs built with
+// package cli.
+package clitest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+// FakeClock is a cli.Clock whose time only changes when Advance is
+// called, allowing timeouts and tickers to be tested without sleeping.
+type FakeClock struct {
+	m       sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a timer or ticker created by a FakeClock.
+type fakeWaiter struct {
+	clk    *FakeClock
+	c      chan time.Time
+	when   time.Time
+	period time.Duration
+}
+
+// NewFakeClock returns a FakeClock set to the time t.
+func NewFakeClock(t time.Time) *FakeClock {
+	f := &FakeClock{now: t}
+	f.cond = sync.NewCond(&f.m)
+
+	return f
+}
+
+// Now returns the current time of the FakeClock.
+func (f *FakeClock) Now() time.Time {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	return f.now
+}
+
+// NewTimer returns a timer which fires once the FakeClock has been
+// advanced by d.
+func (f *FakeClock) NewTimer(d time.Duration) cli.Timer {
+	return f.add(d, 0)
+}
+
+// NewTicker returns a ticker which fires each time the FakeClock has
+// been advanced by d. As with time.Ticker, ticks are dropped if the
+// receiver falls behind.
+func (f *FakeClock) NewTicker(d time.Duration) cli.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	return fakeTicker{f.add(d, d)}
+}
+
+// Advance moves the time of the FakeClock forward by d, firing the
+// timers and tickers which become due in order.
+func (f *FakeClock) Advance(d time.Duration) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	end := f.now.Add(d)
+
+	for {
+		sort.SliceStable(f.waiters, func(i, j int) bool {
+			return f.waiters[i].when.Before(f.waiters[j].when)
+		})
+
+		if len(f.waiters) == 0 || f.waiters[0].when.After(end) {
+			break
+		}
+
+		w := f.waiters[0]
+		f.now = w.when
+
+		select {
+		case w.c <- f.now:
+		default:
+		}
+
+		if w.period > 0 {
+			w.when = w.when.Add(w.period)
+		} else {
+			f.waiters = f.waiters[1:]
+		}
+	}
+
+	f.now = end
+	f.cond.Broadcast()
+}
+
+// BlockUntil blocks until at least n timers and tickers are waiting on
+// the FakeClock, allowing a test to advance the time only once the code
+// under test is ready.
+func (f *FakeClock) BlockUntil(n int) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// add registers a waiter due after d, repeating every period if it is
+// greater than zero.
+func (f *FakeClock) add(d time.Duration, period time.Duration) *fakeWaiter {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	w := &fakeWaiter{
+		clk:    f,
+		c:      make(chan time.Time, 1),
+		when:   f.now.Add(d),
+		period: period,
+	}
+
+	if d <= 0 {
+		w.c <- f.now
+
+		return w
+	}
+
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+
+	return w
+}
+
+// C returns the channel of the waiter.
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+// Stop removes the waiter from its clock, returning false if it had
+// already fired or been stopped.
+func (w *fakeWaiter) Stop() bool {
+	f := w.clk
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	for i, v := range f.waiters {
+		if v == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.cond.Broadcast()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// fakeTicker adapts fakeWaiter to cli.Ticker.
+type fakeTicker struct {
+	w *fakeWaiter
+}
+
+func (t fakeTicker) C() <-chan time.Time {
+	return t.w.c
+}
+
+func (t fakeTicker) Stop() {
+	t.w.Stop()
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package clitest_test
+
+import (
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli/clitest"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clitest.NewFakeClock(start)
+
+	timer := clk.NewTimer(time.Second)
+	ticker := clk.NewTicker(400 * time.Millisecond)
+
+	clk.Advance(500 * time.Millisecond)
+
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired early")
+	default:
+	}
+
+	if v := <-ticker.C(); !v.Equal(start.Add(400 * time.Millisecond)) {
+		t.Error("unexpected tick time:", v)
+	}
+
+	clk.Advance(500 * time.Millisecond)
+
+	if v := <-timer.C(); !v.Equal(start.Add(time.Second)) {
+		t.Error("unexpected timer time:", v)
+	}
+
+	if timer.Stop() {
+		t.Error("expected Stop to report the timer fired")
+	}
+
+	if v := clk.Now(); !v.Equal(start.Add(time.Second)) {
+		t.Error("unexpected time:", v)
+	}
+
+	// the tick at 800ms is still buffered
+	<-ticker.C()
+
+	ticker.Stop()
+	clk.Advance(time.Second)
+
+	select {
+	case <-ticker.C():
+		t.Error("ticker fired after Stop")
+	default:
+	}
+}
+
+func TestFakeClockBlockUntil(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	done := make(chan bool)
+
+	go func() {
+		timer := clk.NewTimer(time.Second)
+		<-timer.C()
+		close(done)
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+
+	<-done
+}
@@ -0,0 +1,147 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import "time"
+
+// Clock is a source of time used by the ExitHandler and the helpers
+// built on it, allowing tests to control the passage of time. The
+// default Clock uses the time package.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer returns a Timer which fires once after d has elapsed.
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker returns a Ticker which fires every d.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a single event created by a Clock, as with time.Timer.
+type Timer interface {
+	// C returns the channel on which the time is delivered.
+	C() <-chan time.Time
+
+	// Stop prevents the Timer from firing, returning false if it has
+	// already fired or been stopped.
+	Stop() bool
+}
+
+// Ticker is a repeating event created by a Clock, as with time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+
+	// Stop turns off the Ticker.
+	Stop()
+}
+
+// realClock implements Clock using the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// realTimer adapts time.Timer to Timer.
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// realTicker adapts time.Ticker to Ticker.
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t realTicker) Stop() {
+	t.t.Stop()
+}
+
+// SetClock sets the Clock used for the exit timeout, Every, After, the
+// reload window, WatchFiles, Retry, and the Scheduler, RateLimiter and
+// WorkerPool using the ExitHandler. A nil Clock restores the default.
+// SetClock must be called before any of these are started.
+func (e *ExitHandler) SetClock(clk Clock) {
+	e.clk = clk
+}
+
+// clock returns the Clock of the ExitHandler.
+func (e *ExitHandler) clock() Clock {
+	if e.clk == nil {
+		return realClock{}
+	}
+
+	return e.clk
+}
+
+// SetClock sets the Clock used for timestamps, elapsed times and
+// animations. A nil Clock restores the default. SetClock must be called
+// before any output is written.
+func (tp *TermPrinter) SetClock(clk Clock) {
+	tp.clk = clk
+}
+
+// clock returns the Clock of the TermPrinter.
+func (tp *TermPrinter) clock() Clock {
+	if tp.clk == nil {
+		return realClock{}
+	}
+
+	return tp.clk
+}
+
+// SetClock sets the Clock of both the ExitHandler and the TermPrinter,
+// so that all timing is controlled by clk, and restarts the elapsed
+// time reported by Summary. A nil Clock restores the default.
+func (c *Cmd) SetClock(clk Clock) {
+	c.ExitHandler.SetClock(clk)
+	c.TermPrinter.SetClock(clk)
+
+	c.start = c.clock().Now()
+}
+
+// clock returns the Clock of the Cmd.
+func (c *Cmd) clock() Clock {
+	return c.ExitHandler.clock()
+}
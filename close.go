@@ -0,0 +1,40 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+// Close leaves the TermPrinter in a clean final state. It stops the
+// render loop started by StartLive after drawing a final frame, resumes
+// the live region if it is paused and makes it part of the permanent
+// output, prints the count of any messages suppressed by SetErrorDedup,
+// then stops the write queue, performing any pending writes.
+// The return value is the first write error reported by the queue.
+//
+// The TermPrinter remains usable after Close, writing directly to
+// Stdout and Stderr, and Close may be called more than once. A Cmd
+// calls Close after its other exit hooks have run.
+func (tp *TermPrinter) Close() error {
+	tp.StopLive()
+	tp.FinalizeLive("")
+
+	return tp.StopWriteQueue()
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestClose(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(buf)
+	p.SetLiveRenderer(func() string { return "3 of 3 done\n" })
+	p.StartLive(time.Hour)
+	p.StartWriteQueue(10)
+
+	p.Println("working")
+
+	err := p.Close()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if exp := "working\n3 of 3 done\n"; buf.String() != exp {
+		t.Errorf("expected %q, received %q", exp, buf)
+	}
+
+	err = p.Close()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	p.Println("after")
+
+	if exp := "working\n3 of 3 done\nafter\n"; buf.String() != exp {
+		t.Errorf("expected %q, received %q", exp, buf)
+	}
+}
+
+func TestCmdClose(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+	cmd.StartWriteQueue(10)
+
+	code := cmd.Run(nil, func(context.Context, []string) error {
+		cmd.Println("queued")
+
+		return nil
+	})
+
+	if code != 0 {
+		t.Error("unexpected exit code:", code)
+	}
+
+	if buf.String() != "queued\n" {
+		t.Errorf("unexpected output: %q", buf)
+	}
+}
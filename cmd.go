@@ -24,8 +24,14 @@ package cli
 
 import (
 	"flag"
+	"io"
 	"os"
+	"runtime"
+	"sync"
 	"syscall"
+	"time"
+
+	"github.com/mattn/go-isatty"
 )
 
 // Cmd is a simple structure for building an application. It includes
@@ -36,17 +42,131 @@ type Cmd struct {
 	*TermPrinter
 
 	FlagSet *flag.FlagSet
+
+	stdin  io.Reader
+	utf8In bool
+
+	commands     map[string]*Command
+	helpTopics   map[string]*HelpTopic
+	examples     []Example
+	pluginPrefix string
+	command      string
+
+	shellAliases map[string][]string
+	shellHook    []string
+
+	required    map[string]bool
+	secret      map[string]bool
+	promptFlags bool
+	argFiles    bool
+
+	interspersed bool
+	deadline     time.Duration
+
+	start       time.Time
+	exitReason  string
+	exitCode    int
+	autoSummary bool
+
+	metrics   *Metrics
+	trace     Tracer
+	scheduler *Scheduler
+	checks    []check
+
+	version   string
+	crashDir  string
+	crashCode int
+
+	update         *updateCheck
+	updateInterval time.Duration
+
+	secrets   SecretStore
+	telemetry *Telemetry
+	results   resultChannel
+
+	configFile   string
+	useConfig    bool
+	strictConfig bool
+	envPrefix    string
+
+	// cmdlineFlags are the flags set before the config file was first
+	// loaded, configFlags those set from the config file
+	cmdlineFlags map[string]bool
+	configFlags  map[string]bool
+	configM      sync.Mutex
+
+	flagValues     map[string][]string
+	flagCompleters map[string]func(string) []string
+	flagCategories map[string]string
+	categoryOrder  []string
+
+	sortBy *string
+	filter *string
+	format *string
 }
 
-// NewCmd returns a new initialized Cmd configured with default settings.
-func NewCmd() *Cmd {
-	c := new(Cmd)
+// NewCmd returns a new initialized Cmd configured with default settings,
+// modified by any options given.
+func NewCmd(opts ...Option) *Cmd {
+	c := &Cmd{start: time.Now()}
 	c.ExitHandler = new(ExitHandler)
 	c.TermPrinter = NewTermPrinter()
+	c.SetExitFunc(c.Exit)
+
+	// added first so that it runs after the other exit hooks
+	c.OnExit(func(error) {
+		err := c.Close()
+		if err != nil {
+			_, _ = c.Eprintln("unable to write output:", err)
+		}
+	})
 
 	c.Watch(syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
 
 	c.FlagSet = flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	return c
 }
+
+// SetStdin sets the source of input read by the Cmd, which defaults to
+// os.Stdin.
+func (c *Cmd) SetStdin(r io.Reader) {
+	c.stdin = r
+}
+
+// ExpectPipedStdin returns the source of input for a command which
+// expects input to be piped to it. If the input is a terminal, a hint is
+// printed to Stderr so the user is not left wondering why the command
+// appears to hang. If the Cmd was created WithUTF8, invalid UTF-8 in the
+// input is replaced.
+func (c *Cmd) ExpectPipedStdin() io.Reader {
+	r := c.stdinReader()
+
+	if f, ok := r.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		eof := "Ctrl-D"
+		if runtime.GOOS == "windows" {
+			eof = "Ctrl-Z then Enter"
+		}
+
+		c.Eprintf("reading from terminal; press %s to finish or pipe input\n", eof)
+	}
+
+	if c.utf8In {
+		return NewUTF8Reader(r)
+	}
+
+	return r
+}
+
+// stdinReader returns the source of input.
+func (c *Cmd) stdinReader() io.Reader {
+	if c.stdin == nil {
+		return os.Stdin
+	}
+
+	return c.stdin
+}
@@ -24,8 +24,11 @@
 package cli_test
 
 import (
+	"bytes"
 	"errors"
 	"os"
+	"strings"
+	"testing"
 	"time"
 
 	"kreklow.us/go/cli"
@@ -90,3 +93,33 @@ func Example() {
 	// Message
 	// Cleaned up
 }
+
+func TestExpectPipedStdin(t *testing.T) {
+	cons := newTestConsole(t)
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(errbuf)
+	cmd.SetStdin(cons.Tty())
+
+	if r := cmd.ExpectPipedStdin(); r != cons.Tty() {
+		t.Error("unexpected reader", r)
+	}
+
+	if !strings.HasPrefix(errbuf.String(), "reading from terminal; press Ctrl-") {
+		t.Errorf("unexpected output %q", errbuf)
+	}
+
+	errbuf.Reset()
+
+	in := strings.NewReader("data")
+	cmd.SetStdin(in)
+
+	if r := cmd.ExpectPipedStdin(); r != in {
+		t.Error("unexpected reader", r)
+	}
+
+	if errbuf.Len() != 0 {
+		t.Errorf("unexpected output %q", errbuf)
+	}
+}
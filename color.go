@@ -0,0 +1,133 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"os"
+	"strconv"
+)
+
+// ColorMode determines whether color escape sequences are written to an
+// output stream.
+type ColorMode int
+
+// Color modes accepted by SetColorMode, SetStdoutColorMode and
+// SetStderrColorMode.
+const (
+	// ColorAuto enables color if the stream is a terminal, the NO_COLOR
+	// environment variable is empty and TERM is not "dumb".
+	ColorAuto ColorMode = iota
+
+	// ColorAlways enables color regardless of the stream type.
+	ColorAlways
+
+	// ColorNever disables color.
+	ColorNever
+)
+
+// Color is an ANSI foreground color.
+type Color int
+
+// Colors accepted by Colorize and Ecolorize.
+const (
+	Black Color = iota + 30
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+)
+
+// SetColorMode sets the color mode of both Stdout and Stderr.
+func (tp *TermPrinter) SetColorMode(m ColorMode) {
+	tp.outColor = m
+	tp.errColor = m
+}
+
+// SetStdoutColorMode sets the color mode of Stdout.
+func (tp *TermPrinter) SetStdoutColorMode(m ColorMode) {
+	tp.outColor = m
+}
+
+// SetStderrColorMode sets the color mode of Stderr.
+func (tp *TermPrinter) SetStderrColorMode(m ColorMode) {
+	tp.errColor = m
+}
+
+// OutColor reports whether color is enabled for Stdout.
+func (tp *TermPrinter) OutColor() bool {
+	return colorEnabled(tp.outColor, tp.outIsTerm)
+}
+
+// ErrColor reports whether color is enabled for Stderr.
+func (tp *TermPrinter) ErrColor() bool {
+	return colorEnabled(tp.errColor, tp.errIsTerm)
+}
+
+// Colorize returns s wrapped in the escape sequences for color c if
+// color is enabled for Stdout, otherwise s is returned unchanged.
+func (tp *TermPrinter) Colorize(c Color, s string) string {
+	if !tp.OutColor() {
+		return s
+	}
+
+	return colorize(c, s)
+}
+
+// Ecolorize returns s wrapped in the escape sequences for color c if
+// color is enabled for Stderr, otherwise s is returned unchanged.
+func (tp *TermPrinter) Ecolorize(c Color, s string) string {
+	if !tp.ErrColor() {
+		return s
+	}
+
+	return colorize(c, s)
+}
+
+// colorize wraps s in the escape sequences for color c.
+func colorize(c Color, s string) string {
+	return "\x1b[" + strconv.Itoa(int(c)) + "m" + s + "\x1b[0m"
+}
+
+// colorEnabled resolves m for a stream.
+func colorEnabled(m ColorMode, isTerm bool) bool {
+	switch m {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	case ColorAuto:
+	}
+
+	if !isTerm {
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	return os.Getenv("TERM") != "dumb"
+}
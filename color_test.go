@@ -0,0 +1,92 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestColorMode(t *testing.T) {
+	t.Run("Auto", testColorAuto)
+	t.Run("PerStream", testColorPerStream)
+}
+
+func testColorAuto(t *testing.T) {
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	t.Setenv("TERM", "xterm")
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(new(bytes.Buffer))
+	p.SetStderr(cons.Tty())
+
+	if p.OutColor() {
+		t.Error("expected color disabled on buffer")
+	}
+
+	if !p.ErrColor() {
+		t.Error("expected color enabled on terminal")
+	}
+
+	if s := p.Ecolorize(cli.Red, "error"); s != "\x1b[31merror\x1b[0m" {
+		t.Errorf("unexpected output %q", s)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+
+	if p.ErrColor() {
+		t.Error("expected color disabled by NO_COLOR")
+	}
+}
+
+func testColorPerStream(t *testing.T) {
+	p := cli.NewTermPrinter()
+	p.SetStdout(new(bytes.Buffer))
+	p.SetStderr(new(bytes.Buffer))
+
+	p.SetColorMode(cli.ColorAlways)
+	p.SetStdoutColorMode(cli.ColorNever)
+
+	if s := p.Colorize(cli.Green, "ok"); s != "ok" {
+		t.Errorf("unexpected output %q", s)
+	}
+
+	if s := p.Ecolorize(cli.Green, "ok"); s != "\x1b[32mok\x1b[0m" {
+		t.Errorf("unexpected output %q", s)
+	}
+
+	p.SetStderrColorMode(cli.ColorNever)
+
+	if p.ErrColor() {
+		t.Error("expected color disabled")
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrUnknownCommand is returned by Dispatch when the named subcommand
+// has not been added. The error suggests similarly named subcommands
+// when there are any.
+var ErrUnknownCommand = errors.New("unknown command")
+
+// ErrCommandBusy is returned by Dispatch when an Exclusive command is
+// already running.
+var ErrCommandBusy = errors.New("command already running")
+
+// ErrUnterminatedQuote is returned by SplitArgs when a quote is not
+// closed.
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// Command is a subcommand of a Cmd, with its own flags. A Command may
+// itself have subcommands, forming a group such as "tool remote add".
+type Command struct {
+	// Name is the name used to invoke the command.
+	Name string
+
+	// Summary is a one line description shown in help output.
+	Summary string
+
+	// FlagSet parses the arguments following the command name.
+	FlagSet *flag.FlagSet
+
+	// PersistentFlags holds flags which are accepted by this command
+	// and inherited by all of its subcommands.
+	PersistentFlags *flag.FlagSet
+
+	// Run is called with the arguments remaining after flag parsing.
+	// Run may be nil for a group which only holds subcommands.
+	Run func(args []string) error
+
+	// RunContext, if set, is called in place of Run with a context
+	// which is cancelled when Timeout expires.
+	RunContext func(ctx context.Context, args []string) error
+
+	// Timeout, if positive, limits how long Dispatch waits for Run to
+	// return. When it expires Dispatch returns an error wrapping
+	// ErrDeadline and cancels the context passed to RunContext, which
+	// must honour it and return promptly. A Run function without a
+	// context is left to finish in the background.
+	Timeout time.Duration
+
+	// RecoverPanics makes Dispatch recover a panic in Run, reporting it
+	// to Stderr and returning an *ExitError instead of crashing.
+	RecoverPanics bool
+
+	// Exclusive makes Dispatch return ErrCommandBusy rather than run the
+	// command while an earlier dispatch of it is still running.
+	Exclusive bool
+
+	running  atomic.Bool
+	parent   *Command
+	commands map[string]*Command
+	examples []Example
+}
+
+// newCommand returns a Command with initialized flag sets.
+func newCommand(name string, summary string, fn func(args []string) error) *Command {
+	return &Command{
+		Name:            name,
+		Summary:         summary,
+		FlagSet:         flag.NewFlagSet(name, flag.ContinueOnError),
+		PersistentFlags: flag.NewFlagSet(name, flag.ContinueOnError),
+		Run:             fn,
+	}
+}
+
+// AddCommand adds a subcommand which runs fn, returning the Command so
+// flags may be added to its FlagSet.
+func (c *Cmd) AddCommand(name string, summary string, fn func(args []string) error) *Command {
+	cmd := newCommand(name, summary, fn)
+
+	if c.commands == nil {
+		c.commands = make(map[string]*Command)
+	}
+
+	c.commands[name] = cmd
+
+	return cmd
+}
+
+// AddCommand adds a subcommand of cmd which runs fn, returning the new
+// Command. The subcommand inherits the PersistentFlags of cmd and its
+// parents.
+func (cmd *Command) AddCommand(name string, summary string, fn func(args []string) error) *Command {
+	sub := newCommand(name, summary, fn)
+	sub.parent = cmd
+
+	if cmd.commands == nil {
+		cmd.commands = make(map[string]*Command)
+	}
+
+	cmd.commands[name] = sub
+
+	return sub
+}
+
+// Commands returns the subcommands sorted by name.
+func (c *Cmd) Commands() []*Command {
+	return sortCommands(c.commands)
+}
+
+// Commands returns the subcommands of cmd sorted by name.
+func (cmd *Command) Commands() []*Command {
+	return sortCommands(cmd.commands)
+}
+
+// Path returns the names of cmd and its parents separated by spaces,
+// such as "remote add".
+func (cmd *Command) Path() string {
+	if cmd.parent == nil {
+		return cmd.Name
+	}
+
+	return cmd.parent.Path() + " " + cmd.Name
+}
+
+// sortCommands returns the commands in m sorted by name.
+func sortCommands(m map[string]*Command) []*Command {
+	cmds := make([]*Command, 0, len(m))
+
+	for _, cmd := range m {
+		cmds = append(cmds, cmd)
+	}
+
+	sort.Slice(cmds, func(i, j int) bool {
+		return cmds[i].Name < cmds[j].Name
+	})
+
+	return cmds
+}
+
+// Dispatch runs the subcommand named by the first element of args,
+// parsing the remaining elements with the FlagSet of the subcommand.
+// If the subcommand has subcommands of its own, the first argument
+// remaining after flag parsing selects one of them, and so on down the
+// tree. Typically args is the result of calling Args on the Cmd FlagSet
+// after parsing the global flags. Unknown subcommands are run as
+// plugins if EnablePlugins has been called.
+func (c *Cmd) Dispatch(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: no command given", ErrUnknownCommand)
+	}
+
+	cmd, ok := c.commands[args[0]]
+	if !ok {
+		if path := c.lookPlugin(args[0]); path != "" {
+			return c.runPlugin(path, args[1:])
+		}
+
+		return unknownCommand(args[0], c.Commands())
+	}
+
+	for {
+		cmd.inheritFlags()
+
+		err := c.parseFlags(cmd.FlagSet, args[1:], cmd.isCommand)
+		if err != nil {
+			return err
+		}
+
+		args = cmd.FlagSet.Args()
+
+		if len(cmd.commands) == 0 {
+			break
+		}
+
+		if len(args) == 0 {
+			if cmd.runnable() {
+				break
+			}
+
+			return fmt.Errorf("%w: no command given to '%s'",
+				ErrUnknownCommand, cmd.Path())
+		}
+
+		sub, ok := cmd.commands[args[0]]
+		if !ok {
+			if cmd.runnable() {
+				break
+			}
+
+			return unknownCommand(args[0], cmd.Commands())
+		}
+
+		cmd = sub
+	}
+
+	c.command = cmd.Path()
+
+	return c.runCommand(cmd, args)
+}
+
+// runnable reports whether cmd has a Run or RunContext function.
+func (cmd *Command) runnable() bool {
+	return cmd.Run != nil || cmd.RunContext != nil
+}
+
+// isCommand reports whether name is a subcommand of cmd.
+func (cmd *Command) isCommand(name string) bool {
+	_, ok := cmd.commands[name]
+
+	return ok
+}
+
+// inheritFlags adds the PersistentFlags of cmd and its parents to the
+// FlagSet of cmd, unless a flag of the same name is already defined.
+func (cmd *Command) inheritFlags() {
+	for p := cmd; p != nil; p = p.parent {
+		p.PersistentFlags.VisitAll(func(f *flag.Flag) {
+			if cmd.FlagSet.Lookup(f.Name) == nil {
+				cmd.FlagSet.Var(f.Value, f.Name, f.Usage)
+			}
+		})
+	}
+}
+
+// PrintCommands prints the tree of subcommands with their summaries,
+// indenting the subcommands of each group beneath it, followed by the
+// help topics added with AddHelpTopic.
+func (c *Cmd) PrintCommands() {
+	cmds := c.Commands()
+
+	w := max(commandWidth(cmds, 0), c.topicWidth())
+
+	c.printCommands(cmds, 0, w)
+	c.printHelpTopics(w)
+}
+
+// printCommands prints cmds and their subcommands at the given depth,
+// padding names to width w.
+func (c *Cmd) printCommands(cmds []*Command, depth int, w int) {
+	for _, cmd := range cmds {
+		name := strings.Repeat("  ", depth+1) + cmd.Name
+
+		if cmd.Summary == "" {
+			c.Println(name)
+		} else {
+			c.Printf("%s  %s\n", padRight(name, w), cmd.Summary)
+		}
+
+		c.printCommands(cmd.Commands(), depth+1, w)
+	}
+}
+
+// commandWidth returns the width of the longest indented name in the
+// tree of cmds.
+func commandWidth(cmds []*Command, depth int) int {
+	var w int
+
+	for _, cmd := range cmds {
+		w = max(w, 2*(depth+1)+textWidth(cmd.Name),
+			commandWidth(cmd.Commands(), depth+1))
+	}
+
+	return w
+}
+
+// SplitArgs splits line into arguments at spaces in the manner of a
+// shell. Single quotes preserve their contents literally, while within
+// double quotes and unquoted text a backslash escapes the following
+// character.
+func SplitArgs(line string) ([]string, error) {
+	var (
+		args  []string
+		arg   strings.Builder
+		inArg bool
+		quote rune
+		esc   bool
+	)
+
+	for _, r := range line {
+		switch {
+		case esc:
+			arg.WriteRune(r)
+
+			esc = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			esc = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+
+			inArg = true
+		}
+	}
+
+	if quote != 0 || esc {
+		return nil, ErrUnterminatedQuote
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func ExampleCmd_Dispatch() {
+	cmd := cli.NewCmd()
+
+	add := cmd.AddCommand("add", "add a remote", nil)
+	url := add.FlagSet.String("url", "", "remote URL")
+	add.Run = func(args []string) error {
+		cmd.Printf("adding %s at %s\n", args[0], *url)
+
+		return nil
+	}
+
+	err := cmd.FlagSet.Parse([]string{"add", "-url", "https://example.com", "origin"})
+	if err != nil {
+		cmd.Eprintln(err)
+	}
+
+	err = cmd.Dispatch(cmd.FlagSet.Args())
+	if err != nil {
+		cmd.Eprintln(err)
+	}
+
+	// Output:
+	// adding origin at https://example.com
+}
+
+func TestDispatch(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStderr(new(bytes.Buffer))
+
+	cmd.AddCommand("b", "second", func([]string) error { return errTest })
+	cmd.AddCommand("a", "first", func([]string) error { return nil })
+
+	if c := cmd.Commands(); len(c) != 2 || c[0].Name != "a" || c[1].Name != "b" {
+		t.Error("unexpected commands", c)
+	}
+
+	err := cmd.Dispatch([]string{"b"})
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.Dispatch([]string{"c"})
+	if !errors.Is(err, cli.ErrUnknownCommand) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.Dispatch(nil)
+	if !errors.Is(err, cli.ErrUnknownCommand) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.Dispatch([]string{"a", "-bogus"})
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+}
+
+func TestDispatchSuggest(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	for _, name := range []string{"install", "status", "remove", "list"} {
+		cmd.AddCommand(name, "", func([]string) error { return nil })
+	}
+
+	tests := []struct {
+		in  string
+		exp string
+	}{
+		{"instal", "unknown command 'instal'; did you mean 'install'?"},
+		{"stauts", "unknown command 'stauts'; did you mean 'status'?"},
+		{"li", "unknown command 'li'; did you mean 'list'?"},
+		{"lst", "unknown command 'lst'; did you mean 'list'?"},
+		{"zzzzzz", "unknown command 'zzzzzz'"},
+	}
+
+	for _, tc := range tests {
+		err := cmd.Dispatch([]string{tc.in})
+		if !errors.Is(err, cli.ErrUnknownCommand) {
+			t.Errorf("%s: unexpected error: %v", tc.in, err)
+
+			continue
+		}
+
+		if err.Error() != tc.exp {
+			t.Errorf("expected %q, received %q", tc.exp, err)
+		}
+	}
+}
+
+func TestCommandGroups(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	var got []string
+
+	remote := cmd.AddCommand("remote", "manage remotes", nil)
+	verbose := remote.PersistentFlags.Bool("v", false, "verbose output")
+
+	add := remote.AddCommand("add", "add a remote", func(args []string) error {
+		got = args
+
+		return nil
+	})
+	force := add.FlagSet.Bool("f", false, "replace existing")
+
+	remote.AddCommand("remove", "remove a remote", func([]string) error { return errTest })
+	cmd.AddCommand("status", "show status", func([]string) error { return nil })
+
+	err := cmd.Dispatch([]string{"remote", "-v", "add", "-f", "origin", "url"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !*verbose || !*force || len(got) != 2 || got[0] != "origin" {
+		t.Errorf("unexpected result %v %v %q", *verbose, *force, got)
+	}
+
+	*verbose = false
+
+	err = cmd.Dispatch([]string{"remote", "add", "-v", "origin"})
+	if err != nil || !*verbose {
+		t.Errorf("expected inherited flag, received %v %v", err, *verbose)
+	}
+
+	err = cmd.Dispatch([]string{"remote", "remove"})
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.Dispatch([]string{"remote"})
+	if !errors.Is(err, cli.ErrUnknownCommand) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.Dispatch([]string{"remote", "ad"})
+	if err == nil || err.Error() != "unknown command 'ad'; did you mean 'add'?" {
+		t.Error("unexpected error:", err)
+	}
+
+	if p := add.Path(); p != "remote add" {
+		t.Errorf("expected %q, received %q", "remote add", p)
+	}
+
+	buf := new(bytes.Buffer)
+	cmd.SetStdout(buf)
+	cmd.PrintCommands()
+
+	exp := "  remote    manage remotes\n" +
+		"    add     add a remote\n" +
+		"    remove  remove a remote\n" +
+		"  status    show status\n"
+
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\nreceived:\n%s", exp, buf)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp []string
+	}{
+		{"", nil},
+		{"  one  two ", []string{"one", "two"}},
+		{`say "hello world" 'it''s' a\ b`, []string{"say", "hello world", "its", "a b"}},
+		{`"" 'a\b' "c\"d"`, []string{"", `a\b`, `c"d`}},
+	}
+
+	for _, tc := range tests {
+		args, err := cli.SplitArgs(tc.in)
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+
+		if strings.Join(args, "|") != strings.Join(tc.exp, "|") || len(args) != len(tc.exp) {
+			t.Errorf("unexpected result for %q: %q", tc.in, args)
+		}
+	}
+
+	for _, in := range []string{`"open`, `'open`, `trailing\`} {
+		_, err := cli.SplitArgs(in)
+		if !errors.Is(err, cli.ErrUnterminatedQuote) {
+			t.Error("unexpected error:", err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrConfig is returned when the config file cannot be parsed.
+var ErrConfig = errors.New("invalid config")
+
+// WithConfigFile makes Parse read flag values from the config file
+// named by ConfigFile. Flags given on the command line take precedence
+// over the config file.
+func WithConfigFile() Option {
+	return func(c *Cmd) {
+		c.useConfig = true
+	}
+}
+
+// SetConfigFile sets the path of the config file and enables reading
+// it as with WithConfigFile.
+func (c *Cmd) SetConfigFile(path string) {
+	c.configFile = path
+	c.useConfig = true
+}
+
+// ConfigFile returns the path of the config file, which defaults to
+// the file "config" in the ConfigDir of the application. The directory
+// is not created. An empty string is returned if the path cannot be
+// determined.
+func (c *Cmd) ConfigFile() string {
+	if c.configFile != "" {
+		return c.configFile
+	}
+
+	dir, err := configDirPath(filepath.Base(c.FlagSet.Name()))
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "config")
+}
+
+// LoadConfig sets flags of the FlagSet from the config file, skipping
+// those which are already set. Each line of the file holds a flag name
+// and value separated by "=", and lines beginning with "#" are ignored.
+// Values may be quoted in the manner of strconv.Quote. Names which are
+// not flags are ignored, unless the Cmd was created WithStrictConfig. A
+// missing config file is not an error.
+func (c *Cmd) LoadConfig() error {
+	path := c.ConfigFile()
+	if path == "" {
+		return nil
+	}
+
+	entries, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+
+	err = c.strictEntries(path, entries)
+	if err != nil {
+		return err
+	}
+
+	c.recordCmdlineFlags()
+
+	for _, e := range entries {
+		if c.cmdlineFlags[e.name] || c.FlagSet.Lookup(e.name) == nil {
+			continue
+		}
+
+		err = c.FlagSet.Set(e.name, e.value)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%d: %w", ErrConfig, path, e.line, err)
+		}
+
+		c.configFlags[e.name] = true
+	}
+
+	return nil
+}
+
+// recordCmdlineFlags records the flags which are set, once, before the
+// config file first sets any.
+func (c *Cmd) recordCmdlineFlags() {
+	if c.cmdlineFlags != nil {
+		return
+	}
+
+	c.cmdlineFlags = make(map[string]bool)
+	c.configFlags = make(map[string]bool)
+
+	c.FlagSet.Visit(func(f *flag.Flag) {
+		c.cmdlineFlags[f.Name] = true
+	})
+}
+
+// configEntry is a setting read from a config file.
+type configEntry struct {
+	name  string
+	value string
+	line  int
+}
+
+// readConfig returns the settings in the config file at path, in the
+// order in which they appear. A missing file holds no settings.
+func readConfig(path string) ([]configEntry, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var entries []configEntry
+
+	s := bufio.NewScanner(f)
+
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s:%d: expected name = value", ErrConfig, path, n)
+		}
+
+		v, err = unquoteConfig(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %w", ErrConfig, path, n, err)
+		}
+
+		entries = append(entries, configEntry{name: strings.TrimSpace(name), value: v, line: n})
+	}
+
+	return entries, s.Err()
+}
+
+// SaveConfig writes the flags of the FlagSet which have been set to the
+// config file, replacing its contents and creating its directory if
+// necessary. Flags marked secret are not saved.
+func (c *Cmd) SaveConfig() error {
+	path := c.ConfigFile()
+	if path == "" {
+		return ErrNoHome
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s configuration\n", filepath.Base(c.FlagSet.Name()))
+
+	c.FlagSet.Visit(func(f *flag.Flag) {
+		if !c.secret[f.Name] {
+			fmt.Fprintf(&sb, "%s = %s\n", f.Name, quoteConfig(f.Value.String()))
+		}
+	})
+
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, strings.NewReader(sb.String()))
+}
+
+// quoteConfig quotes v if it would not otherwise be read back intact.
+func quoteConfig(v string) string {
+	if v != strings.TrimSpace(v) || strings.HasPrefix(v, `"`) ||
+		strings.ContainsAny(v, "\r\n") {
+		return strconv.Quote(v)
+	}
+
+	return v
+}
+
+// unquoteConfig removes the quotes from v if it is quoted.
+func unquoteConfig(v string) (string, error) {
+	if !strings.HasPrefix(v, `"`) {
+		return v, nil
+	}
+
+	return strconv.Unquote(v)
+}
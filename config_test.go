@@ -0,0 +1,120 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	err := os.WriteFile(path, []byte("# comment\nname = from config\nlevel=3\nunknown = x\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd()
+	cmd.SetConfigFile(path)
+
+	name := cmd.FlagSet.String("name", "", "name")
+	level := cmd.FlagSet.Int("level", 0, "level")
+
+	err = cmd.Parse([]string{"-level", "5"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *name != "from config" || *level != 5 {
+		t.Errorf("unexpected values %q, %d", *name, *level)
+	}
+
+	err = os.WriteFile(path, []byte("level = high\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd = cli.NewCmd(cli.WithConfigFile())
+	cmd.SetConfigFile(path)
+	cmd.FlagSet.Int("level", 0, "level")
+
+	err = cmd.Parse(nil)
+	if !errors.Is(err, cli.ErrConfig) {
+		t.Error("expected ErrConfig, received", err)
+	}
+}
+
+func TestFirstRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app", "config")
+
+	for i, in := range []string{"example.com\nhunter2\n", ""} {
+		cmd := cli.NewCmd()
+		cmd.SetStdout(new(bytes.Buffer))
+		cmd.SetStdin(strings.NewReader(in))
+		cmd.SetConfigFile(path)
+
+		server := cmd.FlagSet.String("server", "", "server address")
+		cmd.FlagSet.String("password", "", "password")
+		cmd.MarkSecret("password")
+
+		err := cmd.Parse(nil)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		var called bool
+
+		err = cmd.FirstRun(func() error {
+			called = true
+
+			return cmd.PromptFlags("server", "password")
+		})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if called != (i == 0) {
+			t.Errorf("run %d: unexpected call to setup: %v", i, called)
+		}
+
+		if *server != "example.com" {
+			t.Errorf("run %d: expected %q, received %q", i, "example.com", *server)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if strings.Contains(string(b), "hunter2") {
+		t.Errorf("expected secret not to be saved, received:\n%s", b)
+	}
+}
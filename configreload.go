@@ -0,0 +1,182 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+)
+
+// Config holds the settings of the config file, keyed by flag name. It
+// includes names which are not flags.
+type Config map[string]string
+
+// OnConfigReload makes SIGHUP, calls to Reload and, once WatchConfig is
+// called, changes to the file re-read the config file named by
+// ConfigFile, updating the flags it sets. Flags given on the command
+// line keep their values, and flags which were set by the config file
+// but no longer are return to their defaults.
+//
+// The change is applied atomically: every value is checked as Parse
+// would before any flag is changed, then fn is called with the new
+// settings so the application can act on them. If a value is invalid
+// or fn returns an error, the flags keep their previous values and the
+// error is printed with Errorf, leaving the application running.
+//
+// OnConfigReload uses OnReload, replacing any function set with it.
+func (c *Cmd) OnConfigReload(fn func(cfg Config) error) {
+	c.OnReload(func() error {
+		return c.reloadConfig(fn)
+	}, func(err error) {
+		c.Errorf("config reload failed: %v", err)
+	})
+}
+
+// WatchConfig requests a reload whenever the config file changes, for
+// use with OnConfigReload. See WatchFiles.
+func (c *Cmd) WatchConfig() error {
+	return c.WatchFiles([]string{c.ConfigFile()}, func(FileEvent) {
+		c.Reload()
+	})
+}
+
+// reloadConfig implements OnConfigReload.
+func (c *Cmd) reloadConfig(fn func(cfg Config) error) error {
+	path := c.ConfigFile()
+	if path == "" {
+		return ErrNoHome
+	}
+
+	entries, err := readConfig(path)
+	if err == nil {
+		err = c.strictEntries(path, entries)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.configM.Lock()
+	defer c.configM.Unlock()
+
+	c.recordCmdlineFlags()
+
+	cfg := make(Config, len(entries))
+	want := make(map[string]string)
+
+	for name := range c.configFlags {
+		want[name] = c.FlagSet.Lookup(name).DefValue
+	}
+
+	for _, e := range entries {
+		cfg[e.name] = e.value
+
+		f := c.FlagSet.Lookup(e.name)
+		if f == nil || c.cmdlineFlags[e.name] {
+			continue
+		}
+
+		err = checkFlagValue(f, e.value)
+		if err == nil {
+			err = c.validateFlag(e.name, e.value)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %s:%d: %w", ErrConfig, path, e.line, err)
+		}
+
+		want[e.name] = e.value
+	}
+
+	prev := make(map[string]string)
+
+	restore := func() {
+		for name, v := range prev {
+			_ = c.FlagSet.Set(name, v)
+		}
+	}
+
+	for name, v := range want {
+		f := c.FlagSet.Lookup(name)
+		if old := f.Value.String(); old != v {
+			prev[name] = old
+
+			err = c.FlagSet.Set(name, v)
+			if err != nil {
+				restore()
+
+				return fmt.Errorf("%w: %s: -%s: %w", ErrConfig, path, name, err)
+			}
+		}
+	}
+
+	if fn != nil {
+		err = fn(cfg)
+		if err != nil {
+			restore()
+
+			return err
+		}
+	}
+
+	c.configFlags = make(map[string]bool)
+
+	for _, e := range entries {
+		if _, ok := want[e.name]; ok {
+			c.configFlags[e.name] = true
+		}
+	}
+
+	return nil
+}
+
+// checkFlagValue reports whether v can be set as the value of f,
+// without changing it, where the type of its value allows a scratch
+// copy to be made. Values which cannot be set while zero are not
+// checked.
+func checkFlagValue(f *flag.Flag, v string) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = nil
+		}
+	}()
+
+	t := reflect.TypeOf(f.Value)
+	if t.Kind() != reflect.Pointer {
+		return nil
+	}
+
+	scratch, ok := reflect.New(t.Elem()).Interface().(flag.Value)
+	if !ok {
+		return nil
+	}
+
+	err = scratch.Set(v)
+	if err != nil {
+		// worded as by flag.FlagSet.Set
+		return fmt.Errorf("invalid value %q for flag -%s: %w", v, f.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestOnConfigReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	write := func(s string) {
+		t.Helper()
+
+		err := os.WriteFile(path, []byte(s), 0o600)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	write("workers = 4\nlevel = info\n")
+
+	pr, pw := io.Pipe()
+	errs := bufio.NewReader(pr)
+
+	cmd := cli.NewCmd()
+	cmd.SetConfigFile(path)
+	cmd.SetStderr(pw)
+	cmd.SetReloadWindow(time.Millisecond)
+
+	level := cmd.FlagSet.String("level", "warn", "log level")
+	workers := cmd.FlagSet.Int("workers", 1, "number of workers")
+
+	err := cmd.Parse([]string{"-level", "debug"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *level != "debug" || *workers != 4 {
+		t.Fatalf("unexpected flags %s %d", *level, *workers)
+	}
+
+	applied := make(chan cli.Config)
+
+	var fail error
+
+	cmd.OnConfigReload(func(cfg cli.Config) error {
+		if fail != nil {
+			return fail
+		}
+
+		applied <- cfg
+
+		return nil
+	})
+
+	expectError := func(substr string) {
+		t.Helper()
+
+		line, err := errs.ReadString('\n')
+		if err != nil || !strings.Contains(line, substr) {
+			t.Errorf("expected error containing %q, received %q %v", substr, line, err)
+		}
+	}
+
+	write("workers = 8\nlevel = info\nextra = 1\n")
+	cmd.Reload()
+
+	cfg := <-applied
+	if cfg["extra"] != "1" || *workers != 8 || *level != "debug" {
+		t.Errorf("unexpected reload %v %s %d", cfg, *level, *workers)
+	}
+
+	write("workers = 16\nlevel = x\nworkers = many\n")
+	cmd.Reload()
+	expectError(path + ":3: invalid value")
+
+	if *workers != 8 {
+		t.Error("expected workers unchanged after invalid config, received", *workers)
+	}
+
+	fail = errTest
+	write("")
+	cmd.Reload()
+	expectError("config reload failed: testing error")
+
+	if *workers != 8 {
+		t.Error("expected workers restored after failure, received", *workers)
+	}
+
+	fail = nil
+	cmd.Reload()
+	<-applied
+
+	if *workers != 1 || *level != "debug" {
+		t.Errorf("expected default after removal, received %s %d", *level, *workers)
+	}
+
+	cmd.Exit(nil)
+	_ = cmd.Wait()
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build unix
+
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestOnConfigReloadSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	err := os.WriteFile(path, []byte("workers = 4\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd()
+	cmd.SetConfigFile(path)
+	cmd.SetReloadWindow(time.Millisecond)
+
+	workers := cmd.FlagSet.Int("workers", 1, "number of workers")
+
+	err = cmd.Parse(nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	applied := make(chan bool, 1)
+
+	cmd.OnConfigReload(func(cli.Config) error {
+		applied <- true
+
+		return nil
+	})
+
+	err = os.WriteFile(path, []byte("workers = 8\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	select {
+	case <-applied:
+	case <-cmd.C:
+		t.Fatal("SIGHUP closed the exit channel")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	if *workers != 8 {
+		t.Error("expected 8 workers, received", *workers)
+	}
+
+	select {
+	case <-cmd.C:
+		t.Error("SIGHUP closed the exit channel")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cmd.Exit(nil)
+
+	err = cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build windows
+
+package cli
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// codePageUTF8 is the Windows code page identifier for UTF-8.
+const codePageUTF8 = 65001
+
+//nolint:gochecknoglobals // lazily loaded system procedures
+var (
+	kernel32               = windows.NewLazySystemDLL("kernel32.dll")
+	procSetConsoleCP       = kernel32.NewProc("SetConsoleCP")
+	procSetConsoleOutputCP = kernel32.NewProc("SetConsoleOutputCP")
+)
+
+// setConsoleUTF8 switches the input and output code pages of the
+// console to UTF-8. Errors are ignored, as there may be no console.
+func setConsoleUTF8() {
+	_, _, _ = procSetConsoleCP.Call(codePageUTF8)
+	_, _, _ = procSetConsoleOutputCP.Call(codePageUTF8)
+}
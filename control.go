@@ -0,0 +1,371 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrControl is returned by CallControl when the control socket returns
+// an error.
+var ErrControl = errors.New("control request failed")
+
+// JSON-RPC 2.0 error codes returned by the control socket.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcServerError    = -32000
+)
+
+// ControlOptions configures the control socket started by ServeControl.
+type ControlOptions struct {
+	// OnPause is called by the pause method, after the live region is
+	// paused, so the application can suspend its work. An error is
+	// returned to the caller.
+	OnPause func() error
+
+	// OnResume is called by the resume method, before the live region
+	// is resumed.
+	OnResume func() error
+}
+
+// controlServer is the state of a control socket.
+type controlServer struct {
+	c    *Cmd
+	opts ControlOptions
+
+	m      sync.Mutex
+	paused bool
+	conns  map[net.Conn]bool
+}
+
+// rpcRequest is a JSON-RPC 2.0 request.
+type rpcRequest struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response.
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC 2.0 response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ServeControl listens on a Unix socket at path, through which another
+// process can control the running Cmd, returning the address it is
+// listening on. Requests are JSON-RPC 2.0 objects, one per line, and
+// the methods are:
+//
+//   - status returns the document served by ServeStatus, along with
+//     whether the Cmd is paused
+//   - pause pauses the live region and calls opts.OnPause
+//   - resume calls opts.OnResume and resumes the live region
+//   - shutdown calls Exit, as on receiving SIGTERM
+//
+// A stale socket left at path is replaced. The socket is only
+// accessible to the current user, and is removed when the exit channel
+// closes. CallControl can be used to send requests.
+func (c *Cmd) ServeControl(path string, opts ControlOptions) (net.Addr, error) {
+	if fi, err := os.Lstat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("%w: %s", os.ErrExist, path)
+		}
+
+		_ = os.Remove(path)
+	}
+
+	l, err := listenPrivate(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.snapshotEnabled() {
+		c.SetSnapshotSize(defaultStatusLines)
+	}
+
+	cs := &controlServer{c: c, opts: opts, conns: make(map[net.Conn]bool)}
+
+	c.Add(1)
+
+	go func() {
+		defer c.Done()
+
+		<-c.C
+
+		l.Close()
+		_ = os.Remove(path)
+		cs.closeAll()
+	}()
+
+	go cs.serve(l)
+
+	return &net.UnixAddr{Name: path, Net: "unix"}, nil
+}
+
+// listenPrivate listens on a Unix socket at path which only the current
+// user can connect to. The socket is created in a new directory which
+// only the current user can enter, then linked into place, so it is
+// never reachable with wider permissions. An existing file at path is
+// left unchanged and results in an error.
+func listenPrivate(path string) (*net.UnixListener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".ctl")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+
+	l.SetUnlinkOnClose(false)
+
+	err = os.Chmod(tmp, 0o600)
+	if err == nil {
+		err = os.Link(tmp, path)
+	}
+
+	if err != nil {
+		l.Close()
+
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// serve accepts connections until l is closed.
+func (cs *controlServer) serve(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		cs.m.Lock()
+		cs.conns[conn] = true
+		cs.m.Unlock()
+
+		go cs.handle(conn)
+	}
+}
+
+// closeAll closes the open connections.
+func (cs *controlServer) closeAll() {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	for conn := range cs.conns {
+		conn.Close()
+	}
+}
+
+// handle answers the requests received on conn.
+func (cs *controlServer) handle(conn net.Conn) {
+	defer func() {
+		cs.m.Lock()
+		delete(cs.conns, conn)
+		cs.m.Unlock()
+
+		conn.Close()
+	}()
+
+	s := bufio.NewScanner(conn)
+	enc := json.NewEncoder(conn)
+
+	for s.Scan() {
+		res := cs.call(s.Bytes())
+		if res == nil {
+			continue
+		}
+
+		err := enc.Encode(res)
+		if err != nil {
+			return
+		}
+	}
+}
+
+// call answers a request, returning nil for a notification.
+func (cs *controlServer) call(b []byte) *rpcResponse {
+	var req rpcRequest
+
+	err := json.Unmarshal(b, &req)
+	if err != nil {
+		return rpcFail(nil, rpcParseError, err.Error())
+	}
+
+	if req.Version != "2.0" || req.Method == "" {
+		return rpcFail(req.ID, rpcInvalidRequest, "invalid request")
+	}
+
+	var result interface{}
+
+	switch req.Method {
+	case "status":
+		result = cs.status()
+	case "pause":
+		err = cs.setPaused(true)
+	case "resume":
+		err = cs.setPaused(false)
+	case "shutdown":
+		cs.c.Exit(nil)
+	default:
+		return rpcFail(req.ID, rpcMethodNotFound, "method not found: "+req.Method)
+	}
+
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	if err != nil {
+		return rpcFail(req.ID, rpcServerError, err.Error())
+	}
+
+	if result == nil {
+		result = true
+	}
+
+	return &rpcResponse{Version: "2.0", ID: req.ID, Result: result}
+}
+
+// status returns the status document of the status method.
+func (cs *controlServer) status() interface{} {
+	cs.m.Lock()
+	paused := cs.paused
+	cs.m.Unlock()
+
+	return struct {
+		statusReport
+		Paused bool `json:"paused"`
+	}{cs.c.statusReport(), paused}
+}
+
+// setPaused pauses or resumes the Cmd.
+func (cs *controlServer) setPaused(paused bool) error {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	if cs.paused == paused {
+		return nil
+	}
+
+	if paused {
+		cs.c.PauseLive()
+
+		if cs.opts.OnPause != nil {
+			err := cs.opts.OnPause()
+			if err != nil {
+				cs.c.ResumeLive()
+
+				return err
+			}
+		}
+	} else {
+		if cs.opts.OnResume != nil {
+			err := cs.opts.OnResume()
+			if err != nil {
+				return err
+			}
+		}
+
+		cs.c.ResumeLive()
+	}
+
+	cs.paused = paused
+
+	return nil
+}
+
+// rpcFail returns an error response.
+func rpcFail(id json.RawMessage, code int, msg string) *rpcResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &rpcResponse{Version: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
+
+// CallControl sends a request for method to the control socket at path
+// started by ServeControl, returning the result. An error returned by
+// the socket is wrapped in ErrControl.
+func CallControl(ctx context.Context, path string, method string) (json.RawMessage, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	err = json.NewEncoder(conn).Encode(rpcRequest{Version: "2.0", ID: json.RawMessage("1"), Method: method})
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+
+	err = json.NewDecoder(conn).Decode(&res)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Error != nil {
+		return nil, fmt.Errorf("%w: %s", ErrControl, res.Error.Message)
+	}
+
+	return res.Result, nil
+}
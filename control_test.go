@@ -0,0 +1,156 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestServeControl(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	var paused, resumed int
+
+	path := filepath.Join(t.TempDir(), "control.sock")
+
+	_, err := cmd.ServeControl(path, cli.ControlOptions{
+		OnPause:  func() error { paused++; return nil },
+		OnResume: func() error { resumed++; return nil },
+	})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	_, err = cmd.ServeControl(path, cli.ControlOptions{})
+	if err == nil {
+		t.Error("expected error for socket in use")
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("unexpected socket %v %v", fi.Mode(), err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("expected only the socket, received %d entries", len(entries))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cmd.Println("hello")
+
+	call := func(method string) json.RawMessage {
+		t.Helper()
+
+		res, err := cli.CallControl(ctx, path, method)
+		if err != nil {
+			t.Fatalf("unexpected error calling %s: %v", method, err)
+		}
+
+		return res
+	}
+
+	call("pause")
+	call("pause")
+
+	if !cmd.LivePaused() || paused != 1 {
+		t.Errorf("expected paused once, received %t %d", cmd.LivePaused(), paused)
+	}
+
+	var doc struct {
+		statusDoc
+		Paused bool `json:"paused"`
+	}
+
+	err = json.Unmarshal(call("status"), &doc)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !doc.Paused || len(doc.Lines) != 1 || doc.Lines[0].Text != "hello" {
+		t.Errorf("unexpected status %+v", doc)
+	}
+
+	call("resume")
+
+	if cmd.LivePaused() || resumed != 1 {
+		t.Errorf("expected resumed once, received %t %d", cmd.LivePaused(), resumed)
+	}
+
+	_, err = cli.CallControl(ctx, path, "bogus")
+	if !errors.Is(err, cli.ErrControl) {
+		t.Error("expected ErrControl, received", err)
+	}
+
+	call("shutdown")
+
+	err = cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	_, err = cli.CallControl(ctx, path, "status")
+	if err == nil {
+		t.Error("expected error after exit")
+	}
+
+	_, err = os.Stat(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected socket removed, received", err)
+	}
+}
+
+func TestServeControlExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.sock")
+
+	err := os.WriteFile(path, []byte("data"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd()
+
+	_, err = cmd.ServeControl(path, cli.ControlOptions{})
+	if !errors.Is(err, os.ErrExist) {
+		t.Error("expected ErrExist, received", err)
+	}
+
+	b, _ := os.ReadFile(path)
+	if string(b) != "data" {
+		t.Errorf("expected file unchanged, received %q", b)
+	}
+
+	cmd.Exit(nil)
+}
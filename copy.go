@@ -0,0 +1,87 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// CopySummary describes a copy made by Copy.
+type CopySummary struct {
+	// Bytes is the number of bytes copied.
+	Bytes int64
+
+	// Elapsed is the duration of the copy.
+	Elapsed time.Duration
+}
+
+// Rate returns the average throughput in bytes per second.
+func (cs CopySummary) Rate() float64 {
+	if cs.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(cs.Bytes) / cs.Elapsed.Seconds()
+}
+
+// Copy copies from src to dst as with io.Copy, showing a live progress
+// line with the bytes copied and throughput. If total is positive, the
+// line also shows a bar, percentage and estimated time remaining. Once
+// the copy ends, the line is replaced by a summary, or by the error.
+// If the exit channel closes, the copy stops and ErrInterrupted is
+// returned.
+func (c *Cmd) Copy(dst io.Writer, src io.Reader, total int64) (CopySummary, error) {
+	return c.copyProgress("copy", dst, src, total, func(cs CopySummary) string {
+		return fmt.Sprintf("copied %s in %s  %s/s", c.FormatBytes(cs.Bytes),
+			cs.Elapsed.Round(time.Millisecond), c.FormatBytes(int64(cs.Rate())))
+	})
+}
+
+// copyProgress implements Copy, showing progress with label and
+// replacing the line with the result of done once the copy succeeds.
+func (c *Cmd) copyProgress(label string, dst io.Writer, src io.Reader, total int64,
+	done func(CopySummary) string,
+) (CopySummary, error) {
+	pw := newProgressWriter(c.TermPrinter, label, 0, total)
+
+	n, err := io.Copy(dst, io.TeeReader(exitReader{c: c, r: src}, pw))
+
+	cs := CopySummary{Bytes: n, Elapsed: pw.tp.now().Sub(pw.start)}
+
+	if err != nil {
+		if c.exiting() {
+			err = ErrInterrupted
+		}
+
+		c.FinalizeLive(fmt.Sprintf("%s: %v", label, err))
+		pw.end(err)
+
+		return cs, err
+	}
+
+	pw.finish(done(cs))
+
+	return cs, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestCopy(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetLocale("C")
+
+	data := bytes.Repeat([]byte("x"), 100000)
+	dst := new(bytes.Buffer)
+
+	cs, err := cmd.Copy(dst, bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if cs.Bytes != int64(len(data)) || !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("expected %d bytes, received %d", len(data), cs.Bytes)
+	}
+
+	if cs.Elapsed <= 0 || cs.Rate() <= 0 {
+		t.Errorf("expected elapsed time and rate, received %+v", cs)
+	}
+
+	if !strings.Contains(outbuf.String(), "copied 97.7 KiB in ") {
+		t.Errorf("expected summary, received %q", outbuf)
+	}
+}
+
+func TestCopyInterrupted(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+
+	_, err := cmd.Copy(new(bytes.Buffer), strings.NewReader("data"), 0)
+	if !errors.Is(err, cli.ErrInterrupted) {
+		t.Error("expected ErrInterrupted, received", err)
+	}
+
+	_ = cmd.Wait()
+}
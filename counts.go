@@ -0,0 +1,124 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+)
+
+// outputCounts counts the warnings and errors printed.
+type outputCounts struct {
+	warnings atomic.Int64
+	errors   atomic.Int64
+}
+
+// Counts holds the numbers of warnings and errors printed by a
+// TermPrinter.
+type Counts struct {
+	Warnings int64
+	Errors   int64
+}
+
+// Warnf prints a warning to Stderr, formatted in the manner of
+// fmt.Printf and prefixed with "warning:", and counts it. A newline is
+// added if the message has none.
+func (tp *TermPrinter) Warnf(f string, v ...interface{}) (int, error) {
+	tp.counts.warnings.Add(1)
+
+	return tp.printSeverity(Yellow, "warning:", fmt.Sprintf(f, v...))
+}
+
+// Errorf prints an error to Stderr, formatted in the manner of
+// fmt.Printf and prefixed with "error:", and counts it. A newline is
+// added if the message has none. Unlike Fatalf, Errorf does not exit.
+func (tp *TermPrinter) Errorf(f string, v ...interface{}) (int, error) {
+	tp.counts.errors.Add(1)
+
+	return tp.printSeverity(Red, "error:", fmt.Sprintf(f, v...))
+}
+
+// printSeverity prints msg to Stderr after the colored prefix.
+func (tp *TermPrinter) printSeverity(c Color, prefix string, msg string) (int, error) {
+	if !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+
+	return tp.Eprint(tp.Ecolorize(c, prefix) + " " + msg)
+}
+
+// Counts returns the numbers of warnings printed with Warnf and errors
+// printed with Errorf or PrintError, including log records counted by
+// a handler from CountLogs. Commands can use the counts to report
+// "completed with 3 warnings", or to choose an exit status.
+func (tp *TermPrinter) Counts() Counts {
+	return Counts{
+		Warnings: tp.counts.warnings.Load(),
+		Errors:   tp.counts.errors.Load(),
+	}
+}
+
+// CountLogs returns a slog.Handler which passes records to h, counting
+// those of level slog.LevelWarn as warnings and those of level
+// slog.LevelError or above as errors in Counts.
+func (tp *TermPrinter) CountLogs(h slog.Handler) slog.Handler {
+	return countingHandler{h: h, counts: &tp.counts}
+}
+
+// countingHandler is the slog.Handler returned by CountLogs.
+type countingHandler struct {
+	h      slog.Handler
+	counts *outputCounts
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (ch countingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return ch.h.Enabled(ctx, level)
+}
+
+// Handle counts r and passes it to the wrapped handler.
+func (ch countingHandler) Handle(ctx context.Context, r slog.Record) error {
+	switch {
+	case r.Level >= slog.LevelError:
+		ch.counts.errors.Add(1)
+	case r.Level >= slog.LevelWarn:
+		ch.counts.warnings.Add(1)
+	}
+
+	return ch.h.Handle(ctx, r)
+}
+
+// WithAttrs returns a countingHandler wrapping the wrapped handler with
+// attrs.
+func (ch countingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return countingHandler{h: ch.h.WithAttrs(attrs), counts: ch.counts}
+}
+
+// WithGroup returns a countingHandler wrapping the wrapped handler with
+// the group name.
+func (ch countingHandler) WithGroup(name string) slog.Handler {
+	return countingHandler{h: ch.h.WithGroup(name), counts: ch.counts}
+}
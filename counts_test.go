@@ -0,0 +1,69 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestCounts(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	tp := cli.NewTermPrinter()
+	tp.SetStderr(errbuf)
+
+	tp.Warnf("disk %d%% full", 90)
+	tp.Warnf("retrying\n")
+	tp.Errorf("unable to open %s", "file")
+	tp.PrintError(errTest)
+
+	expect := "warning: disk 90% full\nwarning: retrying\nerror: unable to open file\ntesting error\n"
+	if errbuf.String() != expect {
+		t.Errorf("expected %q, received %q", expect, errbuf)
+	}
+
+	if c := tp.Counts(); c != (cli.Counts{Warnings: 2, Errors: 2}) {
+		t.Errorf("unexpected counts %+v", c)
+	}
+}
+
+func TestCountLogs(t *testing.T) {
+	tp := cli.NewTermPrinter()
+
+	log := slog.New(tp.CountLogs(slog.NewTextHandler(io.Discard, nil))).With("job", 1)
+
+	log.Info("started")
+	log.Warn("slow")
+	log.WithGroup("g").Error("failed")
+	log.Log(context.Background(), slog.LevelError+4, "fatal")
+
+	if c := tp.Counts(); c != (cli.Counts{Warnings: 1, Errors: 2}) {
+		t.Errorf("unexpected counts %+v", c)
+	}
+}
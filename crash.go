@@ -0,0 +1,179 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Defaults for crash reports.
+const (
+	defaultCrashCode  = 70
+	defaultCrashLines = 100
+)
+
+// EnableCrashReports enables writing a crash report to dir when a
+// panic is recovered by RecoverCrash. The report holds the panic value,
+// the stack traces of all goroutines, the version and arguments of the
+// application and its most recent output, which is recorded as with
+// SetSnapshotSize, enabling it with a default size if needed.
+//
+// Run recovers panics in its function automatically. Other goroutines,
+// including main, should defer RecoverCrash.
+func (c *Cmd) EnableCrashReports(dir string) {
+	c.crashDir = dir
+
+	if c.crashCode == 0 {
+		c.crashCode = defaultCrashCode
+	}
+
+	if !c.snapshotEnabled() {
+		c.SetSnapshotSize(defaultCrashLines)
+	}
+}
+
+// SetCrashExitCode sets the exit status used after a crash report has
+// been written. The default is 70.
+func (c *Cmd) SetCrashExitCode(code int) {
+	c.crashCode = code
+}
+
+// RecoverCrash must be called directly by defer. If the goroutine is
+// panicking and crash reports are enabled, RecoverCrash writes a crash
+// report, prints a short message naming the report to Stderr, calls
+// the exit hooks added with OnExit, then exits the program. If crash
+// reports are not enabled, the panic continues.
+func (c *Cmd) RecoverCrash() {
+	if c.crashDir == "" {
+		return
+	}
+
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	c.crash(v)
+}
+
+// crash writes a crash report for the panic value v and exits.
+func (c *Cmd) crash(v any) {
+	c.restoreTerminal()
+
+	if c.outIsTerm && !c.ciEnabled() {
+		c.clearLiveLines()
+	}
+
+	name := filepath.Base(c.FlagSet.Name())
+
+	path, err := c.writeCrashReport(name, v)
+	if err != nil {
+		c.Eprintf("%s crashed: %v\n", name, v)
+		c.Eprintf("unable to write crash report: %v\n", err)
+	} else {
+		c.Eprintf("%s crashed unexpectedly; details were written to %s\n", name, path)
+		c.Eprintln("please include this file when reporting the problem")
+	}
+
+	//nolint:err113 // describes the panic
+	c.runHooks(fmt.Errorf("panic: %v", v), true)
+
+	os.Exit(c.crashCode)
+}
+
+// writeCrashReport writes a crash report for the panic value v,
+// returning the path of the report.
+func (c *Cmd) writeCrashReport(name string, v any) (string, error) {
+	err := os.MkdirAll(c.crashDir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(c.crashDir,
+		name+"-crash-"+time.Now().Format("20060102-150405")+"-*.txt")
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "panic: %v\n\n", v)
+	fmt.Fprintf(&sb, "time:    %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "version: %s\n", c.Version())
+	fmt.Fprintf(&sb, "go:      %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&sb, "args:    %q\n", c.redactArgs(os.Args))
+
+	sb.WriteString("\nrecent output:\n")
+
+	snap := c.Snapshot()
+
+	for _, e := range snap.Lines {
+		fmt.Fprintf(&sb, "  [%s] %s\n", e.Stream, e.Text)
+	}
+
+	for _, l := range strings.Split(strings.TrimSuffix(snap.Live, "\n"), "\n") {
+		if l != "" {
+			fmt.Fprintf(&sb, "  [live] %s\n", l)
+		}
+	}
+
+	sb.WriteString("\ngoroutines:\n\n")
+	sb.Write(allStacks())
+
+	_, err = f.WriteString(sb.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return f.Name(), err
+}
+
+// redactArgs returns a copy of args with the registered secrets masked.
+func (c *Cmd) redactArgs(args []string) []string {
+	out := make([]string, len(args))
+
+	for i, a := range args {
+		out[i] = c.redactSecrets(a)
+	}
+
+	return out
+}
+
+// allStacks returns the stack traces of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64*1024)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
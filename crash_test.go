@@ -0,0 +1,97 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestCrashReport(t *testing.T) {
+	if dir := os.Getenv("CLI_TEST_CRASH_DIR"); dir != "" {
+		cmd := cli.NewCmd()
+		cmd.SetVersion("v1.2.3")
+		cmd.SetCrashExitCode(3)
+		cmd.EnableCrashReports(dir)
+		cmd.RedactSecrets("hunter2")
+
+		cmd.Run(nil, func(context.Context, []string) error {
+			cmd.Println("before the crash")
+			panic("test crash")
+		})
+
+		return
+	}
+
+	dir := t.TempDir()
+	errbuf := new(bytes.Buffer)
+
+	proc := exec.Command(os.Args[0], "-test.run=^TestCrashReport$", "password=hunter2")
+	proc.Env = append(os.Environ(), "CLI_TEST_CRASH_DIR="+dir)
+	proc.Stderr = errbuf
+
+	err := proc.Run()
+
+	var ee *exec.ExitError
+	if !errors.As(err, &ee) || ee.ExitCode() != 3 {
+		t.Fatal("expected exit status 3, received", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*-crash-*.txt"))
+	if len(files) != 1 {
+		t.Fatal("expected one crash report, found", files)
+	}
+
+	if !strings.Contains(errbuf.String(), "details were written to "+files[0]) {
+		t.Errorf("unexpected message %q", errbuf)
+	}
+
+	b, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	for _, s := range []string{
+		"panic: test crash\n",
+		"version: v1.2.3\n",
+		"[stdout] before the crash\n",
+		"goroutine ",
+		`"password=********"`,
+	} {
+		if !strings.Contains(string(b), s) {
+			t.Errorf("expected report to contain %q, received:\n%s", s, b)
+		}
+	}
+
+	if strings.Contains(string(b), "hunter2") {
+		t.Errorf("expected secret to be redacted, received:\n%s", b)
+	}
+}
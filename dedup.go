@@ -0,0 +1,112 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dedupState holds the state of error deduplication for a TermPrinter.
+type dedupState struct {
+	window atomic.Int64
+
+	m     sync.Mutex
+	last  string
+	start time.Time
+	count int
+}
+
+// SetErrorDedup enables suppression of repeated identical messages
+// written by Eprint, Eprintf and Eprintln. Repeats of the previous
+// message within window of its first appearance are counted rather than
+// printed. When a different message is written, or the same message
+// after the window has passed, a line reporting the number of
+// suppressed repeats is printed first. The line is also printed by
+// FinalizeLive and Close, so the count is not lost when the application
+// ends during a run of repeats. A zero or negative value disables
+// deduplication.
+func (tp *TermPrinter) SetErrorDedup(window time.Duration) {
+	tp.dedup.window.Store(int64(window))
+}
+
+// dedupEnabled reports whether error deduplication is enabled.
+func (tp *TermPrinter) dedupEnabled() bool {
+	return tp.dedup.window.Load() > 0
+}
+
+// dedupWrite writes s to Stderr unless it repeats the previous message.
+func (tp *TermPrinter) dedupWrite(s string) (int, error) {
+	d := &tp.dedup
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	now := tp.now()
+	window := time.Duration(d.window.Load())
+
+	if s == d.last && now.Sub(d.start) < window {
+		d.count++
+
+		return 0, nil
+	}
+
+	msg := s
+	if d.count > 0 {
+		msg = fmt.Sprintf("last message repeated %d times\n", d.count) + s
+	}
+
+	d.last = s
+	d.start = now
+	d.count = 0
+
+	_, err := tp.writeMessage(Stderr, msg)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(s), nil
+}
+
+// flushDedup prints the number of suppressed repeats of the previous
+// message, if any. The next message is then printed in full, even if it
+// repeats the previous one.
+func (tp *TermPrinter) flushDedup() {
+	d := &tp.dedup
+
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	if d.count == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("last message repeated %d times\n", d.count)
+
+	d.last = ""
+	d.count = 0
+
+	_, _ = tp.writeMessage(Stderr, msg)
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestErrorDedup(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStderr(errbuf)
+	p.SetErrorDedup(time.Minute)
+
+	for i := 0; i < 5; i++ {
+		p.Eprintln("connection refused")
+	}
+
+	p.Eprintf("retry %d\n", 1)
+	p.Eprint("retry 1\n")
+	p.Eprintln("done")
+
+	p.SetErrorDedup(0)
+	p.Eprintln("done")
+
+	exp := "connection refused\n" +
+		"last message repeated 4 times\n" +
+		"retry 1\n" +
+		"last message repeated 1 times\n" +
+		"done\n" +
+		"done\n"
+	if errbuf.String() != exp {
+		t.Error("unexpected output", errbuf.String())
+	}
+}
+
+func TestErrorDedupClose(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStderr(errbuf)
+	p.SetErrorDedup(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		p.Eprintln("disk full")
+	}
+
+	p.FinalizeLive("")
+
+	p.Eprintln("disk full")
+	p.Eprintln("disk full")
+
+	err := p.Close()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	err = p.Close()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	exp := "disk full\n" +
+		"last message repeated 2 times\n" +
+		"disk full\n" +
+		"last message repeated 1 times\n"
+	if errbuf.String() != exp {
+		t.Error("unexpected output", errbuf.String())
+	}
+}
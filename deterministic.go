@@ -0,0 +1,70 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import "time"
+
+// Terminal size used in deterministic mode when no size has been set
+// with SetTermSize.
+const (
+	deterministicWidth  = 80
+	deterministicHeight = 24
+)
+
+// SetDeterministic enables or disables deterministic rendering, making
+// output independent of timing and the terminal so that it can be
+// compared against golden files in tests. In deterministic mode:
+//
+//   - animations draw only their first frame, with fixed spinner
+//     glyphs, and live renderers are drawn only by Refresh and StopLive
+//   - timestamps are the zero time and durations are zero, so progress
+//     lines show no rate or ETA
+//   - the terminal is 80 columns by 24 rows unless set with SetTermSize
+//
+// SetDeterministic must be called before any output is written.
+func (tp *TermPrinter) SetDeterministic(on bool) {
+	tp.deterministic = on
+}
+
+// now returns the current time from the Clock, or the zero time in
+// deterministic mode.
+func (tp *TermPrinter) now() time.Time {
+	if tp.deterministic {
+		return time.Time{}
+	}
+
+	return tp.clock().Now()
+}
+
+// frames returns a channel delivering animation frames every d and a
+// function which stops it. In deterministic mode the channel is nil, so
+// no further frames are drawn.
+func (tp *TermPrinter) frames(d time.Duration) (<-chan time.Time, func()) {
+	if tp.deterministic {
+		return nil, func() {}
+	}
+
+	t := tp.clock().NewTicker(d)
+
+	return t.C(), t.Stop
+}
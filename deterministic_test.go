@@ -0,0 +1,67 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestDeterministic(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetLocale("C")
+	cmd.SetDeterministic(true)
+
+	cmd.SetLiveRenderer(func() string { return "rendering\n" })
+	cmd.StartLive(time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	cmd.StopLive()
+
+	_, err := cmd.Copy(new(bytes.Buffer), strings.NewReader(strings.Repeat("x", 2048)), 2048)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	table := cli.NewTable(cli.Column{Name: "NAME", Priority: 1}, cli.Column{Name: "VALUE", MinWidth: 10})
+	table.AddRow("key", strings.Repeat("v", 100))
+
+	_, _ = cmd.PrintTable(table)
+
+	exp := "rendering\n" +
+		"copy  [>                       ]   0%  0 B / 2.0 KiB\n" +
+		"copied 2.0 KiB in 0s  0 B/s\n" +
+		"NAME  VALUE\n" +
+		"key   " + strings.Repeat("v", 73) + "…\n"
+	if outbuf.String() != exp {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes.
+const diffContext = 3
+
+// diffOp is a line of an edit script.
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// PrintDiff prints a unified diff of the lines of from and to on
+// Stdout, with removed lines in red, added lines in green and hunk
+// headers in cyan when color is enabled. Nothing is printed if the
+// strings are equal.
+//
+// The diff is computed with a simple longest common subsequence
+// algorithm, which is intended for the modest inputs typical of
+// configuration files and dry-run output.
+func (tp *TermPrinter) PrintDiff(from string, to string) (int, error) {
+	var sb strings.Builder
+
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	for _, h := range diffHunks(ops) {
+		sb.WriteString(tp.Colorize(Cyan, h.header()))
+		sb.WriteByte('\n')
+
+		for _, op := range ops[h.start:h.end] {
+			line := string(op.kind) + op.text
+
+			switch op.kind {
+			case '-':
+				line = tp.Colorize(Red, line)
+			case '+':
+				line = tp.Colorize(Green, line)
+			}
+
+			sb.WriteString(line)
+			sb.WriteByte('\n')
+		}
+	}
+
+	if sb.Len() == 0 {
+		return 0, nil
+	}
+
+	return tp.Print(sb.String())
+}
+
+// splitLines splits s into lines, ignoring a trailing newline.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the edit script transforming a into b.
+func diffLines(a []string, b []string) []diffOp {
+	// lcs[i][j] is the length of the common subsequence of a[i:], b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, max(len(a), len(b)))
+
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i]})
+			i++
+			j++
+		case j >= len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// diffHunk is a range of an edit script along with the line numbers
+// where it starts in the old and new text.
+type diffHunk struct {
+	start, end int
+	oldLine    int
+	newLine    int
+	oldCount   int
+	newCount   int
+}
+
+// header returns the unified diff hunk header.
+func (h diffHunk) header() string {
+	return fmt.Sprintf("@@ -%s +%s @@",
+		hunkRange(h.oldLine, h.oldCount), hunkRange(h.newLine, h.newCount))
+}
+
+// hunkRange formats a line range of a hunk header.
+func hunkRange(line int, count int) string {
+	if count == 0 {
+		line--
+	}
+
+	if count == 1 {
+		return strconv.Itoa(line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// diffHunks groups the changes in ops into hunks with surrounding
+// context lines.
+func diffHunks(ops []diffOp) []diffHunk {
+	var hunks []diffHunk
+
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+
+		start := max(0, i-diffContext)
+
+		// extend the hunk until there are more than two context
+		// lengths of unchanged lines after the last change
+		end := i
+
+		for k := i; k < len(ops) && k <= end+2*diffContext; k++ {
+			if ops[k].kind != ' ' {
+				end = k
+			}
+		}
+
+		end = min(len(ops), end+diffContext+1)
+
+		if n := len(hunks); n > 0 && start <= hunks[n-1].end {
+			start = hunks[n-1].start
+			hunks = hunks[:n-1]
+		}
+
+		hunks = append(hunks, newHunk(ops, start, end))
+		i = end - 1
+	}
+
+	return hunks
+}
+
+// newHunk returns the hunk covering ops[start:end].
+func newHunk(ops []diffOp, start int, end int) diffHunk {
+	h := diffHunk{start: start, end: end, oldLine: 1, newLine: 1}
+
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			h.oldLine++
+		}
+
+		if op.kind != '-' {
+			h.newLine++
+		}
+	}
+
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			h.oldCount++
+		}
+
+		if op.kind != '-' {
+			h.newCount++
+		}
+	}
+
+	return h
+}
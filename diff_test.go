@@ -0,0 +1,99 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestPrintDiff(t *testing.T) {
+	t.Run("Hunks", testDiffHunks)
+	t.Run("Color", testDiffColor)
+	t.Run("Equal", testDiffEqual)
+}
+
+func testDiffHunks(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	to := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+
+	_, err := p.PrintDiff(from, to)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+		"@@ -13,3 +13,4 @@\n 13\n 14\n 15\n+16\n"
+	if outbuf.String() != exp {
+		t.Errorf("unexpected output %q", outbuf.String())
+	}
+}
+
+func testDiffColor(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+	p.SetColorMode(cli.ColorAlways)
+
+	_, err := p.PrintDiff("a\n", "b")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "\x1b[36m@@ -1 +1 @@\x1b[0m\n\x1b[31m-a\x1b[0m\n\x1b[32m+b\x1b[0m\n"
+	if outbuf.String() != exp {
+		t.Errorf("unexpected output %q", outbuf.String())
+	}
+
+	outbuf.Reset()
+
+	_, err = p.PrintDiff("", "new\n")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp = "\x1b[36m@@ -0,0 +1 @@\x1b[0m\n\x1b[32m+new\x1b[0m\n"
+	if outbuf.String() != exp {
+		t.Errorf("unexpected output %q", outbuf.String())
+	}
+}
+
+func testDiffEqual(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+
+	n, err := p.PrintDiff("same\n", "same\n")
+	if n != 0 || err != nil || outbuf.Len() != 0 {
+		t.Error("unexpected output", n, err, outbuf.String())
+	}
+}
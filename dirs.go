@@ -0,0 +1,144 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ErrNoHome is returned by the directory helpers when the directory
+// cannot be determined because the required environment variables are
+// not set.
+var ErrNoHome = errors.New("unable to determine home directory")
+
+// ConfigDir returns the directory for configuration files of the named
+// application, creating it if necessary. This is $XDG_CONFIG_HOME/app
+// or ~/.config/app on Unix systems, ~/Library/Application Support/app
+// on macOS and %APPDATA%\app on Windows.
+func ConfigDir(app string) (string, error) {
+	dir, err := configDirPath(app)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// configDirPath returns the path of ConfigDir without creating it.
+func configDirPath(app string) (string, error) {
+	return appDirPath(app, "XDG_CONFIG_HOME", ".config",
+		"Library/Application Support", "APPDATA", "")
+}
+
+// CacheDir returns the directory for cached data of the named
+// application, creating it if necessary. This is $XDG_CACHE_HOME/app
+// or ~/.cache/app on Unix systems, ~/Library/Caches/app on macOS and
+// %LOCALAPPDATA%\app\cache on Windows.
+func CacheDir(app string) (string, error) {
+	return appDir(app, "XDG_CACHE_HOME", ".cache",
+		"Library/Caches", "LOCALAPPDATA", "cache")
+}
+
+// StateDir returns the directory for state which should persist
+// between runs of the named application, such as history, but which is
+// not important enough for DataDir, creating it if necessary. This is
+// $XDG_STATE_HOME/app or ~/.local/state/app on Unix systems,
+// ~/Library/Application Support/app on macOS and %LOCALAPPDATA%\app on
+// Windows.
+func StateDir(app string) (string, error) {
+	return appDir(app, "XDG_STATE_HOME", ".local/state",
+		"Library/Application Support", "LOCALAPPDATA", "")
+}
+
+// DataDir returns the directory for data files of the named
+// application, creating it if necessary. This is $XDG_DATA_HOME/app or
+// ~/.local/share/app on Unix systems, ~/Library/Application Support/app
+// on macOS and %APPDATA%\app on Windows.
+func DataDir(app string) (string, error) {
+	return appDir(app, "XDG_DATA_HOME", ".local/share",
+		"Library/Application Support", "APPDATA", "")
+}
+
+// appDir returns and creates the directory for app, as given by
+// appDirPath.
+func appDir(app string, xdgEnv string, xdgDir string,
+	macDir string, winEnv string, winSub string,
+) (string, error) {
+	dir, err := appDirPath(app, xdgEnv, xdgDir, macDir, winEnv, winSub)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// appDirPath returns the directory for app. On Windows, it is below the
+// directory named by winEnv, with the subdirectory winSub if given. On
+// macOS, it is below macDir in the home directory. Otherwise it is below
+// the directory named by xdgEnv, or xdgDir in the home directory if
+// xdgEnv is unset or not absolute.
+func appDirPath(app string, xdgEnv string, xdgDir string,
+	macDir string, winEnv string, winSub string,
+) (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		base := os.Getenv(winEnv)
+		if base == "" {
+			return "", ErrNoHome
+		}
+
+		return filepath.Join(base, app, winSub), nil
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ErrNoHome
+		}
+
+		return filepath.Join(home, filepath.FromSlash(macDir), app), nil
+	default:
+		base := os.Getenv(xdgEnv)
+		if !filepath.IsAbs(base) {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", ErrNoHome
+			}
+
+			base = filepath.Join(home, filepath.FromSlash(xdgDir))
+		}
+
+		return filepath.Join(base, app), nil
+	}
+}
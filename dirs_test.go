@@ -0,0 +1,72 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestAppDirs(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG directories are not used on", runtime.GOOS)
+	}
+
+	home := t.TempDir()
+	xdg := t.TempDir()
+
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(xdg, "config"))
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "relative")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	for _, tc := range []struct {
+		name string
+		fn   func(string) (string, error)
+		exp  string
+	}{
+		{"Config", cli.ConfigDir, filepath.Join(xdg, "config", "app")},
+		{"Cache", cli.CacheDir, filepath.Join(home, ".cache", "app")},
+		{"State", cli.StateDir, filepath.Join(home, ".local", "state", "app")},
+		{"Data", cli.DataDir, filepath.Join(home, ".local", "share", "app")},
+	} {
+		dir, err := tc.fn("app")
+		if err != nil {
+			t.Fatal(tc.name, "unexpected error", err)
+		}
+
+		if dir != tc.exp {
+			t.Errorf("%s: expected %q, received %q", tc.name, tc.exp, dir)
+		}
+
+		fi, err := os.Stat(dir)
+		if err != nil || !fi.IsDir() {
+			t.Errorf("%s: expected directory to be created, received %v", tc.name, err)
+		}
+	}
+}
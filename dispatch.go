@@ -0,0 +1,117 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// runCommand calls the Run or RunContext function of cmd with args,
+// enforcing the Exclusive, Timeout and RecoverPanics options of cmd.
+func (c *Cmd) runCommand(cmd *Command, args []string) error {
+	if cmd.Exclusive {
+		if !cmd.running.CompareAndSwap(false, true) {
+			return fmt.Errorf("%w: '%s'", ErrCommandBusy, cmd.Path())
+		}
+	}
+
+	if cmd.Timeout <= 0 {
+		return c.callCommand(context.Background(), cmd, args)
+	}
+
+	// the context is cancelled by the timer below rather than with
+	// context.WithTimeout, so that it follows the Clock
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		defer c.RecoverCrash()
+
+		done <- c.callCommand(ctx, cmd, args)
+	}()
+
+	t := c.clock().NewTimer(cmd.Timeout)
+	defer t.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-t.C():
+		return fmt.Errorf("%w: '%s' after %s", ErrDeadline, cmd.Path(), cmd.Timeout)
+	}
+}
+
+// callCommand calls the RunContext function of cmd with ctx, or its
+// Run function, recovering a panic if RecoverPanics is set. The running
+// flag of an Exclusive command is cleared once it returns.
+func (c *Cmd) callCommand(ctx context.Context, cmd *Command, args []string) (err error) {
+	if cmd.Exclusive {
+		defer cmd.running.Store(false)
+	}
+
+	if cmd.RecoverPanics {
+		defer func() {
+			if v := recover(); v != nil {
+				err = c.commandPanic(cmd, v)
+			}
+		}()
+	}
+
+	if cmd.RunContext != nil {
+		return cmd.RunContext(ctx, args)
+	}
+
+	return cmd.Run(args)
+}
+
+// commandPanic reports the panic value v recovered from cmd, returning
+// the error passed back from Dispatch.
+func (c *Cmd) commandPanic(cmd *Command, v any) error {
+	c.restoreTerminal()
+
+	c.Eprintf("'%s' panicked: %v\n", cmd.Path(), v)
+
+	if c.Debug() {
+		c.Eprintf("%s", debug.Stack())
+	}
+
+	code := c.crashCode
+	if code == 0 {
+		code = defaultCrashCode
+	}
+
+	if c.crashDir != "" {
+		path, err := c.writeCrashReport(cmd.Name, v)
+		if err != nil {
+			c.Eprintf("unable to write crash report: %v\n", err)
+		} else {
+			c.Eprintf("details were written to %s\n", path)
+		}
+	}
+
+	return &ExitError{Name: cmd.Path(), Code: code}
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
+)
+
+func TestDispatchTimeout(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	cmd := cli.NewCmd()
+	cmd.SetClock(clk)
+
+	release := make(chan bool)
+	defer close(release)
+
+	slow := cmd.AddCommand("slow", "", func([]string) error {
+		<-release
+
+		return nil
+	})
+	slow.Timeout = time.Minute
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.Dispatch([]string{"slow"})
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+
+	err := <-errc
+	if !errors.Is(err, cli.ErrDeadline) {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if exp := "deadline exceeded: 'slow' after 1m0s"; err.Error() != exp {
+		t.Errorf("expected %q, received %q", exp, err)
+	}
+
+	fast := cmd.AddCommand("fast", "", func([]string) error { return errTest })
+	fast.Timeout = time.Minute
+
+	err = cmd.Dispatch([]string{"fast"})
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestDispatchTimeoutContext(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	cmd := cli.NewCmd()
+	cmd.SetClock(clk)
+
+	cancelled := make(chan error, 1)
+
+	slow := cmd.AddCommand("slow", "", nil)
+	slow.Timeout = time.Minute
+	slow.RunContext = func(ctx context.Context, _ []string) error {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+
+		return ctx.Err()
+	}
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.Dispatch([]string{"slow"})
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+
+	err := <-errc
+	if !errors.Is(err, cli.ErrDeadline) {
+		t.Fatal("unexpected error:", err)
+	}
+
+	select {
+	case err := <-cancelled:
+		if !errors.Is(err, context.Canceled) {
+			t.Error("unexpected context error:", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not cancelled after timeout")
+	}
+
+	fast := cmd.AddCommand("fast", "", nil)
+	fast.RunContext = func(ctx context.Context, args []string) error {
+		if ctx.Err() != nil || len(args) != 1 || args[0] != "x" {
+			return errTest
+		}
+
+		return nil
+	}
+
+	err = cmd.Dispatch([]string{"fast", "x"})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestDispatchRecoverPanics(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(buf)
+
+	grp := cmd.AddCommand("remote", "", nil)
+	add := grp.AddCommand("add", "", func([]string) error { panic("boom") })
+	add.RecoverPanics = true
+
+	err := cmd.Dispatch([]string{"remote", "add"})
+
+	var ee *cli.ExitError
+	if !errors.As(err, &ee) {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if ee.Name != "remote add" || ee.Code != 70 {
+		t.Errorf("unexpected exit error: %+v", ee)
+	}
+
+	if !strings.Contains(buf.String(), "'remote add' panicked: boom") {
+		t.Errorf("unexpected output: %q", buf)
+	}
+}
+
+func TestDispatchExclusive(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	started := make(chan bool)
+	release := make(chan bool)
+
+	job := cmd.AddCommand("job", "", func([]string) error {
+		started <- true
+		<-release
+
+		return nil
+	})
+	job.Exclusive = true
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.Dispatch([]string{"job"})
+	}()
+
+	<-started
+
+	err := cmd.Dispatch([]string{"job"})
+	if !errors.Is(err, cli.ErrCommandBusy) {
+		t.Error("unexpected error:", err)
+	}
+
+	close(release)
+
+	if err := <-errc; err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	go func() { <-started }()
+
+	err = cmd.Dispatch([]string{"job"})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
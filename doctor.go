@@ -0,0 +1,156 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrChecksFailed is returned by Doctor when a check fails.
+var ErrChecksFailed = errors.New("checks failed")
+
+// doctorCommand is the name of the subcommand added by AddCheck.
+const doctorCommand = "doctor"
+
+// CheckStatus is the outcome of a diagnostic check.
+type CheckStatus int
+
+// Outcomes of a diagnostic check.
+const (
+	CheckPass CheckStatus = iota
+	CheckWarn
+	CheckFail
+)
+
+// CheckResult is the result of a diagnostic check.
+type CheckResult struct {
+	// Status is the outcome of the check.
+	Status CheckStatus
+
+	// Message describes the outcome, such as a version found or the
+	// problem detected.
+	Message string
+
+	// Hint suggests how to fix a problem.
+	Hint string
+}
+
+// check is a diagnostic check added with AddCheck.
+type check struct {
+	name string
+	fn   func(ctx context.Context) CheckResult
+}
+
+// AddCheck adds a diagnostic check run by Doctor. The first call also
+// adds a "doctor" subcommand which runs Doctor, unless a command of
+// that name already exists.
+func (c *Cmd) AddCheck(name string, fn func(ctx context.Context) CheckResult) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+
+	if _, ok := c.commands[doctorCommand]; !ok {
+		c.AddCommand(doctorCommand, "check for common problems", func([]string) error {
+			return c.Doctor(context.Background())
+		})
+	}
+}
+
+// Doctor runs the checks added with AddCheck in order, showing each as
+// it runs, then prints a summary of the results. The context passed to
+// the checks is canceled if the exit channel closes. If any check
+// fails, the error wraps ErrChecksFailed.
+func (c *Cmd) Doctor(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.Add(1)
+	defer c.Done()
+
+	go func() {
+		select {
+		case <-c.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var counts [3]int
+
+	steps := c.Steps()
+
+	for _, chk := range c.checks {
+		var res CheckResult
+
+		run := func() error {
+			res = chk.fn(ctx)
+
+			return nil
+		}
+
+		if c.outIsTerm && !c.ciEnabled() {
+			_ = steps.runLive(chk.name, run)
+		} else {
+			_ = run()
+		}
+
+		c.printCheck(chk.name, res)
+
+		if res.Status >= CheckPass && res.Status <= CheckFail {
+			counts[res.Status]++
+		}
+	}
+
+	c.Printf("\n%d passed, %d warnings, %d failed\n",
+		counts[CheckPass], counts[CheckWarn], counts[CheckFail])
+
+	if counts[CheckFail] > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrChecksFailed, counts[CheckFail], len(c.checks))
+	}
+
+	return nil
+}
+
+// printCheck prints the result of the named check.
+func (c *Cmd) printCheck(name string, res CheckResult) {
+	var mark string
+
+	switch res.Status {
+	case CheckPass:
+		mark = c.Colorize(Green, "✓")
+	case CheckWarn:
+		mark = c.Colorize(Yellow, "!")
+	case CheckFail:
+		mark = c.Colorize(Red, "✗")
+	}
+
+	if res.Message != "" {
+		c.Printf("%s %s: %s\n", mark, name, res.Message)
+	} else {
+		c.Printf("%s %s\n", mark, name)
+	}
+
+	if res.Hint != "" {
+		c.Printf("    %s\n", res.Hint)
+	}
+}
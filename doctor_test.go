@@ -0,0 +1,75 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestDoctor(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+
+	cmd.AddCheck("config", func(context.Context) cli.CheckResult {
+		return cli.CheckResult{Message: "found"}
+	})
+	cmd.AddCheck("cache", func(context.Context) cli.CheckResult {
+		return cli.CheckResult{Status: cli.CheckWarn, Message: "stale", Hint: "run 'tool cache clear'"}
+	})
+
+	err := cmd.Dispatch([]string{"doctor"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := "✓ config: found\n" +
+		"! cache: stale\n" +
+		"    run 'tool cache clear'\n" +
+		"\n1 passed, 1 warnings, 0 failed\n"
+
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\nreceived:\n%s", exp, buf)
+	}
+
+	cmd.AddCheck("network", func(context.Context) cli.CheckResult {
+		return cli.CheckResult{Status: cli.CheckFail}
+	})
+
+	buf.Reset()
+
+	err = cmd.Dispatch([]string{"doctor"})
+	if !errors.Is(err, cli.ErrChecksFailed) {
+		t.Error("expected ErrChecksFailed, received", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("✗ network\n")) {
+		t.Errorf("expected failed check, received:\n%s", buf)
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksum is returned by Download when the downloaded file does not
+// match the expected checksum.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// ErrDownload is returned by Download when the server responds with an
+// unexpected status.
+var ErrDownload = errors.New("download failed")
+
+// DownloadOptions configures Download.
+type DownloadOptions struct {
+	// SHA256 is the expected SHA-256 checksum of the file, in
+	// hexadecimal. If set, the file is verified once downloaded.
+	SHA256 string
+
+	// Client is the HTTP client used. The default is
+	// http.DefaultClient.
+	Client *http.Client
+
+	// NoResume disables resuming a partial download left by an earlier
+	// attempt.
+	NoResume bool
+}
+
+// Download downloads url to the file dest, showing a live progress
+// line. The data is written to dest with ".part" appended, which is
+// renamed to dest once complete and verified. If a partial file exists
+// from an interrupted attempt, the download is resumed with an HTTP
+// range request where the server supports it.
+//
+// The ETag or Last-Modified header of the response is kept alongside
+// the partial file, with ".validator" appended, and sent in an If-Range
+// header when resuming, so a file which has changed on the server is
+// downloaded again from the start. The download also starts over if
+// the server responds with a range other than the one requested, which
+// is the only check made if the server sent neither header.
+//
+// Download stops with ErrInterrupted if the exit channel closes, or
+// with the error of ctx if it is done, leaving the partial file in
+// place so a later call may resume. If the checksum does not match,
+// the partial file is removed and an error wrapping ErrChecksum is
+// returned.
+func (c *Cmd) Download(ctx context.Context, url string, dest string, opts DownloadOptions) error {
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		select {
+		case <-c.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	part := dest + ".part"
+	label := filepath.Base(dest)
+
+	err := c.download(ctx, url, part, label, opts)
+	if err != nil {
+		if c.exiting() {
+			err = ErrInterrupted
+		}
+
+		c.FinalizeLive(fmt.Sprintf("%s: %v", label, err))
+
+		return err
+	}
+
+	_ = os.Remove(part + validatorSuffix)
+
+	return os.Rename(part, dest)
+}
+
+// download implements Download, writing to the partial file part.
+func (c *Cmd) download(ctx context.Context, url string, part string, label string,
+	opts DownloadOptions,
+) error {
+	var (
+		offset    int64
+		validator string
+	)
+
+	if fi, err := os.Stat(part); err == nil && !opts.NoResume {
+		offset = fi.Size()
+
+		b, err := os.ReadFile(part + validatorSuffix)
+		if err == nil {
+			validator = strings.TrimSpace(string(b))
+		}
+	}
+
+	resp, err := downloadRequest(ctx, opts.Client, url, offset, validator)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		offset = 0
+
+		err = saveValidator(part, resp)
+		if err != nil {
+			return err
+		}
+	case http.StatusPartialContent:
+		flags = os.O_WRONLY | os.O_APPEND
+	default:
+		return fmt.Errorf("%w: %s: %s", ErrDownload, url, resp.Status)
+	}
+
+	f, err := os.OpenFile(part, flags, 0o644) //nolint:gosec // downloads are not secret
+	if err != nil {
+		return err
+	}
+
+	h := sha256.New()
+
+	if offset > 0 {
+		err = hashFile(h, part)
+		if err != nil {
+			f.Close()
+
+			return err
+		}
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+
+	pw := newProgressWriter(c.TermPrinter, label, offset, total)
+
+	_, err = io.Copy(io.MultiWriter(f, h, pw), resp.Body)
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		pw.end(err)
+
+		return err
+	}
+
+	if opts.SHA256 != "" {
+		sum := hex.EncodeToString(h.Sum(nil))
+
+		if !strings.EqualFold(sum, opts.SHA256) {
+			_ = os.Remove(part)
+			_ = os.Remove(part + validatorSuffix)
+
+			err = fmt.Errorf("%w: expected %s, received %s", ErrChecksum, opts.SHA256, sum)
+			pw.end(err)
+
+			return err
+		}
+	}
+
+	pw.finish(fmt.Sprintf("%s  %s downloaded", label, c.FormatBytes(pw.done)))
+
+	return nil
+}
+
+// validatorSuffix is appended to the name of a partial file to name the
+// file holding the validator of the download.
+const validatorSuffix = ".validator"
+
+// downloadRequest requests url, starting from offset if it is not
+// zero, on the condition that the file still matches validator if it
+// is not empty. If the server cannot satisfy the range, or responds
+// with a different range, the whole file is requested instead.
+func downloadRequest(ctx context.Context, client *http.Client, url string, offset int64,
+	validator string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	badRange := resp.StatusCode == http.StatusRequestedRangeNotSatisfiable ||
+		resp.StatusCode == http.StatusPartialContent &&
+			rangeStart(resp.Header.Get("Content-Range")) != offset
+
+	if !badRange {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	if offset == 0 {
+		return nil, fmt.Errorf("%w: %s: %s", ErrDownload, url, resp.Status)
+	}
+
+	return downloadRequest(ctx, client, url, 0, "")
+}
+
+// rangeStart returns the first byte position of a Content-Range header
+// value, or -1 if it cannot be parsed.
+func rangeStart(s string) int64 {
+	s, ok := strings.CutPrefix(s, "bytes ")
+	if !ok {
+		return -1
+	}
+
+	s, _, ok = strings.Cut(s, "-")
+	if !ok {
+		return -1
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return -1
+	}
+
+	return n
+}
+
+// saveValidator records the ETag or Last-Modified header of resp for
+// the partial file part, or removes any previous record if it has
+// neither. Weak ETags cannot be used with If-Range and are ignored.
+func saveValidator(part string, resp *http.Response) error {
+	v := resp.Header.Get("ETag")
+	if v == "" || strings.HasPrefix(v, "W/") {
+		v = resp.Header.Get("Last-Modified")
+	}
+
+	if v == "" {
+		err := os.Remove(part + validatorSuffix)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	return os.WriteFile(part+validatorSuffix, []byte(v+"\n"), 0o644) //nolint:gosec // downloads are not secret
+}
+
+// hashFile writes the contents of the file at path to h.
+func hashFile(h hash.Hash, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	_, err = io.Copy(h, f)
+
+	return err
+}
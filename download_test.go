@@ -0,0 +1,274 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestDownload(t *testing.T) {
+	content := []byte(strings.Repeat("download test data\n", 1000))
+	sum := sha256.Sum256(content)
+	hexsum := hex.EncodeToString(sum[:])
+
+	var ranged atomic.Bool
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		if r.Header.Get("Range") != "" {
+			ranged.Store(true)
+		}
+
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetLocale("C")
+
+	t.Run("Complete", func(t *testing.T) {
+		dest := filepath.Join(dir, "complete")
+
+		err := cmd.Download(context.Background(), srv.URL+"/file", dest, cli.DownloadOptions{SHA256: hexsum})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		checkFile(t, dest, content)
+
+		if !strings.Contains(outbuf.String(), "complete  18.6 KiB downloaded\n") {
+			t.Errorf("unexpected output %q", outbuf)
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		dest := filepath.Join(dir, "resume")
+
+		err := os.WriteFile(dest+".part", content[:5000], 0o600)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		err = cmd.Download(context.Background(), srv.URL+"/file", dest, cli.DownloadOptions{SHA256: hexsum})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		checkFile(t, dest, content)
+
+		if !ranged.Load() {
+			t.Error("expected a range request")
+		}
+	})
+
+	t.Run("Checksum", func(t *testing.T) {
+		dest := filepath.Join(dir, "checksum")
+
+		err := cmd.Download(context.Background(), srv.URL+"/file", dest,
+			cli.DownloadOptions{SHA256: strings.Repeat("0", 64)})
+		if !errors.Is(err, cli.ErrChecksum) {
+			t.Error("expected ErrChecksum, received", err)
+		}
+
+		for _, path := range []string{dest, dest + ".part"} {
+			_, err = os.Stat(path)
+			if !os.IsNotExist(err) {
+				t.Errorf("expected %s to be removed, received %v", filepath.Base(path), err)
+			}
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		err := cmd.Download(context.Background(), srv.URL+"/missing",
+			filepath.Join(dir, "missing"), cli.DownloadOptions{})
+		if !errors.Is(err, cli.ErrDownload) {
+			t.Error("expected ErrDownload, received", err)
+		}
+	})
+}
+
+func TestDownloadChanged(t *testing.T) {
+	old := []byte(strings.Repeat("old data\n", 1000))
+	content := []byte(strings.Repeat("new data\n", 1000))
+
+	var (
+		changed atomic.Bool
+		ranged  atomic.Bool
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !changed.Load() {
+			// send half of the old file, then drop the connection
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", strconv.Itoa(len(old)))
+			_, _ = w.Write(old[:len(old)/2])
+
+			panic(http.ErrAbortHandler)
+		}
+
+		if r.Header.Get("If-Range") != `"v1"` {
+			t.Errorf("unexpected If-Range %q", r.Header.Get("If-Range"))
+		}
+
+		if r.Header.Get("Range") != "" {
+			ranged.Store(true)
+		}
+
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "changed")
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	err := cmd.Download(context.Background(), srv.URL, dest, cli.DownloadOptions{})
+	if err == nil {
+		t.Fatal("expected error for dropped connection")
+	}
+
+	checkFile(t, dest+".part", old[:len(old)/2])
+
+	changed.Store(true)
+
+	err = cmd.Download(context.Background(), srv.URL, dest, cli.DownloadOptions{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, dest, content)
+
+	if !ranged.Load() {
+		t.Error("expected a range request")
+	}
+
+	_, err = os.Stat(dest + ".part.validator")
+	if !os.IsNotExist(err) {
+		t.Error("expected validator to be removed, received", err)
+	}
+}
+
+func TestDownloadWrongRange(t *testing.T) {
+	content := []byte(strings.Repeat("download test data\n", 1000))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") == "" {
+			http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+
+			return
+		}
+
+		// ignore the requested offset
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 100-%d/%d", len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(content[100:])
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "range")
+
+	err := os.WriteFile(dest+".part", content[:5000], 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	err = cmd.Download(context.Background(), srv.URL, dest, cli.DownloadOptions{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, dest, content)
+}
+
+func TestDownloadBadRange(t *testing.T) {
+	var requests atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "range")
+
+	err := os.WriteFile(dest+".part", []byte("partial"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	err = cmd.Download(context.Background(), srv.URL, dest, cli.DownloadOptions{})
+	if !errors.Is(err, cli.ErrDownload) {
+		t.Error("expected ErrDownload, received", err)
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expected 2 requests, received %d", n)
+	}
+}
+
+// checkFile checks that the file at path holds exp.
+func checkFile(t *testing.T, path string, exp []byte) {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !bytes.Equal(b, exp) {
+		t.Errorf("%s: unexpected content of %d bytes", filepath.Base(path), len(b))
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ColorDepth is the number of colors supported by a terminal.
+type ColorDepth int
+
+// Color depths reported by Environment.
+const (
+	ColorDepthNone ColorDepth = iota
+	ColorDepth16
+	ColorDepth256
+	ColorDepthTrue
+)
+
+// String returns a description of the color depth.
+func (d ColorDepth) String() string {
+	switch d {
+	case ColorDepth16:
+		return "16 colors"
+	case ColorDepth256:
+		return "256 colors"
+	case ColorDepthTrue:
+		return "true color"
+	case ColorDepthNone:
+	}
+
+	return "none"
+}
+
+// EnvironmentInfo describes the context the process is running in.
+type EnvironmentInfo struct {
+	// OS and Arch are the operating system and architecture.
+	OS   string
+	Arch string
+
+	// StdinTerminal, StdoutTerminal and StderrTerminal report whether
+	// each standard stream is a terminal.
+	StdinTerminal  bool
+	StdoutTerminal bool
+	StderrTerminal bool
+
+	// Interactive reports whether both Stdin and Stdout are terminals,
+	// so a user can be prompted.
+	Interactive bool
+
+	// SSH reports whether the process is running in an SSH session.
+	SSH bool
+
+	// CI reports whether the process is running in continuous
+	// integration, as with IsCI, and CIProvider names the provider if
+	// it is recognized.
+	CI         bool
+	CIProvider string
+
+	// Container names the container runtime the process is running in,
+	// such as "docker", "podman" or "kubernetes", or is empty.
+	Container string
+
+	// Terminal names the terminal emulator, or the terminal type from
+	// TERM if the emulator is not known.
+	Terminal string
+
+	// ColorDepth is the number of colors supported by the terminal. It
+	// is ColorDepthNone if NO_COLOR is set.
+	ColorDepth ColorDepth
+
+	// UTF8 reports whether the locale uses UTF-8.
+	UTF8 bool
+}
+
+// Environment detects the context the process is running in from the
+// standard streams, environment variables and well known files. The
+// result can be logged to help diagnose problems reported by users.
+func Environment() EnvironmentInfo {
+	env := EnvironmentInfo{
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		StdinTerminal:  isTerminal(os.Stdin),
+		StdoutTerminal: isTerminal(os.Stdout),
+		StderrTerminal: isTerminal(os.Stderr),
+		SSH:            isSSH(),
+		CI:             IsCI(),
+		CIProvider:     ciProvider(),
+		Container:      container(),
+		Terminal:       terminalName(),
+		ColorDepth:     colorDepth(),
+		UTF8:           localeUTF8(),
+	}
+
+	env.Interactive = env.StdinTerminal && env.StdoutTerminal
+
+	return env
+}
+
+// String returns the description of the environment as one "name:
+// value" line per field, suitable for bug reports.
+func (env EnvironmentInfo) String() string {
+	var sb strings.Builder
+
+	for _, f := range []struct {
+		name  string
+		value any
+	}{
+		{"os", env.OS + "/" + env.Arch},
+		{"stdin terminal", env.StdinTerminal},
+		{"stdout terminal", env.StdoutTerminal},
+		{"stderr terminal", env.StderrTerminal},
+		{"interactive", env.Interactive},
+		{"ssh", env.SSH},
+		{"ci", env.CI},
+		{"ci provider", env.CIProvider},
+		{"container", env.Container},
+		{"terminal", env.Terminal},
+		{"color", env.ColorDepth},
+		{"utf-8", env.UTF8},
+	} {
+		if s, ok := f.value.(string); ok && s == "" {
+			continue
+		}
+
+		fmt.Fprintf(&sb, "%s: %v\n", f.name, f.value)
+	}
+
+	return sb.String()
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd())
+}
+
+// isSSH reports whether the process is running in an SSH session.
+func isSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// container returns the name of the container runtime the process is
+// running in, or an empty string.
+func container() string {
+	switch {
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "":
+		return "kubernetes"
+	case fileExists("/.dockerenv"):
+		return "docker"
+	case fileExists("/run/.containerenv"):
+		return "podman"
+	}
+
+	// set by systemd-nspawn, LXC and others
+	return os.Getenv("container")
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+// terminalName returns the name of the terminal emulator, or the value
+// of TERM if the emulator is not known.
+func terminalName() string {
+	if p := os.Getenv("TERM_PROGRAM"); p != "" {
+		if v := os.Getenv("TERM_PROGRAM_VERSION"); v != "" {
+			return p + " " + v
+		}
+
+		return p
+	}
+
+	switch {
+	case os.Getenv("WT_SESSION") != "":
+		return "Windows Terminal"
+	case os.Getenv("KITTY_WINDOW_ID") != "":
+		return "kitty"
+	case os.Getenv("KONSOLE_VERSION") != "":
+		return "Konsole"
+	case os.Getenv("VTE_VERSION") != "":
+		return "VTE"
+	}
+
+	return os.Getenv("TERM")
+}
+
+// colorDepth returns the color depth of the terminal based on the
+// COLORTERM and TERM variables.
+func colorDepth() ColorDepth {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorDepthNone
+	}
+
+	ct := strings.ToLower(os.Getenv("COLORTERM"))
+	if ct == "truecolor" || ct == "24bit" || os.Getenv("WT_SESSION") != "" {
+		return ColorDepthTrue
+	}
+
+	term := os.Getenv("TERM")
+
+	switch {
+	case strings.Contains(term, "256color"):
+		return ColorDepth256
+	case term == "dumb":
+		return ColorDepthNone
+	case term != "" || runtime.GOOS == "windows":
+		return ColorDepth16
+	}
+
+	return ColorDepthNone
+}
@@ -0,0 +1,85 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestEnvironment(t *testing.T) {
+	for _, k := range []string{"GITHUB_ACTIONS", "NO_COLOR", "TERM_PROGRAM", "WT_SESSION", "KITTY_WINDOW_ID", "KONSOLE_VERSION", "VTE_VERSION"} {
+		t.Setenv(k, "")
+	}
+
+	t.Setenv("CI", "")
+	os.Unsetenv("CI")
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("SSH_CONNECTION", "10.0.0.1 22 10.0.0.2 22")
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLORTERM", "")
+	t.Setenv("KUBERNETES_SERVICE_HOST", "10.0.0.1")
+
+	env := cli.Environment()
+
+	if !env.CI || env.CIProvider != "GitLab CI" {
+		t.Errorf("expected GitLab CI, received %v %q", env.CI, env.CIProvider)
+	}
+
+	if !env.SSH {
+		t.Error("expected SSH session")
+	}
+
+	if env.Container != "kubernetes" {
+		t.Errorf("expected kubernetes, received %q", env.Container)
+	}
+
+	if env.Terminal != "xterm-256color" || env.ColorDepth != cli.ColorDepth256 {
+		t.Errorf("unexpected terminal %q with %s", env.Terminal, env.ColorDepth)
+	}
+
+	if env.Interactive != (env.StdinTerminal && env.StdoutTerminal) {
+		t.Error("unexpected interactive value")
+	}
+
+	t.Setenv("COLORTERM", "truecolor")
+	t.Setenv("TERM_PROGRAM", "WezTerm")
+	t.Setenv("TERM_PROGRAM_VERSION", "20240203")
+
+	env = cli.Environment()
+
+	if env.Terminal != "WezTerm 20240203" || env.ColorDepth != cli.ColorDepthTrue {
+		t.Errorf("unexpected terminal %q with %s", env.Terminal, env.ColorDepth)
+	}
+
+	s := env.String()
+
+	for _, line := range []string{"ci provider: GitLab CI\n", "container: kubernetes\n", "color: true color\n", "ssh: true\n"} {
+		if !strings.Contains(s, line) {
+			t.Errorf("expected %q in:\n%s", line, s)
+		}
+	}
+}
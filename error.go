@@ -0,0 +1,232 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth is the number of frames recorded by an Error.
+const maxStackDepth = 32
+
+// Error is a user facing error, carrying an underlying cause,
+// suggestions for resolving the problem and a link to documentation.
+// PrintError, Fatal and Run present an Error as a block on Stderr:
+//
+//	error: unable to load configuration
+//	  caused by: open /etc/tool.conf: permission denied
+//	try: run with sudo
+//	see: https://example.com/docs/config
+//
+// An Error records the stack where it was created, which is printed
+// only when debugging is enabled, see SetDebug.
+type Error struct {
+	// Message describes the failure.
+	Message string
+
+	// Cause is the underlying error, if any.
+	Cause error
+
+	// Suggestions are hints for resolving the problem.
+	Suggestions []string
+
+	// DocsURL links to further documentation.
+	DocsURL string
+
+	stack []uintptr
+}
+
+// NewError returns an Error with the given message, recording the stack
+// of the caller.
+func NewError(msg string) *Error {
+	return newError(msg, nil)
+}
+
+// WrapError returns an Error with the given message and cause,
+// recording the stack of the caller.
+func WrapError(err error, msg string) *Error {
+	return newError(msg, err)
+}
+
+// newError returns an Error recording the stack of the caller of its
+// caller.
+func newError(msg string, cause error) *Error {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(3, pcs)
+
+	return &Error{Message: msg, Cause: cause, stack: pcs[:n]}
+}
+
+// WithCause sets the underlying cause of e and returns e.
+func (e *Error) WithCause(err error) *Error {
+	e.Cause = err
+
+	return e
+}
+
+// WithSuggestion adds suggestions to e and returns e.
+func (e *Error) WithSuggestion(s ...string) *Error {
+	e.Suggestions = append(e.Suggestions, s...)
+
+	return e
+}
+
+// WithDocs sets the documentation link of e and returns e.
+func (e *Error) WithDocs(url string) *Error {
+	e.DocsURL = url
+
+	return e
+}
+
+// Error returns the message followed by the cause, if any.
+func (e *Error) Error() string {
+	if e.Cause == nil {
+		return e.Message
+	}
+
+	return e.Message + ": " + e.Cause.Error()
+}
+
+// Unwrap returns the cause of e.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// StackTrace returns the stack recorded when e was created, one frame
+// per pair of lines in the format used by panics, or an empty string if
+// no stack was recorded.
+func (e *Error) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	frames := runtime.CallersFrames(e.stack)
+
+	for {
+		f, more := frames.Next()
+
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+// SetDebug sets whether debugging output, such as the stack traces of
+// errors printed by PrintError, is shown. Debugging is also enabled if
+// the DEBUG environment variable is set to a true value as understood
+// by strconv.ParseBool.
+func (tp *TermPrinter) SetDebug(on bool) {
+	tp.debug = on
+}
+
+// Debug reports whether debugging output is enabled.
+func (tp *TermPrinter) Debug() bool {
+	if tp.debug {
+		return true
+	}
+
+	on, _ := strconv.ParseBool(os.Getenv("DEBUG"))
+
+	return on
+}
+
+// WithDebugFlag adds a -debug flag which enables debugging output as
+// with SetDebug.
+func WithDebugFlag() Option {
+	return func(c *Cmd) {
+		c.FlagSet.BoolVar(&c.debug, "debug", false, "show debugging output, such as error stack traces")
+	}
+}
+
+// PrintError prints err to Stderr. If err is or wraps an *Error, it is
+// printed as a block with its cause chain indented beneath the message,
+// followed by its suggestions and documentation link, colored if color
+// is enabled, and its stack trace if debugging is enabled. Other errors
+// are printed as with Eprintln. Each error printed is counted in the
+// Summary.
+func (tp *TermPrinter) PrintError(err error) {
+	tp.counts.errors.Add(1)
+
+	var ce *Error
+
+	if !errors.As(err, &ce) {
+		tp.Eprintln(err)
+
+		return
+	}
+
+	var sb strings.Builder
+
+	// keep any context added by wrapping the Error
+	msg := strings.TrimSuffix(err.Error(), ce.Error()) + ce.Message
+
+	sb.WriteString(tp.Ecolorize(Red, "error:") + " " + msg + "\n")
+
+	indent := "  "
+
+	for cause := ce.Cause; cause != nil; {
+		text := cause.Error()
+		next := errors.Unwrap(cause)
+
+		if next != nil {
+			trimmed := strings.TrimSuffix(text, ": "+next.Error())
+			if trimmed == text {
+				// the message does not end with the wrapped error, so
+				// it already describes the rest of the chain
+				next = nil
+			}
+
+			text = trimmed
+		}
+
+		sb.WriteString(indent + "caused by: " + text + "\n")
+
+		indent += "  "
+		cause = next
+	}
+
+	for _, s := range ce.Suggestions {
+		sb.WriteString(tp.Ecolorize(Yellow, "try:") + " " + s + "\n")
+	}
+
+	if ce.DocsURL != "" {
+		sb.WriteString(tp.Ecolorize(Cyan, "see:") + " " + ce.DocsURL + "\n")
+	}
+
+	if st := ce.StackTrace(); st != "" && tp.Debug() {
+		sb.WriteString("\nstack:\n" + st)
+	}
+
+	tp.Eprint(sb.String())
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestPrintError(t *testing.T) {
+	t.Setenv("DEBUG", "")
+
+	base := fmt.Errorf("open config: %w", errTest)
+
+	cerr := cli.NewError("unable to load configuration").
+		WithCause(base).
+		WithSuggestion("run 'tool init'", "pass -config").
+		WithDocs("https://example.com/config")
+
+	if !errors.Is(cerr, errTest) {
+		t.Error("expected error to wrap cause")
+	}
+
+	if s := cerr.Error(); s != "unable to load configuration: open config: testing error" {
+		t.Errorf("unexpected message %q", s)
+	}
+
+	for _, tc := range []struct {
+		name string
+		err  error
+		exp  string
+	}{
+		{
+			name: "Error",
+			err:  cerr,
+			exp: "error: unable to load configuration\n" +
+				"  caused by: open config\n" +
+				"    caused by: testing error\n" +
+				"try: run 'tool init'\n" +
+				"try: pass -config\n" +
+				"see: https://example.com/config\n",
+		},
+		{
+			name: "Wrapped",
+			err:  fmt.Errorf("startup: %w", cli.NewError("failed")),
+			exp:  "error: startup: failed\n",
+		},
+		{
+			name: "Plain",
+			err:  base,
+			exp:  "open config: testing error\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+
+			tp := cli.NewTermPrinter()
+			tp.SetStderr(buf)
+			tp.PrintError(tc.err)
+
+			if buf.String() != tc.exp {
+				t.Errorf("expected:\n%s\nreceived:\n%s", tc.exp, buf)
+			}
+		})
+	}
+}
+
+func TestErrorStackTrace(t *testing.T) {
+	t.Setenv("DEBUG", "")
+
+	cerr := cli.WrapError(errTest, "failed")
+
+	if !strings.Contains(cerr.StackTrace(), "cli_test.TestErrorStackTrace\n") {
+		t.Errorf("expected stack of caller, received:\n%s", cerr.StackTrace())
+	}
+
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd(cli.WithDebugFlag())
+	cmd.SetStderr(buf)
+	cmd.PrintError(cerr)
+
+	if strings.Contains(buf.String(), "stack:") {
+		t.Errorf("unexpected stack trace in %q", buf)
+	}
+
+	err := cmd.Parse([]string{"-debug"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	buf.Reset()
+	cmd.PrintError(cerr)
+
+	if !strings.Contains(buf.String(), "\nstack:\nkreklow.us/go/cli_test.TestErrorStackTrace\n") {
+		t.Errorf("expected stack trace, received %q", buf)
+	}
+
+	t.Setenv("DEBUG", "1")
+
+	if !cli.NewTermPrinter().Debug() {
+		t.Error("expected DEBUG to enable debugging")
+	}
+}
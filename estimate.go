@@ -0,0 +1,150 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"math"
+	"time"
+)
+
+// defaultHalfLife is the half-life of the estimator used by progress
+// displays unless another is set with SetRateEstimator.
+const defaultHalfLife = 3 * time.Second
+
+// RateEstimator estimates the throughput of a progress display, from
+// which the estimated time remaining is derived. A RateEstimator is
+// used by a single display, so it need not be safe for concurrent use.
+type RateEstimator interface {
+	// Update records that done units had been completed at time t.
+	// Updates are made in order of t.
+	Update(t time.Time, done int64)
+
+	// Rate returns the estimated units completed per second, or zero
+	// if there is not yet an estimate.
+	Rate() float64
+}
+
+// ewmaEstimator is the RateEstimator returned by NewEWMAEstimator.
+type ewmaEstimator struct {
+	halfLife time.Duration
+
+	last     time.Time
+	lastDone int64
+	rate     float64
+	started  bool
+	primed   bool
+}
+
+// NewEWMAEstimator returns a RateEstimator which smooths the rate
+// measured between updates with an exponentially weighted moving
+// average. A measurement halfLife old carries half the weight of the
+// latest one, so longer half-lives give steadier but slower to react
+// estimates. The weight depends on the time between updates, so the
+// estimate does not depend on how often the display is drawn.
+func NewEWMAEstimator(halfLife time.Duration) RateEstimator {
+	if halfLife <= 0 {
+		halfLife = defaultHalfLife
+	}
+
+	return &ewmaEstimator{halfLife: halfLife}
+}
+
+// Update records that done units had been completed at time t.
+func (e *ewmaEstimator) Update(t time.Time, done int64) {
+	if !e.started {
+		e.last, e.lastDone, e.started = t, done, true
+
+		return
+	}
+
+	dt := t.Sub(e.last)
+	if dt <= 0 {
+		return
+	}
+
+	rate := float64(done-e.lastDone) / dt.Seconds()
+
+	if e.primed {
+		alpha := 1 - math.Exp(-math.Ln2*float64(dt)/float64(e.halfLife))
+		rate = e.rate + alpha*(rate-e.rate)
+	}
+
+	e.rate, e.primed = rate, true
+	e.last, e.lastDone = t, done
+}
+
+// Rate returns the smoothed rate.
+func (e *ewmaEstimator) Rate() float64 {
+	return e.rate
+}
+
+// averageEstimator is the RateEstimator returned by
+// NewAverageEstimator.
+type averageEstimator struct {
+	start     time.Time
+	startDone int64
+	rate      float64
+	started   bool
+}
+
+// NewAverageEstimator returns a RateEstimator which divides the units
+// completed by the time elapsed since the first update. It is steady
+// once the rate settles, but slow to reflect changes.
+func NewAverageEstimator() RateEstimator {
+	return new(averageEstimator)
+}
+
+// Update records that done units had been completed at time t.
+func (e *averageEstimator) Update(t time.Time, done int64) {
+	if !e.started {
+		e.start, e.startDone, e.started = t, done, true
+
+		return
+	}
+
+	if s := t.Sub(e.start).Seconds(); s > 0 {
+		e.rate = float64(done-e.startDone) / s
+	}
+}
+
+// Rate returns the average rate.
+func (e *averageEstimator) Rate() float64 {
+	return e.rate
+}
+
+// SetRateEstimator sets the function called to create the RateEstimator
+// of each progress display, such as those of Copy and Download. The
+// default is an estimator from NewEWMAEstimator with a half-life of
+// three seconds. Passing nil restores the default.
+func (tp *TermPrinter) SetRateEstimator(fn func() RateEstimator) {
+	tp.estimator = fn
+}
+
+// newRateEstimator returns a RateEstimator for a progress display.
+func (tp *TermPrinter) newRateEstimator() RateEstimator {
+	if tp.estimator == nil {
+		return NewEWMAEstimator(defaultHalfLife)
+	}
+
+	return tp.estimator()
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestEWMAEstimator(t *testing.T) {
+	est := cli.NewEWMAEstimator(time.Second)
+	start := time.Unix(0, 0)
+
+	if est.Rate() != 0 {
+		t.Error("expected no estimate before updates, received", est.Rate())
+	}
+
+	est.Update(start, 0)
+	est.Update(start.Add(time.Second), 100)
+
+	if est.Rate() != 100 {
+		t.Error("expected first measured rate 100, received", est.Rate())
+	}
+
+	// a measurement one half-life long moves the estimate halfway
+	est.Update(start.Add(2*time.Second), 400)
+
+	if est.Rate() != 200 {
+		t.Error("expected rate 200, received", est.Rate())
+	}
+
+	// a burst followed by a stall is smoothed
+	done := int64(400)
+
+	for i := 3; i < 13; i++ {
+		if i%2 == 0 {
+			done += 200
+		}
+
+		est.Update(start.Add(time.Duration(i)*100*time.Millisecond+2*time.Second), done)
+
+		if r := est.Rate(); r < 150 || r > 1000 {
+			t.Errorf("expected smoothed rate, received %f", r)
+		}
+	}
+}
+
+func TestAverageEstimator(t *testing.T) {
+	est := cli.NewAverageEstimator()
+	start := time.Unix(0, 0)
+
+	est.Update(start, 50)
+	est.Update(start.Add(time.Second), 50)
+	est.Update(start.Add(4*time.Second), 450)
+
+	if math.Abs(est.Rate()-100) > 1e-9 {
+		t.Error("expected rate 100, received", est.Rate())
+	}
+}
+
+// fixedEstimator is a RateEstimator with a constant rate.
+type fixedEstimator float64
+
+func (fixedEstimator) Update(time.Time, int64) {}
+
+func (f fixedEstimator) Rate() float64 { return float64(f) }
+
+func TestSetRateEstimator(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetLocale("C")
+	cmd.SetRateEstimator(func() cli.RateEstimator { return fixedEstimator(1024) })
+
+	_, err := cmd.Copy(new(bytes.Buffer), strings.NewReader("data"), 10240)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.Contains(outbuf.String(), "1.0 KiB/s  ETA 10s") {
+		t.Errorf("expected rate and ETA from estimator, received %q", outbuf)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event types written to the event writer.
+const (
+	// EventPrint is emitted for content written by Print, Printf and
+	// Println.
+	EventPrint = "print"
+
+	// EventError is emitted for content written by Eprint, Eprintf and
+	// Eprintln.
+	EventError = "error"
+
+	// EventProgress is emitted for content written to the live region.
+	EventProgress = "progress"
+)
+
+// Event is a structured record of printer activity, written as a line
+// of JSON to the event writer.
+type Event struct {
+	Time   time.Time `json:"time"`
+	Type   string    `json:"type"`
+	Stream string    `json:"stream,omitempty"`
+	Text   string    `json:"text,omitempty"`
+}
+
+// eventState holds the event writer of a TermPrinter.
+type eventState struct {
+	m       sync.Mutex
+	enc     *json.Encoder
+	enabled atomic.Bool
+}
+
+// SetEventWriter enables a stream of events written to w as one JSON
+// object per line, allowing other programs to follow the progress of
+// the application while the terminal output is unchanged. Events are
+// emitted for all printed content, along with any events sent with
+// Event. Passing nil disables events.
+func (tp *TermPrinter) SetEventWriter(w io.Writer) {
+	tp.events.m.Lock()
+	defer tp.events.m.Unlock()
+
+	if w == nil {
+		tp.events.enc = nil
+		tp.events.enabled.Store(false)
+
+		return
+	}
+
+	tp.events.enc = json.NewEncoder(w)
+	tp.events.enabled.Store(true)
+}
+
+// Event writes an application-defined event of type typ, such as the
+// start of a new section of work, to the event writer. Event does
+// nothing if no event writer is set.
+func (tp *TermPrinter) Event(typ string, text string) {
+	tp.events.m.Lock()
+	defer tp.events.m.Unlock()
+
+	if tp.events.enc == nil {
+		return
+	}
+
+	_ = tp.events.enc.Encode(Event{
+		Time: tp.now(),
+		Type: typ,
+		Text: text,
+	})
+}
+
+// eventsEnabled reports whether an event writer is set, without taking
+// the lock, as it is checked on every write.
+func (tp *TermPrinter) eventsEnabled() bool {
+	return tp.events.enabled.Load()
+}
+
+// emit writes an event for content written to stream s.
+func (tp *TermPrinter) emit(typ string, s Stream, b []byte) {
+	tp.events.m.Lock()
+	defer tp.events.m.Unlock()
+
+	if tp.events.enc == nil {
+		return
+	}
+
+	_ = tp.events.enc.Encode(Event{
+		Time:   tp.now(),
+		Type:   typ,
+		Stream: s.String(),
+		Text:   string(b),
+	})
+}
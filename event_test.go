@@ -0,0 +1,87 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestEventWriter(t *testing.T) {
+	evbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(new(bytes.Buffer))
+	p.SetStderr(new(bytes.Buffer))
+
+	p.Event("section", "ignored")
+
+	p.SetEventWriter(evbuf)
+
+	p.Event("section", "build")
+	writeLprintf(p)
+
+	p.SetEventWriter(nil)
+	p.Println("ignored")
+
+	exp := []cli.Event{
+		{Type: "section", Text: "build"},
+		{Type: cli.EventPrint, Stream: "stdout", Text: "print 1\n"},
+		{Type: cli.EventError, Stream: "stderr", Text: "print 2\n"},
+		{Type: cli.EventPrint, Stream: "stdout", Text: "print 3\n"},
+		{Type: cli.EventProgress, Stream: "stdout", Text: "print 4\n"},
+		{Type: cli.EventProgress, Stream: "stdout", Text: "print 5\n"},
+		{Type: cli.EventError, Stream: "stderr", Text: "print 6\n"},
+		{Type: cli.EventProgress, Stream: "stdout", Text: "print 7\n"},
+		{Type: cli.EventPrint, Stream: "stdout", Text: "print 8\n"},
+		{Type: cli.EventError, Stream: "stderr", Text: "print 9\n"},
+	}
+
+	dec := json.NewDecoder(evbuf)
+
+	for i, e := range exp {
+		var ev cli.Event
+
+		err := dec.Decode(&ev)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if ev.Time.IsZero() {
+			t.Error("expected time in event", i)
+		}
+
+		ev.Time = e.Time
+
+		if ev != e {
+			t.Errorf("unexpected event %d: %+v", i, ev)
+		}
+	}
+
+	if dec.More() {
+		t.Error("unexpected additional events")
+	}
+}
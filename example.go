@@ -0,0 +1,220 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// ErrExample is returned by VerifyExamples when an example does not
+// match the commands and flags of the Cmd.
+var ErrExample = errors.New("invalid example")
+
+// Example is a sample invocation shown in help output.
+type Example struct {
+	// Description explains what the example does.
+	Description string
+
+	// Cmdline is the complete command line, beginning with the name of
+	// the program.
+	Cmdline string
+}
+
+// AddExample adds an example of running the program, shown by Help when
+// called without arguments.
+func (c *Cmd) AddExample(desc string, cmdline string) {
+	c.examples = append(c.examples, Example{Description: desc, Cmdline: cmdline})
+}
+
+// AddExample adds an example of running cmd, shown by Help for cmd.
+// The command line includes the program name and the path of cmd, such
+// as "tool remote add origin https://example.com".
+func (cmd *Command) AddExample(desc string, cmdline string) {
+	cmd.examples = append(cmd.examples, Example{Description: desc, Cmdline: cmdline})
+}
+
+// printExamples prints examples under a heading, if there are any.
+func (c *Cmd) printExamples(examples []Example) {
+	if len(examples) == 0 {
+		return
+	}
+
+	c.Println("\nexamples:")
+
+	for _, ex := range examples {
+		if ex.Description != "" {
+			c.Printf("  %s\n", ex.Description)
+		}
+
+		c.Printf("    $ %s\n", ex.Cmdline)
+	}
+}
+
+// VerifyExamples checks that each example added with AddExample selects
+// the command it was added to and uses only flags defined for that
+// command. Flag values are not checked and no flags are modified. It is
+// intended to be called from a test, so documentation stays accurate
+// as commands change. All failures are joined in the returned error.
+func (c *Cmd) VerifyExamples() error {
+	var errs []error
+
+	check := func(want string, examples []Example) {
+		for _, ex := range examples {
+			got, err := c.resolveExample(ex.Cmdline)
+			if err == nil && got != want {
+				err = fmt.Errorf("runs %s, expected %s", //nolint:err113 // wrapped below
+					describePath(got), describePath(want))
+			}
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w '%s': %w", ErrExample, ex.Cmdline, err))
+			}
+		}
+	}
+
+	check("", c.examples)
+
+	var walk func(cmds []*Command)
+
+	walk = func(cmds []*Command) {
+		for _, cmd := range cmds {
+			check(cmd.Path(), cmd.examples)
+			walk(cmd.Commands())
+		}
+	}
+
+	walk(c.Commands())
+
+	return errors.Join(errs...)
+}
+
+// describePath returns the quoted command path, or "the program" if
+// path is empty.
+func describePath(path string) string {
+	if path == "" {
+		return "the program"
+	}
+
+	return "'" + path + "'"
+}
+
+// resolveExample parses cmdline as Dispatch would, using placeholder
+// flag values, and returns the path of the command selected, or an
+// empty string if no command is selected.
+func (c *Cmd) resolveExample(cmdline string) (string, error) {
+	args, err := SplitArgs(cmdline)
+	if err != nil {
+		return "", err
+	}
+
+	if len(args) > 0 && filepath.Base(args[0]) == filepath.Base(c.FlagSet.Name()) {
+		args = args[1:]
+	}
+
+	fs := exampleFlagSet(c.FlagSet)
+
+	err = c.parseFlags(fs, args, func(name string) bool {
+		_, ok := c.commands[name]
+
+		return ok
+	})
+	if err != nil {
+		return "", err
+	}
+
+	args = fs.Args()
+
+	if len(c.commands) == 0 || len(args) == 0 {
+		return "", nil
+	}
+
+	cmd, ok := c.commands[args[0]]
+	if !ok {
+		return "", unknownCommand(args[0], c.Commands())
+	}
+
+	for {
+		cmd.inheritFlags()
+
+		fs = exampleFlagSet(cmd.FlagSet)
+
+		err = c.parseFlags(fs, args[1:], cmd.isCommand)
+		if err != nil {
+			return "", err
+		}
+
+		args = fs.Args()
+
+		if len(cmd.commands) == 0 || len(args) == 0 {
+			return cmd.Path(), nil
+		}
+
+		sub, ok := cmd.commands[args[0]]
+		if !ok {
+			if cmd.runnable() {
+				return cmd.Path(), nil
+			}
+
+			return "", unknownCommand(args[0], cmd.Commands())
+		}
+
+		cmd = sub
+	}
+}
+
+// exampleFlagSet returns a FlagSet defining the flags of fs with values
+// which accept anything, so examples can be parsed without side
+// effects.
+func exampleFlagSet(fs *flag.FlagSet) *flag.FlagSet {
+	efs := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	efs.SetOutput(io.Discard)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		efs.Var(exampleValue(isBoolFlag(f)), f.Name, f.Usage)
+	})
+
+	return efs
+}
+
+// exampleValue is a flag.Value accepting any value, which is a boolean
+// flag if true.
+type exampleValue bool
+
+// String returns an empty string.
+func (exampleValue) String() string {
+	return ""
+}
+
+// Set accepts any value.
+func (exampleValue) Set(string) error {
+	return nil
+}
+
+// IsBoolFlag reports whether the flag is a boolean flag.
+func (v exampleValue) IsBoolFlag() bool {
+	return bool(v)
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestExamples(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+
+	verbose := cmd.FlagSet.Bool("v", false, "verbose output")
+
+	remote := cmd.AddCommand("remote", "manage remotes", nil)
+	add := remote.AddCommand("add", "add a remote", func([]string) error { return nil })
+	fetch := add.FlagSet.Bool("fetch", false, "fetch after adding")
+	name := add.FlagSet.String("name", "origin", "remote name")
+
+	prog := filepath.Base(cmd.FlagSet.Name())
+
+	cmd.AddExample("list commands", prog+" -v")
+	add.AddExample("add and fetch a remote", prog+" -v remote add -fetch -name up https://example.com")
+
+	err := cmd.VerifyExamples()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if *verbose || *fetch || *name != "origin" {
+		t.Error("flags modified by VerifyExamples")
+	}
+
+	err = cmd.Help([]string{"remote", "add"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := "\nexamples:\n  add and fetch a remote\n    $ " + prog +
+		" -v remote add -fetch -name up https://example.com\n"
+	if !strings.HasSuffix(buf.String(), exp) {
+		t.Errorf("expected suffix %q, received %q", exp, buf)
+	}
+
+	remote.AddExample("wrong command", prog+" remote add x")
+	add.AddExample("unknown flag", prog+" remote add -force x")
+	cmd.AddExample("unknown command", prog+" remotes")
+
+	err = cmd.VerifyExamples()
+	if !errors.Is(err, cli.ErrExample) {
+		t.Fatal("expected ErrExample, received", err)
+	}
+
+	for _, s := range []string{
+		"runs 'remote add', expected 'remote'",
+		"flag provided but not defined: -force",
+		"unknown command 'remotes'",
+	} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("expected error to contain %q, received %q", s, err)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// ExitError is returned by Exec when the command runs but exits with a
+// non-zero status.
+type ExitError struct {
+	Name string
+	Code int
+}
+
+// Error returns a description of the exit status.
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("%s: exit status %d", e.Name, e.Code)
+}
+
+// ExecOptions configures the behavior of Exec.
+type ExecOptions struct {
+	// Prefix is printed before each line of output.
+	Prefix string
+
+	// Color, if not zero, is used to color the prefix.
+	Color Color
+
+	// ColorStderr prints lines from the command's stderr in red.
+	ColorStderr bool
+
+	// Dir is the working directory of the command.
+	Dir string
+
+	// Env is the environment of the command, see exec.Cmd.
+	Env []string
+
+	// Stdin is the input of the command.
+	Stdin io.Reader
+
+	// ProcessGroup starts the command in a process group of its own,
+	// so signals generated by the terminal, such as Ctrl-C, reach the
+	// program but not the command. Signals sent to the command are
+	// sent to its whole group.
+	ProcessGroup bool
+
+	// ForwardSignals lists signals which are passed on to the command
+	// when received by the program while the command runs. On Windows
+	// only os.Interrupt can be forwarded, as a Ctrl-Break event, and
+	// only with ProcessGroup.
+	ForwardSignals []os.Signal
+
+	// GracePeriod, if positive, makes the command be interrupted with
+	// SIGTERM rather than killed when ctx is done or the exit channel
+	// closes, and killed only if it has not exited after GracePeriod.
+	// With ProcessGroup, this lets the program shut down in order on
+	// the first Ctrl-C, giving the command time to exit cleanly.
+	GracePeriod time.Duration
+}
+
+// Exec runs the named command with the given arguments, streaming its
+// output through the TermPrinter. See ExecWith.
+func (c *Cmd) Exec(ctx context.Context, name string, args ...string) error {
+	return c.ExecWith(ctx, ExecOptions{}, name, args...)
+}
+
+// ExecWith runs the named command with the given arguments and options.
+// Each line the command writes to stdout is printed with Println, and
+// each line written to stderr is printed with Eprintln, so the output
+// of the command does not disrupt the live region.
+//
+// The command is killed if ctx is done or the exit channel closes, or
+// interrupted first if GracePeriod is set. If the command exits with a
+// non-zero status, the error is an *ExitError. If a line is longer than
+// 1 MiB, the rest of that output is discarded, and once the command has
+// exited successfully, an error wrapping bufio.ErrTooLong is returned.
+func (c *Cmd) ExecWith(ctx context.Context, opts ExecOptions, name string, args ...string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.Add(1)
+	defer c.Done()
+
+	go func() {
+		select {
+		case <-c.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.Env
+	cmd.Stdin = opts.Stdin
+
+	if opts.ProcessGroup {
+		setProcessGroup(cmd)
+	}
+
+	setCancel(cmd, opts.ProcessGroup, opts.GracePeriod)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	defer forwardSignals(cmd.Process, opts.ProcessGroup, opts.ForwardSignals)()
+
+	var (
+		wg   sync.WaitGroup
+		errs [2]error
+	)
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		errs[0] = c.streamLines(stdout, opts, Stdout)
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		errs[1] = c.streamLines(stderr, opts, Stderr)
+	}()
+
+	wg.Wait()
+
+	err = cmd.Wait()
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ctx.Err() == nil {
+		return &ExitError{Name: name, Code: ee.ExitCode()}
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+
+	if err == nil {
+		if serr := errors.Join(errs[:]...); serr != nil {
+			return fmt.Errorf("%s: %w", name, serr)
+		}
+	}
+
+	return err
+}
+
+// setCancel sets how cmd is stopped when its context is done. The
+// command, or its process group if group is true, is killed, or if
+// grace is positive it is interrupted, then killed if it has not exited
+// after grace.
+func setCancel(cmd *exec.Cmd, group bool, grace time.Duration) {
+	if grace <= 0 {
+		cmd.Cancel = func() error {
+			return signalProcess(cmd.Process, group, os.Kill)
+		}
+
+		return
+	}
+
+	cmd.Cancel = func() error {
+		return interruptProcess(cmd.Process, group)
+	}
+	cmd.WaitDelay = grace
+}
+
+// forwardSignals passes the signals sigs received by the program on to
+// p, or its process group if group is true, until the returned
+// function is called.
+func forwardSignals(p *os.Process, group bool, sigs []os.Signal) func() {
+	if len(sigs) == 0 {
+		return func() {}
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	done := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				_ = signalProcess(p, group, sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// streamLines prints each line read from r to stream s. If r cannot be
+// read, or holds a line which is too long, the rest of r is discarded
+// and the error is returned.
+func (c *Cmd) streamLines(r io.Reader, opts ExecOptions, s Stream) error {
+	prefix := opts.Prefix
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1024*1024)
+
+	for sc.Scan() {
+		line := sc.Text()
+
+		if s == Stderr {
+			if opts.Prefix != "" && opts.Color != 0 {
+				prefix = c.Ecolorize(opts.Color, opts.Prefix)
+			}
+
+			if opts.ColorStderr {
+				line = c.Ecolorize(Red, line)
+			}
+
+			c.Eprintln(prefix + line)
+
+			continue
+		}
+
+		if opts.Prefix != "" && opts.Color != 0 {
+			prefix = c.Colorize(opts.Color, opts.Prefix)
+		}
+
+		c.Println(prefix + line)
+	}
+
+	// drain any remaining output so the command does not block
+	_, _ = io.Copy(io.Discard, r)
+
+	return sc.Err()
+}
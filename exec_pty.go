@@ -0,0 +1,218 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/mattn/go-isatty"
+	"golang.org/x/sys/unix"
+)
+
+// ptyKillDelay is the time a child run by ExecPTY is given to exit
+// after SIGTERM before it is killed.
+const ptyKillDelay = 5 * time.Second
+
+// ExecPTY runs the named command with the given arguments attached to a
+// pseudo-terminal, for interactive programs such as editors or ssh.
+// The live region is cleared first, then input is passed to the
+// command and its output is written to Stdout unchanged.
+//
+// If Stdin is a terminal, it is put into raw mode while the command
+// runs and restored afterward, and changes to its size are passed on
+// to the pseudo-terminal. If Stdin has been set to a reader which is
+// not an *os.File, it continues to be read in the background after the
+// command exits until it returns an error. Signals generated by keys
+// such as Ctrl-C are delivered to the command through the
+// pseudo-terminal. If ctx is done or the exit channel closes, the
+// command is sent SIGTERM, then killed if it has not exited after five
+// seconds. If the command exits with a non-zero status, the error is an
+// *ExitError.
+func (c *Cmd) ExecPTY(ctx context.Context, name string, args ...string) error {
+	return c.ExecPTYWith(ctx, ExecOptions{}, name, args...)
+}
+
+// ExecPTYWith runs the named command attached to a pseudo-terminal, as
+// with ExecPTY, using the Dir, Env, ForwardSignals and GracePeriod
+// options. The command always runs in a session and process group of
+// its own, and signals are sent to the whole group. If GracePeriod is
+// zero, the command is killed five seconds after SIGTERM.
+func (c *Cmd) ExecPTYWith(ctx context.Context, opts ExecOptions, name string, args ...string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c.Add(1)
+	defer c.Done()
+
+	go func() {
+		select {
+		case <-c.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if c.outIsTerm && !c.ciEnabled() {
+		c.clearLiveLines()
+	}
+
+	grace := opts.GracePeriod
+	if grace <= 0 {
+		grace = ptyKillDelay
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.Env
+
+	// pty.Start makes the command a session leader, which also leads
+	// its own process group
+	setCancel(cmd, true, grace)
+
+	ptmx, err := pty.Start(cmd)
+	if err != nil {
+		return err
+	}
+
+	defer ptmx.Close()
+	defer forwardSignals(cmd.Process, true, opts.ForwardSignals)()
+
+	in := c.stdinReader()
+
+	if f, ok := in.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		stop := proxyWinsize(f, ptmx)
+		defer stop()
+
+		restore, err := makeRaw(f.Fd())
+		if err == nil {
+			defer c.guardTerminal(restore)()
+		}
+	}
+
+	stopInput := copyInput(ptmx, in)
+
+	done := make(chan bool)
+
+	go func() {
+		defer close(done)
+
+		// reading fails with EIO once the command and its children
+		// have closed the terminal
+		_, _ = io.Copy(c.writer(Stdout), ptmx)
+	}()
+
+	err = cmd.Wait()
+
+	stopInput()
+	<-done
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) && ctx.Err() == nil {
+		return &ExitError{Name: name, Code: ee.ExitCode()}
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", name, ctx.Err())
+	}
+
+	return err
+}
+
+// proxyWinsize sets the size of ptmx to that of tty, and again each
+// time the size of tty changes, until the returned function is called.
+func proxyWinsize(tty *os.File, ptmx *os.File) func() {
+	_ = pty.InheritSize(tty, ptmx)
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+
+	done := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				_ = pty.InheritSize(tty, ptmx)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+// copyInput copies in to ptmx until the returned function is called.
+// If in is a file, it is read through a non-blocking duplicate of its
+// descriptor, so reading can be interrupted rather than consuming
+// input typed after the command exits.
+func copyInput(ptmx *os.File, in io.Reader) func() {
+	f, ok := in.(*os.File)
+	if !ok {
+		go func() { _, _ = io.Copy(ptmx, in) }()
+
+		return func() {}
+	}
+
+	fd, err := unix.Dup(int(f.Fd()))
+	if err != nil {
+		go func() { _, _ = io.Copy(ptmx, in) }()
+
+		return func() {}
+	}
+
+	// the flag is shared with the original descriptor, so it is reset
+	// once copying stops
+	_ = unix.SetNonblock(fd, true)
+
+	dup := os.NewFile(uintptr(fd), f.Name())
+	done := make(chan bool)
+
+	go func() {
+		defer close(done)
+
+		_, _ = io.Copy(ptmx, dup)
+	}()
+
+	return func() {
+		_ = dup.SetReadDeadline(time.Now())
+		<-done
+
+		_ = unix.SetNonblock(fd, false)
+		_ = dup.Close()
+	}
+}
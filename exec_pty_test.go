@@ -0,0 +1,97 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestExecPTY(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals not supported")
+	}
+
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer null.Close()
+
+	cmd.SetStdin(null)
+
+	err = cmd.ExecPTY(context.Background(), "sh", "-c", "test -t 0 && test -t 1 && echo on a tty")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.Contains(buf.String(), "on a tty") {
+		t.Errorf("expected output from a terminal, received %q", buf)
+	}
+
+	err = cmd.ExecPTY(context.Background(), "sh", "-c", "exit 3")
+
+	var ee *cli.ExitError
+	if !errors.As(err, &ee) || ee.Code != 3 {
+		t.Error("expected exit status 3, received", err)
+	}
+}
+
+func TestExecPTYTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pseudo-terminals not supported")
+	}
+
+	cons := newTestConsole(t)
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+	cmd.SetStdin(cons.Tty())
+
+	go func() {
+		_, _ = cons.Send("hello\r")
+	}()
+
+	err := cmd.ExecPTY(context.Background(), "sh", "-c", "read line; echo got $line")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.Contains(buf.String(), "got hello") {
+		t.Errorf("expected input to be passed on, received %q", buf)
+	}
+}
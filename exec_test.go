@@ -0,0 +1,116 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestExec(t *testing.T) {
+	t.Run("Output", testExecOutput)
+	t.Run("Canceled", testExecCanceled)
+	t.Run("LongLine", testExecLongLine)
+}
+
+func testExecOutput(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetStderr(errbuf)
+
+	err := cmd.ExecWith(context.Background(), cli.ExecOptions{Prefix: "[sh] "},
+		"sh", "-c", "echo one; echo two >&2; echo three; exit 3")
+
+	var ee *cli.ExitError
+	if !errors.As(err, &ee) || ee.Code != 3 || ee.Error() != "sh: exit status 3" {
+		t.Error("unexpected error:", err)
+	}
+
+	if outbuf.String() != "[sh] one\n[sh] three\n" {
+		t.Error("unexpected output", outbuf.String())
+	}
+
+	if errbuf.String() != "[sh] two\n" {
+		t.Error("unexpected output", errbuf.String())
+	}
+
+	err = cmd.Exec(context.Background(), "true")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.Exec(context.Background(), "/nonexistent/command")
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+}
+
+func testExecLongLine(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	// a 2 MiB line followed by enough output to fill the pipe
+	err := cmd.Exec(context.Background(), "sh", "-c",
+		"echo first; head -c 2097152 /dev/zero | tr '\\0' x; echo; "+
+			"head -c 1048576 /dev/zero | tr '\\0' '\\n'; echo last")
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Error("expected ErrTooLong, received", err)
+	}
+
+	if outbuf.String() != "first\n" {
+		t.Errorf("unexpected output %.40q", outbuf.String())
+	}
+}
+
+func testExecCanceled(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	cmd.After(50*time.Millisecond, func() { cmd.Exit(nil) })
+
+	start := time.Now()
+
+	err := cmd.Exec(context.Background(), "sleep", "10")
+	if !errors.Is(err, context.Canceled) {
+		t.Error("unexpected error:", err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Error("command not killed")
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
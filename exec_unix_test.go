@@ -0,0 +1,128 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build unix
+
+package cli_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+// execLines runs script with sh and opts, returning the error of
+// ExecWith and a channel receiving each line of output. The script
+// should print "ready" once its traps are set.
+func execLines(cmd *cli.Cmd, opts cli.ExecOptions, script string) (chan error, chan string) {
+	r, w := io.Pipe()
+	cmd.SetStdout(w)
+
+	// the shell reports its sleep being killed
+	cmd.SetStderr(io.Discard)
+
+	lines := make(chan string, 10)
+
+	go func() {
+		defer close(lines)
+
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.ExecWith(context.Background(), opts, "sh", "-c", script)
+		w.Close()
+	}()
+
+	return errc, lines
+}
+
+func TestExecGracePeriod(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	errc, lines := execLines(cmd, cli.ExecOptions{
+		ProcessGroup: true,
+		GracePeriod:  5 * time.Second,
+	}, "trap 'echo stopping; exit 0' TERM; echo ready; while :; do sleep 0.05; done")
+
+	if l := <-lines; l != "ready" {
+		t.Fatal("unexpected output:", l)
+	}
+
+	start := time.Now()
+
+	cmd.Exit(nil)
+
+	if l := <-lines; l != "stopping" {
+		t.Error("unexpected output:", l)
+	}
+
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Error("unexpected error:", err)
+	}
+
+	if time.Since(start) > 4*time.Second {
+		t.Error("command not interrupted")
+	}
+
+	if err := cmd.Wait(); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestExecForwardSignals(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	errc, lines := execLines(cmd, cli.ExecOptions{
+		ProcessGroup:   true,
+		ForwardSignals: []os.Signal{syscall.SIGUSR1},
+	}, "trap 'echo usr1; exit 0' USR1; echo ready; while :; do sleep 0.05; done")
+
+	if l := <-lines; l != "ready" {
+		t.Fatal("unexpected output:", l)
+	}
+
+	err := syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if l := <-lines; l != "usr1" {
+		t.Error("unexpected output:", l)
+	}
+
+	if err := <-errc; err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -45,7 +45,8 @@ import (
 // If a timeout has been set, the closure of the exit channel will also
 // trigger a timer which calls os.Exit upon expiration. Sending an exit
 // signal during the timeout will abort the timer and call os.Exit
-// immediately.
+// immediately. The timer stops once Wait has returned and the exit
+// hooks have finished.
 //
 // If an error is passed to Exit, the error will be returned to the
 // caller of Wait once all the goroutines being awaited call Done. If a
@@ -53,6 +54,7 @@ import (
 // printed to os.Stderr before os.Exit is called.
 type ExitHandler struct {
 	timeout int64 // guarantee 64 bit alignment on 32 bit platforms
+	window  int64
 
 	wg sync.WaitGroup
 
@@ -63,11 +65,24 @@ type ExitHandler struct {
 	// ec is the send end of C, reserved for closing.
 	ec chan<- bool
 
-	sc chan os.Signal
+	sc      chan os.Signal
+	watchM  sync.Mutex
+	signals []os.Signal
 
 	exitOnce  sync.Once
 	watchOnce sync.Once
 
+	finOnce  sync.Once
+	fin      chan bool
+	finClose sync.Once
+
+	rl    reloader
+	hooks exitHooks
+	term  termRestorers
+	clk   Clock
+
+	cleanup cleanupState
+
 	err error
 }
 
@@ -97,17 +112,36 @@ func (e *ExitHandler) Exit(err error) {
 
 // timeoutWait implements the timeout, called once by Exit.
 func (e *ExitHandler) timeoutWait(t int64) {
+	timer := e.clock().NewTimer(time.Duration(t))
+	defer timer.Stop()
+
+	var reason string
+
 	select {
-	case <-time.After(time.Duration(t)):
-		fmt.Fprintln(os.Stderr, "exit forced by timeout")
+	case <-timer.C():
+		reason = "exit forced by timeout"
 	case <-e.sc:
-		fmt.Fprintln(os.Stderr, "exit forced by signal")
+		reason = "exit forced by signal"
+	case <-e.finished():
+		return
 	}
 
+	// shutdown may have completed as the timer fired
+	select {
+	case <-e.finished():
+		return
+	default:
+	}
+
+	e.restoreTerminal()
+	fmt.Fprintln(os.Stderr, reason)
+
 	if e.err != nil {
 		fmt.Fprintln(os.Stderr, e.err)
 	}
 
+	e.runHooks(errForcedExit, true)
+
 	os.Exit(int(syscall.ETIME))
 }
 
@@ -131,31 +165,111 @@ func (e *ExitHandler) Done() {
 	e.wg.Done()
 }
 
-// Wait blocks until the WaitGroup counter is zero. The return value is
-// the first error value passed to Exit.
+// Wait blocks until the WaitGroup counter is zero, then restores any
+// terminal changes registered with GuardTerminal and calls the exit
+// hooks added with OnExit. The return value is the first error value
+// passed to Exit. If SetBackgroundCleanup is enabled, the exit hooks are
+// started in a new goroutine instead, and WaitCleanup waits for them.
 func (e *ExitHandler) Wait() error {
 	e.wg.Wait()
 
+	e.restoreTerminal()
+
+	if e.cleanup.background.Load() {
+		e.startCleanup()
+
+		return e.err
+	}
+
+	e.runHooks(e.err, false)
+	e.finish()
+
 	return e.err
 }
 
+// finished returns a channel which is closed once shutdown is complete.
+func (e *ExitHandler) finished() <-chan bool {
+	e.finOnce.Do(func() {
+		e.fin = make(chan bool)
+	})
+
+	return e.fin
+}
+
+// finish marks shutdown as complete, stopping the timeout timer.
+func (e *ExitHandler) finish() {
+	e.finished()
+
+	e.finClose.Do(func() {
+		close(e.fin)
+	})
+}
+
+// Every calls fn repeatedly at interval d in a goroutine managed by the
+// ExitHandler, until the exit channel closes. The first call occurs
+// after d has elapsed. If a call to fn is still running when the exit
+// channel closes, it is allowed to complete.
+func (e *ExitHandler) Every(d time.Duration, fn func()) {
+	e.Add(1)
+
+	go func() {
+		defer e.Done()
+
+		t := e.clock().NewTicker(d)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-t.C():
+				fn()
+			case <-e.C:
+				return
+			}
+		}
+	}()
+}
+
+// After calls fn once after d has elapsed in a goroutine managed by the
+// ExitHandler. If the exit channel closes before d has elapsed, fn is
+// not called.
+func (e *ExitHandler) After(d time.Duration, fn func()) {
+	e.Add(1)
+
+	go func() {
+		defer e.Done()
+
+		t := e.clock().NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-t.C():
+			fn()
+		case <-e.C:
+		}
+	}()
+}
+
 // Watch takes a list of signals to receive from the operating system
 // which will trigger Exit. Watch can be called multiple times, each
 // call to Watch will replace the previous list of signals with the new
-// list. An empty list will stop receiving signals from the OS.
+// list. An empty list will stop receiving signals from the OS. Once
+// OnReload has been called, SIGHUP triggers a reload rather than Exit.
 func (e *ExitHandler) Watch(signals ...os.Signal) {
+	e.watchM.Lock()
+	defer e.watchM.Unlock()
+
 	if e.sc == nil {
 		e.sc = make(chan os.Signal, 1)
 	}
 
-	signal.Stop(e.sc)
+	e.signals = signals
+
+	e.notifyWatched()
 
 	if len(signals) == 0 {
 		return
 	}
 
-	signal.Notify(e.sc, signals...)
-
 	e.watchOnce.Do(func() {
 		if e.ec == nil {
 			c := make(chan bool)
@@ -174,3 +288,28 @@ func (e *ExitHandler) Watch(signals ...os.Signal) {
 		}()
 	})
 }
+
+// notifyWatched relays the signals passed to Watch to the exit channel,
+// leaving out SIGHUP if it is used for reloads. Must be called with
+// watchM held.
+func (e *ExitHandler) notifyWatched() {
+	if e.sc == nil {
+		return
+	}
+
+	signal.Stop(e.sc)
+
+	signals := make([]os.Signal, 0, len(e.signals))
+
+	for _, s := range e.signals {
+		if s == syscall.SIGHUP && e.rl.active.Load() {
+			continue
+		}
+
+		signals = append(signals, s)
+	}
+
+	if len(signals) > 0 {
+		signal.Notify(e.sc, signals...)
+	}
+}
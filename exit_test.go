@@ -28,11 +28,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
 
 	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
 )
 
 func ExampleExitHandler() {
@@ -84,10 +86,116 @@ func ExampleExitHandler() {
 	// Cleaned up
 }
 
+func TestScheduled(t *testing.T) {
+	t.Run("Every", testExitEvery)
+	t.Run("After", testExitAfter)
+	t.Run("AfterCanceled", testExitAfterCanceled)
+	t.Run("FakeClock", testExitFakeClock)
+}
+
+func testExitEvery(t *testing.T) {
+	eh := new(cli.ExitHandler)
+
+	var count int64
+
+	eh.Every(10*time.Millisecond, func() {
+		if atomic.AddInt64(&count, 1) == 3 {
+			eh.Exit(nil)
+		}
+	})
+
+	err := eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if n := atomic.LoadInt64(&count); n != 3 {
+		t.Error("expected 3 calls, got", n)
+	}
+}
+
+func testExitAfter(t *testing.T) {
+	eh := new(cli.ExitHandler)
+
+	var called bool
+
+	eh.After(10*time.Millisecond, func() {
+		called = true
+	})
+
+	err := eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if !called {
+		t.Error("expected function to be called")
+	}
+}
+
+func testExitAfterCanceled(t *testing.T) {
+	eh := new(cli.ExitHandler)
+
+	var called bool
+
+	eh.After(time.Second, func() {
+		called = true
+	})
+
+	eh.Exit(nil)
+
+	err := eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if called {
+		t.Error("expected function not to be called")
+	}
+}
+
+func testExitFakeClock(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	eh := new(cli.ExitHandler)
+	eh.SetClock(clk)
+
+	ticks := make(chan bool)
+	after := make(chan bool)
+
+	eh.Every(time.Minute, func() { ticks <- true })
+	eh.After(90*time.Second, func() { after <- true })
+
+	clk.BlockUntil(2)
+
+	clk.Advance(time.Minute)
+	<-ticks
+
+	select {
+	case <-after:
+		t.Fatal("After called early")
+	default:
+	}
+
+	clk.Advance(30 * time.Second)
+	<-after
+
+	clk.Advance(30 * time.Second)
+	<-ticks
+
+	eh.Exit(nil)
+
+	err := eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
 func TestSignalExit(t *testing.T) {
 	t.Run("Normal", testExitSignal)
 	t.Run("Reset", testExitReset)
 	t.Run("None", testExitNone)
+	t.Run("TimeoutStopped", testExitTimeoutStopped)
 }
 
 func testExitSignal(t *testing.T) {
@@ -142,11 +250,33 @@ func testExitReset(t *testing.T) {
 	signal.Reset()
 }
 
+func testExitTimeoutStopped(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	eh := new(cli.ExitHandler)
+	eh.SetClock(clk)
+	eh.SetTimeout(time.Second)
+
+	eh.Add(1)
+	eh.Exit(nil)
+	eh.Done()
+
+	err := eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	// the process exits here if the timer is still running
+	clk.Advance(2 * time.Second)
+	time.Sleep(50 * time.Millisecond)
+}
+
 func testExitNone(t *testing.T) {
 	eh := new(cli.ExitHandler)
 
 	eh.Watch(syscall.SIGUSR1)
 	eh.SetTimeout(10 * time.Second)
+	eh.SetClock(clitest.NewFakeClock(time.Unix(0, 0)))
 
 	eh.Add(1)
 
@@ -0,0 +1,75 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"sync"
+)
+
+// errForcedExit is passed to exit hooks when the exit is forced by a
+// timeout or signal.
+var errForcedExit = errors.New("exit forced")
+
+// exitHooks holds the exit hooks of an ExitHandler.
+type exitHooks struct {
+	m   sync.Mutex
+	fns []func(err error)
+	ran bool
+}
+
+// OnExit adds fn to the functions called once the application is
+// shutting down, in the reverse order in which they were added. Hooks
+// are called by Wait once all goroutines are done, with the error
+// passed to Exit, and also before a forced exit by timeout or signal,
+// with a non-nil error. Each hook is called at most once.
+func (e *ExitHandler) OnExit(fn func(err error)) {
+	e.hooks.m.Lock()
+	e.hooks.fns = append(e.hooks.fns, fn)
+	e.hooks.m.Unlock()
+}
+
+// runHooks calls the exit hooks with err, unless they have already run.
+// If force is true and the hooks are running in another goroutine,
+// runHooks returns immediately rather than waiting.
+func (e *ExitHandler) runHooks(err error, force bool) {
+	if force {
+		if !e.hooks.m.TryLock() {
+			return
+		}
+	} else {
+		e.hooks.m.Lock()
+	}
+
+	defer e.hooks.m.Unlock()
+
+	if e.hooks.ran {
+		return
+	}
+
+	e.hooks.ran = true
+
+	for i := len(e.hooks.fns) - 1; i >= 0; i-- {
+		e.hooks.fns[i](err)
+	}
+}
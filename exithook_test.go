@@ -0,0 +1,64 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestOnExit(t *testing.T) {
+	eh := new(cli.ExitHandler)
+
+	var calls []string
+
+	eh.OnExit(func(err error) {
+		calls = append(calls, "first: "+err.Error())
+	})
+	eh.OnExit(func(err error) {
+		calls = append(calls, "second: "+err.Error())
+	})
+
+	eh.Add(1)
+
+	go func() {
+		defer eh.Done()
+
+		eh.Exit(errTest)
+	}()
+
+	err := eh.Wait()
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error", err)
+	}
+
+	_ = eh.Wait()
+
+	exp := []string{"second: testing error", "first: testing error"}
+	if !reflect.DeepEqual(calls, exp) {
+		t.Errorf("expected %q, received %q", exp, calls)
+	}
+}
@@ -0,0 +1,472 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrArchiveFormat is returned by Extract when the archive is neither
+// a gzip compressed tar file nor a zip file.
+var ErrArchiveFormat = errors.New("unsupported archive format")
+
+// ErrUnsafePath is returned by Extract when an entry of the archive
+// would be written outside of the destination directory.
+var ErrUnsafePath = errors.New("unsafe path in archive")
+
+// Extract extracts the archive at src, which may be a gzip compressed
+// tar file or a zip file, into the directory dest, showing a live
+// progress line. The format is detected from the content of the file.
+//
+// Entries with absolute paths, paths leading outside of dest, or links
+// pointing outside of dest cause Extract to stop with an error wrapping
+// ErrUnsafePath. Extract stops with ErrInterrupted if the exit channel
+// closes, leaving the entries extracted so far in place.
+func (c *Cmd) Extract(src string, dest string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	magic := make([]byte, 4)
+
+	_, err = io.ReadFull(f, magic)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrArchiveFormat, src)
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dest, 0o755)
+	if err != nil {
+		return err
+	}
+
+	realDest, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+
+	x := &extractor{c: c, dest: dest, realDest: realDest}
+	label := filepath.Base(src)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		x.pw = newProgressWriter(c.TermPrinter, label, 0, fi.Size())
+		err = x.tarGz(io.TeeReader(f, x.pw))
+	case bytes.Equal(magic, []byte("PK\x03\x04")):
+		err = x.zip(f, fi.Size(), label)
+	default:
+		return fmt.Errorf("%w: %s", ErrArchiveFormat, src)
+	}
+
+	if err != nil {
+		if c.exiting() {
+			err = ErrInterrupted
+		}
+
+		c.FinalizeLive(fmt.Sprintf("%s: %v", label, err))
+
+		if x.pw != nil {
+			x.pw.end(err)
+		}
+
+		return err
+	}
+
+	x.pw.finish(fmt.Sprintf("%s  %d files extracted", label, x.files))
+
+	return nil
+}
+
+// extractor holds the state of an extraction.
+type extractor struct {
+	c        *Cmd
+	dest     string
+	realDest string
+	pw       *progressWriter
+	files    int
+}
+
+// tarGz extracts a gzip compressed tar file from r.
+func (x *extractor) tarGz(r io.Reader) error {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if x.c.exiting() {
+			return ErrInterrupted
+		}
+
+		path, err := x.path(hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := os.FileMode(hdr.Mode).Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.mkdir(path)
+		case tar.TypeReg:
+			err = x.writeFile(path, mode, tr, nil)
+		case tar.TypeSymlink:
+			err = x.symlink(path, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.link(path, hdr.Linkname)
+		default:
+			// devices, FIFOs and other special files are skipped
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// zip extracts the zip file r of the given size.
+func (x *extractor) zip(r io.ReaderAt, size int64, label string) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, zf := range zr.File {
+		total += int64(zf.UncompressedSize64)
+	}
+
+	x.pw = newProgressWriter(x.c.TermPrinter, label, 0, total)
+
+	for _, zf := range zr.File {
+		if x.c.exiting() {
+			return ErrInterrupted
+		}
+
+		err = x.zipFile(zf)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// zipFile extracts a single entry of a zip file.
+func (x *extractor) zipFile(zf *zip.File) error {
+	path, err := x.path(zf.Name)
+	if err != nil {
+		return err
+	}
+
+	mode := zf.Mode()
+
+	if mode.IsDir() {
+		return x.mkdir(path)
+	}
+
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+
+	defer rc.Close()
+
+	if mode&os.ModeSymlink != 0 {
+		target, err := io.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return err
+		}
+
+		return x.symlink(path, string(target))
+	}
+
+	return x.writeFile(path, mode.Perm(), rc, x.pw)
+}
+
+// path returns the path within dest of the entry name.
+func (x *extractor) path(name string) (string, error) {
+	name = filepath.FromSlash(name)
+
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(name, `\`) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	path := filepath.Join(x.dest, name)
+
+	if !x.inside(path) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	return path, nil
+}
+
+// inside reports whether path is within dest.
+func (x *extractor) inside(path string) bool {
+	rel, err := filepath.Rel(x.dest, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// mkdir creates the directory dir. Links extracted earlier are
+// followed to check that dir is really within dest, since a chain of
+// links may lead outside even though each appears safe.
+func (x *extractor) mkdir(dir string) error {
+	existing := dir
+
+	for {
+		_, err := os.Lstat(existing)
+		if err == nil {
+			break
+		}
+
+		existing = filepath.Dir(existing)
+	}
+
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return err
+	}
+
+	if !x.realInside(resolved) {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, dir)
+	}
+
+	return os.MkdirAll(dir, 0o755)
+}
+
+// writeFile writes the content of r to a new file at path. If pw is not
+// nil, the bytes written are counted towards progress.
+func (x *extractor) writeFile(path string, mode os.FileMode, r io.Reader, pw *progressWriter) error {
+	err := x.mkdir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	// an existing file, or a link left by the archive, is replaced
+	// rather than written through
+	_ = os.Remove(path)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode|0o200)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(f)
+	if pw != nil {
+		w = io.MultiWriter(f, pw)
+	}
+
+	_, err = io.Copy(w, exitReader{c: x.c, r: r})
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	x.files++
+
+	return err
+}
+
+// symlink creates a symbolic link at path to target, which must be
+// within dest, following any links extracted earlier.
+func (x *extractor) symlink(path string, target string) error {
+	resolved := filepath.FromSlash(target)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(filepath.Dir(path), resolved)
+	}
+
+	if filepath.IsAbs(filepath.FromSlash(target)) || !x.inside(resolved) ||
+		!x.insideReal(filepath.Dir(path)+string(filepath.Separator)+filepath.FromSlash(target)) {
+		return fmt.Errorf("%w: %s -> %s", ErrUnsafePath, path, target)
+	}
+
+	err := x.mkdir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(path)
+
+	return os.Symlink(target, path)
+}
+
+// link creates a hard link at path to the entry name, which must be
+// within dest, following any links extracted earlier.
+func (x *extractor) link(path string, name string) error {
+	_, err := x.path(name)
+	if err != nil {
+		return err
+	}
+
+	target, err := resolveLinks(x.dest + string(filepath.Separator) + filepath.FromSlash(name))
+	if err != nil {
+		return err
+	}
+
+	if !x.realInside(target) {
+		return fmt.Errorf("%w: %s", ErrUnsafePath, name)
+	}
+
+	err = x.mkdir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(path)
+
+	return os.Link(target, path)
+}
+
+// insideReal reports whether path is within dest once the links in it
+// have been followed.
+func (x *extractor) insideReal(path string) bool {
+	resolved, err := resolveLinks(path)
+
+	return err == nil && x.realInside(resolved)
+}
+
+// realInside reports whether the resolved path is within the resolved
+// dest.
+func (x *extractor) realInside(resolved string) bool {
+	rel, err := filepath.Rel(x.realDest, resolved)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// maxLinks is the number of links resolveLinks follows before giving up.
+const maxLinks = 40
+
+// resolveLinks returns the location of the absolute path p once any
+// links in it have been followed. Unlike filepath.EvalSymlinks, p and
+// the targets of its links need not exist, and each ".." applies to the
+// location reached so far, as it does when the path is opened.
+func resolveLinks(p string) (string, error) {
+	sep := string(filepath.Separator)
+	vol := filepath.VolumeName(p)
+
+	resolved := vol + sep
+	pending := strings.Split(p[len(vol):], sep)
+	links := 0
+
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		switch name {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+
+			continue
+		}
+
+		next := filepath.Join(resolved, name)
+
+		fi, err := os.Lstat(next)
+
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			resolved = next
+		case err != nil:
+			return "", err
+		case fi.Mode()&os.ModeSymlink != 0:
+			links++
+			if links > maxLinks {
+				return "", fmt.Errorf("%w: too many links: %s", ErrUnsafePath, p)
+			}
+
+			target, err := os.Readlink(next)
+			if err != nil {
+				return "", err
+			}
+
+			target = filepath.FromSlash(target)
+
+			if filepath.IsAbs(target) {
+				vol = filepath.VolumeName(target)
+				resolved = vol + sep
+				target = target[len(vol):]
+			}
+
+			pending = append(strings.Split(target, sep), pending...)
+		default:
+			resolved = next
+		}
+	}
+
+	return resolved, nil
+}
+
+// exitReader is a reader which fails with ErrInterrupted once the exit
+// channel of c closes.
+type exitReader struct {
+	c *Cmd
+	r io.Reader
+}
+
+// Read reads from the embedded reader unless the Cmd is exiting.
+func (er exitReader) Read(b []byte) (int, error) {
+	if er.c.exiting() {
+		return 0, ErrInterrupted
+	}
+
+	return er.r.Read(b)
+}
@@ -0,0 +1,279 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+// tarEntry is an entry of a test archive.
+type tarEntry struct {
+	name string
+	body string
+	link string
+	hard bool
+}
+
+// writeTarGz writes a gzip compressed tar file of entries to path.
+func writeTarGz(t *testing.T, path string, entries []tarEntry) {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+
+		switch {
+		case e.hard:
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, e.link, 0
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		}
+
+		err := tw.WriteHeader(hdr)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		_, _ = tw.Write([]byte(e.body))
+	}
+
+	tw.Close()
+	gz.Close()
+
+	err := os.WriteFile(path, buf.Bytes(), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	t.Run("TarGz", testExtractTarGz)
+	t.Run("Zip", testExtractZip)
+	t.Run("Unsafe", testExtractUnsafe)
+	t.Run("HardLink", testExtractHardLink)
+	t.Run("Format", testExtractFormat)
+}
+
+func testExtractTarGz(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "test.tar.gz")
+
+	writeTarGz(t, src, []tarEntry{
+		{name: "top.txt", body: "top"},
+		{name: "sub/nested.txt", body: "nested"},
+	})
+
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	err := cmd.Extract(src, filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, filepath.Join(dir, "out", "top.txt"), []byte("top"))
+	checkFile(t, filepath.Join(dir, "out", "sub", "nested.txt"), []byte("nested"))
+
+	if !strings.HasSuffix(outbuf.String(), "\ntest.tar.gz  2 files extracted\n") {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+}
+
+func testExtractZip(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "test.zip")
+
+	buf := new(bytes.Buffer)
+	zw := zip.NewWriter(buf)
+
+	for name, body := range map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		_, _ = w.Write([]byte(body))
+	}
+
+	zw.Close()
+
+	err := os.WriteFile(src, buf.Bytes(), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	err = cmd.Extract(src, filepath.Join(dir, "out"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, filepath.Join(dir, "out", "a.txt"), []byte("alpha"))
+	checkFile(t, filepath.Join(dir, "out", "dir", "b.txt"), []byte("bravo"))
+}
+
+func testExtractUnsafe(t *testing.T) {
+	cases := map[string][]tarEntry{
+		"Parent":   {{name: "../evil.txt", body: "evil"}},
+		"Absolute": {{name: "/tmp/evil.txt", body: "evil"}},
+		"Symlink":  {{name: "link", link: "../.."}},
+	}
+
+	if runtime.GOOS != "windows" {
+		// each link appears to stay within the destination, but
+		// together they lead to its parent
+		cases["Chain"] = []tarEntry{
+			{name: "d", link: "."},
+			{name: "c", link: "d/.."},
+			{name: "c/evil.txt", body: "evil"},
+		}
+
+		// a hard link through a chain of links to a file outside
+		cases["LinkChain"] = []tarEntry{
+			{name: "d", link: "."},
+			{name: "c", link: "d/.."},
+			{name: "evil.txt", link: "c/secret.txt", hard: true},
+		}
+	}
+
+	for name, entries := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			src := filepath.Join(dir, "unsafe.tar.gz")
+
+			writeTarGz(t, src, entries)
+
+			err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			cmd := cli.NewCmd()
+			cmd.SetStdout(new(bytes.Buffer))
+
+			err = cmd.Extract(src, filepath.Join(dir, "out"))
+			if !errors.Is(err, cli.ErrUnsafePath) {
+				t.Error("expected ErrUnsafePath, received", err)
+			}
+
+			outside := filepath.Join(dir, "evil.txt")
+			linked := filepath.Join(dir, "out", "evil.txt")
+
+			for _, path := range []string{outside, linked} {
+				_, err = os.Stat(path)
+				if !os.IsNotExist(err) {
+					t.Errorf("expected %s not to exist", path)
+				}
+			}
+		})
+	}
+}
+
+func testExtractHardLink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require privileges on windows")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+
+	err := os.MkdirAll(filepath.Join(dir, "outside"), 0o755)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "outside", "secret.txt"), []byte("secret"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	src := filepath.Join(dir, "links.tar.gz")
+
+	writeTarGz(t, src, []tarEntry{
+		{name: "a.txt", body: "alpha"},
+		{name: "sub/b.txt", link: "a.txt", hard: true},
+		{name: "up", link: "sub/.."},
+		{name: "c.txt", link: "up/sub/b.txt", hard: true},
+	})
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	err = cmd.Extract(src, out)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	checkFile(t, filepath.Join(out, "sub", "b.txt"), []byte("alpha"))
+	checkFile(t, filepath.Join(out, "c.txt"), []byte("alpha"))
+
+	// a link already in the destination leads outside
+	err = os.Symlink(filepath.Join(dir, "outside"), filepath.Join(out, "ext"))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	writeTarGz(t, src, []tarEntry{{name: "copy.txt", link: "ext/secret.txt", hard: true}})
+
+	err = cmd.Extract(src, out)
+	if !errors.Is(err, cli.ErrUnsafePath) {
+		t.Error("expected ErrUnsafePath, received", err)
+	}
+
+	_, err = os.Stat(filepath.Join(out, "copy.txt"))
+	if !os.IsNotExist(err) {
+		t.Error("expected hard link not to exist")
+	}
+}
+
+func testExtractFormat(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "plain.txt")
+
+	err := os.WriteFile(src, []byte("not an archive"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cli.NewCmd().Extract(src, t.TempDir())
+	if !errors.Is(err, cli.ErrArchiveFormat) {
+		t.Error("expected ErrArchiveFormat, received", err)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetExitFunc sets the function called by Fatal and Fatalf after the
+// error has been printed. The default calls os.Exit(1). A Cmd sets the
+// exit func to call Exit on its ExitHandler.
+func (tp *TermPrinter) SetExitFunc(fn func(err error)) {
+	tp.exitFunc = fn
+}
+
+// Fatal clears the live region, prints err to Stderr with PrintError,
+// then calls the exit func. With the default exit func, Fatal does not
+// return. If the exit func returns, as with a Cmd, the caller should
+// return promptly to allow the application to shut down.
+func (tp *TermPrinter) Fatal(err error) {
+	if tp.outIsTerm && !tp.ciEnabled() {
+		tp.clearLiveLines()
+	}
+
+	tp.PrintError(err)
+
+	if tp.exitFunc == nil {
+		_ = tp.StopWriteQueue()
+
+		os.Exit(1)
+	}
+
+	tp.exitFunc(err)
+}
+
+// Fatalf operates in the manner of Fatal, using an error formatted in
+// the manner of fmt.Errorf.
+func (tp *TermPrinter) Fatalf(f string, v ...interface{}) {
+	//nolint:err113 // dynamic error requested by caller
+	tp.Fatal(fmt.Errorf(f, v...))
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestFatal(t *testing.T) {
+	t.Run("Cmd", testFatalCmd)
+	t.Run("Console", testFatalConsole)
+}
+
+func testFatalCmd(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(errbuf)
+
+	cmd.Add(1)
+
+	go func() {
+		defer cmd.Done()
+
+		cmd.Fatalf("fatal: %w", errTest)
+	}()
+
+	err := cmd.Wait()
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error:", err)
+	}
+
+	if errbuf.String() != "fatal: testing error\n" {
+		t.Error("unexpected output", errbuf.String())
+	}
+}
+
+func testFatalConsole(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	var outstr string
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		outstr, err = cons.ExpectString("testing error")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetStderr(cons.Tty())
+
+	var exitErr error
+
+	p.SetExitFunc(func(err error) { exitErr = err })
+
+	p.Lprintf("status\n")
+	p.Fatal(errTest)
+
+	wg.Wait()
+
+	if outstr != "status\r\n\x1b[1A\x1b[2Ktesting error" {
+		t.Errorf("unexpected output %q", outstr)
+	}
+
+	if !errors.Is(exitErr, errTest) {
+		t.Error("unexpected error:", exitErr)
+	}
+}
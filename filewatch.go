@@ -0,0 +1,326 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timing of file watching.
+const (
+	fileWatchDebounce = 100 * time.Millisecond
+	filePollInterval  = 500 * time.Millisecond
+)
+
+// FileOp describes the changes made to a file. Changes made within the
+// debounce window are combined, so more than one may be set.
+type FileOp uint8
+
+// File changes reported by WatchFiles.
+const (
+	FileCreate FileOp = 1 << iota
+	FileWrite
+	FileRemove
+)
+
+// String returns the names of the changes separated by "|".
+func (op FileOp) String() string {
+	var names []string
+
+	for _, n := range []struct {
+		op   FileOp
+		name string
+	}{
+		{FileCreate, "create"},
+		{FileWrite, "write"},
+		{FileRemove, "remove"},
+	} {
+		if op&n.op != 0 {
+			names = append(names, n.name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// FileEvent is a change to a watched file.
+type FileEvent struct {
+	Path string
+	Op   FileOp
+}
+
+// fileWatcher is the platform specific source of file events.
+type fileWatcher interface {
+	// run sends events to out until close is called.
+	run(out chan<- FileEvent)
+	close()
+}
+
+// WatchFiles calls fn for changes to the files at paths. A path naming a
+// directory watches the entries of the directory, but not of its
+// subdirectories. Files do not need to exist when WatchFiles is called.
+//
+// Changes are debounced: events for a path are combined until no
+// change has been seen for 100ms, then fn is called once for each path
+// in sorted order. Calls to fn never run concurrently. Watching runs in
+// goroutines managed by the ExitHandler and stops when the exit channel
+// closes.
+func (e *ExitHandler) WatchFiles(paths []string, fn func(FileEvent)) error {
+	targets, err := watchTargets(paths)
+	if err != nil {
+		return err
+	}
+
+	w, err := newFileWatcher(targets, e.clock())
+	if err != nil {
+		return err
+	}
+
+	raw := make(chan FileEvent)
+
+	e.Add(2)
+
+	go func() {
+		defer e.Done()
+		defer close(raw)
+
+		w.run(raw)
+	}()
+
+	go func() {
+		defer e.Done()
+
+		e.debounceFiles(raw, w, fn)
+	}()
+
+	return nil
+}
+
+// debounceFiles combines the events received from raw and passes them
+// to fn, closing w when the exit channel closes.
+func (e *ExitHandler) debounceFiles(raw <-chan FileEvent, w fileWatcher, fn func(FileEvent)) {
+	var (
+		pending = make(map[string]FileOp)
+		timer   Timer
+		fire    <-chan time.Time
+		exit    = e.C
+	)
+
+	for {
+		select {
+		case ev, ok := <-raw:
+			if !ok {
+				return
+			}
+
+			if exit == nil {
+				continue
+			}
+
+			pending[ev.Path] |= ev.Op
+
+			if timer != nil {
+				timer.Stop()
+			}
+
+			timer = e.clock().NewTimer(fileWatchDebounce)
+			fire = timer.C()
+		case <-fire:
+			fire = nil
+
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+
+			sort.Strings(paths)
+
+			for _, p := range paths {
+				fn(FileEvent{Path: p, Op: pending[p]})
+				delete(pending, p)
+			}
+		case <-exit:
+			exit = nil
+			fire = nil
+
+			if timer != nil {
+				timer.Stop()
+			}
+
+			w.close()
+		}
+	}
+}
+
+// watchTargets groups paths by the directory to watch. Directories map
+// to a nil set, meaning all entries are watched, while files add their
+// name to the set of their parent directory, so files replaced by
+// renaming are still seen.
+func watchTargets(paths []string) (map[string]map[string]bool, error) {
+	targets := make(map[string]map[string]bool)
+	all := make(map[string]bool)
+
+	for _, p := range paths {
+		p = filepath.Clean(p)
+
+		fi, err := os.Stat(p)
+
+		switch {
+		case err == nil && fi.IsDir():
+			all[p] = true
+			targets[p] = nil
+
+			continue
+		case err != nil && !os.IsNotExist(err):
+			return nil, err
+		}
+
+		dir, name := filepath.Split(p)
+		dir = filepath.Clean(dir)
+
+		if all[dir] {
+			continue
+		}
+
+		if targets[dir] == nil {
+			targets[dir] = make(map[string]bool)
+		}
+
+		targets[dir][name] = true
+	}
+
+	return targets, nil
+}
+
+// fileState is the state of a file used for polling.
+type fileState struct {
+	mod  time.Time
+	size int64
+}
+
+// pollWatcher watches files by comparing their state at an interval.
+type pollWatcher struct {
+	targets map[string]map[string]bool
+	clk     Clock
+	done    chan bool
+	once    sync.Once
+}
+
+// newPollWatcher returns a pollWatcher for targets.
+func newPollWatcher(targets map[string]map[string]bool, clk Clock) *pollWatcher {
+	return &pollWatcher{targets: targets, clk: clk, done: make(chan bool)}
+}
+
+// run sends the differences between states to out until close is
+// called.
+func (pw *pollWatcher) run(out chan<- FileEvent) {
+	t := pw.clk.NewTicker(filePollInterval)
+	defer t.Stop()
+
+	prev := pw.scan()
+
+	for {
+		select {
+		case <-t.C():
+		case <-pw.done:
+			return
+		}
+
+		cur := pw.scan()
+
+		for p, st := range cur {
+			var op FileOp
+
+			old, ok := prev[p]
+
+			switch {
+			case !ok:
+				op = FileCreate
+			case old != st:
+				op = FileWrite
+			default:
+				continue
+			}
+
+			select {
+			case out <- FileEvent{Path: p, Op: op}:
+			case <-pw.done:
+				return
+			}
+		}
+
+		for p := range prev {
+			if _, ok := cur[p]; ok {
+				continue
+			}
+
+			select {
+			case out <- FileEvent{Path: p, Op: FileRemove}:
+			case <-pw.done:
+				return
+			}
+		}
+
+		prev = cur
+	}
+}
+
+// close stops run.
+func (pw *pollWatcher) close() {
+	pw.once.Do(func() { close(pw.done) })
+}
+
+// scan returns the state of each watched file.
+func (pw *pollWatcher) scan() map[string]fileState {
+	states := make(map[string]fileState)
+
+	for dir, names := range pw.targets {
+		ents, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, ent := range ents {
+			if names != nil && !names[ent.Name()] {
+				continue
+			}
+
+			fi, err := ent.Info()
+			if err != nil {
+				continue
+			}
+
+			states[filepath.Join(dir, ent.Name())] = fileState{
+				mod:  fi.ModTime(),
+				size: fi.Size(),
+			}
+		}
+	}
+
+	return states
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build linux
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask selects the inotify events which are reported.
+const inotifyMask = unix.IN_CREATE | unix.IN_MOVED_TO | unix.IN_MODIFY |
+	unix.IN_CLOSE_WRITE | unix.IN_DELETE | unix.IN_MOVED_FROM
+
+// inotifyDir is a directory watched with inotify.
+type inotifyDir struct {
+	path  string
+	names map[string]bool
+}
+
+// inotifyWatcher watches files with inotify.
+type inotifyWatcher struct {
+	f    *os.File
+	dirs map[int]inotifyDir
+}
+
+// newFileWatcher returns a watcher using inotify for targets, falling
+// back to polling if inotify is not available.
+func newFileWatcher(targets map[string]map[string]bool, clk Clock) (fileWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return newPollWatcher(targets, clk), nil //nolint:nilerr // polling is the fallback
+	}
+
+	// a non-blocking descriptor is handled by the runtime poller, so
+	// closing the file interrupts a pending read
+	iw := &inotifyWatcher{
+		f:    os.NewFile(uintptr(fd), "inotify"),
+		dirs: make(map[int]inotifyDir),
+	}
+
+	for dir, names := range targets {
+		wd, err := unix.InotifyAddWatch(fd, dir, inotifyMask)
+		if err != nil {
+			_ = iw.f.Close()
+
+			return nil, &os.PathError{Op: "watch", Path: dir, Err: err}
+		}
+
+		iw.dirs[wd] = inotifyDir{path: dir, names: names}
+	}
+
+	return iw, nil
+}
+
+// run reads inotify events and sends them to out until close is
+// called.
+func (iw *inotifyWatcher) run(out chan<- FileEvent) {
+	buf := make([]byte, 64*1024)
+
+	for {
+		n, err := iw.f.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for off := 0; off+unix.SizeofInotifyEvent <= n; {
+			raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			name := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(raw.Len)]
+			off += unix.SizeofInotifyEvent + int(raw.Len)
+
+			ev, ok := iw.event(int(raw.Wd), raw.Mask, string(bytes.TrimRight(name, "\x00")))
+			if ok {
+				out <- ev
+			}
+		}
+	}
+}
+
+// event converts an inotify event, reporting whether it is for a
+// watched file.
+func (iw *inotifyWatcher) event(wd int, mask uint32, name string) (FileEvent, bool) {
+	dir, ok := iw.dirs[wd]
+	if !ok || name == "" || (dir.names != nil && !dir.names[name]) {
+		return FileEvent{}, false
+	}
+
+	var op FileOp
+
+	switch {
+	case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		op = FileCreate
+	case mask&(unix.IN_MODIFY|unix.IN_CLOSE_WRITE) != 0:
+		op = FileWrite
+	case mask&(unix.IN_DELETE|unix.IN_MOVED_FROM) != 0:
+		op = FileRemove
+	default:
+		return FileEvent{}, false
+	}
+
+	return FileEvent{Path: filepath.Join(dir.path, name), Op: op}, true
+}
+
+// close closes the inotify descriptor, stopping run.
+func (iw *inotifyWatcher) close() {
+	_ = iw.f.Close()
+}
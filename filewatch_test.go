@@ -0,0 +1,100 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestWatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watched")
+
+	eh := new(cli.ExitHandler)
+	events := make(chan cli.FileEvent, 10)
+
+	err := eh.WatchFiles([]string{path}, func(ev cli.FileEvent) {
+		events <- ev
+	})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expect := func(op cli.FileOp) {
+		t.Helper()
+
+		select {
+		case ev := <-events:
+			if ev.Path != path || ev.Op&op == 0 {
+				t.Errorf("expected %s %s, received %s %s", op, path, ev.Op, ev.Path)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", op)
+		}
+	}
+
+	err = os.WriteFile(filepath.Join(dir, "ignored"), []byte("data"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = os.WriteFile(path, []byte("data"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expect(cli.FileCreate)
+
+	err = os.Remove(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expect(cli.FileRemove)
+
+	eh.Exit(nil)
+
+	err = eh.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	select {
+	case ev := <-events:
+		t.Error("unexpected event", ev)
+	default:
+	}
+}
+
+func TestFileOpString(t *testing.T) {
+	op := cli.FileCreate | cli.FileWrite
+
+	if op.String() != "create|write" {
+		t.Errorf("expected %q, received %q", "create|write", op)
+	}
+}
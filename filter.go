@@ -0,0 +1,170 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// lineFilters holds the line filters of a TermPrinter.
+type lineFilters struct {
+	m       sync.RWMutex
+	fns     []func(string) (string, bool)
+	enabled atomic.Bool
+}
+
+// AddLineFilter adds fn to the list of functions applied to each line
+// printed to Stdout by the Print functions, before it is written. The
+// line is passed without its newline. If fn returns false the line is
+// omitted, otherwise the returned string replaces it. Filters are
+// applied in the order they were added.
+//
+// Output which does not end in a newline is filtered as a line on its
+// own. The live region is not filtered.
+func (tp *TermPrinter) AddLineFilter(fn func(line string) (string, bool)) {
+	tp.filters.m.Lock()
+	tp.filters.fns = append(tp.filters.fns, fn)
+	tp.filters.enabled.Store(true)
+	tp.filters.m.Unlock()
+}
+
+// filterLines applies the line filters to s.
+func (tp *TermPrinter) filterLines(s string) string {
+	if !tp.filters.enabled.Load() {
+		return s
+	}
+
+	tp.filters.m.RLock()
+	fns := tp.filters.fns
+	tp.filters.m.RUnlock()
+
+	if len(fns) == 0 {
+		return s
+	}
+
+	var sb strings.Builder
+
+	for s != "" {
+		line, rest, nl := strings.Cut(s, "\n")
+		s = rest
+
+		keep := true
+
+		for _, fn := range fns {
+			line, keep = fn(line)
+			if !keep {
+				break
+			}
+		}
+
+		if !keep {
+			continue
+		}
+
+		sb.WriteString(line)
+
+		if nl {
+			sb.WriteByte('\n')
+		}
+	}
+
+	return sb.String()
+}
+
+// grepValue is the value of the --grep flag.
+type grepValue struct {
+	re *regexp.Regexp
+}
+
+// String returns the pattern.
+func (g *grepValue) String() string {
+	if g == nil || g.re == nil {
+		return ""
+	}
+
+	return g.re.String()
+}
+
+// Set compiles the pattern.
+func (g *grepValue) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+
+	g.re = re
+
+	return nil
+}
+
+// filter keeps lines matching the pattern, ignoring color escape
+// sequences. All lines are kept if no pattern is set.
+func (g *grepValue) filter(line string) (string, bool) {
+	if g.re == nil {
+		return line, true
+	}
+
+	return line, g.re.MatchString(stripEscapes(line))
+}
+
+// AddGrepFlag adds the --grep flag to the FlagSet, which limits the
+// output printed to Stdout to lines matching a regular expression, in
+// the manner of grep. Color escape sequences are ignored when matching.
+func (c *Cmd) AddGrepFlag() {
+	g := new(grepValue)
+
+	c.FlagSet.Var(g, "grep", "only print output lines matching `regexp`")
+	c.AddLineFilter(g.filter)
+}
+
+// stripEscapes removes escape sequences, such as color and cursor
+// movement sequences, from s.
+func stripEscapes(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+
+	var sb strings.Builder
+
+	for {
+		before, after, ok := strings.Cut(s, "\x1b")
+		sb.WriteString(before)
+
+		if !ok {
+			return sb.String()
+		}
+
+		_, rest, ok := cutEscape("\x1b" + after)
+		if !ok {
+			// a lone ESC at the end of s
+			sb.WriteString("\x1b")
+
+			return sb.String()
+		}
+
+		s = rest
+	}
+}
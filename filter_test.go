@@ -0,0 +1,96 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestLineFilter(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+	errbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+	p.SetStderr(errbuf)
+
+	p.AddLineFilter(func(line string) (string, bool) {
+		return line, !strings.HasPrefix(line, "#")
+	})
+	p.AddLineFilter(func(line string) (string, bool) {
+		return strings.ToUpper(line), true
+	})
+
+	p.Print("one\n# comment\ntwo\n")
+	p.Print("partial")
+	p.Println("# skipped")
+	p.Eprintln("# stderr")
+
+	if outbuf.String() != "ONE\nTWO\nPARTIAL" {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+
+	if errbuf.String() != "# stderr\n" {
+		t.Errorf("unexpected error output %q", errbuf)
+	}
+
+	if s := p.Sprintln("# hidden"); s != "" {
+		t.Errorf("expected Sprintln to filter, received %q", s)
+	}
+}
+
+func TestGrepFlag(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.FlagSet.Init("test", flag.ContinueOnError)
+	cmd.FlagSet.SetOutput(io.Discard)
+	cmd.SetStdout(outbuf)
+	cmd.SetColorMode(cli.ColorAlways)
+	cmd.AddGrepFlag()
+
+	err := cmd.Parse([]string{"--grep", "^ok:"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Println(cmd.Colorize(cli.Green, "ok:") + " first")
+	cmd.Println("fail: second")
+	cmd.Println("ok: third")
+
+	exp := "\x1b[32mok:\x1b[0m first\nok: third\n"
+	if outbuf.String() != exp {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+
+	err = cmd.Parse([]string{"--grep", "("})
+	if err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// FirstRun calls fn if the config file named by ConfigFile does not
+// exist, as on the first run of the application, so that fn can guide
+// the user through setting it up, typically by calling PromptFlags.
+// When fn returns successfully, the flags which are set are saved with
+// SaveConfig, so fn is not called again. FirstRun should be called
+// after Parse.
+func (c *Cmd) FirstRun(fn func() error) error {
+	path := c.ConfigFile()
+	if path == "" {
+		return ErrNoHome
+	}
+
+	_, err := os.Stat(path)
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err = fn()
+	if err != nil {
+		return err
+	}
+
+	return c.SaveConfig()
+}
+
+// PromptFlags prompts for the value of each named flag in turn, as Parse
+// does for missing required flags, and sets the flag to the value
+// entered. Flags marked secret are read without echo.
+func (c *Cmd) PromptFlags(names ...string) error {
+	r := c.NewLineReader()
+	r.SetExitOnInterrupt(false)
+
+	for _, name := range names {
+		err := c.promptFlag(r, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
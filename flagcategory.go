@@ -0,0 +1,113 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// SetFlagCategory places the named flag under a heading such as
+// "Output options" in help output, in place of the single alphabetical
+// list of flags. Categories are listed in the order in which they were
+// first used, after the flags which have no category. The category also
+// applies to a subcommand flag of the same name shown by Help.
+func (c *Cmd) SetFlagCategory(name string, category string) {
+	if c.flagCategories == nil {
+		c.flagCategories = make(map[string]string)
+	}
+
+	if !containsString(c.categoryOrder, category) {
+		c.categoryOrder = append(c.categoryOrder, category)
+	}
+
+	c.flagCategories[name] = category
+
+	c.FlagSet.Usage = func() {
+		w := c.FlagSet.Output()
+
+		if name := c.FlagSet.Name(); name == "" {
+			fmt.Fprintf(w, "Usage:\n")
+		} else {
+			fmt.Fprintf(w, "Usage of %s:\n", name)
+		}
+
+		c.printFlagDefaults(w, c.FlagSet, "")
+	}
+}
+
+// printFlagDefaults writes the defaults of the flags of fs to w in the
+// manner of flag.PrintDefaults, grouped by category. The flags without
+// a category are preceded by heading, if it is not empty.
+func (c *Cmd) printFlagDefaults(w io.Writer, fs *flag.FlagSet, heading string) {
+	groups := make(map[string]*flag.FlagSet)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		cat := c.flagCategories[f.Name]
+
+		g, ok := groups[cat]
+		if !ok {
+			g = flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+			groups[cat] = g
+		}
+
+		g.Var(f.Value, f.Name, f.Usage)
+
+		// the default is shown as it was defined, not as parsed
+		g.Lookup(f.Name).DefValue = f.DefValue
+	})
+
+	printGroup := func(g *flag.FlagSet, heading string) {
+		if g == nil {
+			return
+		}
+
+		if heading != "" {
+			fmt.Fprintln(w, heading)
+		}
+
+		g.SetOutput(w)
+		g.PrintDefaults()
+	}
+
+	printGroup(groups[""], heading)
+
+	for _, cat := range c.categoryOrder {
+		if g, ok := groups[cat]; ok {
+			fmt.Fprintln(w)
+			printGroup(g, cat+":")
+		}
+	}
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
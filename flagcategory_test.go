@@ -0,0 +1,100 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestSetFlagCategory(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.FlagSet.Init("test", 0)
+	cmd.FlagSet.SetOutput(outbuf)
+	cmd.FlagSet.Bool("verbose", false, "show more output")
+	cmd.FlagSet.String("host", "localhost", "server `name`")
+	cmd.FlagSet.Bool("color", false, "colorize output")
+	cmd.FlagSet.Int("port", 443, "server port")
+
+	cmd.SetFlagCategory("host", "Connection options")
+	cmd.SetFlagCategory("color", "Output options")
+	cmd.SetFlagCategory("port", "Connection options")
+
+	cmd.FlagSet.Usage()
+
+	expect := `Usage of test:
+  -verbose
+    	show more output
+
+Connection options:
+  -host name
+    	server name (default "localhost")
+  -port int
+    	server port (default 443)
+
+Output options:
+  -color
+    	colorize output
+`
+
+	if outbuf.String() != expect {
+		t.Errorf("expected %q, received %q", expect, outbuf)
+	}
+}
+
+func TestSetFlagCategoryCommandHelp(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	syncCmd := cmd.AddCommand("sync", "", nil)
+	syncCmd.FlagSet.Bool("dry-run", false, "show changes only")
+	syncCmd.FlagSet.Bool("json", false, "print json")
+
+	cmd.SetFlagCategory("json", "Output options")
+
+	err := cmd.Help([]string{"sync"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	expect := `sync
+
+flags:
+  -dry-run
+    	show changes only
+
+Output options:
+  -json
+    	print json
+`
+
+	if outbuf.String() != expect {
+		t.Errorf("expected %q, received %q", expect, outbuf)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidValue is returned by Parse when a flag is set to a value
+// outside of those given to SetFlagValues.
+var ErrInvalidValue = errors.New("invalid flag value")
+
+// SetFlagValues sets the valid values of the named flag. The values
+// are offered as completions, and Parse returns an error listing them
+// if the flag is set to any other value.
+func (c *Cmd) SetFlagValues(name string, values ...string) {
+	if c.flagValues == nil {
+		c.flagValues = make(map[string][]string)
+	}
+
+	c.flagValues[name] = values
+}
+
+// SetFlagCompleter sets a function which returns suggested values of
+// the named flag beginning with partial. Unlike SetFlagValues, values
+// returned by fn are not used for validation.
+func (c *Cmd) SetFlagCompleter(name string, fn func(partial string) []string) {
+	if c.flagCompleters == nil {
+		c.flagCompleters = make(map[string]func(string) []string)
+	}
+
+	c.flagCompleters[name] = fn
+}
+
+// CompleteFlag returns the sorted values of the named flag which begin
+// with partial, or nil if no completion hints have been set for it.
+func (c *Cmd) CompleteFlag(name string, partial string) []string {
+	var cands []string
+
+	if fn, ok := c.flagCompleters[name]; ok {
+		cands = fn(partial)
+	} else {
+		for _, v := range c.flagValues[name] {
+			if strings.HasPrefix(v, partial) {
+				cands = append(cands, v)
+			}
+		}
+	}
+
+	sort.Strings(cands)
+
+	return cands
+}
+
+// validateFlags checks the value of each set flag which has a list of
+// valid values.
+func (c *Cmd) validateFlags() error {
+	var err error
+
+	c.FlagSet.Visit(func(f *flag.Flag) {
+		if err == nil {
+			err = c.validateFlag(f.Name, f.Value.String())
+		}
+	})
+
+	return err
+}
+
+// validateFlag checks value against the valid values of the named flag.
+func (c *Cmd) validateFlag(name string, value string) error {
+	values, ok := c.flagValues[name]
+	if !ok {
+		return nil
+	}
+
+	for _, v := range values {
+		if v == value {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w %q for -%s: must be one of %s",
+		ErrInvalidValue, value, name, strings.Join(values, ", "))
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestFlagValues(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.FlagSet.String("level", "", "log level")
+	cmd.SetFlagValues("level", "debug", "info", "warn", "error")
+
+	err := cmd.Parse([]string{"-level", "warn"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cmd.Parse([]string{"-level", "loud"})
+	if !errors.Is(err, cli.ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue, received %v", err)
+	}
+
+	exp := `invalid flag value "loud" for -level: must be one of debug, info, warn, error`
+	if err.Error() != exp {
+		t.Errorf("expected %q, received %q", exp, err)
+	}
+
+	c := cmd.CompleteFlag("level", "")
+	if !reflect.DeepEqual(c, []string{"debug", "error", "info", "warn"}) {
+		t.Errorf("unexpected completions %q", c)
+	}
+
+	c = cmd.CompleteFlag("level", "d")
+	if !reflect.DeepEqual(c, []string{"debug"}) {
+		t.Errorf("unexpected completions %q", c)
+	}
+
+	c = cmd.CompleteFlag("other", "")
+	if c != nil {
+		t.Errorf("unexpected completions %q", c)
+	}
+}
+
+func TestFlagCompleter(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.FlagSet.String("host", "", "host name")
+	cmd.SetFlagCompleter("host", func(partial string) []string {
+		return []string{partial + "b", partial + "a"}
+	})
+
+	c := cmd.CompleteFlag("host", "x")
+	if !reflect.DeepEqual(c, []string{"xa", "xb"}) {
+		t.Errorf("unexpected completions %q", c)
+	}
+
+	err := cmd.Parse([]string{"-host", "anything"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestFlagValuesPrompt(t *testing.T) {
+	cons := newTestConsole(t)
+	errbuf := new(strings.Builder)
+
+	cmd := cli.NewCmd(cli.WithInteractiveFlagPrompts())
+	cmd.SetStdin(cons.Tty())
+	cmd.SetStdout(cons.Tty())
+	cmd.SetStderr(errbuf)
+
+	level := cmd.FlagSet.String("level", "", "log level")
+	cmd.MarkRequired("level")
+	cmd.SetFlagValues("level", "debug", "info")
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.Parse(nil)
+	}()
+
+	expectSend(t, cons, "level (log level): ", "loud\r")
+	expectSend(t, cons, "level (log level): ", "i\t\r")
+
+	err := <-errc
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *level != "info" {
+		t.Errorf("expected info, received %q", *level)
+	}
+
+	if !strings.Contains(errbuf.String(), "must be one of debug, info") {
+		t.Errorf("unexpected error output %q", errbuf)
+	}
+}
@@ -4,10 +4,7 @@ go 1.21
 
 require (
 	github.com/Netflix/go-expect v0.0.0-20220104043353-73e0943537d2
+	github.com/creack/pty v1.1.17
 	github.com/mattn/go-isatty v0.0.20
-)
-
-require (
-	github.com/creack/pty v1.1.17 // indirect
-	golang.org/x/sys v0.6.0 // indirect
+	golang.org/x/sys v0.6.0
 )
@@ -0,0 +1,95 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"crypto/md5"  //nolint:gosec // provided for compatibility
+	"crypto/sha1" //nolint:gosec // provided for compatibility
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownHash is returned by HashFile for an unsupported algorithm.
+var ErrUnknownHash = errors.New("unknown hash algorithm")
+
+// hashes are the algorithms supported by HashFile.
+//
+//nolint:gochecknoglobals // read-only table
+var hashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// HashFile returns the hex encoded digest of the file at path using
+// algo, which is one of md5, sha1, sha256 or sha512. A live progress
+// line is shown while the file is read, then replaced by the digest in
+// the format of sha256sum and similar tools. If the exit channel
+// closes, reading stops and ErrInterrupted is returned.
+func (c *Cmd) HashFile(path string, algo string) (string, error) {
+	newHash, ok := hashes[strings.ToLower(algo)]
+	if !ok {
+		names := make([]string, 0, len(hashes))
+		for n := range hashes {
+			names = append(names, n)
+		}
+
+		sort.Strings(names)
+
+		return "", fmt.Errorf("%w '%s', expected one of %s",
+			ErrUnknownHash, algo, strings.Join(names, ", "))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	h := newHash()
+
+	var sum string
+
+	_, err = c.copyProgress(filepath.Base(path), h, f, fi.Size(), func(CopySummary) string {
+		sum = hex.EncodeToString(h.Sum(nil))
+
+		return sum + "  " + path
+	})
+
+	return sum, err
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestHashFile(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	path := filepath.Join(t.TempDir(), "file")
+
+	err := os.WriteFile(path, []byte("hello\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	for _, tc := range []struct {
+		algo string
+		exp  string
+	}{
+		{"md5", "b1946ac92492d2347c6235b4d2611184"},
+		{"sha1", "f572d396fae9206628714fb2ce00f72e94f2258f"},
+		{"SHA256", "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"},
+	} {
+		t.Run(tc.algo, func(t *testing.T) {
+			outbuf := new(bytes.Buffer)
+
+			cmd := cli.NewCmd()
+			cmd.SetStdout(outbuf)
+
+			sum, err := cmd.HashFile(path, tc.algo)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if sum != tc.exp {
+				t.Errorf("expected %s, received %s", tc.exp, sum)
+			}
+
+			if !strings.HasSuffix(outbuf.String(), tc.exp+"  "+path+"\n") {
+				t.Errorf("expected digest line, received %q", outbuf)
+			}
+		})
+	}
+
+	_, err = cli.NewCmd().HashFile(path, "crc")
+	if !errors.Is(err, cli.ErrUnknownHash) {
+		t.Error("expected ErrUnknownHash, received", err)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// noProgress is the progress fraction stored when no progress display
+// is active.
+const noProgress = -1
+
+// EnableHeartbeat prints a line to Stderr such as "still working
+// (elapsed 5m, 42% complete)" after each interval d in which nothing
+// was printed, so that CI systems and other supervisors watching for
+// inactivity do not assume a quiet but long-running job has hung. The
+// percentage is included while a progress display, such as that of
+// Copy or a Progress, is active.
+//
+// EnableHeartbeat does nothing if Stdout is a terminal, where the live
+// region shows that the job is working, or if d is zero or negative.
+// The heartbeat stops when the exit channel closes.
+func (c *Cmd) EnableHeartbeat(d time.Duration) {
+	if d <= 0 || c.outIsTerm {
+		return
+	}
+
+	start := c.clock().Now()
+	last := atomic.LoadInt64(&c.writes)
+
+	c.Every(d, func() {
+		if atomic.LoadInt64(&c.writes) != last {
+			last = atomic.LoadInt64(&c.writes)
+
+			return
+		}
+
+		msg := "elapsed " + formatElapsed(c.clock().Now().Sub(start))
+
+		if f := c.progressFraction(); f >= 0 {
+			msg += fmt.Sprintf(", %d%% complete", int(f*100))
+		}
+
+		c.Eprintf("still working (%s)\n", msg)
+
+		last = atomic.LoadInt64(&c.writes)
+	})
+}
+
+// setProgressFraction records the fraction complete of the active
+// progress display, or noProgress.
+func (tp *TermPrinter) setProgressFraction(f float64) {
+	atomic.StoreUint64(&tp.fraction, math.Float64bits(f))
+}
+
+// progressFraction returns the fraction complete of the active progress
+// display, or a negative value if there is none.
+func (tp *TermPrinter) progressFraction() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&tp.fraction))
+}
+
+// formatElapsed returns d rounded to the second, without trailing zero
+// units, such as "5m" or "1h2m".
+func formatElapsed(d time.Duration) string {
+	s := d.Round(time.Second).String()
+
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+
+	return s
+}
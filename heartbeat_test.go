@@ -0,0 +1,91 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
+)
+
+func TestHeartbeat(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+	pr, pw := io.Pipe()
+
+	cmd := cli.NewCmd()
+	cmd.SetClock(clk)
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.SetStderr(pw)
+
+	job := cmd.NewProgress("job", 100)
+	job.Set(42)
+
+	cmd.EnableHeartbeat(5 * time.Minute)
+
+	clk.BlockUntil(1)
+	clk.Advance(5 * time.Minute)
+
+	line, err := bufio.NewReader(pr).ReadString('\n')
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if line != "still working (elapsed 5m, 42% complete)\n" {
+		t.Errorf("unexpected heartbeat %q", line)
+	}
+
+	cmd.Exit(nil)
+
+	err = cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+func TestHeartbeatQuiet(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetClock(clk)
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.SetStderr(errbuf)
+
+	cmd.EnableHeartbeat(time.Minute)
+	clk.BlockUntil(1)
+
+	cmd.Println("working")
+	clk.Advance(time.Minute)
+
+	cmd.Exit(nil)
+	_ = cmd.Wait()
+
+	if errbuf.Len() != 0 {
+		t.Errorf("unexpected heartbeat after output %q", errbuf)
+	}
+}
@@ -0,0 +1,280 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownTopic is returned by Help when the topic is neither a help
+// topic nor a command. The error suggests similar names when there are
+// any.
+var ErrUnknownTopic = errors.New("unknown help topic")
+
+// Escape sequences used to render help topics.
+const (
+	sgrBold  = "\x1b[1m"
+	sgrReset = "\x1b[0m"
+)
+
+// HelpTopic is a page of prose shown by Help, such as an explanation of
+// the concepts used by an application.
+type HelpTopic struct {
+	// Name is the name used to show the topic.
+	Name string
+
+	// Title is a one line description shown in help output.
+	Title string
+
+	// Text is the body of the topic in markdown.
+	Text string
+}
+
+// AddHelpTopic adds a help topic, which is listed by PrintCommands and
+// shown by Help. Text is markdown: headings, lists, fenced code blocks,
+// **bold** and `code` are rendered when Stdout is a terminal.
+func (c *Cmd) AddHelpTopic(name string, title string, text string) {
+	if c.helpTopics == nil {
+		c.helpTopics = make(map[string]*HelpTopic)
+	}
+
+	c.helpTopics[name] = &HelpTopic{Name: name, Title: title, Text: text}
+}
+
+// HelpTopics returns the help topics sorted by name.
+func (c *Cmd) HelpTopics() []*HelpTopic {
+	topics := make([]*HelpTopic, 0, len(c.helpTopics))
+
+	for _, t := range c.helpTopics {
+		topics = append(topics, t)
+	}
+
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Name < topics[j].Name
+	})
+
+	return topics
+}
+
+// Help implements a help command. With no arguments, the commands,
+// help topics and examples are listed. A single argument naming a help
+// topic shows the topic, otherwise the arguments are taken as the path
+// of a command, such as "remote add", and its summary, subcommands,
+// flags and examples are shown.
+func (c *Cmd) Help(args []string) error {
+	if len(args) == 0 {
+		c.PrintCommands()
+		c.printExamples(c.examples)
+
+		return nil
+	}
+
+	if t, ok := c.helpTopics[args[0]]; ok && len(args) == 1 {
+		c.Print(c.renderMarkdown(t.Text))
+
+		return nil
+	}
+
+	cmd, ok := c.commands[args[0]]
+
+	for _, name := range args[1:] {
+		if !ok {
+			break
+		}
+
+		cmd, ok = cmd.commands[name]
+	}
+
+	if !ok {
+		names := c.Commands()
+		for _, t := range c.HelpTopics() {
+			names = append(names, &Command{Name: t.Name})
+		}
+
+		return unknownName(ErrUnknownTopic, strings.Join(args, " "), names)
+	}
+
+	c.printCommandHelp(cmd)
+
+	return nil
+}
+
+// printCommandHelp prints the summary, subcommands, flags and examples
+// of cmd.
+func (c *Cmd) printCommandHelp(cmd *Command) {
+	if cmd.Summary != "" {
+		c.Printf("%s - %s\n", cmd.Path(), cmd.Summary)
+	} else {
+		c.Println(cmd.Path())
+	}
+
+	if subs := cmd.Commands(); len(subs) > 0 {
+		c.Println("\ncommands:")
+		c.printCommands(subs, 0, commandWidth(subs, 0))
+	}
+
+	cmd.inheritFlags()
+
+	var sb strings.Builder
+
+	c.printFlagDefaults(&sb, cmd.FlagSet, "\nflags:")
+
+	if sb.Len() > 0 {
+		c.Print(sb.String())
+	}
+
+	c.printExamples(cmd.examples)
+}
+
+// topicWidth returns the width of the longest indented topic name.
+func (c *Cmd) topicWidth() int {
+	var w int
+
+	for name := range c.helpTopics {
+		w = max(w, 2+textWidth(name))
+	}
+
+	return w
+}
+
+// printHelpTopics prints the names and titles of the help topics, if
+// there are any, following the list of commands, padding names to
+// width w.
+func (c *Cmd) printHelpTopics(w int) {
+	topics := c.HelpTopics()
+	if len(topics) == 0 {
+		return
+	}
+
+	if len(c.commands) > 0 {
+		c.Println()
+	}
+
+	c.Println("help topics:")
+
+	for _, t := range topics {
+		c.Printf("%s  %s\n", padRight("  "+t.Name, w), t.Title)
+	}
+}
+
+// renderMarkdown renders text for Stdout. If Stdout is not a terminal,
+// text is returned unchanged. Otherwise markup is removed, with
+// headings and bold text emphasized and code colored if color is
+// enabled.
+func (c *Cmd) renderMarkdown(text string) string {
+	text = strings.TrimRight(text, "\n") + "\n"
+
+	if !c.outIsTerm {
+		return text
+	}
+
+	bullet := "*"
+	if c.utf8 || localeUTF8() {
+		bullet = "•"
+	}
+
+	var (
+		sb    strings.Builder
+		fence bool
+	)
+
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			fence = !fence
+
+			continue
+		case fence:
+			sb.WriteString("    " + c.Colorize(Cyan, line))
+		case strings.HasPrefix(trimmed, "#"):
+			sb.WriteString(c.emphasize(strings.TrimSpace(strings.TrimLeft(trimmed, "#"))))
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+			sb.WriteString(indent + "  " + bullet + " " + c.renderInline(trimmed[2:]))
+		default:
+			sb.WriteString(c.renderInline(line))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// renderInline renders **bold** and `code` spans within a line.
+func (c *Cmd) renderInline(line string) string {
+	var sb strings.Builder
+
+	for line != "" {
+		i := strings.IndexAny(line, "*`")
+		if i < 0 {
+			sb.WriteString(line)
+
+			break
+		}
+
+		sb.WriteString(line[:i])
+		line = line[i:]
+
+		delim := "`"
+		if strings.HasPrefix(line, "**") {
+			delim = "**"
+		} else if line[0] == '*' {
+			sb.WriteString("*")
+			line = line[1:]
+
+			continue
+		}
+
+		end := strings.Index(line[len(delim):], delim)
+		if end < 0 {
+			sb.WriteString(line)
+
+			break
+		}
+
+		span := line[len(delim) : len(delim)+end]
+		line = line[2*len(delim)+end:]
+
+		if delim == "`" {
+			sb.WriteString(c.Colorize(Cyan, span))
+		} else {
+			sb.WriteString(c.emphasize(span))
+		}
+	}
+
+	return sb.String()
+}
+
+// emphasize returns s in bold if color is enabled for Stdout.
+func (c *Cmd) emphasize(s string) string {
+	if !c.OutColor() {
+		return s
+	}
+
+	return sgrBold + s + sgrReset
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestHelpTopics(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+
+	remote := cmd.AddCommand("remote", "manage remotes", nil)
+	add := remote.AddCommand("add", "add a remote", func([]string) error { return nil })
+	add.FlagSet.Bool("fetch", false, "fetch after adding")
+
+	cmd.AddHelpTopic("concepts", "core concepts", "# Concepts\n\nUse **remotes** with `add`.\n")
+	cmd.AddHelpTopic("config", "configuration files", "text")
+
+	err := cmd.Help(nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := "  remote    manage remotes\n" +
+		"    add     add a remote\n" +
+		"\n" +
+		"help topics:\n" +
+		"  concepts  core concepts\n" +
+		"  config    configuration files\n"
+
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\nreceived:\n%s", exp, buf)
+	}
+
+	buf.Reset()
+
+	err = cmd.Help([]string{"concepts"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp = "# Concepts\n\nUse **remotes** with `add`.\n"
+	if buf.String() != exp {
+		t.Errorf("expected %q, received %q", exp, buf)
+	}
+
+	buf.Reset()
+
+	err = cmd.Help([]string{"remote", "add"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "remote add - add a remote\n\nflags:\n") ||
+		!strings.Contains(buf.String(), "-fetch") {
+		t.Errorf("unexpected command help %q", buf)
+	}
+
+	err = cmd.Help([]string{"concept"})
+	if !errors.Is(err, cli.ErrUnknownTopic) || !strings.Contains(err.Error(), "'concepts'") {
+		t.Error("expected ErrUnknownTopic with suggestion, received", err)
+	}
+}
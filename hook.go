@@ -0,0 +1,140 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Stream identifies an output stream of a TermPrinter.
+type Stream int
+
+// Output streams of a TermPrinter.
+const (
+	Stdout Stream = iota
+	Stderr
+)
+
+// String returns the name of the stream.
+func (s Stream) String() string {
+	if s == Stderr {
+		return "stderr"
+	}
+
+	return "stdout"
+}
+
+// outputHooks holds the output hooks of a TermPrinter.
+type outputHooks struct {
+	m       sync.RWMutex
+	fns     []func(Stream, []byte)
+	enabled atomic.Bool
+}
+
+// AddOutputHook adds fn to the list of functions called for every
+// write of printed content, after formatting and before the content is
+// written. Terminal control sequences used to redraw the live region
+// are not passed to fn.
+//
+// Hooks are called synchronously in the order they were added. The
+// slice passed to fn must not be modified or retained after fn returns.
+func (tp *TermPrinter) AddOutputHook(fn func(stream Stream, b []byte)) {
+	tp.hooks.m.Lock()
+	tp.hooks.fns = append(tp.hooks.fns, fn)
+	tp.hooks.enabled.Store(true)
+	tp.hooks.m.Unlock()
+}
+
+// writer returns the writer for printed content on stream s, which
+// calls the output hooks and emits events if enabled.
+func (tp *TermPrinter) writer(s Stream) io.Writer {
+	return tp.hookedWriter(s, false)
+}
+
+// liveWriter returns the writer for live region content.
+func (tp *TermPrinter) liveWriter() io.Writer {
+	return tp.hookedWriter(Stdout, true)
+}
+
+// hookedWriter returns the writer for content on stream s, wrapping it
+// in a hookWriter if output hooks, events or snapshots are enabled, and
+// in a utf8Writer if UTF-8 output is enabled.
+func (tp *TermPrinter) hookedWriter(s Stream, live bool) io.Writer {
+	var w io.Writer = tp.out
+	if s == Stderr {
+		w = tp.err
+	}
+
+	if tp.utf8 {
+		w = utf8Writer{w: w}
+	}
+
+	if !tp.hooks.enabled.Load() && !tp.eventsEnabled() &&
+		!tp.snapshotEnabled() {
+		return w
+	}
+
+	return &hookWriter{tp: tp, s: s, w: w, live: live}
+}
+
+// hookWriter calls the output hooks and emits events before passing
+// data to w.
+type hookWriter struct {
+	tp   *TermPrinter
+	s    Stream
+	w    io.Writer
+	live bool
+}
+
+// Write calls each output hook with b and emits an event, then writes
+// b to the embedded io.Writer.
+func (hw *hookWriter) Write(b []byte) (int, error) {
+	hw.notify(b)
+
+	return hw.w.Write(b)
+}
+
+// notify calls each output hook with b, emits an event and records b
+// for Snapshot.
+func (hw *hookWriter) notify(b []byte) {
+	hw.tp.hooks.m.RLock()
+
+	for _, fn := range hw.tp.hooks.fns {
+		fn(hw.s, b)
+	}
+
+	hw.tp.hooks.m.RUnlock()
+
+	switch {
+	case hw.live:
+		hw.tp.emit(EventProgress, hw.s, b)
+	case hw.s == Stderr:
+		hw.tp.emit(EventError, hw.s, b)
+	default:
+		hw.tp.emit(EventPrint, hw.s, b)
+	}
+
+	hw.tp.record(hw.s, b, hw.live)
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestOutputHook(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		_, err := cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetStderr(new(bytes.Buffer))
+
+	var audit strings.Builder
+
+	p.AddOutputHook(func(s cli.Stream, b []byte) {
+		audit.WriteString(s.String() + ": ")
+		audit.Write(b)
+	})
+
+	writeLprintf(p)
+	p.Print("END")
+
+	wg.Wait()
+
+	exp := "stdout: print 1\n" +
+		"stderr: print 2\n" +
+		"stdout: print 3\n" +
+		"stdout: print 4\n" +
+		"stdout: print 5\n" +
+		"stderr: print 6\n" +
+		"stdout: print 7\n" +
+		"stdout: print 8\n" +
+		"stderr: print 9\n" +
+		"stdout: END"
+	if audit.String() != exp {
+		t.Error("unexpected output", audit.String())
+	}
+}
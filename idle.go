@@ -0,0 +1,155 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// idleCheckInterval is how often a paused live renderer is called to
+// check whether its content has changed.
+const idleCheckInterval = time.Second
+
+// idleState holds the idle pause settings of a TermPrinter.
+type idleState struct {
+	after     atomic.Int64 // idle period in nanoseconds, zero if disabled
+	active    atomic.Int64 // time of the last activity in nanoseconds
+	unfocused atomic.Bool
+
+	// wake is closed and replaced when activity resumes, if waiting
+	// is set by a paused animation
+	m       sync.Mutex
+	wake    chan bool
+	waiting bool
+}
+
+// SetIdlePause pauses animations, such as the spinner of Steps and the
+// render loop started by StartLive, once nothing has changed for d.
+// Printed messages, calls to Lprintf, changes in the content of the
+// live renderer and calls to Touch count as changes, and resume the
+// animations. A paused render loop still calls the live renderer once
+// a second to check for changes. Zero disables pausing, which is the
+// default.
+//
+// Animations are also paused while the terminal window is unfocused, as
+// reported with SetFocused or detected by EnablePauseKey.
+func (tp *TermPrinter) SetIdlePause(d time.Duration) {
+	tp.idle.after.Store(int64(max(d, 0)))
+	tp.idle.active.Store(tp.now().UnixNano())
+	tp.wakeAnimations()
+}
+
+// Touch records a change in state which is not otherwise visible to the
+// TermPrinter, resuming any animations paused by SetIdlePause.
+func (tp *TermPrinter) Touch() {
+	tp.markActive()
+}
+
+// SetFocused records whether the terminal window has focus. Animations
+// are paused while it does not. Applications reading input themselves
+// may enable focus reporting and pass on the reports.
+func (tp *TermPrinter) SetFocused(focused bool) {
+	tp.idle.unfocused.Store(!focused)
+
+	if focused {
+		tp.wakeAnimations()
+	}
+}
+
+// markActive records activity, resuming paused animations.
+func (tp *TermPrinter) markActive() {
+	if tp.idle.after.Load() == 0 {
+		return
+	}
+
+	tp.idle.active.Store(tp.now().UnixNano())
+	tp.wakeAnimations()
+}
+
+// wakeAnimations resumes animations waiting in idleWait.
+func (tp *TermPrinter) wakeAnimations() {
+	tp.idle.m.Lock()
+	defer tp.idle.m.Unlock()
+
+	if tp.idle.waiting {
+		close(tp.idle.wake)
+		tp.idle.waiting = false
+	}
+}
+
+// animationIdle reports whether animations should pause.
+func (tp *TermPrinter) animationIdle() bool {
+	if tp.idle.unfocused.Load() {
+		return true
+	}
+
+	after := tp.idle.after.Load()
+	if after == 0 {
+		return false
+	}
+
+	return tp.now().UnixNano()-tp.idle.active.Load() >= after
+}
+
+// idleWait returns a channel which is closed when paused animations
+// should resume.
+func (tp *TermPrinter) idleWait() <-chan bool {
+	tp.idle.m.Lock()
+	defer tp.idle.m.Unlock()
+
+	if !tp.idle.waiting {
+		tp.idle.wake = make(chan bool)
+		tp.idle.waiting = true
+	}
+
+	return tp.idle.wake
+}
+
+// focusReports recognizes the reports sent by a terminal with focus
+// reporting enabled: ESC [ I on gaining focus and ESC [ O on losing it.
+type focusReports struct {
+	n int
+}
+
+// feed passes the next byte of input to the parser. It returns whether
+// b is part of a report, and once a report is complete, whether it
+// reports focus.
+func (r *focusReports) feed(b byte) (part bool, complete bool, focused bool) {
+	switch {
+	case r.n == 0 && b == '\x1b', r.n == 1 && b == '[':
+		r.n++
+
+		return true, false, false
+	case r.n == 2 && (b == 'I' || b == 'O'):
+		r.n = 0
+
+		return true, true, b == 'I'
+	default:
+		part = r.n > 0
+		r.n = 0
+
+		return part, false, false
+	}
+}
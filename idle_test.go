@@ -0,0 +1,107 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+// countCalls returns the number of calls made to a renderer counting
+// with n during d.
+func countCalls(n *atomic.Int64, d time.Duration) int64 {
+	start := n.Load()
+	time.Sleep(d)
+
+	return n.Load() - start
+}
+
+func TestIdlePause(t *testing.T) {
+	var calls atomic.Int64
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(io.Discard)
+	p.SetIdlePause(50 * time.Millisecond)
+	p.SetLiveRenderer(func() string {
+		calls.Add(1)
+
+		return "waiting\n"
+	})
+	p.StartLive(5 * time.Millisecond)
+
+	defer p.StopLive()
+
+	time.Sleep(100 * time.Millisecond)
+
+	// once idle, the renderer is only checked once a second
+	if n := countCalls(&calls, 200*time.Millisecond); n > 1 {
+		t.Errorf("expected render loop to pause, %d calls", n)
+	}
+
+	p.Touch()
+
+	if n := countCalls(&calls, 40*time.Millisecond); n < 2 {
+		t.Errorf("expected render loop to resume, %d calls", n)
+	}
+
+	p.SetIdlePause(0)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if n := countCalls(&calls, 40*time.Millisecond); n < 2 {
+		t.Errorf("expected render loop to run, %d calls", n)
+	}
+}
+
+func TestIdlePauseFocus(t *testing.T) {
+	var calls atomic.Int64
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(io.Discard)
+	p.SetLiveRenderer(func() string {
+		calls.Add(1)
+
+		return "waiting\n"
+	})
+	p.StartLive(5 * time.Millisecond)
+
+	defer p.StopLive()
+
+	p.SetFocused(false)
+
+	time.Sleep(20 * time.Millisecond)
+
+	if n := countCalls(&calls, 200*time.Millisecond); n > 1 {
+		t.Errorf("expected render loop to pause, %d calls", n)
+	}
+
+	p.SetFocused(true)
+
+	if n := countCalls(&calls, 40*time.Millisecond); n < 2 {
+		t.Errorf("expected render loop to resume, %d calls", n)
+	}
+}
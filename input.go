@@ -0,0 +1,347 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// ErrInterrupted is returned by ReadLine when the read is interrupted
+// by Ctrl-C or the exit channel closing.
+var ErrInterrupted = errors.New("interrupted")
+
+// ErrNotSupported is returned when a feature is not supported on the
+// current platform.
+var ErrNotSupported = errors.New("not supported on this platform")
+
+// maxHistory is the maximum number of history entries kept.
+const maxHistory = 1000
+
+// keyRead is a rune read from the input, or the error which ended the
+// input.
+type keyRead struct {
+	r   rune
+	err error
+}
+
+// LineReader reads lines of input, providing basic line editing and
+// history when the input is a terminal.
+//
+// On a terminal, the left and right arrows, Home, End, Delete,
+// Backspace and the common Emacs-style control keys edit the line,
+// while the up and down arrows move through the history. Ctrl-D on an
+// empty line returns io.EOF. Ctrl-C returns ErrInterrupted and, if an
+// ExitHandler has been set, calls Exit as if SIGINT had been received.
+type LineReader struct {
+	eh   *ExitHandler
+	in   io.Reader
+	out  io.Writer
+	fd   uintptr
+	term bool
+
+	keys     chan keyRead
+	keysOnce sync.Once
+
+	history  []string
+	histFile string
+
+	exitOnInterrupt bool
+
+	onPassword func(string)
+	onLine     func(string)
+
+	completer func(partial string) []string
+}
+
+// NewLineReader returns a LineReader reading from in and echoing to
+// out. Line editing is enabled if in is a terminal.
+func NewLineReader(in io.Reader, out io.Writer) *LineReader {
+	r := &LineReader{
+		in:              in,
+		out:             out,
+		exitOnInterrupt: true,
+	}
+
+	if f, ok := in.(*os.File); ok {
+		r.fd = f.Fd()
+		r.term = isatty.IsTerminal(r.fd)
+	}
+
+	return r
+}
+
+// NewLineReader returns a LineReader reading from the Stdin of the Cmd,
+// echoing to the Stdout of the Cmd and tied to the ExitHandler of the
+// Cmd. Passwords read with ReadPassword are registered with
+// RedactSecrets.
+func (c *Cmd) NewLineReader() *LineReader {
+	r := NewLineReader(c.stdinReader(), c.out)
+	r.SetExitHandler(c.ExitHandler)
+	r.onPassword = func(s string) { c.RedactSecrets(s) }
+	r.onLine = c.recordInput
+
+	return r
+}
+
+// SetExitHandler sets the ExitHandler which interrupts reads when the
+// exit channel closes, and which is exited when Ctrl-C is pressed.
+func (r *LineReader) SetExitHandler(eh *ExitHandler) {
+	r.eh = eh
+}
+
+// SetExitOnInterrupt sets whether Ctrl-C calls Exit on the ExitHandler.
+// The default is true. In either case, ReadLine returns ErrInterrupted.
+func (r *LineReader) SetExitOnInterrupt(exit bool) {
+	r.exitOnInterrupt = exit
+}
+
+// SetCompleter sets a function which provides tab completion when the
+// input is a terminal. The function is passed the word before the
+// cursor and returns the possible completions of that word.
+func (r *LineReader) SetCompleter(fn func(partial string) []string) {
+	r.completer = fn
+}
+
+// CompleteFiles is a completer for use with SetCompleter which completes
+// file and directory paths. Directories are completed with a trailing
+// slash.
+func CompleteFiles(partial string) []string {
+	dir, base := filepath.Split(partial)
+
+	read := dir
+	if read == "" {
+		read = "."
+	}
+
+	entries, err := os.ReadDir(read)
+	if err != nil {
+		return nil
+	}
+
+	var cands []string
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if !strings.HasPrefix(name, base) ||
+			(strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+
+		if e.IsDir() {
+			name += string(filepath.Separator)
+		}
+
+		cands = append(cands, dir+name)
+	}
+
+	return cands
+}
+
+// SetHistoryFile loads the history from path, if it exists, and appends
+// each new line to it.
+func (r *LineReader) SetHistoryFile(path string) error {
+	r.histFile = path
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		r.addHistory(s.Text(), false)
+	}
+
+	return s.Err()
+}
+
+// History returns a copy of the history entries, oldest first.
+func (r *LineReader) History() []string {
+	return append([]string(nil), r.history...)
+}
+
+// ReadLine prints prompt and reads a line of input, returning the line
+// without the line ending. Non-empty lines are added to the history.
+func (r *LineReader) ReadLine(prompt string) (string, error) {
+	var (
+		line string
+		err  error
+	)
+
+	if r.term {
+		line, err = r.readTerm(prompt)
+	} else {
+		fmt.Fprint(r.out, prompt)
+
+		line, err = r.readPlain()
+	}
+
+	if err == nil {
+		r.addHistory(line, true)
+
+		if r.onLine != nil {
+			r.onLine(line)
+		}
+	}
+
+	return line, err
+}
+
+// ReadPassword prints prompt and reads a line of input without echo,
+// printing an asterisk for each character typed when the input is a
+// terminal. The line is not added to the history.
+func (r *LineReader) ReadPassword(prompt string) (string, error) {
+	var (
+		line string
+		err  error
+	)
+
+	if r.term {
+		line, err = r.readMasked(prompt)
+	} else {
+		fmt.Fprint(r.out, prompt)
+
+		line, err = r.readPlain()
+	}
+
+	if err == nil && r.onPassword != nil {
+		r.onPassword(line)
+	}
+
+	return line, err
+}
+
+// readPlain reads a line without editing.
+func (r *LineReader) readPlain() (string, error) {
+	var sb strings.Builder
+
+	for {
+		k, err := r.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) && sb.Len() > 0 {
+				return sb.String(), nil
+			}
+
+			return sb.String(), err
+		}
+
+		if k == '\n' {
+			return strings.TrimSuffix(sb.String(), "\r"), nil
+		}
+
+		sb.WriteRune(k)
+	}
+}
+
+// next returns the next rune of input, or ErrInterrupted if the exit
+// channel closes.
+func (r *LineReader) next() (rune, error) {
+	r.keysOnce.Do(func() {
+		r.keys = make(chan keyRead)
+
+		go func() {
+			br := bufio.NewReader(r.in)
+
+			for {
+				c, _, err := br.ReadRune()
+				r.keys <- keyRead{c, err}
+
+				if err != nil {
+					close(r.keys)
+
+					return
+				}
+			}
+		}()
+	})
+
+	var exit <-chan bool
+	if r.eh != nil {
+		exit = r.eh.C
+	}
+
+	select {
+	case k, ok := <-r.keys:
+		if !ok {
+			return 0, io.EOF
+		}
+
+		return k.r, k.err
+	case <-exit:
+		return 0, ErrInterrupted
+	}
+}
+
+// interrupt handles Ctrl-C.
+func (r *LineReader) interrupt() error {
+	if r.eh != nil && r.exitOnInterrupt {
+		r.eh.Exit(nil)
+	}
+
+	return ErrInterrupted
+}
+
+// addHistory adds line to the history, and to the history file if save
+// is true.
+func (r *LineReader) addHistory(line string, save bool) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	if n := len(r.history); n > 0 && r.history[n-1] == line {
+		return
+	}
+
+	r.history = append(r.history, line)
+	if len(r.history) > maxHistory {
+		r.history = r.history[len(r.history)-maxHistory:]
+	}
+
+	if !save || r.histFile == "" {
+		return
+	}
+
+	f, err := os.OpenFile(r.histFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(f, line)
+	f.Close()
+}
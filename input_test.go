@@ -0,0 +1,299 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestLineReader(t *testing.T) {
+	t.Run("Plain", testLineReaderPlain)
+	t.Run("Editing", testLineReaderEditing)
+	t.Run("Interrupt", testLineReaderInterrupt)
+	t.Run("HistoryFile", testLineReaderHistoryFile)
+	t.Run("Completion", testLineReaderCompletion)
+	t.Run("CompleteFiles", testCompleteFiles)
+}
+
+func testLineReaderPlain(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+	r := cli.NewLineReader(strings.NewReader("one\r\ntwo"), outbuf)
+
+	for _, exp := range []string{"one", "two"} {
+		line, err := r.ReadLine("> ")
+		if err != nil || line != exp {
+			t.Error("unexpected result:", line, err)
+		}
+	}
+
+	_, err := r.ReadLine("> ")
+	if !errors.Is(err, io.EOF) {
+		t.Error("unexpected error:", err)
+	}
+
+	if outbuf.String() != "> > > " {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+// readLines starts reading lines from r in the background, returning
+// channels receiving the result of each ReadLine call.
+func readLines(r *cli.LineReader, n int) (<-chan string, <-chan error) {
+	lines := make(chan string, n)
+	errs := make(chan error, n)
+
+	go func() {
+		for i := 1; i <= n; i++ {
+			line, err := r.ReadLine(fmt.Sprintf("%d> ", i))
+			lines <- line
+			errs <- err
+		}
+	}()
+
+	return lines, errs
+}
+
+func newTestConsole(t *testing.T) *expect.Console {
+	t.Helper()
+
+	cons, err := expect.NewConsole(expect.WithDefaultTimeout(5 * time.Second))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	t.Cleanup(func() { cons.Close() })
+
+	return cons
+}
+
+// sendLine waits for the numbered prompt, then sends s to cons.
+func sendLine(t *testing.T, cons *expect.Console, n int, s string) {
+	t.Helper()
+
+	_, err := cons.ExpectString(fmt.Sprintf("%d> ", n))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	_, err = cons.Send(s)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func testLineReaderEditing(t *testing.T) {
+	cons := newTestConsole(t)
+
+	r := cli.NewLineReader(cons.Tty(), cons.Tty())
+	lines, errs := readLines(r, 4)
+
+	// edit with arrows, home and end
+	sendLine(t, cons, 1, "helo\x1b[Dl\x1b[H>\x1b[Fx\x7f\r")
+
+	if line, err := <-lines, <-errs; line != ">hello" || err != nil {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	// control keys
+	sendLine(t, cons, 2, "foo bar\x17baz\x01\x04\x05\r")
+
+	if line, err := <-lines, <-errs; line != "oo baz" || err != nil {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	// history
+	sendLine(t, cons, 3, "new\x1b[A\x1b[A\x1b[B\r")
+
+	if line, err := <-lines, <-errs; line != "oo baz" || err != nil {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	// end of input
+	sendLine(t, cons, 4, "\x04")
+
+	if line, err := <-lines, <-errs; line != "" || !errors.Is(err, io.EOF) {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	exp := []string{">hello", "oo baz"}
+	if h := r.History(); len(h) != 2 || h[0] != exp[0] || h[1] != exp[1] {
+		t.Error("unexpected history", h)
+	}
+}
+
+func testLineReaderInterrupt(t *testing.T) {
+	cons := newTestConsole(t)
+
+	eh := new(cli.ExitHandler)
+	eh.Add(1)
+
+	r := cli.NewLineReader(cons.Tty(), cons.Tty())
+	r.SetExitHandler(eh)
+
+	lines, errs := readLines(r, 2)
+
+	sendLine(t, cons, 1, "partial\x03")
+
+	if line, err := <-lines, <-errs; line != "" || !errors.Is(err, cli.ErrInterrupted) {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	// the exit channel is closed, so the next read is interrupted
+	if line, err := <-lines, <-errs; line != "" || !errors.Is(err, cli.ErrInterrupted) {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	eh.Done()
+
+	err := eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func testLineReaderHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	err := os.WriteFile(path, []byte("first\nsecond\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	r := cli.NewLineReader(strings.NewReader("third\n"), io.Discard)
+
+	err = r.SetHistoryFile(path)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	_, err = r.ReadLine("")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil || string(b) != "first\nsecond\nthird\n" {
+		t.Error("unexpected history file", string(b), err)
+	}
+
+	if h := r.History(); len(h) != 3 {
+		t.Error("unexpected history", h)
+	}
+
+	err = cli.NewLineReader(nil, nil).SetHistoryFile(filepath.Join(path, "missing"))
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+}
+
+func testLineReaderCompletion(t *testing.T) {
+	cons := newTestConsole(t)
+
+	words := []string{"info", "install", "uninstall"}
+
+	r := cli.NewLineReader(cons.Tty(), cons.Tty())
+	r.SetCompleter(func(partial string) []string {
+		var cands []string
+
+		for _, w := range words {
+			if strings.HasPrefix(w, partial) {
+				cands = append(cands, w)
+			}
+		}
+
+		return cands
+	})
+
+	lines, errs := readLines(r, 2)
+
+	sendLine(t, cons, 1, "un\tx")
+
+	_, err := cons.Send("\r")
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if line, err := <-lines, <-errs; line != "uninstall x" || err != nil {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+
+	sendLine(t, cons, 2, "i\t\t")
+
+	_, err = cons.ExpectString("info  install")
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	_, err = cons.Send("s\t\r")
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if line, err := <-lines, <-errs; line != "install " || err != nil {
+		t.Errorf("unexpected result: %q %v", line, err)
+	}
+}
+
+func testCompleteFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"alpha.txt", "alpine.txt", ".hidden"} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o600)
+		if err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	err := os.Mkdir(filepath.Join(dir, "albums"), 0o700)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	prefix := dir + string(filepath.Separator)
+
+	cands := cli.CompleteFiles(prefix + "al")
+	exp := []string{prefix + "albums" + string(filepath.Separator), prefix + "alpha.txt", prefix + "alpine.txt"}
+
+	if strings.Join(cands, ",") != strings.Join(exp, ",") {
+		t.Error("unexpected candidates", cands)
+	}
+
+	if cands = cli.CompleteFiles(prefix + "."); len(cands) != 1 {
+		t.Error("unexpected candidates", cands)
+	}
+
+	if cands = cli.CompleteFiles(prefix + "missing/"); cands != nil {
+		t.Error("unexpected candidates", cands)
+	}
+}
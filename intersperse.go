@@ -0,0 +1,79 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"flag"
+)
+
+// WithInterspersedFlags makes Parse and Dispatch accept flags after
+// positional arguments, in the manner of GNU tools, so that
+// "tool copy src dst -force" sets the force flag. Parsing of flags stops
+// at "--" or at the name of a subcommand, whose arguments are left for
+// the subcommand to parse.
+func WithInterspersedFlags() Option {
+	return func(c *Cmd) {
+		c.interspersed = true
+	}
+}
+
+// parseFlags parses args with fs, in interspersed mode if enabled.
+// Positional arguments for which stop returns true end flag parsing.
+// Combined single letter flags are split before parsing. The argument
+// "--" always ends flag parsing and is removed, with all arguments
+// following it left as positional arguments.
+func (c *Cmd) parseFlags(fs *flag.FlagSet, args []string, stop func(string) bool) error {
+	args = c.expandShortFlags(fs, args, stop)
+
+	if !c.interspersed {
+		return fs.Parse(args)
+	}
+
+	var pos []string
+
+	for {
+		err := fs.Parse(args)
+		if err != nil {
+			return err
+		}
+
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+
+		// the remaining arguments are all positional if parsing
+		// stopped at "--" or a subcommand name
+		n := len(args) - len(rest)
+		if (n > 0 && args[n-1] == "--") || stop(rest[0]) {
+			pos = append(pos, rest...)
+
+			break
+		}
+
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+
+	return fs.Parse(append([]string{"--"}, pos...))
+}
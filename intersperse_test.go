@@ -0,0 +1,87 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"reflect"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestInterspersedFlags(t *testing.T) {
+	cmd := cli.NewCmd(cli.WithInterspersedFlags())
+	verbose := cmd.FlagSet.Bool("v", false, "verbose")
+
+	err := cmd.Parse([]string{"a", "-v", "b", "--", "-c"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !*verbose || !reflect.DeepEqual(cmd.FlagSet.Args(), []string{"a", "b", "-c"}) {
+		t.Errorf("unexpected result %v %q", *verbose, cmd.FlagSet.Args())
+	}
+
+	var got []string
+
+	cp := cmd.AddCommand("copy", "copy files", func(args []string) error {
+		got = args
+
+		return nil
+	})
+	force := cp.FlagSet.Bool("force", false, "overwrite")
+
+	*verbose = false
+
+	err = cmd.Parse([]string{"copy", "src", "-v", "--force"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *verbose || !reflect.DeepEqual(cmd.FlagSet.Args(), []string{"copy", "src", "-v", "--force"}) {
+		t.Fatalf("unexpected result %v %q", *verbose, cmd.FlagSet.Args())
+	}
+
+	err = cmd.Dispatch([]string{"copy", "src", "dst", "--force"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !*force || !reflect.DeepEqual(got, []string{"src", "dst"}) {
+		t.Errorf("unexpected result %v %q", *force, got)
+	}
+}
+
+func TestInterspersedFlagsDisabled(t *testing.T) {
+	cmd := cli.NewCmd()
+	verbose := cmd.FlagSet.Bool("v", false, "verbose")
+
+	err := cmd.Parse([]string{"a", "-v"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *verbose || !reflect.DeepEqual(cmd.FlagSet.Args(), []string{"a", "-v"}) {
+		t.Errorf("unexpected result %v %q", *verbose, cmd.FlagSet.Args())
+	}
+}
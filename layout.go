@@ -0,0 +1,229 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPaneLines is the number of lines kept by a scrolling pane.
+const maxPaneLines = 1000
+
+// Layout divides the live region of a TermPrinter into panes stacked
+// top to bottom, such as a header, a scrolling list of workers and a
+// footer, each of which is updated separately.
+//
+// Changes are drawn at most once per progress interval, so many
+// goroutines may update their panes freely. Use Draw to show the latest
+// state immediately. A Layout is safe for concurrent use.
+type Layout struct {
+	tp *TermPrinter
+
+	m        sync.Mutex
+	panes    []*Pane
+	last     time.Time
+	finished bool
+}
+
+// Pane is a section of a Layout holding lines of text.
+type Pane struct {
+	layout *Layout
+	height int
+
+	// guarded by the lock of the layout
+	lines []string
+}
+
+// NewLayout returns a new Layout drawn in the live region of tp.
+func (tp *TermPrinter) NewLayout() *Layout {
+	return &Layout{tp: tp}
+}
+
+// AddPane adds a pane below those already added. A pane with a height
+// greater than zero shows at most that many of its lines, from the top.
+// A pane with a height of zero or less scrolls: the scrolling panes
+// share the rows of the terminal left by the other panes, each showing
+// its most recent lines.
+func (l *Layout) AddPane(height int) *Pane {
+	p := &Pane{layout: l, height: max(height, 0)}
+
+	l.m.Lock()
+	l.panes = append(l.panes, p)
+	l.m.Unlock()
+
+	return p
+}
+
+// Set replaces the content of p with the lines of text.
+func (p *Pane) Set(text string) {
+	var lines []string
+	if text != "" {
+		lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+
+	p.layout.m.Lock()
+	p.lines = lines
+	p.layout.m.Unlock()
+
+	p.layout.draw(false)
+}
+
+// Setf replaces the content of p with text formatted in the manner of
+// fmt.Sprintf.
+func (p *Pane) Setf(f string, v ...interface{}) {
+	p.Set(fmt.Sprintf(f, v...))
+}
+
+// Append adds line to the end of p. A scrolling pane keeps its most
+// recent 1000 lines.
+func (p *Pane) Append(line string) {
+	p.layout.m.Lock()
+
+	p.lines = append(p.lines, strings.TrimSuffix(line, "\n"))
+	if len(p.lines) > maxPaneLines {
+		p.lines = p.lines[len(p.lines)-maxPaneLines:]
+	}
+
+	p.layout.m.Unlock()
+
+	p.layout.draw(false)
+}
+
+// Clear removes the content of p.
+func (p *Pane) Clear() {
+	p.Set("")
+}
+
+// Draw redraws the live region immediately.
+func (l *Layout) Draw() {
+	l.draw(true)
+}
+
+// Finish replaces the live region with the final state of the layout,
+// leaving it in the scrollback. Later updates are not drawn.
+func (l *Layout) Finish() {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if l.finished {
+		return
+	}
+
+	l.finished = true
+
+	l.tp.FinalizeLive(l.render())
+}
+
+// draw redraws the live region if the progress interval has passed
+// since it was last drawn, or if force is true.
+func (l *Layout) draw(force bool) {
+	l.m.Lock()
+	defer l.m.Unlock()
+
+	if l.finished {
+		return
+	}
+
+	now := l.tp.now()
+	if !force && !l.last.IsZero() && now.Sub(l.last) < progressInterval {
+		return
+	}
+
+	l.last = now
+
+	l.tp.Lprintf("%s", l.render())
+}
+
+// render returns the lines of the live region, called with the lock
+// held. Scrolling panes fill the rows of the terminal left after the
+// fixed panes, leaving a row for the cursor.
+func (l *Layout) render() string {
+	w, h := l.tp.termSize()
+
+	var fixed, scrolling int
+
+	for _, p := range l.panes {
+		if p.height > 0 {
+			fixed += min(p.height, len(p.lines))
+		} else {
+			scrolling++
+		}
+	}
+
+	rows := -1
+	if h > 0 && scrolling > 0 {
+		rows = max(h-1-fixed, 0)
+	}
+
+	var sb strings.Builder
+
+	for _, p := range l.panes {
+		lines := p.lines
+
+		switch {
+		case p.height > 0:
+			lines = lines[:min(p.height, len(lines))]
+		case rows >= 0:
+			// earlier panes take any rows left over by the division
+			n := rows / scrolling
+			if rows%scrolling > 0 {
+				n++
+			}
+
+			rows -= n
+			scrolling--
+
+			lines = scrollLines(lines, n)
+		}
+
+		for _, line := range lines {
+			if w > 0 {
+				line = truncateWidth(line, w)
+			}
+
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// scrollLines returns the last lines which fit in n rows. If lines are
+// omitted, the first row counts them.
+func scrollLines(lines []string, n int) []string {
+	if len(lines) <= n {
+		return lines
+	}
+
+	if n < 2 {
+		return lines[len(lines)-n:]
+	}
+
+	more := len(lines) - n + 1
+
+	return append([]string{fmt.Sprintf("... %d earlier lines", more)}, lines[len(lines)-n+1:]...)
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestLayout(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(buf)
+	p.SetTermSize(20, 8)
+
+	l := p.NewLayout()
+	header := l.AddPane(1)
+	workers := l.AddPane(0)
+	footer := l.AddPane(1)
+
+	header.Set("deploying 10 services to production\nsecond line")
+	footer.Setf("%d of %d done", 3, 10)
+
+	for i := 1; i <= 10; i++ {
+		workers.Append(fmt.Sprintf("worker %d", i))
+	}
+
+	l.Finish()
+
+	exp := strings.Join([]string{
+		"deploying 10 service",
+		"... 6 earlier lines",
+		"worker 7",
+		"worker 8",
+		"worker 9",
+		"worker 10",
+		"3 of 10 done",
+	}, "\n") + "\n"
+
+	if !strings.HasSuffix(buf.String(), exp) {
+		t.Errorf("expected suffix:\n%s\nreceived:\n%s", exp, buf)
+	}
+
+	n := buf.Len()
+
+	footer.Set("ignored")
+	l.Draw()
+
+	if buf.Len() != n {
+		t.Error("layout drawn after Finish")
+	}
+}
+
+func TestLayoutScrollingPanes(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(buf)
+	p.SetTermSize(80, 6)
+
+	l := p.NewLayout()
+	a := l.AddPane(0)
+	b := l.AddPane(0)
+
+	a.Set("a1\na2\na3\na4")
+	b.Set("b1\nb2\nb3\nb4")
+
+	l.Finish()
+
+	// five rows are shared, with the extra row going to the first pane
+	exp := "... 2 earlier lines\na3\na4\n... 3 earlier lines\nb4\n"
+
+	if !strings.HasSuffix(buf.String(), exp) {
+		t.Errorf("expected suffix %q, received %q", exp, buf)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrWaitAborted is returned by RateLimiter.Wait and Semaphore.Acquire
+// when the exit channel closes while waiting.
+var ErrWaitAborted = errors.New("wait aborted")
+
+// RateLimiter limits the rate of events, allowing n events in each
+// period. Up to n events may occur at once after a quiet period.
+type RateLimiter struct {
+	eh       *ExitHandler
+	interval time.Duration
+	burst    float64
+
+	m      sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing n events in each period
+// per, which aborts waiting when the exit channel of eh closes.
+func NewRateLimiter(eh *ExitHandler, n int, per time.Duration) *RateLimiter {
+	if n < 1 {
+		n = 1
+	}
+
+	return &RateLimiter{
+		eh:       eh,
+		interval: per / time.Duration(n),
+		burst:    float64(n),
+		tokens:   float64(n),
+		last:     eh.clock().Now(),
+	}
+}
+
+// Wait blocks until an event is allowed. Wait returns ErrWaitAborted if
+// the exit channel closes first, or has already closed.
+func (r *RateLimiter) Wait() error {
+	r.eh.Add(1)
+	defer r.eh.Done()
+
+	select {
+	case <-r.eh.C:
+		return ErrWaitAborted
+	default:
+	}
+
+	wait := r.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	t := r.eh.clock().NewTimer(wait)
+
+	select {
+	case <-t.C():
+		return nil
+	case <-r.eh.C:
+		t.Stop()
+
+		r.m.Lock()
+		r.tokens++
+		r.m.Unlock()
+
+		return ErrWaitAborted
+	}
+}
+
+// reserve takes a token, returning how long to wait until it is
+// available.
+func (r *RateLimiter) reserve() time.Duration {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	now := r.eh.clock().Now()
+
+	if r.interval > 0 {
+		r.tokens += float64(now.Sub(r.last)) / float64(r.interval)
+	} else {
+		r.tokens = r.burst
+	}
+
+	r.tokens = min(r.tokens, r.burst)
+	r.last = now
+
+	r.tokens--
+	if r.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-r.tokens * float64(r.interval))
+}
+
+// Semaphore limits the number of concurrent operations.
+type Semaphore struct {
+	eh    *ExitHandler
+	slots chan bool
+}
+
+// NewSemaphore returns a Semaphore allowing n concurrent holders, which
+// aborts waiting when the exit channel of eh closes.
+func NewSemaphore(eh *ExitHandler, n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+
+	return &Semaphore{eh: eh, slots: make(chan bool, n)}
+}
+
+// Acquire blocks until a slot is available and takes it. Acquire
+// returns ErrWaitAborted if the exit channel closes first, or has
+// already closed. Each successful call must be followed by a call to
+// Release.
+func (s *Semaphore) Acquire() error {
+	s.eh.Add(1)
+	defer s.eh.Done()
+
+	select {
+	case <-s.eh.C:
+		return ErrWaitAborted
+	default:
+	}
+
+	select {
+	case s.slots <- true:
+		return nil
+	case <-s.eh.C:
+		return ErrWaitAborted
+	}
+}
+
+// TryAcquire takes a slot if one is available, reporting whether it
+// did.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.slots <- true:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release returns a slot taken by Acquire or TryAcquire.
+func (s *Semaphore) Release() {
+	<-s.slots
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestRateLimiter(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	rl := cli.NewRateLimiter(eh, 5, 100*time.Millisecond)
+
+	start := time.Now()
+
+	for i := 0; i < 10; i++ {
+		err := rl.Wait()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("expected 10 events to take 100ms, took %v", d)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		eh.Exit(nil)
+	}()
+
+	for i := 0; i < 10; i++ {
+		err := rl.Wait()
+		if errors.Is(err, cli.ErrWaitAborted) {
+			break
+		}
+
+		if err != nil || i == 9 {
+			t.Fatal("expected ErrWaitAborted, received", err)
+		}
+	}
+
+	_ = eh.Wait()
+}
+
+func TestSemaphore(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	sem := cli.NewSemaphore(eh, 2)
+
+	for i := 0; i < 2; i++ {
+		err := sem.Acquire()
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	if sem.TryAcquire() {
+		t.Error("expected TryAcquire to fail")
+	}
+
+	sem.Release()
+
+	if !sem.TryAcquire() {
+		t.Error("expected TryAcquire to succeed")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		eh.Exit(nil)
+	}()
+
+	err := sem.Acquire()
+	if !errors.Is(err, cli.ErrWaitAborted) {
+		t.Error("expected ErrWaitAborted, received", err)
+	}
+
+	_ = eh.Wait()
+}
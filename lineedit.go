@@ -0,0 +1,396 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Control keys handled by the line editor.
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyCtrlH     = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyBackspace = 127
+)
+
+// lineEditor holds the state of a line being edited on a terminal.
+type lineEditor struct {
+	r      *LineReader
+	prompt string
+	buf    []rune
+	pos    int
+
+	// hist is the index of the history entry being shown, equal to
+	// the length of the history when editing a new line
+	hist  int
+	saved []rune
+
+	// tabbed is set when the previous key was Tab
+	tabbed bool
+}
+
+// readTerm reads a line from a terminal with line editing.
+func (r *LineReader) readTerm(prompt string) (string, error) {
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		fmt.Fprint(r.out, prompt)
+
+		return r.readPlain()
+	}
+
+	defer r.eh.guardTerminal(restore)()
+
+	// the prompt is printed after entering raw mode so that input
+	// typed in response to the prompt is never processed by the
+	// terminal driver
+	e := &lineEditor{r: r, prompt: prompt, hist: len(r.history)}
+	e.redraw()
+
+	for {
+		k, err := r.next()
+		if err != nil {
+			fmt.Fprint(r.out, "\r\n")
+
+			return "", err
+		}
+
+		tabbed := e.tabbed
+		e.tabbed = false
+
+		if k == keyTab {
+			e.complete(tabbed)
+			e.tabbed = true
+
+			continue
+		}
+
+		done, err := e.key(k)
+		if done || err != nil {
+			return string(e.buf), err
+		}
+
+		e.redraw()
+	}
+}
+
+// readMasked reads a line from a terminal, echoing an asterisk for each
+// character.
+func (r *LineReader) readMasked(prompt string) (string, error) {
+	restore, err := makeRaw(r.fd)
+	if err != nil {
+		fmt.Fprint(r.out, prompt)
+
+		return r.readPlain()
+	}
+
+	defer r.eh.guardTerminal(restore)()
+
+	fmt.Fprint(r.out, prompt)
+
+	var buf []rune
+
+	for {
+		k, err := r.next()
+		if err != nil {
+			fmt.Fprint(r.out, "\r\n")
+
+			return "", err
+		}
+
+		switch k {
+		case keyCR, keyLF:
+			fmt.Fprint(r.out, "\r\n")
+
+			return string(buf), nil
+		case keyCtrlC:
+			fmt.Fprint(r.out, "^C\r\n")
+
+			return "", r.interrupt()
+		case keyCtrlD:
+			if len(buf) == 0 {
+				fmt.Fprint(r.out, "\r\n")
+
+				return "", io.EOF
+			}
+		case keyCtrlH, keyBackspace:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				fmt.Fprint(r.out, "\b \b")
+			}
+		case keyCtrlU:
+			fmt.Fprint(r.out, strings.Repeat("\b \b", len(buf)))
+			buf = nil
+		default:
+			if k >= ' ' {
+				buf = append(buf, k)
+				fmt.Fprint(r.out, "*")
+			}
+		}
+	}
+}
+
+// key handles a key press, reporting whether the line is complete.
+//
+//nolint:cyclop // a flat switch over keys is clearest
+func (e *lineEditor) key(k rune) (bool, error) {
+	switch k {
+	case keyCR, keyLF:
+		fmt.Fprint(e.r.out, "\r\n")
+
+		return true, nil
+	case keyCtrlC:
+		fmt.Fprint(e.r.out, "^C\r\n")
+		e.buf = nil
+
+		return true, e.r.interrupt()
+	case keyCtrlD:
+		if len(e.buf) == 0 {
+			fmt.Fprint(e.r.out, "\r\n")
+
+			return true, io.EOF
+		}
+
+		e.deleteChar()
+	case keyCtrlA:
+		e.pos = 0
+	case keyCtrlE:
+		e.pos = len(e.buf)
+	case keyCtrlB:
+		e.pos = max(0, e.pos-1)
+	case keyCtrlF:
+		e.pos = min(len(e.buf), e.pos+1)
+	case keyCtrlH, keyBackspace:
+		if e.pos > 0 {
+			e.pos--
+			e.deleteChar()
+		}
+	case keyCtrlK:
+		e.buf = e.buf[:e.pos]
+	case keyCtrlU:
+		e.buf = append([]rune(nil), e.buf[e.pos:]...)
+		e.pos = 0
+	case keyCtrlW:
+		e.deleteWord()
+	case keyCtrlP:
+		e.history(-1)
+	case keyCtrlN:
+		e.history(1)
+	case keyEscape:
+		return false, e.escape()
+	default:
+		if k >= ' ' {
+			e.insert(k)
+		}
+	}
+
+	return false, nil
+}
+
+// escape handles an escape sequence, such as an arrow key.
+func (e *lineEditor) escape() error {
+	k, err := e.r.next()
+	if err != nil || (k != '[' && k != 'O') {
+		return err
+	}
+
+	k, err = e.r.next()
+	if err != nil {
+		return err
+	}
+
+	// sequences of the form ESC [ n ~
+	var num strings.Builder
+
+	for k >= '0' && k <= '9' {
+		num.WriteRune(k)
+
+		k, err = e.r.next()
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case k == 'A':
+		e.history(-1)
+	case k == 'B':
+		e.history(1)
+	case k == 'C':
+		e.pos = min(len(e.buf), e.pos+1)
+	case k == 'D':
+		e.pos = max(0, e.pos-1)
+	case k == 'H', k == '~' && (num.String() == "1" || num.String() == "7"):
+		e.pos = 0
+	case k == 'F', k == '~' && (num.String() == "4" || num.String() == "8"):
+		e.pos = len(e.buf)
+	case k == '~' && num.String() == "3":
+		e.deleteChar()
+	}
+
+	return nil
+}
+
+// complete completes the word before the cursor. If there are several
+// candidates, the word is extended to their longest common prefix, and
+// if that makes no progress on a second consecutive Tab, the candidates
+// are listed below the line.
+func (e *lineEditor) complete(listed bool) {
+	if e.r.completer == nil {
+		return
+	}
+
+	start := e.pos
+	for start > 0 && e.buf[start-1] != ' ' {
+		start--
+	}
+
+	word := string(e.buf[start:e.pos])
+	cands := e.r.completer(word)
+
+	if len(cands) == 0 {
+		return
+	}
+
+	prefix := cands[0]
+	for _, c := range cands[1:] {
+		prefix = commonPrefix(prefix, c)
+	}
+
+	// a single candidate is completed with a space, unless it is a
+	// directory which may be completed further
+	if len(cands) == 1 && !os.IsPathSeparator(prefix[len(prefix)-1]) {
+		prefix += " "
+	}
+
+	if prefix != word && strings.HasPrefix(prefix, word) {
+		tail := append([]rune(prefix), e.buf[e.pos:]...)
+		e.buf = append(e.buf[:start], tail...)
+		e.pos = start + len([]rune(prefix))
+		e.redraw()
+
+		return
+	}
+
+	if listed && len(cands) > 1 {
+		fmt.Fprintf(e.r.out, "\r\n%s\r\n", strings.Join(cands, "  "))
+		e.redraw()
+	}
+}
+
+// commonPrefix returns the longest common prefix of a and b.
+func commonPrefix(a string, b string) string {
+	ra, rb := []rune(a), []rune(b)
+
+	n := 0
+	for n < len(ra) && n < len(rb) && ra[n] == rb[n] {
+		n++
+	}
+
+	return string(ra[:n])
+}
+
+// insert inserts k at the cursor.
+func (e *lineEditor) insert(k rune) {
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = k
+	e.pos++
+}
+
+// deleteChar deletes the character under the cursor.
+func (e *lineEditor) deleteChar() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+// deleteWord deletes the word before the cursor.
+func (e *lineEditor) deleteWord() {
+	i := e.pos
+
+	for i > 0 && e.buf[i-1] == ' ' {
+		i--
+	}
+
+	for i > 0 && e.buf[i-1] != ' ' {
+		i--
+	}
+
+	e.buf = append(e.buf[:i], e.buf[e.pos:]...)
+	e.pos = i
+}
+
+// history moves d entries through the history.
+func (e *lineEditor) history(d int) {
+	h := e.hist + d
+	if h < 0 || h > len(e.r.history) {
+		return
+	}
+
+	if e.hist == len(e.r.history) {
+		e.saved = e.buf
+	}
+
+	e.hist = h
+
+	if h == len(e.r.history) {
+		e.buf = e.saved
+	} else {
+		e.buf = []rune(e.r.history[h])
+	}
+
+	e.pos = len(e.buf)
+}
+
+// redraw rewrites the prompt and line, then positions the cursor.
+func (e *lineEditor) redraw() {
+	var sb strings.Builder
+
+	sb.WriteString("\r")
+	sb.WriteString(e.prompt)
+	sb.WriteString(string(e.buf))
+	sb.WriteString("\x1b[K")
+
+	if n := len(e.buf) - e.pos; n > 0 {
+		fmt.Fprintf(&sb, "\x1b[%dD", n)
+	}
+
+	fmt.Fprint(e.r.out, sb.String())
+}
@@ -0,0 +1,203 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveRenderer holds the state of the live render loop of a
+// TermPrinter.
+type liveRenderer struct {
+	m       sync.Mutex // serializes calls to fn
+	fn      func() string
+	last    string
+	stop    chan bool
+	stopped chan bool
+
+	// paused holds updates in pending rather than drawing them,
+	// guarded by the frame lock of the TermPrinter
+	paused     bool
+	pending    string
+	hasPending bool
+}
+
+// SetLiveRenderer sets a function which returns the content of the live
+// region. Once set, the live region is redrawn by calling fn on the
+// interval given to StartLive, or on demand with Refresh. The content
+// is written in the manner of Lprintf.
+//
+// Using a renderer allows many goroutines to update shared state
+// without each formatting and writing status output. While a renderer
+// is running, Lprintf should not be called directly.
+func (tp *TermPrinter) SetLiveRenderer(fn func() string) {
+	tp.live.m.Lock()
+	tp.live.fn = fn
+	tp.live.m.Unlock()
+}
+
+// StartLive starts calling the live renderer at interval d, stopping
+// any previously started render loop.
+func (tp *TermPrinter) StartLive(d time.Duration) {
+	tp.StopLive()
+
+	stop := make(chan bool)
+	stopped := make(chan bool)
+
+	tp.live.m.Lock()
+	tp.live.stop = stop
+	tp.live.stopped = stopped
+	tp.live.m.Unlock()
+
+	go func() {
+		defer close(stopped)
+
+		tick, stopTick := tp.frames(d)
+		defer stopTick()
+
+		for {
+			select {
+			case <-tick:
+			case <-stop:
+				return
+			}
+
+			if tp.animationIdle() {
+				tp.idlePoll(stop)
+			}
+
+			tp.refresh(false)
+		}
+	}()
+}
+
+// StopLive stops the render loop started by StartLive and draws one
+// final frame so the live region reflects the latest state. StopLive
+// does nothing if the render loop is not running.
+func (tp *TermPrinter) StopLive() {
+	tp.live.m.Lock()
+	stop := tp.live.stop
+	stopped := tp.live.stopped
+	tp.live.stop = nil
+	tp.live.stopped = nil
+	tp.live.m.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-stopped
+
+	tp.refresh(true)
+}
+
+// Refresh immediately redraws the live region by calling the live
+// renderer. Refresh does nothing if no renderer is set.
+func (tp *TermPrinter) Refresh() {
+	tp.refresh(true)
+}
+
+// idlePoll waits while the render loop is paused by SetIdlePause,
+// calling the live renderer once per idleCheckInterval to check for
+// changes, until animations resume or stop is closed.
+func (tp *TermPrinter) idlePoll(stop chan bool) {
+	t := tp.clock().NewTicker(idleCheckInterval)
+	defer t.Stop()
+
+	for tp.animationIdle() {
+		select {
+		case <-tp.idleWait():
+		case <-t.C():
+			tp.live.m.Lock()
+			if tp.live.fn != nil && tp.live.fn() != tp.live.last {
+				tp.markActive()
+			}
+			tp.live.m.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// refresh implements Refresh. If force is false, the frame may be
+// dropped in CI environments. A change in the content counts as
+// activity for SetIdlePause.
+func (tp *TermPrinter) refresh(force bool) {
+	tp.live.m.Lock()
+	defer tp.live.m.Unlock()
+
+	if tp.live.fn == nil {
+		return
+	}
+
+	s := tp.live.fn()
+
+	if s != tp.live.last {
+		tp.live.last = s
+		tp.markActive()
+	}
+
+	tp.lprintf(force, "%s", s)
+}
+
+// FinalizeLive makes the live region part of the permanent output, so
+// it is not cleared by later updates and remains in the scrollback. If
+// msg is not empty, it first replaces the content of the live region,
+// in the manner of Lprintf, with a newline added if it has none. If the
+// live region is paused, it is resumed, drawing the latest update. Any
+// count of messages suppressed by SetErrorDedup is printed first.
+func (tp *TermPrinter) FinalizeLive(msg string) {
+	if msg != "" && !strings.HasSuffix(msg, "\n") {
+		msg += "\n"
+	}
+
+	msg = tp.redactSecrets(msg)
+
+	tp.flushDedup()
+
+	tp.frame.Lock()
+	defer tp.frame.Unlock()
+
+	if tp.live.paused && tp.live.hasPending && msg == "" {
+		_, _ = tp.drawLive(tp.live.pending)
+	}
+
+	tp.live.paused = false
+	tp.live.pending = ""
+	tp.live.hasPending = false
+
+	switch {
+	case msg == "":
+	case tp.outIsTerm && !tp.ciEnabled():
+		_, _ = tp.drawLive(msg)
+	default:
+		_, _ = io.WriteString(tp.liveWriter(), msg)
+	}
+
+	tp.resetLiveLines()
+	tp.setProgressFraction(noProgress)
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestLiveRenderer(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+
+	p.Refresh()
+
+	if outbuf.Len() != 0 {
+		t.Error("unexpected output", outbuf.String())
+	}
+
+	var count int64
+
+	p.SetLiveRenderer(func() string {
+		return fmt.Sprintf("frame %d\n", atomic.AddInt64(&count, 1))
+	})
+
+	p.StartLive(10 * time.Millisecond)
+	time.Sleep(55 * time.Millisecond)
+	p.StopLive()
+	p.StopLive()
+
+	n := atomic.LoadInt64(&count)
+	if n < 3 {
+		t.Error("expected at least 3 frames, got", n)
+	}
+
+	lines := strings.Split(strings.TrimSpace(outbuf.String()), "\n")
+	if int64(len(lines)) != n || lines[len(lines)-1] != fmt.Sprintf("frame %d", n) {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func TestFinalizeLive(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	var outstr string
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		outstr, err = cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+
+	p.Lprintf("progress 100%%\n")
+	p.FinalizeLive("")
+	p.Lprintf("working\n")
+	p.FinalizeLive("done")
+	p.Println("END")
+
+	wg.Wait()
+
+	// only the working line is cleared, to be replaced by done
+	exp := "progress 100%\r\nworking\r\n\x1b[1A\x1b[2Kdone\r\nEND"
+	if outstr != exp {
+		t.Errorf("unexpected output %q", outstr)
+	}
+}
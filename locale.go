@@ -0,0 +1,214 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale holds the conventions used to format numbers and dates.
+type Locale struct {
+	// Decimal separates the integer and fractional parts of a number.
+	Decimal string
+
+	// Group separates groups of three digits in the integer part of a
+	// number. An empty string disables grouping.
+	Group string
+
+	// Date is the layout used to format dates, as used by time.Format.
+	Date string
+}
+
+// Locale conventions, selected by language or language and territory.
+//
+//nolint:gochecknoglobals // read-only table
+var (
+	localeC = Locale{Decimal: ".", Date: "2006-01-02"}
+
+	locales = map[string]Locale{
+		"en":    {Decimal: ".", Group: ",", Date: "02/01/2006"},
+		"en_US": {Decimal: ".", Group: ",", Date: "01/02/2006"},
+		"en_CA": {Decimal: ".", Group: ",", Date: "2006-01-02"},
+		"ja":    {Decimal: ".", Group: ",", Date: "2006/01/02"},
+		"zh":    {Decimal: ".", Group: ",", Date: "2006/01/02"},
+		"ko":    {Decimal: ".", Group: ",", Date: "2006. 01. 02."},
+		"de":    {Decimal: ",", Group: ".", Date: "02.01.2006"},
+		"de_CH": {Decimal: ".", Group: "'", Date: "02.01.2006"},
+		"da":    {Decimal: ",", Group: ".", Date: "02.01.2006"},
+		"es":    {Decimal: ",", Group: ".", Date: "02/01/2006"},
+		"it":    {Decimal: ",", Group: ".", Date: "02/01/2006"},
+		"pt":    {Decimal: ",", Group: ".", Date: "02/01/2006"},
+		"nl":    {Decimal: ",", Group: ".", Date: "02-01-2006"},
+		"tr":    {Decimal: ",", Group: ".", Date: "02.01.2006"},
+		"fr":    {Decimal: ",", Group: "\u202f", Date: "02/01/2006"},
+		"cs":    {Decimal: ",", Group: "\u00a0", Date: "02.01.2006"},
+		"fi":    {Decimal: ",", Group: "\u00a0", Date: "02.01.2006"},
+		"nb":    {Decimal: ",", Group: "\u00a0", Date: "02.01.2006"},
+		"pl":    {Decimal: ",", Group: "\u00a0", Date: "02.01.2006"},
+		"ru":    {Decimal: ",", Group: "\u00a0", Date: "02.01.2006"},
+		"uk":    {Decimal: ",", Group: "\u00a0", Date: "02.01.2006"},
+		"sv":    {Decimal: ",", Group: "\u00a0", Date: "2006-01-02"},
+	}
+)
+
+// LookupLocale returns the conventions for a locale name such as
+// "de_DE.UTF-8", matching on language and territory, then on language
+// alone. The C and POSIX locales, and unknown names, use a decimal
+// point, no digit grouping and ISO 8601 dates; ok reports whether the
+// name was known.
+func LookupLocale(name string) (loc Locale, ok bool) {
+	name, _, _ = strings.Cut(name, ".")
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.ReplaceAll(name, "-", "_")
+
+	if loc, ok = locales[name]; ok {
+		return loc, true
+	}
+
+	lang, _, _ := strings.Cut(name, "_")
+
+	if loc, ok = locales[strings.ToLower(lang)]; ok {
+		return loc, true
+	}
+
+	return localeC, name == "C" || name == "POSIX"
+}
+
+// SetLocale sets the locale used to format numbers and dates, by name
+// as accepted by LookupLocale. An empty name restores the default, which
+// follows the LC_ALL, LC_NUMERIC, LC_TIME and LANG environment
+// variables.
+func (tp *TermPrinter) SetLocale(name string) {
+	tp.locale = name
+}
+
+// numericLocale returns the locale used to format numbers.
+func (tp *TermPrinter) numericLocale() Locale {
+	return tp.lookupLocale("LC_NUMERIC")
+}
+
+// lookupLocale returns the locale set with SetLocale, or the locale
+// named by the environment for the category env.
+func (tp *TermPrinter) lookupLocale(env string) Locale {
+	name := tp.locale
+
+	for _, v := range []string{"LC_ALL", env, "LANG"} {
+		if name != "" {
+			break
+		}
+
+		name = os.Getenv(v)
+	}
+
+	loc, _ := LookupLocale(name)
+
+	return loc
+}
+
+// FormatInt formats n with the digit grouping of the locale.
+func (tp *TermPrinter) FormatInt(n int64) string {
+	return tp.numericLocale().formatNumber(strconv.FormatInt(n, 10))
+}
+
+// FormatFloat formats f with prec digits after the decimal separator,
+// using the separators of the locale.
+func (tp *TermPrinter) FormatFloat(f float64, prec int) string {
+	return tp.numericLocale().formatNumber(strconv.FormatFloat(f, 'f', prec, 64))
+}
+
+// FormatBytes formats n as a number of bytes with a binary prefix, such
+// as "1.5 MiB", using the decimal separator of the locale.
+func (tp *TermPrinter) FormatBytes(n int64) string {
+	const unit = 1024
+
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return tp.FormatFloat(float64(n)/float64(div), 1) + " " + string("KMGTPE"[exp]) + "iB"
+}
+
+// FormatDate formats the date of t in the layout of the locale, which
+// follows LC_TIME rather than LC_NUMERIC.
+func (tp *TermPrinter) FormatDate(t time.Time) string {
+	return t.Format(tp.lookupLocale("LC_TIME").Date)
+}
+
+// parseNumber parses s, a number formatted with the separators of the
+// locale.
+func (loc Locale) parseNumber(s string) (float64, error) {
+	if loc.Group != "" {
+		s = strings.ReplaceAll(s, loc.Group, "")
+	}
+
+	if loc.Decimal != "." {
+		s = strings.ReplaceAll(s, loc.Decimal, ".")
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+// formatNumber replaces the separators of s, a number formatted by
+// strconv, with those of the locale.
+func (loc Locale) formatNumber(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+
+	if loc.Group != "" && len(whole) > 3 {
+		var sb strings.Builder
+
+		lead := len(whole) % 3
+		if lead == 0 {
+			lead = 3
+		}
+
+		sb.WriteString(whole[:lead])
+
+		for i := lead; i < len(whole); i += 3 {
+			sb.WriteString(loc.Group)
+			sb.WriteString(whole[i : i+3])
+		}
+
+		whole = sb.String()
+	}
+
+	if hasFrac {
+		return sign + whole + loc.Decimal + frac
+	}
+
+	return sign + whole
+}
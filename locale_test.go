@@ -0,0 +1,130 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestLocaleFormat(t *testing.T) {
+	date := time.Date(2024, time.March, 7, 0, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct {
+		locale string
+		num    string
+		bytes  string
+		date   string
+	}{
+		{"C", "-1234567.89", "1.5 KiB", "2024-03-07"},
+		{"en_US.UTF-8", "-1,234,567.89", "1.5 KiB", "03/07/2024"},
+		{"en_GB", "-1,234,567.89", "1.5 KiB", "07/03/2024"},
+		{"de_DE.UTF-8", "-1.234.567,89", "1,5 KiB", "07.03.2024"},
+		{"de_CH", "-1'234'567.89", "1.5 KiB", "07.03.2024"},
+		{"fr_FR", "-1\u202f234\u202f567,89", "1,5 KiB", "07/03/2024"},
+	} {
+		t.Run(tc.locale, func(t *testing.T) {
+			tp := cli.NewTermPrinter()
+			tp.SetLocale(tc.locale)
+
+			if s := tp.FormatFloat(-1234567.891, 2); s != tc.num {
+				t.Errorf("expected %q, received %q", tc.num, s)
+			}
+
+			if s := tp.FormatBytes(1536); s != tc.bytes {
+				t.Errorf("expected %q, received %q", tc.bytes, s)
+			}
+
+			if s := tp.FormatDate(date); s != tc.date {
+				t.Errorf("expected %q, received %q", tc.date, s)
+			}
+		})
+	}
+
+	tp := cli.NewTermPrinter()
+	tp.SetLocale("en")
+
+	for n, exp := range map[int64]string{0: "0", 999: "999", 1000: "1,000", -12345: "-12,345", 123456: "123,456"} {
+		if s := tp.FormatInt(n); s != exp {
+			t.Errorf("expected %q, received %q", exp, s)
+		}
+	}
+}
+
+func TestLocaleEnv(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("LC_NUMERIC", "de_DE.UTF-8")
+	t.Setenv("LC_TIME", "sv_SE.UTF-8")
+
+	tp := cli.NewTermPrinter()
+
+	if s := tp.FormatInt(1000); s != "1.000" {
+		t.Errorf("expected LC_NUMERIC to apply, received %q", s)
+	}
+
+	if s := tp.FormatDate(time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC)); s != "2024-03-07" {
+		t.Errorf("expected LC_TIME to apply, received %q", s)
+	}
+
+	t.Setenv("LC_ALL", "C")
+
+	if s := tp.FormatInt(1000); s != "1000" {
+		t.Errorf("expected LC_ALL to apply, received %q", s)
+	}
+
+	if _, ok := cli.LookupLocale("xx_YY"); ok {
+		t.Error("expected unknown locale")
+	}
+}
+
+func TestPrintTableLocale(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+	cmd.SetLocale("de_DE")
+	cmd.AddTableFlags()
+
+	err := cmd.Parse([]string{"--sort-by", "size"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	tbl := cli.NewTable(cli.Column{Name: "name"}, cli.Column{Name: "size"})
+	tbl.AddRow("big", cmd.FormatInt(12000))
+	tbl.AddRow("small", cmd.FormatInt(900))
+
+	_, err = cmd.PrintTable(tbl)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.Contains(buf.String(), "small  900\nbig    12.000\n") {
+		t.Errorf("expected numeric sort, received:\n%s", buf)
+	}
+}
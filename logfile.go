@@ -0,0 +1,186 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultLogFiles is the number of rotated log files kept if
+// LogFileOptions does not set MaxFiles.
+const defaultLogFiles = 5
+
+// LogFileOptions configures the log file written by LogToFile.
+type LogFileOptions struct {
+	// MaxSize is the size in bytes at which the file is rotated. A
+	// zero or negative value disables rotation.
+	MaxSize int64
+
+	// MaxFiles is the number of rotated files kept, named with the
+	// suffixes ".1" for the most recent through to ".MaxFiles".
+	// Defaults to 5.
+	MaxFiles int
+
+	// Timestamp prefixes each line with the time it was written.
+	Timestamp bool
+}
+
+// LogToFile copies all output written to Stderr by Eprint, Eprintf,
+// Eprintln and Fatal to the file at path, which is created if needed
+// and appended to otherwise. Output to the terminal is unchanged. When
+// the file would exceed opts.MaxSize it is rotated. Errors writing the
+// file are ignored, so a full disk does not interrupt the program.
+func (c *Cmd) LogToFile(path string, opts LogFileOptions) error {
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = defaultLogFiles
+	}
+
+	lf := &logFile{path: path, opts: opts, bol: true}
+
+	err := lf.open()
+	if err != nil {
+		return err
+	}
+
+	c.AddOutputHook(func(s Stream, b []byte) {
+		if s == Stderr {
+			_, _ = lf.Write(b)
+		}
+	})
+
+	return nil
+}
+
+// logFile is a file which rotates itself once it reaches a maximum size.
+type logFile struct {
+	m    sync.Mutex
+	path string
+	opts LogFileOptions
+	f    *os.File
+	size int64
+	bol  bool
+}
+
+// open opens the log file for appending.
+func (lf *logFile) open() error {
+	f, err := os.OpenFile(lf.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	lf.f = f
+	lf.size = st.Size()
+
+	return nil
+}
+
+// Write writes b to the file, rotating it first if b would take it over
+// the maximum size.
+func (lf *logFile) Write(b []byte) (int, error) {
+	lf.m.Lock()
+	defer lf.m.Unlock()
+
+	out := b
+	if lf.opts.Timestamp {
+		out = lf.stamp(b)
+	}
+
+	if lf.opts.MaxSize > 0 && lf.size > 0 && lf.size+int64(len(out)) > lf.opts.MaxSize {
+		err := lf.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := lf.f.Write(out)
+	lf.size += int64(n)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// stamp returns b with the current time inserted at the start of each
+// line.
+func (lf *logFile) stamp(b []byte) []byte {
+	ts := time.Now().Format(time.RFC3339) + " "
+
+	var out bytes.Buffer
+
+	for len(b) > 0 {
+		if lf.bol {
+			out.WriteString(ts)
+		}
+
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			out.Write(b)
+			lf.bol = false
+
+			break
+		}
+
+		out.Write(b[:i+1])
+		b = b[i+1:]
+		lf.bol = true
+	}
+
+	return out.Bytes()
+}
+
+// rotate closes the file, shifts the existing rotated files up by one,
+// discarding the oldest, and opens a new file.
+func (lf *logFile) rotate() error {
+	err := lf.f.Close()
+	if err != nil {
+		return err
+	}
+
+	_ = os.Remove(fmt.Sprintf("%s.%d", lf.path, lf.opts.MaxFiles))
+
+	for i := lf.opts.MaxFiles - 1; i > 0; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", lf.path, i), fmt.Sprintf("%s.%d", lf.path, i+1))
+	}
+
+	err = os.Rename(lf.path, lf.path+".1")
+
+	// reopen even if the rename failed, so logging can continue
+	if oerr := lf.open(); err == nil {
+		err = oerr
+	}
+
+	return err
+}
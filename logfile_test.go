@@ -0,0 +1,102 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestLogToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.SetStderr(errbuf)
+
+	err := cmd.LogToFile(path, cli.LogFileOptions{MaxSize: 20, MaxFiles: 2})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Println("not logged")
+
+	for _, s := range []string{"first error", "second error", "third error", "fourth error"} {
+		cmd.Eprintln(s)
+	}
+
+	if errbuf.String() != "first error\nsecond error\nthird error\nfourth error\n" {
+		t.Errorf("unexpected terminal output %q", errbuf)
+	}
+
+	for name, exp := range map[string]string{
+		path:        "fourth error\n",
+		path + ".1": "third error\n",
+		path + ".2": "second error\n",
+	} {
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if string(b) != exp {
+			t.Errorf("%s: expected %q, received %q", filepath.Base(name), exp, b)
+		}
+	}
+
+	_, err = os.Stat(path + ".3")
+	if !os.IsNotExist(err) {
+		t.Error("expected oldest file to be removed, received", err)
+	}
+}
+
+func TestLogToFileTimestamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(new(bytes.Buffer))
+
+	err := cmd.LogToFile(path, cli.LogFileOptions{Timestamp: true})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Eprint("one\ntw")
+	cmd.Eprint("o\n")
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	ts := `\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\S+ `
+	if !regexp.MustCompile(`^` + ts + `one\n` + ts + `two\n$`).Match(b) {
+		t.Errorf("unexpected log %q", b)
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metric types, as used in the exposition format.
+const (
+	metricCounter = "counter"
+	metricGauge   = "gauge"
+)
+
+// Metrics is a registry of counters and gauges, which can be exported
+// in the Prometheus text exposition format. Metrics is an http.Handler
+// serving the exposition. A nil *Metrics is valid and discards all
+// updates, so helpers can report metrics unconditionally.
+type Metrics struct {
+	m       sync.Mutex
+	metrics map[string]*metric
+}
+
+// metric is a named value in a Metrics registry.
+type metric struct {
+	name string
+	help string
+	typ  string
+	bits atomic.Uint64
+}
+
+// value returns the current value.
+func (m *metric) value() float64 {
+	return math.Float64frombits(m.bits.Load())
+}
+
+// add adds v to the value.
+func (m *metric) add(v float64) {
+	for {
+		old := m.bits.Load()
+		n := math.Float64bits(math.Float64frombits(old) + v)
+
+		if m.bits.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
+
+// NewMetrics returns an empty Metrics registry.
+func NewMetrics() *Metrics {
+	return &Metrics{metrics: make(map[string]*metric)}
+}
+
+// register returns the metric with the given name, creating it if
+// needed. It returns nil if m is nil.
+func (m *Metrics) register(name string, help string, typ string) *metric {
+	if m == nil {
+		return nil
+	}
+
+	m.m.Lock()
+	defer m.m.Unlock()
+
+	if mt, ok := m.metrics[name]; ok {
+		return mt
+	}
+
+	mt := &metric{name: name, help: help, typ: typ}
+	m.metrics[name] = mt
+
+	return mt
+}
+
+// Counter returns the counter with the given name, registering it with
+// the help text if it does not exist.
+func (m *Metrics) Counter(name string, help string) *Counter {
+	return &Counter{m: m.register(name, help, metricCounter)}
+}
+
+// Gauge returns the gauge with the given name, registering it with the
+// help text if it does not exist.
+func (m *Metrics) Gauge(name string, help string) *Gauge {
+	return &Gauge{m: m.register(name, help, metricGauge)}
+}
+
+// WriteTo writes all metrics to w in the Prometheus text exposition
+// format, sorted by name.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	if m == nil {
+		return 0, nil
+	}
+
+	m.m.Lock()
+
+	list := make([]*metric, 0, len(m.metrics))
+	for _, mt := range m.metrics {
+		list = append(list, mt)
+	}
+
+	m.m.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	var b bytes.Buffer
+
+	for _, mt := range list {
+		if mt.help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", mt.name, mt.help)
+		}
+
+		fmt.Fprintf(&b, "# TYPE %s %s\n", mt.name, mt.typ)
+		fmt.Fprintf(&b, "%s %s\n", mt.name, strconv.FormatFloat(mt.value(), 'g', -1, 64))
+	}
+
+	return b.WriteTo(w)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition
+// format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	_, _ = m.WriteTo(w)
+}
+
+// Counter is a metric which only increases.
+type Counter struct {
+	m *metric
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds v, which must not be negative, to the counter.
+func (c *Counter) Add(v float64) {
+	if c == nil || c.m == nil || v < 0 {
+		return
+	}
+
+	c.m.add(v)
+}
+
+// Value returns the current value of the counter.
+func (c *Counter) Value() float64 {
+	if c == nil || c.m == nil {
+		return 0
+	}
+
+	return c.m.value()
+}
+
+// Gauge is a metric which may increase and decrease.
+type Gauge struct {
+	m *metric
+}
+
+// Set sets the gauge to v.
+func (g *Gauge) Set(v float64) {
+	if g == nil || g.m == nil {
+		return
+	}
+
+	g.m.bits.Store(math.Float64bits(v))
+}
+
+// Add adds v to the gauge.
+func (g *Gauge) Add(v float64) {
+	if g == nil || g.m == nil {
+		return
+	}
+
+	g.m.add(v)
+}
+
+// Inc adds one to the gauge.
+func (g *Gauge) Inc() {
+	g.Add(1)
+}
+
+// Dec subtracts one from the gauge.
+func (g *Gauge) Dec() {
+	g.Add(-1)
+}
+
+// Value returns the current value of the gauge.
+func (g *Gauge) Value() float64 {
+	if g == nil || g.m == nil {
+		return 0
+	}
+
+	return g.m.value()
+}
+
+// EnableMetrics creates the metrics registry of the Cmd, if it does not
+// already exist, and returns it. Once enabled, the metrics are also
+// served at /metrics by ServeStatus.
+func (c *Cmd) EnableMetrics() *Metrics {
+	if c.metrics == nil {
+		c.metrics = NewMetrics()
+	}
+
+	return c.metrics
+}
+
+// Metrics returns the metrics registry of the Cmd, or nil if
+// EnableMetrics has not been called.
+func (c *Cmd) Metrics() *Metrics {
+	return c.metrics
+}
+
+// DumpMetrics writes the metrics to the file at path every interval d,
+// and a final time when the exit channel closes. Each write replaces
+// the file atomically, so readers never see a partial dump. Errors are
+// printed to Stderr. Metrics are enabled if needed.
+func (c *Cmd) DumpMetrics(path string, d time.Duration) {
+	m := c.EnableMetrics()
+
+	dump := func() {
+		err := writeFileAtomic(path, m)
+		if err != nil {
+			c.Eprintln("metrics:", err)
+		}
+	}
+
+	c.Every(d, dump)
+
+	c.Add(1)
+
+	go func() {
+		defer c.Done()
+
+		<-c.C
+		dump()
+	}()
+}
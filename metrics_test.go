@@ -0,0 +1,159 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestMetrics(t *testing.T) {
+	m := cli.NewMetrics()
+
+	c := m.Counter("jobs_total", "Jobs processed.")
+	c.Inc()
+	c.Add(2.5)
+	c.Add(-1)
+
+	g := m.Gauge("temperature", "")
+	g.Set(20)
+	g.Dec()
+
+	if m.Counter("jobs_total", "ignored").Value() != 3.5 {
+		t.Error("expected existing counter to be returned")
+	}
+
+	buf := new(bytes.Buffer)
+
+	_, err := m.WriteTo(buf)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := "# HELP jobs_total Jobs processed.\n" +
+		"# TYPE jobs_total counter\n" +
+		"jobs_total 3.5\n" +
+		"# TYPE temperature gauge\n" +
+		"temperature 19\n"
+
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\nreceived:\n%s", exp, buf)
+	}
+
+	var nilm *cli.Metrics
+
+	nilm.Counter("x", "").Inc()
+	nilm.Gauge("y", "").Set(1)
+
+	if nilm.Gauge("y", "").Value() != 0 {
+		t.Error("expected nil registry to discard updates")
+	}
+}
+
+func TestMetricsHelpers(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStderr(new(bytes.Buffer))
+
+	m := cmd.EnableMetrics()
+	if cmd.Metrics() != m || cmd.EnableMetrics() != m {
+		t.Fatal("expected the same registry")
+	}
+
+	pool := cli.NewWorkerPool(cmd.ExitHandler, 2, 10)
+	pool.SetMetrics(m, "pool")
+
+	for i := 0; i < 5; i++ {
+		err := pool.Submit(func() {})
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	n := 0
+
+	err := cli.Retry(cmd.ExitHandler, cli.RetryPolicy{
+		Attempts: 3,
+		Delay:    time.Millisecond,
+		Metrics:  m,
+	}, func() error {
+		n++
+		if n < 2 {
+			return errTest
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	for m.Counter("pool_completed_total", "").Value() < 5 {
+		time.Sleep(time.Millisecond)
+	}
+
+	cmd.Exit(nil)
+	pool.Wait()
+
+	tests := map[string]float64{
+		"pool_queued":          0,
+		"pool_active":          0,
+		"retry_attempts_total": 2,
+		"retry_failures_total": 1,
+	}
+
+	for name, exp := range tests {
+		if v := m.Gauge(name, "").Value(); v != exp {
+			t.Errorf("%s: expected %v, received %v", name, exp, v)
+		}
+	}
+}
+
+func TestDumpMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.prom")
+
+	cmd := cli.NewCmd()
+	cmd.DumpMetrics(path, time.Hour)
+	cmd.Metrics().Counter("done_total", "").Inc()
+
+	cmd.Exit(nil)
+
+	err := cmd.Wait()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if !strings.Contains(string(b), "done_total 1\n") {
+		t.Errorf("unexpected dump %q", b)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownFormat is returned by PrintResult when the output format is
+// not recognized.
+var ErrUnknownFormat = errors.New("unknown output format")
+
+// Output formats accepted by the --output flag.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// AddOutputFlag adds the --output flag to the FlagSet, selecting the
+// format used by PrintResult.
+func (c *Cmd) AddOutputFlag() {
+	c.format = c.FlagSet.String("output", FormatText,
+		"output `format`: text, json or yaml")
+}
+
+// OutputFormat returns the format selected by the --output flag, or
+// FormatText if the flag has not been added.
+func (c *Cmd) OutputFormat() string {
+	if c.format == nil || *c.format == "" {
+		return FormatText
+	}
+
+	return strings.ToLower(*c.format)
+}
+
+// PrintResult prints v to Stdout in the format selected by the --output
+// flag. In text format, a *Table is printed with PrintTable and any
+// other value is printed with Println. In json and yaml formats, v is
+// encoded in the manner of encoding/json, so struct tags for JSON are
+// also used for YAML. The --filter and --sort-by flags added by
+// AddTableFlags apply to a *Table in every format.
+func (c *Cmd) PrintResult(v interface{}) error {
+	format := c.OutputFormat()
+
+	if t, ok := v.(*Table); ok && (format == FormatJSON || format == FormatYAML) {
+		err := c.applyTableFlags(t)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch format {
+	case FormatText:
+		if t, ok := v.(*Table); ok {
+			_, err := c.PrintTable(t)
+
+			return err
+		}
+
+		_, err := c.Println(v)
+
+		return err
+	case FormatJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Printf("%s\n", b)
+
+		return err
+	case FormatYAML:
+		b, err := MarshalYAML(v)
+		if err != nil {
+			return err
+		}
+
+		_, err = c.Print(string(b))
+
+		return err
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+type testResult struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Count   int               `json:"count"`
+	Enabled bool              `json:"enabled"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Items   []testItem        `json:"items"`
+	Empty   []string          `json:"empty"`
+	Note    *string           `json:"note"`
+}
+
+type testItem struct {
+	ID   int    `json:"id"`
+	Path string `json:"path"`
+}
+
+func newTestResult() testResult {
+	return testResult{
+		Name:    "app",
+		Version: "1.0",
+		Count:   2,
+		Enabled: true,
+		Tags:    []string{"web", "yes"},
+		Labels:  map[string]string{"env": "prod: east"},
+		Items:   []testItem{{1, "/a"}, {2, "# b"}},
+		Empty:   []string{},
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	t.Run("YAML", testResultYAML)
+	t.Run("JSON", testResultJSON)
+	t.Run("Text", testResultText)
+	t.Run("TableFlags", testResultTableFlags)
+	t.Run("Unknown", testResultUnknown)
+}
+
+func testResultYAML(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.AddOutputFlag()
+
+	err := cmd.FlagSet.Parse([]string{"-output", "yaml"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = cmd.PrintResult(newTestResult())
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := `name: app
+version: "1.0"
+count: 2
+enabled: true
+tags:
+- web
+- "yes"
+labels:
+  env: "prod: east"
+items:
+- id: 1
+  path: /a
+- id: 2
+  path: "# b"
+empty: []
+note: null
+`
+	if outbuf.String() != exp {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testResultJSON(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.AddOutputFlag()
+
+	err := cmd.FlagSet.Parse([]string{"-output", "json"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	tbl := cli.NewTable(cli.Column{Name: "ID"}, cli.Column{Name: "NAME"})
+	tbl.AddRow("1", "web")
+
+	err = cmd.PrintResult(tbl)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "[\n  {\n    \"ID\": \"1\",\n    \"NAME\": \"web\"\n  }\n]\n"
+	if outbuf.String() != exp {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testResultText(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	tbl := cli.NewTable(cli.Column{Name: "ID"}, cli.Column{Name: "NAME"})
+	tbl.AddRow("1", "web")
+
+	err := cmd.PrintResult(tbl)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.PrintResult("done")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if outbuf.String() != "ID  NAME\n1   web\ndone\n" {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testResultTableFlags(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.AddOutputFlag()
+	cmd.AddTableFlags()
+
+	err := cmd.FlagSet.Parse([]string{"-output", "yaml", "-filter", "state=up",
+		"-sort-by", "-id"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	tbl := cli.NewTable(cli.Column{Name: "ID"}, cli.Column{Name: "STATE"})
+	tbl.AddRow("1", "up")
+	tbl.AddRow("2", "down")
+	tbl.AddRow("3", "up")
+
+	err = cmd.PrintResult(tbl)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "- ID: \"3\"\n  STATE: up\n- ID: \"1\"\n  STATE: up\n"
+	if outbuf.String() != exp {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testResultUnknown(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.AddOutputFlag()
+
+	err := cmd.FlagSet.Parse([]string{"-output", "xml"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	err = cmd.PrintResult("done")
+	if !errors.Is(err, cli.ErrUnknownFormat) {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	tests := []struct {
+		in  interface{}
+		exp string
+	}{
+		{"plain", "plain\n"},
+		{"", "\"\"\n"},
+		{[]int{}, "[]\n"},
+		{[][]int{{1, 2}, {3}}, "-\n  - 1\n  - 2\n-\n  - 3\n"},
+		{map[string]interface{}{"a": map[string]int{"b": 1}}, "a:\n  b: 1\n"},
+		{"line\nbreak", "\"line\\nbreak\"\n"},
+		{"foo:", "\"foo:\"\n"},
+		{"a:b", "a:b\n"},
+		{"0x10", "\"0x10\"\n"},
+		{"0o17", "\"0o17\"\n"},
+		{"0b101", "\"0b101\"\n"},
+		{"0xffffffffffffffffff", "\"0xffffffffffffffffff\"\n"},
+		{"0xfg", "0xfg\n"},
+		{".inf", "\".inf\"\n"},
+		{"2001-12-14", "\"2001-12-14\"\n"},
+		{"2001-12-14t21:59:43.10-05:00", "\"2001-12-14t21:59:43.10-05:00\"\n"},
+		{"2001-12-14 21:59:43", "\"2001-12-14 21:59:43\"\n"},
+		{"2001-12-14x", "2001-12-14x\n"},
+		{map[string]string{"a": "foo:"}, "a: \"foo:\"\n"},
+	}
+
+	for _, tc := range tests {
+		b, err := cli.MarshalYAML(tc.in)
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+
+		if string(b) != tc.exp {
+			t.Errorf("unexpected output for %v: %q", tc.in, b)
+		}
+	}
+
+	_, err := cli.MarshalYAML(make(chan int))
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+}
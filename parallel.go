@@ -0,0 +1,170 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// prefixColors are assigned in turn to the tasks run by RunParallel.
+//
+//nolint:gochecknoglobals // read-only color cycle
+var prefixColors = []Color{Cyan, Green, Yellow, Magenta, Blue}
+
+// taskKey is the context key for the output writer of a task.
+type taskKey struct{}
+
+// ParallelOptions configures the behavior of RunParallel.
+type ParallelOptions struct {
+	// Limit is the maximum number of tasks run at once. A zero or
+	// negative value runs all tasks at once.
+	Limit int
+
+	// Printer receives the output of the tasks. If nil, a new
+	// TermPrinter writing to os.Stdout is used.
+	Printer *TermPrinter
+}
+
+// RunParallel runs each task concurrently, subject to the limit in
+// opts, and waits for all of them to finish. Tasks are started in order
+// of name.
+//
+// Output written by a task to TaskWriter is printed a line at a time,
+// prefixed with the name of the task, colored if color is enabled. The
+// returned error joins the errors of all failed tasks, each prefixed
+// with the task name.
+func RunParallel(ctx context.Context, tasks map[string]func(ctx context.Context) error, opts ParallelOptions) error {
+	tp := opts.Printer
+	if tp == nil {
+		tp = NewTermPrinter()
+	}
+
+	names := make([]string, 0, len(tasks))
+	width := 0
+
+	for name := range tasks {
+		names = append(names, name)
+		width = max(width, textWidth(name))
+	}
+
+	sort.Strings(names)
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = max(1, len(tasks))
+	}
+
+	sem := make(chan bool, limit)
+	errs := make([]error, len(names))
+	wg := new(sync.WaitGroup)
+
+	for i, name := range names {
+		select {
+		case sem <- true:
+		case <-ctx.Done():
+			errs[i] = fmt.Errorf("%s: %w", name, ctx.Err())
+
+			continue
+		}
+
+		prefix := padRight(name, width) + " | "
+		lw := &lineWriter{
+			tp:     tp,
+			prefix: tp.Colorize(prefixColors[i%len(prefixColors)], prefix),
+		}
+
+		wg.Add(1)
+
+		go func(i int, name string, fn func(context.Context) error) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := fn(context.WithValue(ctx, taskKey{}, io.Writer(lw)))
+			lw.flush()
+
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		}(i, name, tasks[name])
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// TaskWriter returns the output writer of the task run by RunParallel
+// with ctx. If ctx does not belong to a task, io.Discard is returned.
+func TaskWriter(ctx context.Context) io.Writer {
+	if w, ok := ctx.Value(taskKey{}).(io.Writer); ok {
+		return w
+	}
+
+	return io.Discard
+}
+
+// lineWriter prints complete lines with a prefix.
+type lineWriter struct {
+	tp     *TermPrinter
+	prefix string
+
+	m   sync.Mutex
+	buf []byte
+}
+
+// Write prints each complete line in b, buffering any partial line.
+func (lw *lineWriter) Write(b []byte) (int, error) {
+	lw.m.Lock()
+	defer lw.m.Unlock()
+
+	lw.buf = append(lw.buf, b...)
+
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		lw.tp.Println(lw.prefix + string(lw.buf[:i]))
+		lw.buf = lw.buf[i+1:]
+	}
+
+	return len(b), nil
+}
+
+// flush prints any buffered partial line.
+func (lw *lineWriter) flush() {
+	lw.m.Lock()
+	defer lw.m.Unlock()
+
+	if len(lw.buf) > 0 {
+		lw.tp.Println(lw.prefix + string(lw.buf))
+		lw.buf = nil
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestRunParallel(t *testing.T) {
+	t.Run("Output", testParallelOutput)
+	t.Run("Limit", testParallelLimit)
+	t.Run("Canceled", testParallelCanceled)
+}
+
+func testParallelOutput(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+
+	err := cli.RunParallel(context.Background(), map[string]func(context.Context) error{
+		"lint": func(ctx context.Context) error {
+			fmt.Fprint(cli.TaskWriter(ctx), "checking\nno ")
+			fmt.Fprint(cli.TaskWriter(ctx), "issues")
+
+			return nil
+		},
+		"test": func(ctx context.Context) error {
+			fmt.Fprintln(cli.TaskWriter(ctx), "running")
+
+			return errTest
+		},
+	}, cli.ParallelOptions{Printer: p})
+	if !errors.Is(err, errTest) || err.Error() != "test: testing error" {
+		t.Error("unexpected error:", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(outbuf.String()), "\n")
+	sort.Strings(lines)
+
+	exp := "lint | checking\nlint | no issues\ntest | running"
+	if strings.Join(lines, "\n") != exp {
+		t.Error("unexpected output", outbuf.String())
+	}
+
+	if cli.TaskWriter(context.Background()) == nil {
+		t.Error("expected writer, received nil")
+	}
+}
+
+func testParallelLimit(t *testing.T) {
+	var running, peak int64
+
+	tasks := make(map[string]func(context.Context) error)
+
+	for i := 0; i < 6; i++ {
+		tasks[fmt.Sprint("task", i)] = func(context.Context) error {
+			n := atomic.AddInt64(&running, 1)
+
+			for {
+				p := atomic.LoadInt64(&peak)
+				if n <= p || atomic.CompareAndSwapInt64(&peak, p, n) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt64(&running, -1)
+
+			return nil
+		}
+	}
+
+	err := cli.RunParallel(context.Background(), tasks, cli.ParallelOptions{Limit: 2})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if p := atomic.LoadInt64(&peak); p != 2 {
+		t.Error("expected peak of 2 tasks, got", p)
+	}
+}
+
+func testParallelCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	block := make(chan bool)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+		close(block)
+	}()
+
+	err := cli.RunParallel(ctx, map[string]func(context.Context) error{
+		"a": func(context.Context) error { <-block; return nil },
+		"b": func(context.Context) error { return nil },
+	}, cli.ParallelOptions{Limit: 1})
+	if !errors.Is(err, context.Canceled) || err.Error() != "b: context canceled" {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"io"
+	"os"
+)
+
+// Sequences enabling and disabling focus reports from the terminal.
+const (
+	focusReportingOn  = "\x1b[?1004h"
+	focusReportingOff = "\x1b[?1004l"
+)
+
+// PauseLive freezes the live region, so its content can be read or
+// copied while the work it describes continues. Updates made by
+// Lprintf or the live renderer while paused are not drawn, but the
+// latest is kept and drawn by ResumeLive. Messages printed while paused
+// appear below the frozen region as usual. PauseLive only affects a
+// live region drawn on a terminal.
+func (tp *TermPrinter) PauseLive() {
+	tp.frame.Lock()
+	tp.live.paused = true
+	tp.frame.Unlock()
+}
+
+// ResumeLive resumes drawing the live region after PauseLive, drawing
+// the latest update made while paused.
+func (tp *TermPrinter) ResumeLive() {
+	tp.frame.Lock()
+	defer tp.frame.Unlock()
+
+	if !tp.live.paused {
+		return
+	}
+
+	tp.live.paused = false
+
+	if tp.live.hasPending {
+		s := tp.live.pending
+		tp.live.pending = ""
+		tp.live.hasPending = false
+
+		_, _ = tp.drawLive(s)
+	}
+}
+
+// LivePaused reports whether the live region is paused.
+func (tp *TermPrinter) LivePaused() bool {
+	tp.frame.Lock()
+	defer tp.frame.Unlock()
+
+	return tp.live.paused
+}
+
+// toggleLive pauses the live region if it is running, or resumes it if
+// it is paused.
+func (tp *TermPrinter) toggleLive() {
+	if tp.LivePaused() {
+		tp.ResumeLive()
+	} else {
+		tp.PauseLive()
+	}
+}
+
+// EnablePauseKey listens for key on Stdin, pausing the live region
+// with PauseLive when it is pressed and resuming it with ResumeLive
+// when it is pressed again. Other keys are discarded. Focus reporting
+// is enabled on the terminal while listening, and the reports are
+// passed to SetFocused. Listening stops,
+// and the live region is resumed, when the exit channel closes.
+//
+// EnablePauseKey does nothing unless Stdin and Stdout are both
+// terminals. Since keys are read as they are pressed, EnablePauseKey
+// should not be used while the program reads from Stdin. Signal keys
+// such as Ctrl-C are unaffected. ErrNotSupported is returned on
+// platforms where keys cannot be read individually.
+func (c *Cmd) EnablePauseKey(key byte) error {
+	f, ok := c.stdinReader().(*os.File)
+	if !ok || !isTerminal(f) || !c.outIsTerm {
+		return nil
+	}
+
+	c.Add(1)
+
+	var focus focusReports
+
+	stop, err := listenKeys(f, func(b byte) {
+		part, complete, focused := focus.feed(b)
+
+		switch {
+		case complete:
+			c.SetFocused(focused)
+		case !part && b == key:
+			c.toggleLive()
+		}
+	})
+	if err != nil {
+		c.Done()
+
+		return err
+	}
+
+	// report focus changes, so that animations can pause while the
+	// window is unfocused
+	_, _ = io.WriteString(c.out, focusReportingOn)
+
+	stopKeys := stop
+	stop = c.GuardTerminal(func() {
+		_, _ = io.WriteString(c.out, focusReportingOff)
+		c.SetFocused(true)
+		stopKeys()
+	})
+
+	go func() {
+		defer c.Done()
+
+		<-c.C
+
+		stop()
+		c.ResumeLive()
+	}()
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestPauseLive(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	var outstr string
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		outstr, err = cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+
+	p.Lprintf("one\n")
+	p.PauseLive()
+	p.Lprintf("two\n")
+	p.Lprintf("three\n")
+
+	if !p.LivePaused() {
+		t.Error("expected live region to be paused")
+	}
+
+	p.ResumeLive()
+	p.Println("END")
+
+	wg.Wait()
+
+	if !strings.Contains(outstr, "one") || strings.Contains(outstr, "two") ||
+		!strings.Contains(outstr, "three") {
+		t.Errorf("unexpected output %q", outstr)
+	}
+}
+
+func TestEnablePauseKey(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	go func() { _, _ = cons.ExpectEOF() }()
+
+	cmd := cli.NewCmd()
+	cmd.SetStdin(cons.Tty())
+	cmd.SetStdout(cons.Tty())
+
+	err = cmd.EnablePauseKey(' ')
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	waitPaused := func(exp bool) {
+		t.Helper()
+
+		for i := 0; i < 100 && cmd.LivePaused() != exp; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if cmd.LivePaused() != exp {
+			t.Fatal("expected paused to be", exp)
+		}
+	}
+
+	_, _ = cons.Send("x ")
+	waitPaused(true)
+
+	_, _ = cons.Send(" ")
+	waitPaused(false)
+
+	_, _ = cons.Send(" ")
+	waitPaused(true)
+
+	cmd.Exit(nil)
+	_ = cmd.Wait()
+
+	if cmd.LivePaused() {
+		t.Error("expected live region to resume at exit")
+	}
+}
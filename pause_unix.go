@@ -0,0 +1,85 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package cli
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// listenKeys puts the terminal f into cbreak mode and calls fn with
+// each byte read from it, until the returned function is called. It is
+// read through a non-blocking duplicate of its descriptor, so reading
+// can be interrupted.
+func listenKeys(f *os.File, fn func(b byte)) (func(), error) {
+	restore, err := makeCbreak(f.Fd())
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := unix.Dup(int(f.Fd()))
+	if err != nil {
+		restore()
+
+		return nil, err
+	}
+
+	// the flag is shared with the original descriptor, so it is reset
+	// once listening stops
+	_ = unix.SetNonblock(fd, true)
+
+	dup := os.NewFile(uintptr(fd), f.Name())
+	done := make(chan bool)
+
+	go func() {
+		defer close(done)
+
+		buf := make([]byte, 16)
+
+		for {
+			n, err := dup.Read(buf)
+
+			for _, b := range buf[:n] {
+				fn(b)
+			}
+
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return func() {
+		_ = dup.SetReadDeadline(time.Now())
+		<-done
+
+		_ = unix.SetNonblock(fd, false)
+		_ = dup.Close()
+
+		restore()
+	}, nil
+}
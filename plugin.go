@@ -0,0 +1,127 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EnablePlugins makes Dispatch look for an external command when it is
+// given an unknown subcommand, in the manner of git and kubectl. For
+// the subcommand "foo", an executable named prefix followed by "foo"
+// is searched for on the PATH. If prefix is empty, the base name of the
+// program followed by a hyphen is used. Subcommand names containing a
+// path separator or ".." are never run as plugins.
+func (c *Cmd) EnablePlugins(prefix string) {
+	if prefix == "" {
+		prefix = filepath.Base(c.FlagSet.Name()) + "-"
+	}
+
+	c.pluginPrefix = prefix
+}
+
+// lookPlugin returns the path of the plugin for the named subcommand,
+// or an empty string if there is none.
+func (c *Cmd) lookPlugin(name string) string {
+	if c.pluginPrefix == "" || name == "" ||
+		strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return ""
+	}
+
+	path, err := exec.LookPath(c.pluginPrefix + name)
+	if err != nil {
+		return ""
+	}
+
+	return path
+}
+
+// runPlugin runs the plugin at path with args, connected to the input
+// and output of the Cmd. Streams which are files, such as a terminal,
+// are passed to the plugin directly so it can detect them, after the
+// live region is cleared and queued output is written. If the exit
+// channel closes while the plugin is running, it is sent an interrupt
+// so it may shut down in turn. If the plugin exits with a non-zero
+// status, the error is an *ExitError.
+func (c *Cmd) runPlugin(path string, args []string) error {
+	c.Add(1)
+	defer c.Done()
+
+	if c.outIsTerm && !c.ciEnabled() {
+		c.clearLiveLines()
+	}
+
+	_ = c.Flush()
+
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = c.stdinReader()
+	cmd.Stdout = c.pluginOutput(Stdout)
+	cmd.Stderr = c.pluginOutput(Stderr)
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-c.C:
+			if cmd.Process.Signal(os.Interrupt) != nil {
+				_ = cmd.Process.Kill()
+			}
+		case <-done:
+		}
+	}()
+
+	err = cmd.Wait()
+
+	var ee *exec.ExitError
+	if errors.As(err, &ee) {
+		return &ExitError{Name: filepath.Base(path), Code: ee.ExitCode()}
+	}
+
+	return err
+}
+
+// pluginOutput returns the destination of stream s for a plugin, which
+// is the file written to by the stream if there is one.
+func (c *Cmd) pluginOutput(s Stream) io.Writer {
+	lw := c.TermPrinter.out
+	if s == Stderr {
+		lw = c.TermPrinter.err
+	}
+
+	if f, ok := lw.w.(*os.File); ok {
+		return f
+	}
+
+	return c.writer(s)
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts require a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	writePlugin(t, dir, "tool-hello", `echo "hello $*"; exit $#`)
+	writePlugin(t, dir, "tool-wait",
+		`trap 'echo stopping; exit 5' INT; echo ready; while :; do sleep 0.05; done`)
+	writePlugin(t, dir, "tool-tty", `read line; if [ -t 1 ]; then echo "tty $line"; else echo "pipe $line"; fi`)
+
+	t.Run("Run", testPluginRun)
+	t.Run("Signal", testPluginSignal)
+	t.Run("Terminal", testPluginTerminal)
+	t.Run("Path", testPluginPath)
+}
+
+func writePlugin(t *testing.T, dir string, name string, script string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func testPluginRun(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(buf)
+	cmd.AddCommand("help", "", func([]string) error { return nil })
+
+	err := cmd.Dispatch([]string{"hello", "a", "b"})
+	if !errors.Is(err, cli.ErrUnknownCommand) {
+		t.Error("expected ErrUnknownCommand, received", err)
+	}
+
+	cmd.EnablePlugins("tool-")
+
+	err = cmd.Dispatch([]string{"hello", "a", "b"})
+
+	var ee *cli.ExitError
+	if !errors.As(err, &ee) || ee.Code != 2 || ee.Name != "tool-hello" {
+		t.Error("unexpected error:", err)
+	}
+
+	if buf.String() != "hello a b\n" {
+		t.Errorf("unexpected output %q", buf)
+	}
+
+	err = cmd.Dispatch([]string{"missing"})
+	if !errors.Is(err, cli.ErrUnknownCommand) {
+		t.Error("expected ErrUnknownCommand, received", err)
+	}
+}
+
+func testPluginSignal(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer r.Close()
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(w)
+	cmd.EnablePlugins("tool-")
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.Dispatch([]string{"wait"})
+		w.Close()
+	}()
+
+	buf := make([]byte, 6)
+
+	_, err = r.Read(buf)
+	if err != nil || string(buf) != "ready\n" {
+		t.Fatalf("unexpected read %q %v", buf, err)
+	}
+
+	cmd.Exit(nil)
+
+	select {
+	case err = <-errc:
+	case <-time.After(5 * time.Second):
+		t.Fatal("plugin did not exit")
+	}
+
+	var ee *cli.ExitError
+	if !errors.As(err, &ee) || ee.Code != 5 {
+		t.Error("unexpected error:", err)
+	}
+
+	out, _ := io.ReadAll(r)
+	if string(out) != "stopping\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func testPluginTerminal(t *testing.T) {
+	cons := newTestConsole(t)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdin(strings.NewReader("input\n"))
+	cmd.SetStdout(cons.Tty())
+	cmd.EnablePlugins("tool-")
+
+	err := cmd.Dispatch([]string{"tty"})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	_, err = cons.ExpectString("tty input")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func testPluginPath(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.Mkdir(filepath.Join(dir, "tool-sub"), 0o755)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	writePlugin(t, filepath.Join(dir, "tool-sub"), "hello", "echo escaped")
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.EnablePlugins(dir + "/tool-")
+
+	for _, name := range []string{"sub/hello", "sub/../sub/hello", ".."} {
+		err = cmd.Dispatch([]string{name})
+		if !errors.Is(err, cli.ErrUnknownCommand) {
+			t.Errorf("expected ErrUnknownCommand for %q, received %v", name, err)
+		}
+	}
+}
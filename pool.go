@@ -0,0 +1,235 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPoolClosed is returned by Submit once the exit channel has closed.
+var ErrPoolClosed = errors.New("worker pool closed")
+
+// WorkerPool runs submitted tasks on a fixed number of goroutines
+// managed by an ExitHandler.
+//
+// Each worker is registered with the ExitHandler, so a call to Wait on
+// the ExitHandler will also wait for the pool to finish. Once Exit is
+// called the pool stops accepting tasks. By default, tasks remaining in
+// the queue are discarded. If drain is enabled with SetDrain, the
+// remaining tasks are run before the workers return, optionally limited
+// by a drain timeout.
+//
+// Tasks which are running when Exit is called are not interrupted, a
+// long-running task should watch the exit channel C itself.
+type WorkerPool struct {
+	abandoned int64 // guarantee 64 bit alignment on 32 bit platforms
+	drainTime int64
+
+	eh    *ExitHandler
+	queue chan func()
+	drain bool
+
+	wg sync.WaitGroup
+	m  sync.RWMutex
+
+	closed    bool
+	closeOnce sync.Once
+	deadline  time.Time
+
+	stats poolStats
+}
+
+// poolStats holds the metrics updated by a WorkerPool.
+type poolStats struct {
+	queued    *Gauge
+	active    *Gauge
+	completed *Counter
+	abandoned *Counter
+}
+
+// NewWorkerPool returns a new WorkerPool running the given number of
+// workers with a queue capacity of size. The workers are started
+// immediately and added to eh.
+func NewWorkerPool(eh *ExitHandler, workers int, size int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+
+	if size < 0 {
+		size = 0
+	}
+
+	p := &WorkerPool{
+		eh:    eh,
+		queue: make(chan func(), size),
+	}
+
+	eh.Add(workers)
+	p.wg.Add(workers)
+
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+
+	return p
+}
+
+// SetDrain sets whether queued tasks are run or discarded after Exit
+// is called. SetDrain must be called before Exit.
+func (p *WorkerPool) SetDrain(drain bool) {
+	p.m.Lock()
+	p.drain = drain
+	p.m.Unlock()
+}
+
+// SetDrainTimeout limits the time spent running queued tasks after
+// Exit is called. Tasks not started before the timeout expires are
+// abandoned. A zero or negative value waits indefinitely.
+func (p *WorkerPool) SetDrainTimeout(t time.Duration) {
+	atomic.StoreInt64(&p.drainTime, int64(t))
+}
+
+// SetMetrics reports the state of the pool to m, using metric names
+// beginning with name: the number of queued and running tasks as
+// gauges, and the number of completed and abandoned tasks as counters.
+// SetMetrics must be called before tasks are submitted.
+func (p *WorkerPool) SetMetrics(m *Metrics, name string) {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.stats = poolStats{
+		queued:    m.Gauge(name+"_queued", "Tasks waiting in the queue."),
+		active:    m.Gauge(name+"_active", "Tasks currently running."),
+		completed: m.Counter(name+"_completed_total", "Tasks run to completion."),
+		abandoned: m.Counter(name+"_abandoned_total", "Tasks discarded at exit."),
+	}
+}
+
+// Submit adds a task to the queue, blocking if the queue is full.
+// Submit returns ErrPoolClosed if Exit has been called.
+func (p *WorkerPool) Submit(task func()) error {
+	p.m.RLock()
+	defer p.m.RUnlock()
+
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.queue <- task:
+		p.stats.queued.Inc()
+
+		return nil
+	case <-p.eh.C:
+		return ErrPoolClosed
+	}
+}
+
+// Wait blocks until all workers have returned. The return value is the
+// number of tasks which were queued but never run.
+func (p *WorkerPool) Wait() int {
+	p.wg.Wait()
+
+	return int(atomic.LoadInt64(&p.abandoned))
+}
+
+// worker runs tasks until the exit channel closes, then drains or
+// discards the remaining queue.
+func (p *WorkerPool) worker() {
+	defer p.eh.Done()
+	defer p.wg.Done()
+
+	for {
+		// check for exit first so queued tasks are not started after
+		// the exit channel closes
+		select {
+		case <-p.eh.C:
+			p.close()
+			p.finish()
+
+			return
+		default:
+		}
+
+		select {
+		case task := <-p.queue:
+			p.run(task)
+		case <-p.eh.C:
+		}
+	}
+}
+
+// close stops Submit from adding new tasks and records the drain
+// deadline, if applicable.
+func (p *WorkerPool) close() {
+	p.closeOnce.Do(func() {
+		p.m.Lock()
+		p.closed = true
+
+		if t := atomic.LoadInt64(&p.drainTime); t > 0 {
+			p.deadline = p.eh.clock().Now().Add(time.Duration(t))
+		}
+
+		p.m.Unlock()
+	})
+}
+
+// finish empties the queue, running each task if drain is enabled and
+// the deadline has not passed.
+func (p *WorkerPool) finish() {
+	p.m.RLock()
+	drain := p.drain
+	deadline := p.deadline
+	p.m.RUnlock()
+
+	for {
+		select {
+		case task := <-p.queue:
+			if drain && (deadline.IsZero() || p.eh.clock().Now().Before(deadline)) {
+				p.run(task)
+			} else {
+				p.stats.queued.Dec()
+				p.stats.abandoned.Inc()
+				atomic.AddInt64(&p.abandoned, 1)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// run runs a task taken from the queue, updating the metrics.
+func (p *WorkerPool) run(task func()) {
+	p.stats.queued.Dec()
+	p.stats.active.Inc()
+
+	defer func() {
+		p.stats.active.Dec()
+		p.stats.completed.Inc()
+	}()
+
+	task()
+}
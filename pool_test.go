@@ -0,0 +1,163 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func ExampleWorkerPool() {
+	eh := new(cli.ExitHandler)
+	pool := cli.NewWorkerPool(eh, 1, 10)
+	pool.SetDrain(true)
+
+	for i := 1; i <= 3; i++ {
+		n := i
+
+		err := pool.Submit(func() {
+			fmt.Println("task", n)
+		})
+		if err != nil {
+			fmt.Println("unexpected error:", err)
+		}
+	}
+
+	eh.Exit(nil)
+
+	fmt.Println("abandoned:", pool.Wait())
+
+	err := pool.Submit(func() {})
+	fmt.Println(err)
+
+	// Output:
+	// task 1
+	// task 2
+	// task 3
+	// abandoned: 0
+	// worker pool closed
+}
+
+func TestWorkerPool(t *testing.T) {
+	t.Run("Discard", testPoolDiscard)
+	t.Run("Timeout", testPoolTimeout)
+	t.Run("Closed", testPoolClosed)
+}
+
+func testPoolDiscard(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	pool := cli.NewWorkerPool(eh, 1, 10)
+
+	var count int64
+
+	started := make(chan bool)
+	block := make(chan bool)
+
+	err := pool.Submit(func() {
+		close(started)
+		<-block
+		atomic.AddInt64(&count, 1)
+	})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		err = pool.Submit(func() { atomic.AddInt64(&count, 1) })
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+	}
+
+	<-started
+	eh.Exit(nil)
+	close(block)
+
+	if n := pool.Wait(); n != 5 {
+		t.Error("expected 5 abandoned, got", n)
+	}
+
+	if n := atomic.LoadInt64(&count); n != 1 {
+		t.Error("expected 1 task run, got", n)
+	}
+
+	err = eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func testPoolTimeout(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	pool := cli.NewWorkerPool(eh, 1, 10)
+	pool.SetDrain(true)
+	pool.SetDrainTimeout(100 * time.Millisecond)
+
+	started := make(chan bool)
+	block := make(chan bool)
+
+	err := pool.Submit(func() {
+		close(started)
+		<-block
+	})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		err = pool.Submit(func() { time.Sleep(75 * time.Millisecond) })
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+	}
+
+	<-started
+	eh.Exit(nil)
+	close(block)
+
+	if n := pool.Wait(); n != 1 {
+		t.Error("expected 1 abandoned, got", n)
+	}
+}
+
+func testPoolClosed(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	pool := cli.NewWorkerPool(eh, 2, 0)
+
+	eh.Exit(errors.New("testing error")) //nolint:goerr113 // ignore in test
+
+	err := pool.Submit(func() {})
+	if !errors.Is(err, cli.ErrPoolClosed) {
+		t.Error("expected ErrPoolClosed, got", err)
+	}
+
+	err = eh.Wait()
+	if err == nil || err.Error() != "testing error" {
+		t.Error("unexpected error:", err)
+	}
+}
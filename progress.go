@@ -0,0 +1,183 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Layout of progress lines.
+const (
+	progressBarWidth = 24
+	progressInterval = 100 * time.Millisecond
+)
+
+// progressLine returns a line describing progress through total bytes,
+// with a bar, percentage and estimated time remaining if total is
+// known. The rate is in bytes per second.
+func (tp *TermPrinter) progressLine(label string, done int64, total int64, rate float64) string {
+	var sb strings.Builder
+
+	sb.WriteString(label)
+	sb.WriteString("  ")
+
+	if total > 0 {
+		writeProgressBar(&sb, float64(min(done, total))/float64(total))
+		fmt.Fprintf(&sb, "  %s / %s", tp.FormatBytes(done), tp.FormatBytes(total))
+	} else {
+		sb.WriteString(tp.FormatBytes(done))
+	}
+
+	if rate > 0 {
+		fmt.Fprintf(&sb, "  %s/s", tp.FormatBytes(int64(rate)))
+
+		if total > done {
+			eta := time.Duration(float64(total-done) / rate * float64(time.Second))
+			fmt.Fprintf(&sb, "  ETA %s", eta.Round(time.Second))
+		}
+	}
+
+	return sb.String()
+}
+
+// writeProgressBar writes a bar and percentage showing the fraction
+// complete, which is between 0 and 1.
+func writeProgressBar(sb *strings.Builder, fraction float64) {
+	// allow for rounding, so that 29/100 shows as 29%
+	fraction += 1e-9
+
+	filled := min(int(fraction*progressBarWidth), progressBarWidth)
+
+	sb.WriteString("[")
+	sb.WriteString(strings.Repeat("=", filled))
+
+	if filled < progressBarWidth {
+		sb.WriteString(">")
+		sb.WriteString(strings.Repeat(" ", progressBarWidth-filled-1))
+	}
+
+	fmt.Fprintf(sb, "] %3d%%", min(int(fraction*100), 100))
+}
+
+// progressWriter counts bytes written to it, updating a live progress
+// line at most once per progressInterval.
+type progressWriter struct {
+	tp    *TermPrinter
+	id    string
+	label string
+	total int64
+	start time.Time
+
+	m    sync.Mutex
+	done int64
+	last time.Time
+	est  RateEstimator
+}
+
+// newProgressWriter returns a progressWriter which has already counted
+// done bytes of total.
+func newProgressWriter(tp *TermPrinter, label string, done int64, total int64) *progressWriter {
+	pw := &progressWriter{
+		tp:    tp,
+		id:    tp.progressID(),
+		label: label,
+		total: total,
+		start: tp.now(),
+		done:  done,
+		est:   tp.newRateEstimator(),
+	}
+	tp.reportProgress(progressStart, pw.event(nil))
+	pw.draw(true)
+
+	return pw
+}
+
+// Write counts the bytes in b.
+func (pw *progressWriter) Write(b []byte) (int, error) {
+	pw.add(int64(len(b)))
+
+	return len(b), nil
+}
+
+// add counts n bytes.
+func (pw *progressWriter) add(n int64) {
+	pw.m.Lock()
+	pw.done += n
+	e := pw.event(nil)
+	pw.m.Unlock()
+
+	pw.tp.reportProgress(progressUpdate, e)
+	pw.draw(false)
+}
+
+// draw updates the progress line if progressInterval has passed since
+// it was last drawn, or if force is true.
+func (pw *progressWriter) draw(force bool) {
+	pw.m.Lock()
+	defer pw.m.Unlock()
+
+	now := pw.tp.now()
+	if !force && now.Sub(pw.last) < progressInterval {
+		return
+	}
+
+	pw.last = now
+	pw.est.Update(now, pw.done)
+
+	if pw.total > 0 {
+		pw.tp.setProgressFraction(float64(min(pw.done, pw.total)) / float64(pw.total))
+	}
+
+	pw.tp.Lprintf("%s\n", pw.tp.progressLine(pw.label, pw.done, pw.total, pw.est.Rate()))
+}
+
+// finish replaces the progress line with msg.
+func (pw *progressWriter) finish(msg string) {
+	pw.tp.FinalizeLive(msg)
+	pw.end(nil)
+}
+
+// end reports that the transfer ended, failing with err if it is not
+// nil, leaving the progress line to the caller.
+func (pw *progressWriter) end(err error) {
+	pw.m.Lock()
+	e := pw.event(err)
+	pw.m.Unlock()
+
+	pw.tp.reportProgress(progressFinish, e)
+}
+
+// event returns a ProgressEvent for the transfer, called with the lock
+// held except during construction.
+func (pw *progressWriter) event(err error) ProgressEvent {
+	return ProgressEvent{
+		ID:    pw.id,
+		Label: pw.label,
+		Done:  pw.done,
+		Total: pw.total,
+		Err:   err,
+	}
+}
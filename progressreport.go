@@ -0,0 +1,124 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProgressEvent describes the state of a task shown by a progress
+// widget.
+type ProgressEvent struct {
+	// ID identifies the task, and is unique within the TermPrinter.
+	ID string
+
+	// Parent is the ID of the task containing this one, or empty for a
+	// top-level task.
+	Parent string
+
+	// Label is the name of the task as shown on the terminal.
+	Label string
+
+	// Done is the number of units done, and Total the number needed to
+	// complete the task, or zero or less if unknown.
+	Done  int64
+	Total int64
+
+	// Err is the error the task failed with, set only on Finish.
+	Err error
+
+	// Time is the time of the event.
+	Time time.Time
+}
+
+// ProgressReporter receives the progress of tasks shown by the widgets
+// of a TermPrinter: Progress bars, Steps, and the transfers made by
+// Download, Copy and Extract. Each task has a Start event, any number of
+// Update events and a Finish event, all with the same ID. Steps which
+// are skipped are not reported.
+//
+// The terminal display is not affected by reporters, so a reporter may
+// pass progress on to a GUI or to CI annotations alongside it. Methods
+// are called synchronously on each change, from any goroutine, so they
+// must be quick and safe for concurrent use.
+type ProgressReporter interface {
+	Start(e ProgressEvent)
+	Update(e ProgressEvent)
+	Finish(e ProgressEvent)
+}
+
+// progressReporters holds the progress reporters of a TermPrinter.
+type progressReporters struct {
+	m      sync.RWMutex
+	rs     []ProgressReporter
+	lastID atomic.Uint64
+}
+
+// progressKind selects the ProgressReporter method called for an event.
+type progressKind int
+
+const (
+	progressStart progressKind = iota
+	progressUpdate
+	progressFinish
+)
+
+// AddProgressReporter adds r to the reporters receiving the progress of
+// tasks started after it is added.
+func (tp *TermPrinter) AddProgressReporter(r ProgressReporter) {
+	tp.reporters.m.Lock()
+	tp.reporters.rs = append(tp.reporters.rs, r)
+	tp.reporters.m.Unlock()
+}
+
+// progressID returns a new task ID.
+func (tp *TermPrinter) progressID() string {
+	return "task-" + strconv.FormatUint(tp.reporters.lastID.Add(1), 10)
+}
+
+// reportProgress passes e to the progress reporters, setting its time.
+func (tp *TermPrinter) reportProgress(kind progressKind, e ProgressEvent) {
+	tp.reporters.m.RLock()
+	rs := tp.reporters.rs
+	tp.reporters.m.RUnlock()
+
+	if len(rs) == 0 {
+		return
+	}
+
+	e.Time = tp.now()
+
+	for _, r := range rs {
+		switch kind {
+		case progressStart:
+			r.Start(e)
+		case progressUpdate:
+			r.Update(e)
+		case progressFinish:
+			r.Finish(e)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+// recordReporter records progress events as lines of text.
+type recordReporter struct {
+	m      sync.Mutex
+	events []string
+}
+
+func (r *recordReporter) record(kind string, e cli.ProgressEvent) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	s := fmt.Sprintf("%s %s %s %d/%d", kind, e.ID, e.Label, e.Done, e.Total)
+	if e.Parent != "" {
+		s += " parent=" + e.Parent
+	}
+
+	if e.Err != nil {
+		s += " err=" + e.Err.Error()
+	}
+
+	r.events = append(r.events, s)
+}
+
+func (r *recordReporter) Start(e cli.ProgressEvent)  { r.record("start", e) }
+func (r *recordReporter) Update(e cli.ProgressEvent) { r.record("update", e) }
+func (r *recordReporter) Finish(e cli.ProgressEvent) { r.record("finish", e) }
+
+func (r *recordReporter) String() string {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	return strings.Join(r.events, "\n")
+}
+
+func TestProgressReporter(t *testing.T) {
+	rec := new(recordReporter)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(io.Discard)
+	cmd.AddProgressReporter(rec)
+
+	job := cmd.NewProgress("job", 0)
+	fetch := job.Child("fetch", 1, 10)
+	fetch.Add(4)
+	fetch.Finish()
+	job.Finish()
+
+	steps := cmd.Steps()
+	_ = steps.Run("build", func() error { return nil })
+	_ = steps.Run("test", func() error { return errTest })
+	_ = steps.Run("deploy", func() error { return nil })
+
+	_, err := cmd.Copy(io.Discard, bytes.NewReader(make([]byte, 5)), 5)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	exp := strings.Join([]string{
+		"start task-1 job 0/0",
+		"start task-2 fetch 0/10 parent=task-1",
+		"update task-2 fetch 4/10 parent=task-1",
+		"finish task-2 fetch 4/10 parent=task-1",
+		"finish task-1 job 0/0",
+		"start task-3 build 0/0",
+		"finish task-3 build 0/0",
+		"start task-4 test 0/0",
+		"finish task-4 test 0/0 err=testing error",
+		"start task-5 copy 0/5",
+		"update task-5 copy 5/5",
+		"finish task-5 copy 5/5",
+	}, "\n")
+
+	if rec.String() != exp {
+		t.Errorf("expected:\n%s\nreceived:\n%s", exp, rec)
+	}
+}
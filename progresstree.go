@@ -0,0 +1,269 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Progress is a progress bar which may be composed of weighted child
+// bars, such as a job made of a download, an extraction and a
+// verification step. The progress of a bar with children is the
+// weighted average of the progress of its children.
+//
+// The bars are drawn in the live region of the TermPrinter, at most
+// once per progress interval. If the terminal is tall enough, the tree
+// of bars is drawn with each child indented beneath its parent,
+// otherwise only the top-level bar is drawn. A Progress is safe for
+// concurrent use.
+type Progress struct {
+	root   *Progress
+	tp     *TermPrinter
+	id     string
+	parent string
+	name   string
+	weight float64
+	depth  int
+
+	// guarded by the lock of the root
+	done     int64
+	total    int64
+	finished bool
+	children []*Progress
+
+	// used by the root only
+	m    sync.Mutex
+	last time.Time
+}
+
+// NewProgress returns a new top-level Progress named name, which is
+// complete once total units are done. A bar with children ignores its
+// total.
+func (tp *TermPrinter) NewProgress(name string, total int64) *Progress {
+	p := &Progress{tp: tp, id: tp.progressID(), name: name, total: total}
+	p.root = p
+
+	tp.reportProgress(progressStart, p.event())
+
+	return p
+}
+
+// Child adds a child bar named name, which is complete once total units
+// are done. Its share of the progress of p is its weight divided by the
+// sum of the weights of the children of p; a weight of zero or less
+// counts as one.
+func (p *Progress) Child(name string, weight float64, total int64) *Progress {
+	if weight <= 0 {
+		weight = 1
+	}
+
+	child := &Progress{
+		root:   p.root,
+		tp:     p.tp,
+		id:     p.tp.progressID(),
+		parent: p.id,
+		name:   name,
+		weight: weight,
+		depth:  p.depth + 1,
+		total:  total,
+	}
+
+	p.root.m.Lock()
+	p.children = append(p.children, child)
+	e := child.event()
+	p.root.m.Unlock()
+
+	p.tp.reportProgress(progressStart, e)
+	p.draw(false)
+
+	return child
+}
+
+// Add adds n to the units done.
+func (p *Progress) Add(n int64) {
+	p.root.m.Lock()
+	p.done += n
+	e := p.event()
+	p.root.m.Unlock()
+
+	p.tp.reportProgress(progressUpdate, e)
+	p.draw(false)
+}
+
+// Set sets the units done.
+func (p *Progress) Set(done int64) {
+	p.root.m.Lock()
+	p.done = done
+	e := p.event()
+	p.root.m.Unlock()
+
+	p.tp.reportProgress(progressUpdate, e)
+	p.draw(false)
+}
+
+// SetTotal sets the units which must be done to complete the bar, for
+// when it was not known in advance.
+func (p *Progress) SetTotal(total int64) {
+	p.root.m.Lock()
+	p.total = total
+	e := p.event()
+	p.root.m.Unlock()
+
+	p.tp.reportProgress(progressUpdate, e)
+	p.draw(false)
+}
+
+// Fraction returns the fraction of p which is complete, between 0 and
+// 1.
+func (p *Progress) Fraction() float64 {
+	p.root.m.Lock()
+	defer p.root.m.Unlock()
+
+	return p.fraction()
+}
+
+// Finish marks p as complete. Finishing the top-level bar replaces the
+// live region with its final state, leaving it in the scrollback.
+func (p *Progress) Finish() {
+	p.root.m.Lock()
+	p.finished = true
+	e := p.event()
+	p.root.m.Unlock()
+
+	p.tp.reportProgress(progressFinish, e)
+
+	if p.root != p {
+		p.draw(true)
+
+		return
+	}
+
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	p.tp.FinalizeLive(p.render())
+}
+
+// event returns a ProgressEvent for p, called with the lock of the root
+// held except during construction.
+func (p *Progress) event() ProgressEvent {
+	return ProgressEvent{
+		ID:     p.id,
+		Parent: p.parent,
+		Label:  p.name,
+		Done:   p.done,
+		Total:  p.total,
+	}
+}
+
+// fraction implements Fraction, called with the lock of the root held.
+func (p *Progress) fraction() float64 {
+	switch {
+	case p.finished:
+		return 1
+	case len(p.children) > 0:
+		var sum, weights float64
+
+		for _, child := range p.children {
+			sum += child.weight * child.fraction()
+			weights += child.weight
+		}
+
+		return sum / weights
+	case p.total > 0:
+		return float64(min(max(p.done, 0), p.total)) / float64(p.total)
+	default:
+		return 0
+	}
+}
+
+// draw redraws the live region if the progress interval has passed
+// since it was last drawn, or if force is true.
+func (p *Progress) draw(force bool) {
+	r := p.root
+
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	if r.finished {
+		return
+	}
+
+	now := r.tp.now()
+	if !force && !r.last.IsZero() && now.Sub(r.last) < progressInterval {
+		return
+	}
+
+	r.last = now
+
+	r.tp.setProgressFraction(r.fraction())
+	r.tp.Lprintf("%s", r.render())
+}
+
+// render returns the lines of the live region, called with the lock of
+// the root held. The tree is expanded if every bar fits on the
+// terminal, leaving a line for the cursor.
+func (p *Progress) render() string {
+	_, h := p.tp.termSize()
+
+	var sb strings.Builder
+
+	if h > 0 && p.count() < h {
+		p.renderTree(&sb)
+	} else {
+		p.renderLine(&sb)
+	}
+
+	return sb.String()
+}
+
+// count returns the number of bars in the tree rooted at p.
+func (p *Progress) count() int {
+	n := 1
+
+	for _, child := range p.children {
+		n += child.count()
+	}
+
+	return n
+}
+
+// renderTree writes the line of p followed by those of its children.
+func (p *Progress) renderTree(sb *strings.Builder) {
+	p.renderLine(sb)
+
+	for _, child := range p.children {
+		child.renderTree(sb)
+	}
+}
+
+// renderLine writes the line of p.
+func (p *Progress) renderLine(sb *strings.Builder) {
+	sb.WriteString(strings.Repeat("  ", p.depth))
+	sb.WriteString(p.name)
+	sb.WriteString("  ")
+	writeProgressBar(sb, p.fraction())
+	sb.WriteString("\n")
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestProgressFraction(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	job := cmd.NewProgress("job", 0)
+	download := job.Child("download", 70, 1000)
+	extract := job.Child("extract", 20, 10)
+	verify := job.Child("verify", 10, 0)
+
+	download.Set(500)
+	extract.Add(5)
+
+	if f := job.Fraction(); math.Abs(f-0.45) > 1e-9 {
+		t.Error("expected 0.45, received", f)
+	}
+
+	download.Finish()
+	verify.Finish()
+
+	if f := job.Fraction(); math.Abs(f-0.9) > 1e-9 {
+		t.Error("expected 0.9, received", f)
+	}
+}
+
+func TestProgressRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		height int
+		expect string
+	}{
+		{
+			name:   "tree",
+			height: 24,
+			expect: "job  [==================>     ]  75%\n" +
+				"  fetch  [========================] 100%\n" +
+				"  unpack  [============>           ]  50%\n",
+		},
+		{
+			name:   "rollup",
+			height: 3,
+			expect: "job  [==================>     ]  75%\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			outbuf := new(bytes.Buffer)
+
+			cmd := cli.NewCmd()
+			cmd.SetStdout(outbuf)
+			cmd.SetTermSize(80, test.height)
+			cmd.SetDeterministic(true)
+
+			job := cmd.NewProgress("job", 0)
+			job.Child("fetch", 1, 2).Finish()
+			unpack := job.Child("unpack", 1, 2)
+
+			outbuf.Reset()
+			unpack.Add(1)
+
+			if !strings.HasSuffix(outbuf.String(), test.expect) {
+				t.Errorf("expected %q, received %q", test.expect, outbuf)
+			}
+		})
+	}
+}
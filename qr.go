@@ -0,0 +1,693 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// ErrQRTooLong is returned by PrintQR when the data does not fit in the
+// largest QR code.
+var ErrQRTooLong = errors.New("data too long for QR code")
+
+// Limits of QR code versions.
+const (
+	qrMinVersion = 1
+	qrMaxVersion = 40
+	qrQuietZone  = 4
+)
+
+// qrECCPerBlock holds the number of error correction codewords in each
+// block for error correction level M, indexed by version.
+//
+//nolint:gochecknoglobals // constant table
+var qrECCPerBlock = [qrMaxVersion + 1]int{
+	-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+	26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+}
+
+// qrBlocks holds the number of error correction blocks for error
+// correction level M, indexed by version.
+//
+//nolint:gochecknoglobals // constant table
+var qrBlocks = [qrMaxVersion + 1]int{
+	-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+	17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49,
+}
+
+// PrintQR prints data to Stdout as a QR code, using error correction
+// level M. The code is drawn with half block characters, two rows of
+// modules to a line, if the output is UTF-8, and with pairs of "#"
+// characters otherwise. Without color, light modules are drawn in the
+// foreground color, suiting the light on dark color scheme of most
+// terminals. With color, the colors are set explicitly.
+func (tp *TermPrinter) PrintQR(data string) error {
+	qr, err := encodeQR([]byte(data))
+	if err != nil {
+		return err
+	}
+
+	var (
+		sb    strings.Builder
+		start string
+		end   string
+	)
+
+	if tp.OutColor() {
+		start, end = "\x1b[97;40m", "\x1b[0m"
+	}
+
+	size := qr.size + 2*qrQuietZone
+
+	// light reports whether the module at x, y, counted from the
+	// outside of the quiet zone, is light
+	light := func(x int, y int) bool {
+		return !qr.module(x-qrQuietZone, y-qrQuietZone)
+	}
+
+	if tp.utf8 || localeUTF8() {
+		for y := 0; y < size; y += 2 {
+			sb.WriteString(start)
+
+			for x := 0; x < size; x++ {
+				top, bottom := light(x, y), y+1 < size && light(x, y+1)
+
+				switch {
+				case top && bottom:
+					sb.WriteString("█")
+				case top:
+					sb.WriteString("▀")
+				case bottom:
+					sb.WriteString("▄")
+				default:
+					sb.WriteString(" ")
+				}
+			}
+
+			sb.WriteString(end + "\n")
+		}
+	} else {
+		for y := 0; y < size; y++ {
+			sb.WriteString(start)
+
+			for x := 0; x < size; x++ {
+				if light(x, y) {
+					sb.WriteString("##")
+				} else {
+					sb.WriteString("  ")
+				}
+			}
+
+			sb.WriteString(end + "\n")
+		}
+	}
+
+	_, err = tp.Print(sb.String())
+
+	return err
+}
+
+// localeUTF8 reports whether the locale uses UTF-8, which is assumed on
+// platforms other than Windows if no locale is set.
+func localeUTF8() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			v = strings.ToLower(v)
+
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+
+	return true
+}
+
+// qrCode is an encoded QR code.
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+// module reports whether the module at x, y is dark. Modules outside
+// the code are light.
+func (qr *qrCode) module(x int, y int) bool {
+	return x >= 0 && y >= 0 && x < qr.size && y < qr.size && qr.modules[y][x]
+}
+
+// encodeQR encodes data in byte mode in the smallest QR code which will
+// hold it.
+func encodeQR(data []byte) (*qrCode, error) {
+	ver := qrMinVersion
+
+	for ; ver <= qrMaxVersion; ver++ {
+		if qrBitLength(ver, len(data)) <= qrDataCodewords(ver)*8 {
+			break
+		}
+	}
+
+	if ver > qrMaxVersion {
+		return nil, ErrQRTooLong
+	}
+
+	var bb qrBits
+
+	bb.append(0b0100, 4)
+	bb.append(len(data), qrCountBits(ver))
+
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	// terminator and padding
+	capacity := qrDataCodewords(ver) * 8
+
+	bb.append(0, min(4, capacity-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i/8] |= 1 << (7 - i%8)
+		}
+	}
+
+	qr := newQRCode(ver)
+	qr.drawCodewords(qrAddECC(ver, codewords))
+	qr.applyBestMask()
+
+	return qr, nil
+}
+
+// qrCountBits returns the width of the character count in byte mode.
+func qrCountBits(ver int) int {
+	if ver < 10 {
+		return 8
+	}
+
+	return 16
+}
+
+// qrBitLength returns the number of bits needed to encode n bytes.
+func qrBitLength(ver int, n int) int {
+	return 4 + qrCountBits(ver) + 8*n
+}
+
+// qrRawModules returns the number of modules available for data and
+// error correction in the given version.
+func qrRawModules(ver int) int {
+	n := (16*ver+128)*ver + 64
+
+	if ver >= 2 {
+		align := ver/7 + 2
+		n -= (25*align-10)*align - 55
+
+		if ver >= 7 {
+			n -= 36
+		}
+	}
+
+	return n
+}
+
+// qrDataCodewords returns the number of data codewords in the given
+// version.
+func qrDataCodewords(ver int) int {
+	return qrRawModules(ver)/8 - qrECCPerBlock[ver]*qrBlocks[ver]
+}
+
+// qrAddECC splits data into blocks, appends error correction codewords
+// to each and interleaves the blocks.
+func qrAddECC(ver int, data []byte) []byte {
+	numBlocks := qrBlocks[ver]
+	eccLen := qrECCPerBlock[ver]
+	raw := qrRawModules(ver) / 8
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := qrDivisor(eccLen)
+	blocks := make([][]byte, numBlocks)
+
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortLen - eccLen
+		if i >= numShort {
+			n++
+		}
+
+		dat := data[k : k+n]
+		k += n
+
+		block := make([]byte, 0, shortLen+1)
+		block = append(block, dat...)
+
+		// short blocks are padded so that the codewords of all blocks
+		// line up when interleaved
+		if i < numShort {
+			block = append(block, 0)
+		}
+
+		blocks[i] = append(block, qrRemainder(dat, divisor)...)
+	}
+
+	result := make([]byte, 0, raw)
+
+	for i := 0; i <= shortLen; i++ {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+// qrDivisor returns the Reed-Solomon generator polynomial of the given
+// degree, without its leading term.
+func qrDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMul(result[j], root)
+
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+
+		root = gfMul(root, 0x02)
+	}
+
+	return result
+}
+
+// qrRemainder returns the Reed-Solomon error correction codewords of
+// data.
+func qrRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+
+	for _, b := range data {
+		factor := b ^ result[0]
+
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+
+		for i, d := range divisor {
+			result[i] ^= gfMul(d, factor)
+		}
+	}
+
+	return result
+}
+
+// gfMul multiplies x and y in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x byte, y byte) byte {
+	var z int
+
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>i)&1) * int(x)
+	}
+
+	return byte(z)
+}
+
+// qrBits is a sequence of bits.
+type qrBits []bool
+
+// append appends the low n bits of v, most significant first.
+func (bb *qrBits) append(v int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, (v>>i)&1 != 0)
+	}
+}
+
+// newQRCode returns a QR code of the given version with its function
+// patterns drawn.
+func newQRCode(ver int) *qrCode {
+	size := 4*ver + 17
+
+	qr := &qrCode{
+		size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+
+	for i := range qr.modules {
+		qr.modules[i] = make([]bool, size)
+		qr.function[i] = make([]bool, size)
+	}
+
+	// timing patterns
+	for i := 0; i < size; i++ {
+		qr.setFunction(6, i, i%2 == 0)
+		qr.setFunction(i, 6, i%2 == 0)
+	}
+
+	// finder patterns
+	for _, c := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x < 0 || y < 0 || x >= size || y >= size {
+					continue
+				}
+
+				d := max(abs(dx), abs(dy))
+				qr.setFunction(x, y, d != 2 && d != 4)
+			}
+		}
+	}
+
+	// alignment patterns
+	pos := qrAlignment(ver)
+	last := len(pos) - 1
+
+	for i := range pos {
+		for j := range pos {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					qr.setFunction(pos[i]+dx, pos[j]+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// reserve the format bits, which are drawn once the mask is known
+	qr.drawFormat(0)
+
+	if ver >= 7 {
+		qr.drawVersion(ver)
+	}
+
+	return qr
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
+
+// setFunction sets the module at x, y as part of a function pattern.
+func (qr *qrCode) setFunction(x int, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.function[y][x] = true
+}
+
+// qrAlignment returns the positions of the alignment patterns.
+func qrAlignment(ver int) []int {
+	if ver == 1 {
+		return nil
+	}
+
+	num := ver/7 + 2
+	step := (ver*8 + num*3 + 5) / (num*4 - 4) * 2
+
+	pos := make([]int, num)
+	pos[0] = 6
+
+	for i, p := num-1, 4*ver+10; i >= 1; i, p = i-1, p-step {
+		pos[i] = p
+	}
+
+	return pos
+}
+
+// drawFormat draws the format bits for error correction level M and the
+// given mask.
+func (qr *qrCode) drawFormat(mask int) {
+	data := mask // level M is 0
+	rem := data
+
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		qr.setFunction(8, i, bit(i))
+	}
+
+	qr.setFunction(8, 7, bit(6))
+	qr.setFunction(8, 8, bit(7))
+	qr.setFunction(7, 8, bit(8))
+
+	for i := 9; i < 15; i++ {
+		qr.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		qr.setFunction(qr.size-1-i, 8, bit(i))
+	}
+
+	for i := 8; i < 15; i++ {
+		qr.setFunction(8, qr.size-15+i, bit(i))
+	}
+
+	qr.setFunction(8, qr.size-8, true)
+}
+
+// drawVersion draws the version bits.
+func (qr *qrCode) drawVersion(ver int) {
+	rem := ver
+
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+
+	bits := ver<<12 | rem
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 != 0
+		a, b := qr.size-11+i%3, i/3
+
+		qr.setFunction(a, b, dark)
+		qr.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag pattern.
+func (qr *qrCode) drawCodewords(codewords []byte) {
+	i := 0
+
+	for right := qr.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+
+		for vert := 0; vert < qr.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+
+				y := vert
+				if (right+1)&2 == 0 {
+					y = qr.size - 1 - vert
+				}
+
+				if !qr.function[y][x] && i < len(codewords)*8 {
+					qr.modules[y][x] = (codewords[i>>3]>>(7-i&7))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by the given mask.
+func (qr *qrCode) applyMask(mask int) {
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if qr.function[y][x] {
+				continue
+			}
+
+			var invert bool
+
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			default:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			qr.modules[y][x] = qr.modules[y][x] != invert
+		}
+	}
+}
+
+// applyBestMask applies the mask with the lowest penalty score.
+func (qr *qrCode) applyBestMask() {
+	best, bestScore := 0, -1
+
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormat(mask)
+
+		if s := qr.penalty(); bestScore < 0 || s < bestScore {
+			best, bestScore = mask, s
+		}
+
+		// masks are their own inverse
+		qr.applyMask(mask)
+	}
+
+	qr.applyMask(best)
+	qr.drawFormat(best)
+}
+
+// penalty returns the penalty score of the code, used to choose a mask
+// which avoids patterns that confuse scanners.
+func (qr *qrCode) penalty() int {
+	var score, dark int
+
+	finder := []bool{true, false, true, true, true, false, true}
+
+	for _, line := range qr.lines() {
+		run := 1
+
+		for i := 1; i <= len(line); i++ {
+			if i < len(line) && line[i] == line[i-1] {
+				run++
+
+				continue
+			}
+
+			if run >= 5 {
+				score += run - 2
+			}
+
+			run = 1
+		}
+
+		// finder-like patterns with four light modules on either side
+		for i := 0; i+7 <= len(line); i++ {
+			if !matchBools(line[i:i+7], finder) {
+				continue
+			}
+
+			if lightRun(line, i-4, i) || lightRun(line, i+7, i+11) {
+				score += 40
+			}
+		}
+	}
+
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			if qr.modules[y][x] {
+				dark++
+			}
+
+			if x+1 < qr.size && y+1 < qr.size {
+				c := qr.modules[y][x]
+				if c == qr.modules[y][x+1] && c == qr.modules[y+1][x] && c == qr.modules[y+1][x+1] {
+					score += 3
+				}
+			}
+		}
+	}
+
+	total := qr.size * qr.size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	score += max(k, 0) * 10
+
+	return score
+}
+
+// lines returns the rows and columns of the code.
+func (qr *qrCode) lines() [][]bool {
+	lines := make([][]bool, 0, 2*qr.size)
+
+	for y := 0; y < qr.size; y++ {
+		lines = append(lines, qr.modules[y])
+	}
+
+	for x := 0; x < qr.size; x++ {
+		col := make([]bool, qr.size)
+		for y := range col {
+			col[y] = qr.modules[y][x]
+		}
+
+		lines = append(lines, col)
+	}
+
+	return lines
+}
+
+// matchBools reports whether a and b are equal.
+func matchBools(a []bool, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// lightRun reports whether the modules of line from start to end are
+// light, treating modules beyond the edges as light.
+func lightRun(line []bool, start int, end int) bool {
+	for i := start; i < end; i++ {
+		if i >= 0 && i < len(line) && line[i] {
+			return false
+		}
+	}
+
+	return true
+}
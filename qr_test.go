@@ -0,0 +1,104 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestPrintQR(t *testing.T) {
+	t.Run("UTF8", testPrintQRUTF8)
+	t.Run("ASCII", testPrintQRASCII)
+	t.Run("TooLong", testPrintQRTooLong)
+}
+
+func testPrintQRUTF8(t *testing.T) {
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+
+	outbuf := new(bytes.Buffer)
+
+	tp := cli.NewTermPrinter()
+	tp.SetStdout(outbuf)
+
+	err := tp.PrintQR("hello")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// version 1 is 21 modules, plus the quiet zone on each side
+	lines := strings.Split(strings.TrimSuffix(outbuf.String(), "\n"), "\n")
+	if len(lines) != 15 {
+		t.Fatalf("expected 15 lines, received %d:\n%s", len(lines), outbuf)
+	}
+
+	// the top of the finder patterns
+	if !strings.HasPrefix(lines[2], "████ ▄▄▄▄▄ █") {
+		t.Errorf("unexpected line %q", lines[2])
+	}
+}
+
+func testPrintQRASCII(t *testing.T) {
+	t.Setenv("LC_ALL", "C")
+
+	outbuf := new(bytes.Buffer)
+
+	tp := cli.NewTermPrinter()
+	tp.SetStdout(outbuf)
+
+	err := tp.PrintQR("hello")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(outbuf.String(), "\n"), "\n")
+	if len(lines) != 29 {
+		t.Fatalf("expected 29 lines, received %d:\n%s", len(lines), outbuf)
+	}
+
+	for i, exp := range map[int]string{
+		0: strings.Repeat("##", 29),
+		4: "########" + strings.Repeat("  ", 7) + "##",
+		5: "########  ##########  ##",
+	} {
+		if !strings.HasPrefix(lines[i], exp) {
+			t.Errorf("line %d: expected prefix %q, received %q", i, exp, lines[i])
+		}
+	}
+}
+
+func testPrintQRTooLong(t *testing.T) {
+	tp := cli.NewTermPrinter()
+	tp.SetStdout(new(bytes.Buffer))
+
+	err := tp.PrintQR(strings.Repeat("x", 3000))
+	if !errors.Is(err, cli.ErrQRTooLong) {
+		t.Error("expected ErrQRTooLong, received", err)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package cli
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// makeRaw puts the terminal open on fd into raw mode, returning a
+// function which restores the previous state.
+func makeRaw(fd uintptr) (func(), error) {
+	return setTermios(fd, func(t *unix.Termios) {
+		t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP |
+			unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+		t.Oflag &^= unix.OPOST
+		t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+		t.Cflag &^= unix.CSIZE | unix.PARENB
+		t.Cflag |= unix.CS8
+	})
+}
+
+// makeCbreak puts the terminal open on fd into cbreak mode, where keys
+// are read as they are pressed without echo, while output processing
+// and signal keys such as Ctrl-C work as normal. It returns a function
+// which restores the previous state.
+func makeCbreak(fd uintptr) (func(), error) {
+	return setTermios(fd, func(t *unix.Termios) {
+		t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON
+	})
+}
+
+// setTermios changes the attributes of the terminal open on fd with
+// fn, reading a byte at a time, and returns a function which restores
+// the previous state.
+func setTermios(fd uintptr, fn func(t *unix.Termios)) (func(), error) {
+	old, err := unix.IoctlGetTermios(int(fd), ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	t := *old
+	fn(&t)
+	t.Cc[unix.VMIN] = 1
+	t.Cc[unix.VTIME] = 0
+
+	err = unix.IoctlSetTermios(int(fd), ioctlSetTermios, &t)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = unix.IoctlSetTermios(int(fd), ioctlSetTermios, old)
+	}, nil
+}
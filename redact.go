@@ -0,0 +1,112 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// redactedText replaces secrets in output.
+const redactedText = "********"
+
+// redactState holds the secrets masked by a TermPrinter.
+type redactState struct {
+	m       sync.RWMutex
+	values  map[string]bool
+	replace *strings.Replacer
+	enabled atomic.Bool
+}
+
+// RedactSecrets registers values which are replaced with asterisks
+// wherever they appear in output written by the Print, Eprint and
+// Lprintf functions, before it reaches the terminal, output hooks or
+// log files. Empty values are ignored.
+//
+// Values read by ReadPassword from a LineReader created with the
+// NewLineReader method of a Cmd, and secrets returned by Secret, are
+// registered automatically.
+func (tp *TermPrinter) RedactSecrets(values ...string) {
+	rs := &tp.redact
+
+	rs.m.Lock()
+	defer rs.m.Unlock()
+
+	if rs.values == nil {
+		rs.values = make(map[string]bool)
+	}
+
+	for _, v := range values {
+		if v != "" {
+			rs.values[v] = true
+		}
+	}
+
+	if len(rs.values) == 0 {
+		return
+	}
+
+	// longer values are replaced first, so a secret containing another
+	// is masked entirely
+	sorted := make([]string, 0, len(rs.values))
+
+	for v := range rs.values {
+		sorted = append(sorted, v)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+
+		return sorted[i] < sorted[j]
+	})
+
+	pairs := make([]string, 0, 2*len(sorted))
+
+	for _, v := range sorted {
+		pairs = append(pairs, v, redactedText)
+	}
+
+	rs.replace = strings.NewReplacer(pairs...)
+	rs.enabled.Store(true)
+}
+
+// redactSecrets masks the registered secrets in s.
+func (tp *TermPrinter) redactSecrets(s string) string {
+	if !tp.redact.enabled.Load() {
+		return s
+	}
+
+	tp.redact.m.RLock()
+	r := tp.redact.replace
+	tp.redact.m.RUnlock()
+
+	if r == nil {
+		return s
+	}
+
+	return r.Replace(s)
+}
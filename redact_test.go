@@ -0,0 +1,65 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	outbuf := new(bytes.Buffer)
+	errbuf := new(bytes.Buffer)
+	hookbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+	p.SetStderr(errbuf)
+	p.AddOutputHook(func(_ cli.Stream, b []byte) { hookbuf.Write(b) })
+
+	p.RedactSecrets("hunter2", "", "hunter2-long")
+
+	p.Printf("password=%s\n", "hunter2")
+	p.Lprintf("using hunter2-long\n")
+	p.Eprintln("failed with hunter2")
+
+	if outbuf.String() != "password=********\nusing ********\n" {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+
+	if errbuf.String() != "failed with ********\n" {
+		t.Errorf("unexpected error output %q", errbuf)
+	}
+
+	if bytes.Contains(hookbuf.Bytes(), []byte("hunter2")) {
+		t.Errorf("secret passed to output hook: %q", hookbuf)
+	}
+
+	if s := p.Sprint("hunter2"); s != "********" {
+		t.Errorf("unexpected Sprint %q", s)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// defaultReloadWindow is the debounce window used if SetReloadWindow
+// has not been called.
+const defaultReloadWindow = 500 * time.Millisecond
+
+// reloader holds the state of the reload pipeline of an ExitHandler.
+type reloader struct {
+	initOnce  sync.Once
+	startOnce sync.Once
+
+	m     sync.Mutex
+	fn    func() error
+	errFn func(error)
+
+	trigger chan bool
+	sc      chan os.Signal
+	active  atomic.Bool
+}
+
+// SetReloadWindow sets the debounce window for reloads. Reload requests
+// received within the window are coalesced into a single call. A zero
+// or negative value restores the default of 500ms.
+func (e *ExitHandler) SetReloadWindow(d time.Duration) {
+	atomic.StoreInt64(&e.window, int64(d))
+}
+
+// OnReload sets fn to be called when SIGHUP is received or Reload is
+// called. Requests are debounced, multiple requests within the reload
+// window result in a single call to fn, and calls to fn never run
+// concurrently. Requests received while fn is running result in one
+// more call after fn returns.
+//
+// If fn returns an error, it is passed to errFn. If errFn is nil, the
+// error is printed to os.Stderr. A failed reload never triggers Exit.
+//
+// Calling OnReload again replaces fn and errFn. Reloads stop when the
+// exit channel closes. Since SIGHUP is used to trigger reloads, it no
+// longer triggers Exit if it was passed to Watch.
+func (e *ExitHandler) OnReload(fn func() error, errFn func(error)) {
+	e.rl.init()
+
+	e.rl.m.Lock()
+	e.rl.fn = fn
+	e.rl.errFn = errFn
+	e.rl.m.Unlock()
+
+	e.rl.startOnce.Do(func() {
+		e.watchM.Lock()
+		e.rl.active.Store(true)
+		e.notifyWatched()
+		e.watchM.Unlock()
+
+		signal.Notify(e.rl.sc, syscall.SIGHUP)
+
+		e.Add(1)
+
+		go e.reloadLoop()
+	})
+}
+
+// Reload requests a reload as if SIGHUP had been received. Reload does
+// nothing if OnReload has not been called.
+func (e *ExitHandler) Reload() {
+	e.rl.init()
+
+	select {
+	case e.rl.trigger <- true:
+	default:
+	}
+}
+
+// init creates the trigger channels.
+func (r *reloader) init() {
+	r.initOnce.Do(func() {
+		r.trigger = make(chan bool, 1)
+		r.sc = make(chan os.Signal, 1)
+	})
+}
+
+// reloadLoop receives reload requests and calls the reload function
+// after the debounce window has elapsed.
+func (e *ExitHandler) reloadLoop() {
+	defer e.Done()
+	defer signal.Stop(e.rl.sc)
+
+	var (
+		timer Timer
+		fire  <-chan time.Time
+	)
+
+	for {
+		select {
+		case <-e.rl.sc:
+		case <-e.rl.trigger:
+		case <-fire:
+			fire = nil
+
+			e.runReload()
+
+			continue
+		case <-e.C:
+			if timer != nil {
+				timer.Stop()
+			}
+
+			return
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		w := time.Duration(atomic.LoadInt64(&e.window))
+		if w <= 0 {
+			w = defaultReloadWindow
+		}
+
+		timer = e.clock().NewTimer(w)
+		fire = timer.C()
+	}
+}
+
+// runReload calls the current reload function and reports any error.
+func (e *ExitHandler) runReload() {
+	e.rl.m.Lock()
+	fn := e.rl.fn
+	errFn := e.rl.errFn
+	e.rl.m.Unlock()
+
+	err := fn()
+	if err == nil {
+		return
+	}
+
+	if errFn != nil {
+		errFn(err)
+	} else {
+		fmt.Fprintln(os.Stderr, "reload failed:", err)
+	}
+}
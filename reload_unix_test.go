@@ -0,0 +1,147 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build unix
+
+package cli_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestReload(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	eh.SetReloadWindow(50 * time.Millisecond)
+
+	var count int64
+
+	errs := make(chan error, 1)
+
+	eh.OnReload(func() error {
+		if atomic.AddInt64(&count, 1) == 2 {
+			return errTest
+		}
+
+		return nil
+	}, func(err error) {
+		errs <- err
+	})
+
+	eh.Reload()
+	eh.Reload()
+	eh.Reload()
+
+	time.Sleep(150 * time.Millisecond)
+
+	if n := atomic.LoadInt64(&count); n != 1 {
+		t.Error("expected 1 reload, got", n)
+	}
+
+	err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	select {
+	case err = <-errs:
+		if !errors.Is(err, errTest) {
+			t.Error("unexpected error:", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("timed out waiting for reload")
+	}
+
+	eh.Exit(nil)
+
+	err = eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if n := atomic.LoadInt64(&count); n != 2 {
+		t.Error("expected 2 reloads, got", n)
+	}
+}
+
+func TestReloadWatchedSignal(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	eh.SetReloadWindow(time.Millisecond)
+	eh.Watch(syscall.SIGHUP, syscall.SIGTERM)
+
+	reloads := make(chan bool, 1)
+
+	eh.OnReload(func() error {
+		reloads <- true
+
+		return nil
+	}, nil)
+
+	err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	select {
+	case <-reloads:
+	case <-eh.C:
+		t.Fatal("SIGHUP closed the exit channel")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	eh.Watch(syscall.SIGHUP, syscall.SIGUSR1)
+
+	err = syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	select {
+	case <-reloads:
+	case <-eh.C:
+		t.Fatal("SIGHUP closed the exit channel")
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for reload")
+	}
+
+	err = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	select {
+	case <-eh.C:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for exit")
+	}
+
+	err = eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+)
+
+// RunREPL runs an interactive loop reading lines from Stdin with line
+// editing and history. If the first word of a line names a subcommand
+// added with AddCommand, the line is dispatched to the subcommand,
+// otherwise the line is passed to eval. A nil eval reports unknown
+// commands as errors. Errors are printed to Stderr and the loop
+// continues.
+//
+// The flags of subcommands are returned to their defaults before each
+// line is dispatched, so values given on one line do not carry over to
+// the next.
+//
+// Ctrl-C cancels the current line. RunREPL returns nil when Ctrl-D is
+// pressed on an empty line, the input ends or the exit channel closes.
+// Subcommand names are offered for tab completion.
+func (c *Cmd) RunREPL(prompt string, eval func(line string) error) error {
+	r := c.NewLineReader()
+	r.SetExitOnInterrupt(false)
+	r.SetCompleter(c.completeCommand)
+
+	for {
+		line, err := r.ReadLine(prompt)
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case errors.Is(err, ErrInterrupted):
+			if c.exiting() {
+				return nil
+			}
+
+			continue
+		case err != nil:
+			return err
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		err = c.evalLine(line, eval)
+		if err != nil {
+			c.Eprintln(err)
+		}
+	}
+}
+
+// evalLine dispatches line to a subcommand or eval.
+func (c *Cmd) evalLine(line string, eval func(line string) error) error {
+	args, err := SplitArgs(line)
+	if err != nil {
+		return err
+	}
+
+	if cmd, ok := c.commands[args[0]]; ok || eval == nil {
+		if ok {
+			cmd.resetFlags()
+		}
+
+		return c.Dispatch(args)
+	}
+
+	return eval(line)
+}
+
+// resetFlags returns the flags of cmd and its subcommands to their
+// defaults.
+func (cmd *Command) resetFlags() {
+	for _, fs := range []*flag.FlagSet{cmd.FlagSet, cmd.PersistentFlags} {
+		fs.VisitAll(func(f *flag.Flag) {
+			_ = f.Value.Set(f.DefValue)
+		})
+	}
+
+	for _, sub := range cmd.commands {
+		sub.resetFlags()
+	}
+}
+
+// completeCommand completes subcommand names.
+func (c *Cmd) completeCommand(partial string) []string {
+	var cands []string
+
+	for _, cmd := range c.Commands() {
+		if strings.HasPrefix(cmd.Name, partial) {
+			cands = append(cands, cmd.Name)
+		}
+	}
+
+	return cands
+}
+
+// exiting reports whether the exit channel has closed.
+func (c *Cmd) exiting() bool {
+	select {
+	case <-c.C:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestREPL(t *testing.T) {
+	cons := newTestConsole(t)
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdin(cons.Tty())
+	cmd.SetStdout(cons.Tty())
+	cmd.SetStderr(errbuf)
+
+	greet := cmd.AddCommand("greet", "print a greeting", nil)
+	name := greet.FlagSet.String("name", "world", "name to greet")
+	greet.Run = func([]string) error {
+		cmd.Println("hello", *name)
+
+		return nil
+	}
+
+	var evals []string
+
+	done := make(chan error)
+
+	go func() {
+		done <- cmd.RunREPL("> ", func(line string) error {
+			evals = append(evals, line)
+
+			return errTest
+		})
+	}()
+
+	// each input is sent once the prompt following the previous input
+	// has been drawn, so the terminal is in raw mode
+	steps := []struct {
+		wait string
+		send string
+	}{
+		{"> ", "gr\t-name bob\r"},
+		{"hello bob\r\n\r> ", "greet\r"},
+		{"hello world\r\n\r> ", "other thing\r"},
+		{"other thing\x1b[K\r\n\r> ", "discarded\x03"},
+		{"^C\r\n\r> ", "'bad\r"},
+		{"'bad\x1b[K\r\n\r> ", "\x04"},
+	}
+
+	for _, step := range steps {
+		_, err := cons.ExpectString(step.wait)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		_, err = cons.Send(step.send)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	err := <-done
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(evals) != 1 || evals[0] != "other thing" {
+		t.Error("unexpected evals", evals)
+	}
+
+	if errbuf.String() != "testing error\nunterminated quote\n" {
+		t.Error("unexpected output", errbuf.String())
+	}
+}
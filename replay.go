@@ -0,0 +1,195 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTranscript is returned when a transcript cannot be parsed.
+var ErrTranscript = errors.New("invalid transcript")
+
+// ReplayTranscript writes the output recorded in a transcript created by
+// RecordTranscript to os.Stdout, reproducing the original timing. The
+// format is detected from the content. The timing is scaled by speed,
+// so 2 replays twice as fast, while a zero or negative speed writes the
+// output without delay. Recorded input is not replayed, since its echo
+// is part of the output.
+func ReplayTranscript(r io.Reader, speed float64) error {
+	return replayTranscript(os.Stdout, r, speed, realClock{}, nil)
+}
+
+// ReplayTranscript operates in the manner of the ReplayTranscript
+// function, writing to the Stdout of the Cmd using the Clock of its
+// ExitHandler. Replay stops with ErrInterrupted if the exit channel
+// closes.
+func (c *Cmd) ReplayTranscript(r io.Reader, speed float64) error {
+	c.Add(1)
+	defer c.Done()
+
+	return replayTranscript(c.out, r, speed, c.clock(), c.C)
+}
+
+// replayTranscript implements ReplayTranscript.
+func replayTranscript(w io.Writer, r io.Reader, speed float64, clk Clock, abort <-chan bool) error {
+	br := bufio.NewReader(r)
+
+	first, err := br.Peek(1)
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	parse := parseTextEvent
+	if first[0] == '{' {
+		parse = parseAsciinemaEvent
+
+		// the header holds nothing needed for replay
+		_, err = br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	start := clk.Now()
+
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if line != "" {
+			at, data, perr := parse(strings.TrimSuffix(line, "\n"))
+			if perr != nil {
+				return fmt.Errorf("%w: line %d: %w", ErrTranscript, n, perr)
+			}
+
+			if data != "" {
+				if speed > 0 && !replayWait(clk, start, at, speed, abort) {
+					return ErrInterrupted
+				}
+
+				_, werr := io.WriteString(w, data)
+				if werr != nil {
+					return werr
+				}
+			}
+		}
+
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// replayWait waits until the event at offset at, scaled by speed, is
+// due, returning false if abort closes first.
+func replayWait(clk Clock, start time.Time, at time.Duration, speed float64, abort <-chan bool) bool {
+	d := time.Duration(float64(at)/speed) - clk.Now().Sub(start)
+	if d <= 0 {
+		return true
+	}
+
+	t := clk.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C():
+		return true
+	case <-abort:
+		return false
+	}
+}
+
+// parseAsciinemaEvent parses an event line of an asciinema transcript,
+// returning its offset and its data if it is output.
+func parseAsciinemaEvent(line string) (time.Duration, string, error) {
+	var ev []interface{}
+
+	err := json.Unmarshal([]byte(line), &ev)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if len(ev) != 3 {
+		return 0, "", fmt.Errorf("expected 3 fields, found %d", len(ev)) //nolint:err113 // wrapped by caller
+	}
+
+	secs, ok := ev[0].(float64)
+	typ, _ := ev[1].(string)
+	data, _ := ev[2].(string)
+
+	if !ok {
+		return 0, "", fmt.Errorf("invalid time %v", ev[0]) //nolint:err113 // wrapped by caller
+	}
+
+	if typ != "o" {
+		data = ""
+	}
+
+	return time.Duration(secs * float64(time.Second)), data, nil
+}
+
+// parseTextEvent parses a line of a text transcript, returning its
+// offset and its text with a newline if it is output.
+func parseTextEvent(line string) (time.Duration, string, error) {
+	stamp, rest, ok := strings.Cut(strings.TrimLeft(line, " "), " ")
+	if !ok || len(rest) < 3 {
+		return 0, "", fmt.Errorf("expected time, stream and text") //nolint:err113 // wrapped by caller
+	}
+
+	secs, err := strconv.ParseFloat(stamp, 64)
+	if err != nil {
+		return 0, "", err
+	}
+
+	tag := strings.TrimSpace(rest[:3])
+
+	text := ""
+	if len(rest) > 4 {
+		text = rest[4:]
+	}
+
+	at := time.Duration(secs * float64(time.Second))
+
+	switch tag {
+	case "out", "err":
+		return at, text + "\n", nil
+	case "in":
+		return at, "", nil
+	default:
+		return 0, "", fmt.Errorf("unknown stream %q", tag) //nolint:err113 // wrapped by caller
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
+)
+
+func TestReplayTranscriptText(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	tr := "    0.000 out hello\n" +
+		"    0.100 in  yes\n" +
+		"    0.200 err  indented\n" +
+		"    0.300 out \n"
+
+	err := cmd.ReplayTranscript(strings.NewReader(tr), 0)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if outbuf.String() != "hello\n indented\n\n" {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+
+	err = cmd.ReplayTranscript(strings.NewReader("0.000 bad line\n"), 0)
+	if !errors.Is(err, cli.ErrTranscript) {
+		t.Error("expected ErrTranscript, received", err)
+	}
+}
+
+func TestReplayTranscriptAsciinema(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetClock(clk)
+
+	cast := `{"version": 2, "width": 80, "height": 24}` + "\n" +
+		`[0.5, "o", "one\r\n"]` + "\n" +
+		`[0.7, "i", "x"]` + "\n" +
+		`[1.0, "o", "\u001b[1Atwo\r\n"]` + "\n"
+
+	done := make(chan error)
+
+	go func() {
+		done <- cmd.ReplayTranscript(strings.NewReader(cast), 2)
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(250 * time.Millisecond)
+	clk.BlockUntil(1)
+
+	clk.Advance(250 * time.Millisecond)
+
+	err := <-done
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if outbuf.String() != "one\r\n\x1b[1Atwo\r\n" {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+}
+
+func TestReplayTranscriptInterrupted(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+
+	err := cmd.ReplayTranscript(strings.NewReader("    5.000 out late\n"), 1)
+	if !errors.Is(err, cli.ErrInterrupted) {
+		t.Error("expected ErrInterrupted, received", err)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrRequiredFlag is returned by Parse when a required flag is not set.
+var ErrRequiredFlag = errors.New("missing required flag")
+
+// Option configures a Cmd created by NewCmd.
+type Option func(*Cmd)
+
+// WithInteractiveFlagPrompts makes Parse prompt for the values of
+// missing required flags when Stdin is a terminal, rather than
+// returning an error. Flags marked secret are read without echo.
+func WithInteractiveFlagPrompts() Option {
+	return func(c *Cmd) {
+		c.promptFlags = true
+	}
+}
+
+// MarkRequired marks the named flags of the FlagSet as required, so
+// Parse returns an error if they are not set.
+func (c *Cmd) MarkRequired(names ...string) {
+	if c.required == nil {
+		c.required = make(map[string]bool)
+	}
+
+	for _, n := range names {
+		c.required[n] = true
+	}
+}
+
+// MarkSecret marks the named flags of the FlagSet as secret, so their
+// values are read without echo when prompted for interactively.
+func (c *Cmd) MarkSecret(names ...string) {
+	if c.secret == nil {
+		c.secret = make(map[string]bool)
+	}
+
+	for _, n := range names {
+		c.secret[n] = true
+	}
+}
+
+// Parse parses args with the FlagSet, then checks that all required
+// flags have been set and that flags with a list of valid values are
+// set to one of them. If interactive prompts are enabled and Stdin is a
+// terminal, the user is prompted for each missing value. Argument files
+// are expanded first if the Cmd was created WithArgFiles, and flags not
+// given on the command line are read from the config file if the Cmd
+// was created WithConfigFile.
+//
+// Unlike calling Parse on the FlagSet directly, combined single letter
+// boolean flags are accepted, so "-abc" is equivalent to "-a -b -c". As
+// with the FlagSet, "--" ends flag parsing and is removed from the
+// remaining arguments.
+func (c *Cmd) Parse(args []string) error {
+	if c.argFiles {
+		var err error
+
+		args, err = ExpandArgFiles(args)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.parseFlags(c.FlagSet, args, func(name string) bool {
+		_, ok := c.commands[name]
+
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.useConfig {
+		err = c.LoadConfig()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.strictEnv()
+	if err != nil {
+		return err
+	}
+
+	err = c.validateFlags()
+	if err != nil {
+		return err
+	}
+
+	missing := c.missingFlags()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var r *LineReader
+
+	if c.promptFlags {
+		r = c.NewLineReader()
+		r.SetExitOnInterrupt(false)
+	}
+
+	if r == nil || !r.term {
+		return fmt.Errorf("%w: -%s", ErrRequiredFlag, missing[0])
+	}
+
+	for _, name := range missing {
+		err = c.promptFlag(r, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// missingFlags returns the names of the required flags which are not
+// set, in sorted order.
+func (c *Cmd) missingFlags() []string {
+	set := make(map[string]bool)
+
+	c.FlagSet.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var missing []string
+
+	for name := range c.required {
+		if !set[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// promptFlag reads the value of the named flag from r, repeating the
+// prompt until a valid value is given.
+func (c *Cmd) promptFlag(r *LineReader, name string) error {
+	f := c.FlagSet.Lookup(name)
+	if f == nil {
+		return fmt.Errorf("%w: -%s", ErrRequiredFlag, name)
+	}
+
+	prompt := fmt.Sprintf("%s (%s): ", name, f.Usage)
+
+	r.SetCompleter(func(partial string) []string {
+		return c.CompleteFlag(name, partial)
+	})
+	defer r.SetCompleter(nil)
+
+	for {
+		var (
+			v   string
+			err error
+		)
+
+		if c.secret[name] {
+			v, err = r.ReadPassword(prompt)
+		} else {
+			v, err = r.ReadLine(prompt)
+			v = strings.TrimSpace(v)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if v == "" {
+			continue
+		}
+
+		err = c.validateFlag(name, v)
+		if err != nil {
+			c.Eprintln(err)
+
+			continue
+		}
+
+		err = c.FlagSet.Set(name, v)
+		if err == nil {
+			return nil
+		}
+
+		c.Eprintln(err)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestParseRequired(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdin(strings.NewReader(""))
+
+	name := cmd.FlagSet.String("name", "", "user name")
+	cmd.MarkRequired("name")
+
+	err := cmd.Parse(nil)
+	if !errors.Is(err, cli.ErrRequiredFlag) {
+		t.Errorf("expected ErrRequiredFlag, received %v", err)
+	}
+
+	err = cmd.Parse([]string{"-name", "bob"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *name != "bob" {
+		t.Errorf("expected bob, received %q", *name)
+	}
+}
+
+func TestParseRequiredNotInteractive(t *testing.T) {
+	cmd := cli.NewCmd(cli.WithInteractiveFlagPrompts())
+	cmd.SetStdin(strings.NewReader("bob\n"))
+
+	cmd.FlagSet.String("name", "", "user name")
+	cmd.MarkRequired("name")
+
+	err := cmd.Parse(nil)
+	if !errors.Is(err, cli.ErrRequiredFlag) {
+		t.Errorf("expected ErrRequiredFlag, received %v", err)
+	}
+}
+
+func TestParseRequiredPrompt(t *testing.T) {
+	cons := newTestConsole(t)
+
+	cmd := cli.NewCmd(cli.WithInteractiveFlagPrompts())
+	cmd.SetStdin(cons.Tty())
+	cmd.SetStdout(cons.Tty())
+
+	name := cmd.FlagSet.String("name", "", "user name")
+	pass := cmd.FlagSet.String("pass", "", "password")
+	cmd.MarkRequired("name", "pass")
+	cmd.MarkSecret("pass")
+
+	errc := make(chan error, 1)
+
+	go func() {
+		errc <- cmd.Parse(nil)
+	}()
+
+	expectSend(t, cons, "name (user name): ", "bob\r")
+	expectSend(t, cons, "pass (password): ", "s3cr\x7fret\r")
+
+	_, err := cons.ExpectString("****\b \b***\r\n")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = <-errc
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if *name != "bob" {
+		t.Errorf("expected bob, received %q", *name)
+	}
+
+	if *pass != "s3cret" {
+		t.Errorf("expected s3cret, received %q", *pass)
+	}
+}
+
+// expectSend waits for s on cons, then sends in.
+func expectSend(t *testing.T, cons *expect.Console, s, in string) {
+	t.Helper()
+
+	_, err := cons.ExpectString(s)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	_, err = cons.Send(in)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+)
+
+// ErrInvalidLimit is returned by SetNice, LimitCPU and LimitMemory when
+// the requested value is out of range.
+var ErrInvalidLimit = errors.New("invalid resource limit")
+
+// SetNice sets the scheduling priority of the process, where n ranges
+// from -20 (most favorable) to 19 (least favorable). Raising the
+// priority above its current value usually requires privileges. On
+// platforms without process priorities SetNice returns ErrNotSupported.
+func (c *Cmd) SetNice(n int) error {
+	if n < -20 || n > 19 {
+		return fmt.Errorf("%w: nice value %d out of range -20 to 19", ErrInvalidLimit, n)
+	}
+
+	err := setNice(n)
+	if err != nil {
+		return fmt.Errorf("set nice value: %w", err)
+	}
+
+	return nil
+}
+
+// LimitCPU limits the process to roughly percent of the CPUs of the
+// machine by reducing GOMAXPROCS, leaving at least one CPU in use. The
+// limit is best-effort: it bounds how many goroutines run in parallel
+// rather than how much time each spends on a CPU.
+func (c *Cmd) LimitCPU(percent int) error {
+	if percent < 1 || percent > 100 {
+		return fmt.Errorf("%w: cpu percent %d out of range 1 to 100", ErrInvalidLimit, percent)
+	}
+
+	runtime.GOMAXPROCS(max(1, runtime.NumCPU()*percent/100))
+
+	return nil
+}
+
+// LimitMemory limits the memory used by the process to bytes. The Go
+// runtime is given a soft limit, as with the GOMEMLIMIT environment
+// variable, which is also set so that Go programs run by the Cmd
+// inherit it. Where available, the data segment resource limit is
+// lowered to bytes as well, so that allocations beyond it fail. If the
+// resource limit cannot be set, the soft limit remains in effect and
+// the error is returned.
+func (c *Cmd) LimitMemory(bytes int64) error {
+	if bytes <= 0 {
+		return fmt.Errorf("%w: memory limit %d is not positive", ErrInvalidLimit, bytes)
+	}
+
+	debug.SetMemoryLimit(bytes)
+
+	err := os.Setenv("GOMEMLIMIT", strconv.FormatInt(bytes, 10))
+	if err != nil {
+		return fmt.Errorf("set GOMEMLIMIT: %w", err)
+	}
+
+	err = limitData(bytes)
+	if err != nil && !errors.Is(err, ErrNotSupported) {
+		return fmt.Errorf("set memory resource limit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestLimitCPU(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	cmd := cli.NewCmd()
+
+	err := cmd.LimitCPU(0)
+	if !errors.Is(err, cli.ErrInvalidLimit) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.LimitCPU(1)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if n := runtime.GOMAXPROCS(0); n != max(1, runtime.NumCPU()/100) {
+		t.Error("unexpected GOMAXPROCS:", n)
+	}
+
+	err = cmd.LimitCPU(100)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if n := runtime.GOMAXPROCS(0); n != runtime.NumCPU() {
+		t.Error("unexpected GOMAXPROCS:", n)
+	}
+}
+
+func TestLimitMemory(t *testing.T) {
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(-1))
+	t.Setenv("GOMEMLIMIT", "")
+
+	cmd := cli.NewCmd()
+
+	err := cmd.LimitMemory(0)
+	if !errors.Is(err, cli.ErrInvalidLimit) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cmd.LimitMemory(1 << 50)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if n := debug.SetMemoryLimit(-1); n != 1<<50 {
+		t.Error("unexpected memory limit:", n)
+	}
+
+	if v := os.Getenv("GOMEMLIMIT"); v != "1125899906842624" {
+		t.Error("unexpected GOMEMLIMIT:", v)
+	}
+}
+
+func TestSetNice(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	if os.Getenv("CLI_TEST_NICE") != "" {
+		// lowering the priority never requires privileges
+		err := cmd.SetNice(19)
+		if err != nil {
+			cmd.Eprintln(err)
+			os.Exit(2)
+		}
+
+		return
+	}
+
+	err := cmd.SetNice(20)
+	if !errors.Is(err, cli.ErrInvalidLimit) {
+		t.Error("unexpected error:", err)
+	}
+
+	if runtime.GOOS == "windows" {
+		err = cmd.SetNice(0)
+		if !errors.Is(err, cli.ErrNotSupported) {
+			t.Error("unexpected error:", err)
+		}
+
+		return
+	}
+
+	// run in a separate process so the tests keep their priority
+	proc := exec.Command(os.Args[0], "-test.run=^TestSetNice$")
+	proc.Env = append(os.Environ(), "CLI_TEST_NICE=1")
+
+	out, err := proc.CombinedOutput()
+	if err != nil {
+		t.Errorf("unexpected error: %v\n%s", err, out)
+	}
+}
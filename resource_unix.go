@@ -0,0 +1,62 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build unix
+
+package cli
+
+import "golang.org/x/sys/unix"
+
+// setNice sets the scheduling priority of the process.
+func setNice(n int) error {
+	return unix.Setpriority(unix.PRIO_PROCESS, 0, n)
+}
+
+// limitData lowers the soft data segment limit of the process to
+// bytes, leaving it unchanged if it is already lower.
+func limitData(bytes int64) error {
+	var rl unix.Rlimit
+
+	err := unix.Getrlimit(unix.RLIMIT_DATA, &rl)
+	if err != nil {
+		return err
+	}
+
+	if !lowerLimit(&rl.Cur, bytes) {
+		return nil
+	}
+
+	return unix.Setrlimit(unix.RLIMIT_DATA, &rl)
+}
+
+// lowerLimit sets limit to n if n is lower, reporting whether it was
+// changed. Rlimit fields are unsigned on most platforms but signed on
+// some, such as FreeBSD.
+func lowerLimit[T int64 | uint64](limit *T, n int64) bool {
+	if uint64(n) >= uint64(*limit) {
+		return false
+	}
+
+	*limit = T(n)
+
+	return true
+}
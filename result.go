@@ -0,0 +1,118 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// ResultFDEnv is the environment variable holding the file descriptor,
+// or handle on Windows, of the result channel used by ResultWriter. A
+// parent process passes an extra pipe to the child, such as fd 3 with
+// exec.Cmd.ExtraFiles, and sets ResultFDEnv=3.
+const ResultFDEnv = "CLI_RESULT_FD"
+
+// resultChannel holds the result channel of a Cmd.
+type resultChannel struct {
+	once sync.Once
+	f    *os.File
+	m    sync.Mutex
+}
+
+// ResultWriter returns the writer for machine-readable results, which
+// the process which started the program passes as described by
+// ResultFDEnv, so that results can be read reliably while the terminal
+// keeps the human readable output. If no result channel was passed, or
+// it cannot be used, writes to the returned writer are discarded. The
+// channel is closed when the Cmd exits.
+func (c *Cmd) ResultWriter() io.Writer {
+	rc := &c.results
+
+	rc.once.Do(func() {
+		rc.f = openResultFD(os.Getenv(ResultFDEnv))
+		if rc.f == nil {
+			return
+		}
+
+		f := rc.f
+
+		c.OnExit(func(error) {
+			rc.m.Lock()
+			defer rc.m.Unlock()
+
+			_ = f.Close()
+		})
+	})
+
+	if rc.f == nil {
+		return io.Discard
+	}
+
+	return rc.f
+}
+
+// HasResultChannel reports whether a result channel was passed to the
+// program, so that writes to ResultWriter are not discarded.
+func (c *Cmd) HasResultChannel() bool {
+	return c.ResultWriter() != io.Discard
+}
+
+// WriteResult writes v to the result channel as a single line of JSON.
+// WriteResult does nothing if there is no result channel.
+func (c *Cmd) WriteResult(v interface{}) error {
+	w := c.ResultWriter()
+	if w == io.Discard {
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.results.m.Lock()
+	defer c.results.m.Unlock()
+
+	_, err = w.Write(append(b, '\n'))
+
+	return err
+}
+
+// openResultFD returns the file for the descriptor named by v, or nil
+// if it is not set or not open. Standard input and output are refused.
+func openResultFD(v string) *os.File {
+	if v == "" {
+		return nil
+	}
+
+	fd, err := strconv.ParseUint(v, 10, 0)
+	if err != nil || fd <= 2 || !validFD(uintptr(fd)) {
+		return nil
+	}
+
+	return os.NewFile(uintptr(fd), "result")
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestResultWriter(t *testing.T) {
+	if os.Getenv("CLI_TEST_RESULT") != "" {
+		cmd := cli.NewCmd()
+		cmd.Println("human output")
+
+		if !cmd.HasResultChannel() {
+			os.Exit(4)
+		}
+
+		_ = cmd.WriteResult(map[string]int{"count": 2})
+
+		cmd.Add(1)
+		cmd.Exit(nil)
+		cmd.Done()
+		_ = cmd.Wait()
+
+		return
+	}
+
+	if runtime.GOOS == "windows" {
+		t.Skip("extra files are not supported on windows")
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	outbuf := new(bytes.Buffer)
+
+	proc := exec.Command(os.Args[0], "-test.run=^TestResultWriter$")
+	proc.Env = append(os.Environ(), "CLI_TEST_RESULT=1", cli.ResultFDEnv+"=3")
+	proc.Stdout = outbuf
+	proc.ExtraFiles = []*os.File{w}
+
+	err = proc.Start()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	w.Close()
+
+	b, _ := io.ReadAll(r)
+
+	err = proc.Wait()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if string(b) != `{"count":2}`+"\n" {
+		t.Errorf("unexpected result %q", b)
+	}
+
+	if !bytes.HasPrefix(outbuf.Bytes(), []byte("human output\n")) {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+}
+
+func TestResultWriterMissing(t *testing.T) {
+	t.Setenv(cli.ResultFDEnv, "")
+
+	cmd := cli.NewCmd()
+
+	if cmd.HasResultChannel() || cmd.ResultWriter() != io.Discard {
+		t.Error("expected no result channel")
+	}
+
+	err := cmd.WriteResult("ignored")
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// ErrRetryAborted is returned by Retry when the exit channel closes
+// before fn succeeds.
+var ErrRetryAborted = errors.New("retry aborted")
+
+// RetryPolicy configures the behavior of Retry.
+type RetryPolicy struct {
+	// Attempts is the maximum number of calls to fn. A zero or
+	// negative value retries until fn succeeds or Exit is called.
+	Attempts int
+
+	// Delay is the wait before the first retry. Defaults to one second.
+	Delay time.Duration
+
+	// MaxDelay caps the wait between retries. A zero value is no limit.
+	MaxDelay time.Duration
+
+	// Multiplier is applied to the delay after each retry. Values less
+	// than 1 default to 2.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to the given fraction, in the
+	// range 0 to 1.
+	Jitter float64
+
+	// Printer, if not nil, receives a status line on Stderr after each
+	// failed attempt.
+	Printer *TermPrinter
+
+	// Metrics, if not nil, counts the attempts and failures using
+	// metric names beginning with Name.
+	Metrics *Metrics
+
+	// Name is the prefix of metric names. Defaults to "retry".
+	Name string
+}
+
+// Retry calls fn until it returns nil, the attempts allowed by p are
+// exhausted, or the exit channel of eh closes. The wait between
+// attempts grows exponentially. If the attempts are exhausted, the last
+// error returned by fn is returned. If the exit channel closes, the
+// returned error wraps both ErrRetryAborted and the last error.
+func Retry(eh *ExitHandler, p RetryPolicy, fn func() error) error {
+	eh.Add(1)
+	defer eh.Done()
+
+	delay := p.Delay
+	if delay <= 0 {
+		delay = time.Second
+	}
+
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 2
+	}
+
+	name := p.Name
+	if name == "" {
+		name = "retry"
+	}
+
+	attempts := p.Metrics.Counter(name+"_attempts_total", "Calls made by Retry.")
+	failures := p.Metrics.Counter(name+"_failures_total", "Calls which returned an error.")
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-eh.C:
+			return ErrRetryAborted
+		default:
+		}
+
+		attempts.Inc()
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		failures.Inc()
+
+		if p.Attempts > 0 && attempt >= p.Attempts {
+			return err
+		}
+
+		wait := jitter(delay, p.Jitter)
+
+		if p.Printer != nil {
+			if p.Attempts > 0 {
+				p.Printer.Eprintf("attempt %d/%d failed: %v, retrying in %v\n",
+					attempt, p.Attempts, err, wait)
+			} else {
+				p.Printer.Eprintf("attempt %d failed: %v, retrying in %v\n",
+					attempt, err, wait)
+			}
+		}
+
+		t := eh.clock().NewTimer(wait)
+
+		select {
+		case <-t.C():
+		case <-eh.C:
+			t.Stop()
+
+			return fmt.Errorf("%w: %w", ErrRetryAborted, err)
+		}
+
+		delay = time.Duration(float64(delay) * mult)
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
+
+// jitter randomizes d by up to the fraction f in either direction.
+func jitter(d time.Duration, f float64) time.Duration {
+	if f <= 0 {
+		return d
+	}
+
+	if f > 1 {
+		f = 1
+	}
+
+	//nolint:gosec // jitter does not require a secure random source
+	r := (rand.Float64()*2 - 1) * f
+
+	return time.Duration(float64(d) * (1 + r)).Round(time.Millisecond)
+}
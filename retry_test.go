@@ -0,0 +1,160 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
+)
+
+//nolint:gochecknoglobals // test error value
+var errTest = errors.New("testing error")
+
+func TestRetry(t *testing.T) {
+	t.Run("Success", testRetrySuccess)
+	t.Run("Exhausted", testRetryExhausted)
+	t.Run("Aborted", testRetryAborted)
+	t.Run("FakeClock", testRetryFakeClock)
+}
+
+func testRetryFakeClock(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	eh := new(cli.ExitHandler)
+	eh.SetClock(clk)
+
+	count := 0
+	done := make(chan error)
+
+	go func() {
+		done <- cli.Retry(eh, cli.RetryPolicy{Attempts: 2, Delay: time.Hour}, func() error {
+			count++
+			if count < 2 {
+				return errTest
+			}
+
+			return nil
+		})
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+
+	err := <-done
+	if err != nil || count != 2 {
+		t.Errorf("unexpected result %v after %d attempts", err, count)
+	}
+}
+
+func testRetrySuccess(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	errbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStderr(errbuf)
+
+	count := 0
+
+	err := cli.Retry(eh, cli.RetryPolicy{
+		Attempts: 5,
+		Delay:    time.Millisecond,
+		Printer:  p,
+	}, func() error {
+		count++
+		if count < 3 {
+			return errTest
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Error("expected 3 attempts, got", count)
+	}
+
+	exp := "attempt 1/5 failed: testing error, retrying in 1ms\n" +
+		"attempt 2/5 failed: testing error, retrying in 2ms\n"
+	if errbuf.String() != exp {
+		t.Error("unexpected output", errbuf.String())
+	}
+}
+
+func testRetryExhausted(t *testing.T) {
+	eh := new(cli.ExitHandler)
+	count := 0
+
+	err := cli.Retry(eh, cli.RetryPolicy{
+		Attempts: 3,
+		Delay:    time.Millisecond,
+		MaxDelay: 2 * time.Millisecond,
+		Jitter:   0.5,
+	}, func() error {
+		count++
+
+		return errTest
+	})
+	if !errors.Is(err, errTest) || errors.Is(err, cli.ErrRetryAborted) {
+		t.Error("unexpected error:", err)
+	}
+
+	if count != 3 {
+		t.Error("expected 3 attempts, got", count)
+	}
+}
+
+func testRetryAborted(t *testing.T) {
+	eh := new(cli.ExitHandler)
+
+	eh.After(50*time.Millisecond, func() { eh.Exit(nil) })
+
+	err := cli.Retry(eh, cli.RetryPolicy{
+		Delay: time.Minute,
+	}, func() error {
+		return errTest
+	})
+	if !errors.Is(err, cli.ErrRetryAborted) || !errors.Is(err, errTest) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = cli.Retry(eh, cli.RetryPolicy{}, func() error {
+		t.Error("unexpected call after exit")
+
+		return nil
+	})
+	if !errors.Is(err, cli.ErrRetryAborted) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = eh.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
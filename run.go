@@ -0,0 +1,233 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// Exit reasons recorded by Run.
+const (
+	ExitCompleted   = "completed"
+	ExitFailed      = "error"
+	ExitUsage       = "usage"
+	ExitInterrupted = "interrupted"
+	ExitDeadline    = "deadline"
+)
+
+// DeadlineExitCode is the exit status returned by Run when the deadline
+// set by WithDeadline expires, matching timeout(1).
+const DeadlineExitCode = 124
+
+// ErrDeadline is returned by Run when the deadline set by WithDeadline
+// expires.
+var ErrDeadline = errors.New("deadline exceeded")
+
+// WithDeadline adds a -timeout flag, defaulting to d, which limits the
+// overall run time of the function passed to Run. Zero or negative
+// values disable the limit.
+//
+// The context passed to the function carries the deadline. When it
+// expires, Exit is called with an error wrapping ErrDeadline, so the
+// shutdown proceeds as on a signal, including the timeout set with
+// SetTimeout if the function does not return promptly. Run then prints
+// the error and returns DeadlineExitCode.
+func WithDeadline(d time.Duration) Option {
+	return func(c *Cmd) {
+		c.FlagSet.DurationVar(&c.deadline, "timeout", d, "limit the run time to `duration`, such as 30s or 5m")
+	}
+}
+
+// Run parses args with Parse, then calls fn in a goroutine managed by
+// the ExitHandler, passing a context which is canceled when the exit
+// channel closes along with the remaining arguments. When fn returns,
+// Exit is called with its error. Run waits for the ExitHandler, then
+// returns an exit status suitable for os.Exit.
+//
+// The status is 0 on success, 2 if the arguments could not be parsed,
+// the Code of an *ExitError, DeadlineExitCode if the deadline set by
+// WithDeadline expired, BrokenPipeExitCode if output stopped because of
+// a broken pipe, or 1 for other errors. Errors other than a broken
+// pipe are printed to Stderr with PrintError, followed by any notice
+// enabled by CheckForUpdates. Panics in fn are handled by RecoverCrash.
+// The run is reported to Telemetry if it is enabled. If a Tracer has
+// been set with WithTracer, a span covers the whole of Run, with child
+// spans for the parse, run and shutdown phases. If the Cmd was created
+// WithSummary, the summary is printed last. If SetBackgroundCleanup is
+// enabled, the exit hooks run while the error and summary are printed,
+// and Run waits for them with WaitCleanup before returning.
+//
+// If the first argument is "__complete", the result of Completions for
+// the remaining arguments is printed instead, for use by the shell
+// integration generated by GenShellInit.
+func (c *Cmd) Run(args []string, fn func(ctx context.Context, args []string) error) int {
+	if len(args) > 0 && args[0] == completeCommand {
+		for _, s := range c.Completions(args[1:]) {
+			c.Println(s)
+		}
+
+		return 0
+	}
+
+	name := filepath.Base(c.FlagSet.Name())
+	start := c.clock().Now()
+
+	ctx, root := c.tracer().Start(context.Background(), name)
+
+	reason, code := c.run(ctx, args, fn)
+
+	c.exitReason, c.exitCode = reason, code
+
+	if c.autoSummary {
+		c.PrintSummary()
+	}
+
+	if c.telemetry != nil {
+		if c.command != "" {
+			name += " " + c.command
+		}
+
+		c.telemetry.Record(name, c.clock().Now().Sub(start), code)
+		c.telemetry.flush()
+	}
+
+	root.SetAttribute("exit.reason", reason)
+	root.SetAttribute("exit.code", code)
+	root.End()
+
+	if c.cleanup.background.Load() {
+		_ = c.WaitCleanup()
+	}
+
+	return code
+}
+
+// run implements Run, returning the exit reason and status.
+func (c *Cmd) run(ctx context.Context, args []string,
+	fn func(ctx context.Context, args []string) error,
+) (string, int) {
+	_, span := c.tracer().Start(ctx, "parse")
+	err := c.Parse(args)
+	span.End()
+
+	if errors.Is(err, flag.ErrHelp) {
+		return ExitCompleted, 0
+	}
+
+	if err != nil {
+		c.Eprintln(err)
+
+		return ExitUsage, 2
+	}
+
+	runCtx, runSpan := c.tracer().Start(ctx, "run")
+
+	var (
+		cancel      context.CancelFunc
+		deadlineErr error
+	)
+
+	if c.deadline > 0 {
+		deadlineErr = fmt.Errorf("%w after %s", ErrDeadline, c.deadline)
+		runCtx, cancel = context.WithTimeout(runCtx, c.deadline)
+	} else {
+		runCtx, cancel = context.WithCancel(runCtx)
+	}
+
+	reason := make(chan string, 1)
+
+	c.Add(1)
+
+	go func() {
+		defer c.Done()
+		defer cancel()
+		defer c.RecoverCrash()
+
+		go func() {
+			select {
+			case <-c.C:
+				cancel()
+			case <-runCtx.Done():
+				if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+					c.Exit(deadlineErr)
+				}
+			}
+		}()
+
+		err := fn(runCtx, c.FlagSet.Args())
+
+		switch {
+		case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+			err = deadlineErr
+			reason <- ExitDeadline
+		case c.exiting():
+			reason <- ExitInterrupted
+		case err != nil:
+			reason <- ExitFailed
+		default:
+			reason <- ExitCompleted
+		}
+
+		runSpan.End()
+
+		c.Exit(err)
+	}()
+
+	<-c.C
+
+	_, span = c.tracer().Start(ctx, "shutdown")
+	err = c.Wait()
+	span.End()
+
+	// a broken pipe is expected when the reader has seen enough
+	if err != nil && !errors.Is(err, ErrBrokenPipe) {
+		c.PrintError(err)
+	}
+
+	c.PrintUpdateNotice()
+
+	return <-reason, exitCode(err)
+}
+
+// exitCode returns the exit status for err.
+func exitCode(err error) int {
+	var ee *ExitError
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrDeadline):
+		return DeadlineExitCode
+	case errors.Is(err, ErrBrokenPipe):
+		return BrokenPipeExitCode
+	case errors.As(err, &ee):
+		return ee.Code
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+// recordTracer records the spans started and ended.
+type recordTracer struct {
+	m   sync.Mutex
+	log []string
+}
+
+type recordSpan struct {
+	t    *recordTracer
+	name string
+}
+
+type spanKey struct{}
+
+func (t *recordTracer) Start(ctx context.Context, name string) (context.Context, cli.Span) {
+	if parent, ok := ctx.Value(spanKey{}).(string); ok {
+		name = parent + "/" + name
+	}
+
+	t.add("start " + name)
+
+	return context.WithValue(ctx, spanKey{}, name), &recordSpan{t: t, name: name}
+}
+
+func (t *recordTracer) add(s string) {
+	t.m.Lock()
+	t.log = append(t.log, s)
+	t.m.Unlock()
+}
+
+func (s *recordSpan) SetAttribute(key string, value any) {
+	s.t.add(fmt.Sprintf("%s %s=%v", s.name, key, value))
+}
+
+func (s *recordSpan) End() {
+	s.t.add("end " + s.name)
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		rest   []string
+		err    error
+		code   int
+		reason string
+	}{
+		{"Success", []string{"-v", "a"}, []string{"a"}, nil, 0, cli.ExitCompleted},
+		{"Error", nil, nil, errTest, 1, cli.ExitFailed},
+		{"ExitError", nil, nil, &cli.ExitError{Name: "x", Code: 3}, 3, cli.ExitFailed},
+		{"Usage", []string{"-bogus"}, nil, nil, 2, cli.ExitUsage},
+		{"Help", []string{"-h"}, nil, nil, 0, cli.ExitCompleted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := new(recordTracer)
+			errbuf := new(bytes.Buffer)
+
+			cmd := cli.NewCmd(cli.WithTracer(tr))
+			cmd.SetStderr(errbuf)
+			cmd.FlagSet.Init("tool", flag.ContinueOnError)
+			cmd.FlagSet.SetOutput(io.Discard)
+			cmd.FlagSet.Bool("v", false, "verbose")
+
+			var got []string
+
+			code := cmd.Run(tc.args, func(ctx context.Context, args []string) error {
+				got = args
+
+				if ctx.Value(spanKey{}) != "tool/run" {
+					t.Error("expected run span in context")
+				}
+
+				return tc.err
+			})
+
+			if code != tc.code {
+				t.Errorf("expected code %d, received %d", tc.code, code)
+			}
+
+			if tc.rest != nil && !reflect.DeepEqual(got, tc.rest) {
+				t.Errorf("unexpected args %q", got)
+			}
+
+			if tc.err != nil && !strings.Contains(errbuf.String(), tc.err.Error()) {
+				t.Errorf("unexpected error output %q", errbuf)
+			}
+
+			log := strings.Join(tr.log, "\n")
+			exp := fmt.Sprintf("tool exit.reason=%s\ntool exit.code=%d\nend tool", tc.reason, tc.code)
+
+			if !strings.HasPrefix(log, "start tool\nstart tool/parse\nend tool/parse\n") ||
+				!strings.HasSuffix(log, exp) {
+				t.Errorf("unexpected spans:\n%s", log)
+			}
+		})
+	}
+}
+
+func TestRunInterrupted(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	code := cmd.Run(nil, func(ctx context.Context, _ []string) error {
+		go cmd.Exit(nil)
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	if code != 0 {
+		t.Errorf("expected code 0, received %d", code)
+	}
+}
+
+func TestRunDeadline(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd(cli.WithDeadline(time.Hour))
+	cmd.FlagSet.Init("test", flag.ContinueOnError)
+	cmd.SetStderr(errbuf)
+
+	code := cmd.Run([]string{"-timeout", "10ms"}, func(ctx context.Context, _ []string) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("expected context deadline")
+		}
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	if code != cli.DeadlineExitCode {
+		t.Errorf("expected code %d, received %d", cli.DeadlineExitCode, code)
+	}
+
+	if errbuf.String() != "deadline exceeded after 10ms\n" {
+		t.Errorf("unexpected error output %q", errbuf)
+	}
+}
+
+func TestRunDeadlineDisabled(t *testing.T) {
+	cmd := cli.NewCmd(cli.WithDeadline(0))
+	cmd.FlagSet.Init("test", flag.ContinueOnError)
+
+	code := cmd.Run(nil, func(ctx context.Context, _ []string) error {
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("unexpected context deadline")
+		}
+
+		return nil
+	})
+
+	if code != 0 {
+		t.Errorf("expected code 0, received %d", code)
+	}
+}
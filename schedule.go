@@ -0,0 +1,440 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSchedule is returned by ParseSchedule when a schedule is invalid.
+var ErrSchedule = errors.New("invalid schedule")
+
+// cronSearchLimit bounds the search for the next matching time, so an
+// expression which never matches, such as February 30, ends the search.
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+// Schedule determines when a job runs.
+type Schedule interface {
+	// Next returns the first time after t at which the job runs, or the
+	// zero time if it never runs again.
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule runs at a fixed interval.
+type intervalSchedule time.Duration
+
+// Next returns t plus the interval.
+func (d intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
+
+// cronSchedule runs at the times matching a cron expression, with one
+// bit set for each matching value of a field.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAll and dowAll are set when the field is "*", as a day matches
+	// if either of the day fields matches when both are restricted.
+	domAll, dowAll bool
+}
+
+// cronDescriptors are the shorthand forms accepted by ParseSchedule.
+//
+//nolint:gochecknoglobals // read-only table
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronNames are the names accepted for months and days of the week.
+//
+//nolint:gochecknoglobals // read-only table
+var cronNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// ParseSchedule parses a schedule, which is either "@every" followed by
+// a duration, such as "@every 5m", or a cron expression. Cron
+// expressions have five fields: minute, hour, day of month, month and
+// day of week. Each field is "*" or a list of values, ranges such as
+// "1-5" and steps such as "*/15" or "0-30/10". Months and days may be
+// given by their three letter names. The descriptors @hourly, @daily,
+// @weekly, @monthly and @yearly are also accepted. Times are in the
+// local time zone.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil || dur <= 0 {
+			return nil, fmt.Errorf("%w: '%s'", ErrSchedule, spec)
+		}
+
+		return intervalSchedule(dur), nil
+	}
+
+	if expr, ok := cronDescriptors[spec]; ok {
+		spec = expr
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: '%s' does not have five fields", ErrSchedule, spec)
+	}
+
+	var (
+		cs  cronSchedule
+		err error
+	)
+
+	for i, f := range []struct {
+		bits      *uint64
+		low, high int
+	}{
+		{&cs.minute, 0, 59},
+		{&cs.hour, 0, 23},
+		{&cs.dom, 1, 31},
+		{&cs.month, 1, 12},
+		{&cs.dow, 0, 7},
+	} {
+		*f.bits, err = parseCronField(fields[i], f.low, f.high)
+		if err != nil {
+			return nil, fmt.Errorf("%w: '%s': %w", ErrSchedule, spec, err)
+		}
+	}
+
+	// Sunday may be given as 0 or 7
+	if cs.dow&(1<<7) != 0 {
+		cs.dow |= 1
+	}
+
+	cs.domAll = fields[2] == "*"
+	cs.dowAll = fields[4] == "*"
+
+	return &cs, nil
+}
+
+// parseCronField returns the bits set by field for values between low
+// and high.
+func parseCronField(field string, low int, high int) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, step, hasStep := strings.Cut(part, "/")
+
+		lo, hi := low, high
+
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+
+			var err error
+
+			lo, err = parseCronValue(a, low, high)
+			if err != nil {
+				return 0, err
+			}
+
+			hi = lo
+
+			if isRange {
+				hi, err = parseCronValue(b, low, high)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				hi = high
+			}
+		}
+
+		if lo > hi {
+			return 0, fmt.Errorf("invalid range '%s'", part) //nolint:err113 // wrapped by caller
+		}
+
+		n := 1
+
+		if hasStep {
+			var err error
+
+			n, err = strconv.Atoi(step)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step '%s'", part) //nolint:err113 // wrapped by caller
+			}
+		}
+
+		for v := lo; v <= hi; v += n {
+			bits |= 1 << v
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a number or name between low and high.
+func parseCronValue(s string, low int, high int) (int, error) {
+	v, ok := cronNames[strings.ToLower(s)]
+	if !ok {
+		var err error
+
+		v, err = strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value '%s'", s) //nolint:err113 // wrapped by caller
+		}
+	}
+
+	if v < low || v > high {
+		return 0, fmt.Errorf("value '%s' out of range %d-%d", s, low, high) //nolint:err113 // wrapped by caller
+	}
+
+	return v, nil
+}
+
+// Next returns the first minute after t matching the expression.
+func (cs *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		switch {
+		case cs.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !cs.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case cs.hour&(1<<uint(t.Hour())) == 0:
+			// in local time, as some zones are offset by half an hour
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case cs.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t matches the day fields.
+func (cs *cronSchedule) dayMatches(t time.Time) bool {
+	dom := cs.dom&(1<<uint(t.Day())) != 0
+	dow := cs.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case cs.domAll:
+		return dow
+	case cs.dowAll:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// OverlapPolicy determines what happens when a job is due while a
+// previous run is still in progress.
+type OverlapPolicy int
+
+// Overlap policies.
+const (
+	// OverlapSkip skips the run. This is the default.
+	OverlapSkip OverlapPolicy = iota
+
+	// OverlapQueue runs the job again as soon as the previous run
+	// finishes. At most one run is queued.
+	OverlapQueue
+
+	// OverlapAllow starts the run alongside the previous one.
+	OverlapAllow
+)
+
+// JobOptions configures a job added to a Scheduler.
+type JobOptions struct {
+	// Timeout, if positive, cancels the context of each run after it
+	// has elapsed.
+	Timeout time.Duration
+
+	// Overlap selects the behavior when a run is due while the previous
+	// run is in progress.
+	Overlap OverlapPolicy
+}
+
+// Scheduler runs jobs on schedules in goroutines managed by the exit
+// handler of a Cmd. When the exit channel closes, no new runs start and
+// the context of each run in progress is canceled; Wait returns once
+// they have finished.
+type Scheduler struct {
+	c *Cmd
+}
+
+// job is a scheduled function and its run state.
+type job struct {
+	name  string
+	sched Schedule
+	fn    func(ctx context.Context) error
+	opts  JobOptions
+
+	m       sync.Mutex
+	running int
+	queued  bool
+}
+
+// Scheduler returns the job scheduler of the Cmd.
+func (c *Cmd) Scheduler() *Scheduler {
+	if c.scheduler == nil {
+		c.scheduler = &Scheduler{c: c}
+	}
+
+	return c.scheduler
+}
+
+// Add parses spec with ParseSchedule and adds a job which calls fn on
+// that schedule. Errors returned by fn are printed to Stderr prefixed
+// with the name of the job.
+func (s *Scheduler) Add(name string, spec string, fn func(ctx context.Context) error, opts JobOptions) error {
+	sched, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+
+	s.AddSchedule(name, sched, fn, opts)
+
+	return nil
+}
+
+// AddSchedule adds a job which calls fn on the given schedule, as with
+// Add.
+func (s *Scheduler) AddSchedule(name string, sched Schedule, fn func(ctx context.Context) error, opts JobOptions) {
+	j := &job{name: name, sched: sched, fn: fn, opts: opts}
+
+	s.c.Add(1)
+
+	go s.loop(j)
+}
+
+// loop starts the runs of j until the schedule ends or the exit channel
+// closes.
+func (s *Scheduler) loop(j *job) {
+	defer s.c.Done()
+
+	for {
+		clk := s.c.clock()
+
+		now := clk.Now()
+
+		next := j.sched.Next(now)
+		if next.IsZero() {
+			return
+		}
+
+		t := clk.NewTimer(next.Sub(now))
+
+		select {
+		case <-t.C():
+			s.start(j)
+		case <-s.c.C:
+			t.Stop()
+
+			return
+		}
+	}
+}
+
+// start starts a run of j, subject to its overlap policy.
+func (s *Scheduler) start(j *job) {
+	j.m.Lock()
+	defer j.m.Unlock()
+
+	if j.running > 0 {
+		switch j.opts.Overlap {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			j.queued = true
+
+			return
+		case OverlapAllow:
+		}
+	}
+
+	j.running++
+
+	s.c.Add(1)
+
+	go s.run(j)
+}
+
+// run calls the function of j, repeating while a run is queued.
+func (s *Scheduler) run(j *job) {
+	defer s.c.Done()
+
+	for {
+		s.call(j)
+
+		j.m.Lock()
+
+		if !j.queued || s.c.exiting() {
+			j.running--
+			j.m.Unlock()
+
+			return
+		}
+
+		j.queued = false
+		j.m.Unlock()
+	}
+}
+
+// call calls the function of j once with a context canceled by the
+// timeout or exit channel, printing any error.
+func (s *Scheduler) call(j *job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if j.opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, j.opts.Timeout)
+		defer cancel()
+	}
+
+	go func() {
+		select {
+		case <-s.c.C:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	err := j.fn(ctx)
+	if err != nil {
+		s.c.Eprintf("%s: %v\n", j.name, err)
+	}
+}
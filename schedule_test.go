@@ -0,0 +1,159 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2024, time.January, 31, 10, 17, 30, 0, time.Local)
+
+	for _, tc := range []struct {
+		spec string
+		exp  time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 31, 10, 18, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2024, 1, 31, 10, 30, 0, 0, time.Local)},
+		{"0 9-17 * * mon-fri", time.Date(2024, 1, 31, 11, 0, 0, 0, time.Local)},
+		{"30 2 * feb *", time.Date(2024, 2, 1, 2, 30, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+		{"0 0 1 * 0", time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"0 0 * * 7", time.Date(2024, 2, 4, 0, 0, 0, 0, time.Local)},
+		{"@daily", time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"@every 90s", start.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := cli.ParseSchedule(tc.spec)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			next := s.Next(start)
+			if !next.Equal(tc.exp) {
+				t.Errorf("expected %v, received %v", tc.exp, next)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "@every -1s", "@every x"} {
+		_, err := cli.ParseSchedule(spec)
+		if !errors.Is(err, cli.ErrSchedule) {
+			t.Errorf("expected ErrSchedule for %q, received %v", spec, err)
+		}
+	}
+}
+
+func TestParseScheduleZones(t *testing.T) {
+	s, err := cli.ParseSchedule("0 9-17 * * mon-fri")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	for _, name := range []string{"UTC", "Asia/Kolkata", "America/St_Johns", "Australia/Eucla"} {
+		t.Run(name, func(t *testing.T) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				t.Skip("time zone not available:", err)
+			}
+
+			start := time.Date(2024, time.January, 31, 8, 17, 30, 0, loc)
+			exp := time.Date(2024, time.January, 31, 9, 0, 0, 0, loc)
+
+			next := s.Next(start)
+			if !next.Equal(exp) {
+				t.Errorf("expected %v, received %v", exp, next)
+			}
+		})
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStderr(errbuf)
+
+	var runs, overlaps atomic.Int32
+
+	release := make(chan bool)
+
+	err := cmd.Scheduler().Add("count", "@every 10ms", func(context.Context) error {
+		runs.Add(1)
+
+		return nil
+	}, cli.JobOptions{})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cmd.Scheduler().Add("slow", "@every 10ms", func(context.Context) error {
+		overlaps.Add(1)
+		<-release
+
+		return nil
+	}, cli.JobOptions{Overlap: cli.OverlapSkip})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cmd.Scheduler().Add("timeout", "@every 10ms", func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}, cli.JobOptions{Timeout: 5 * time.Millisecond})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	close(release)
+	cmd.Exit(nil)
+
+	err = cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if runs.Load() < 2 {
+		t.Errorf("expected at least 2 runs, received %d", runs.Load())
+	}
+
+	if overlaps.Load() != 1 {
+		t.Errorf("expected 1 overlapping run, received %d", overlaps.Load())
+	}
+
+	if !strings.Contains(errbuf.String(), "timeout: context deadline exceeded") {
+		t.Errorf("expected timeout error, received %q", errbuf)
+	}
+}
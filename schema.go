@@ -0,0 +1,142 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaProperty is a property of the JSON Schema of ConfigSchema.
+type schemaProperty struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Default     any      `json:"default,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Format      string   `json:"format,omitempty"`
+}
+
+// ConfigSchema returns a JSON Schema describing the settings accepted
+// in the config file, which are the flags of the FlagSet, with their
+// types, defaults and usage text. Editors can use the schema for
+// completion, and pipelines to validate configuration. Unknown keys are
+// allowed unless the Cmd was created WithStrictConfig.
+func (c *Cmd) ConfigSchema() ([]byte, error) {
+	props := make(map[string]schemaProperty)
+
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		props[f.Name] = c.flagSchema(f)
+	})
+
+	return json.MarshalIndent(map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                filepath.Base(c.FlagSet.Name()) + " configuration",
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": !c.strictConfig,
+	}, "", "  ")
+}
+
+// ConfigSample returns a sample config file in the format read by
+// LoadConfig, listing every flag with its usage text and default value,
+// commented out so that the file has no effect until edited.
+func (c *Cmd) ConfigSample() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s configuration\n", filepath.Base(c.FlagSet.Name()))
+
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+
+		sb.WriteString("\n")
+
+		for _, line := range strings.Split(usage, "\n") {
+			sb.WriteString(strings.TrimSpace("# " + line))
+			sb.WriteString("\n")
+		}
+
+		if values, ok := c.flagValues[f.Name]; ok {
+			fmt.Fprintf(&sb, "# one of: %s\n", strings.Join(values, ", "))
+		}
+
+		fmt.Fprintf(&sb, "# %s = %s\n", f.Name, quoteConfig(f.DefValue))
+	})
+
+	return sb.String()
+}
+
+// flagSchema returns the schema of the setting for f.
+func (c *Cmd) flagSchema(f *flag.Flag) schemaProperty {
+	_, usage := flag.UnquoteUsage(f)
+
+	p := schemaProperty{Type: "string", Description: usage, Enum: c.flagValues[f.Name]}
+
+	var def any = f.DefValue
+
+	if v, ok := flagDefault(f); ok {
+		switch v.(type) {
+		case bool:
+			p.Type, def = "boolean", v
+		case int, int64, uint, uint64:
+			p.Type, def = "integer", v
+		case float64:
+			p.Type, def = "number", v
+		case time.Duration:
+			// durations are written as for time.ParseDuration
+			p.Format = "duration"
+		}
+	}
+
+	if f.DefValue != "" {
+		p.Default = def
+	}
+
+	return p
+}
+
+// flagDefault returns the default value of f, as returned by the Get
+// method of a scratch copy of its value, if it has one.
+func flagDefault(f *flag.Flag) (v any, ok bool) {
+	defer func() {
+		if recover() != nil {
+			v, ok = nil, false
+		}
+	}()
+
+	t := reflect.TypeOf(f.Value)
+	if t.Kind() != reflect.Pointer {
+		return nil, false
+	}
+
+	g, ok := reflect.New(t.Elem()).Interface().(flag.Getter)
+	if !ok || g.Set(f.DefValue) != nil {
+		return nil, false
+	}
+
+	return g.Get(), true
+}
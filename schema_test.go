@@ -0,0 +1,103 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestConfigSchema(t *testing.T) {
+	cmd := cli.NewCmd(cli.WithStrictConfig(""))
+	cmd.FlagSet.Init("tool", flag.ContinueOnError)
+	cmd.FlagSet.Bool("verbose", false, "show more output")
+	cmd.FlagSet.Int("workers", 4, "number of `workers`")
+	cmd.FlagSet.Float64("ratio", 0.5, "sample ratio")
+	cmd.FlagSet.Duration("timeout", time.Minute, "request timeout")
+	cmd.FlagSet.String("mode", "safe", "mode")
+	cmd.FlagSet.String("name", "", "name")
+	cmd.SetFlagValues("mode", "fast", "safe")
+
+	b, err := cmd.ConfigSchema()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var schema struct {
+		Title      string                    `json:"title"`
+		Additional bool                      `json:"additionalProperties"`
+		Properties map[string]map[string]any `json:"properties"`
+	}
+
+	err = json.Unmarshal(b, &schema)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if schema.Title != "tool configuration" || schema.Additional {
+		t.Errorf("unexpected schema %s", b)
+	}
+
+	expect := map[string]map[string]any{
+		"verbose": {"type": "boolean", "description": "show more output", "default": false},
+		"workers": {"type": "integer", "description": "number of workers", "default": 4.0},
+		"ratio":   {"type": "number", "description": "sample ratio", "default": 0.5},
+		"timeout": {"type": "string", "description": "request timeout", "default": "1m0s", "format": "duration"},
+		"mode": {
+			"type": "string", "description": "mode", "default": "safe",
+			"enum": []any{"fast", "safe"},
+		},
+		"name": {"type": "string", "description": "name"},
+	}
+
+	if !reflect.DeepEqual(schema.Properties, expect) {
+		t.Errorf("expected %v, received %v", expect, schema.Properties)
+	}
+}
+
+func TestConfigSample(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.FlagSet.Init("tool", flag.ContinueOnError)
+	cmd.FlagSet.String("mode", "safe", "processing `mode`")
+	cmd.FlagSet.String("greeting", " hi ", "greeting")
+	cmd.SetFlagValues("mode", "fast", "safe")
+
+	expect := `# tool configuration
+
+# greeting
+# greeting = " hi "
+
+# processing mode
+# one of: fast, safe
+# mode = safe
+`
+
+	if s := cmd.ConfigSample(); s != expect {
+		t.Errorf("expected %q, received %q", expect, s)
+	}
+}
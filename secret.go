@@ -0,0 +1,258 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrSecretNotFound is returned by a SecretStore when no secret is
+// stored under a key.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// ErrSecretStore is returned when the secret store cannot be read.
+var ErrSecretStore = errors.New("invalid secret store")
+
+// SecretStore persists secrets such as access tokens. Implementations
+// may use a file, as with FileSecretStore, or an operating system
+// keychain.
+type SecretStore interface {
+	// Get returns the secret stored under key, or ErrSecretNotFound.
+	Get(key string) (string, error)
+
+	// Set stores value under key, replacing any existing secret.
+	Set(key string, value string) error
+
+	// Delete removes the secret stored under key. Deleting a key which
+	// does not exist is not an error.
+	Delete(key string) error
+}
+
+// FileSecretStore is a SecretStore which keeps secrets in a file
+// readable only by its owner, optionally encrypted with AES-GCM.
+type FileSecretStore struct {
+	m    sync.Mutex
+	path string
+	aead cipher.AEAD
+}
+
+// NewFileSecretStore returns a FileSecretStore using the file at path,
+// which is created when the first secret is stored. If key is not nil,
+// the file is encrypted with it using AES-GCM, and key must be 16, 24
+// or 32 bytes long.
+func NewFileSecretStore(path string, key []byte) (*FileSecretStore, error) {
+	fs := &FileSecretStore{path: path}
+
+	if key != nil {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		fs.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fs, nil
+}
+
+// Get returns the secret stored under key, or ErrSecretNotFound.
+func (fs *FileSecretStore) Get(key string) (string, error) {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+
+	secrets, err := fs.load()
+	if err != nil {
+		return "", err
+	}
+
+	v, ok := secrets[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrSecretNotFound, key)
+	}
+
+	return v, nil
+}
+
+// Set stores value under key, replacing any existing secret.
+func (fs *FileSecretStore) Set(key string, value string) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+
+	secrets, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = value
+
+	return fs.save(secrets)
+}
+
+// Delete removes the secret stored under key.
+func (fs *FileSecretStore) Delete(key string) error {
+	fs.m.Lock()
+	defer fs.m.Unlock()
+
+	secrets, err := fs.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+
+	delete(secrets, key)
+
+	return fs.save(secrets)
+}
+
+// load reads the secrets from the file, returning an empty map if the
+// file does not exist.
+func (fs *FileSecretStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+
+	b, err := os.ReadFile(fs.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return secrets, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fs.aead != nil {
+		n := fs.aead.NonceSize()
+		if len(b) < n {
+			return nil, fmt.Errorf("%w: %s", ErrSecretStore, fs.path)
+		}
+
+		b, err = fs.aead.Open(nil, b[:n], b[n:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrSecretStore, fs.path, err)
+		}
+	}
+
+	err = json.Unmarshal(b, &secrets)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrSecretStore, fs.path, err)
+	}
+
+	return secrets, nil
+}
+
+// save writes secrets to the file.
+func (fs *FileSecretStore) save(secrets map[string]string) error {
+	b, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	if fs.aead != nil {
+		nonce := make([]byte, fs.aead.NonceSize())
+
+		_, err = io.ReadFull(rand.Reader, nonce)
+		if err != nil {
+			return err
+		}
+
+		b = fs.aead.Seal(nonce, nonce, b, nil)
+	}
+
+	err = os.MkdirAll(filepath.Dir(fs.path), 0o700)
+	if err != nil {
+		return err
+	}
+
+	// the temporary file is created with mode 0600
+	return writeFileAtomic(fs.path, bytes.NewReader(b))
+}
+
+// SetSecretStore sets the SecretStore used by the Cmd.
+func (c *Cmd) SetSecretStore(s SecretStore) {
+	c.secrets = s
+}
+
+// SecretStore returns the SecretStore set with SetSecretStore. If none
+// has been set, an unencrypted FileSecretStore is used, keeping secrets
+// in the file "secrets.json" in the ConfigDir of the application.
+func (c *Cmd) SecretStore() (SecretStore, error) {
+	if c.secrets != nil {
+		return c.secrets, nil
+	}
+
+	dir, err := ConfigDir(filepath.Base(c.FlagSet.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := NewFileSecretStore(filepath.Join(dir, "secrets.json"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c.secrets = fs
+
+	return fs, nil
+}
+
+// Secret returns the secret stored under key. If there is none, prompt
+// is printed and the secret is read with ReadPassword, then stored for
+// later use. The secret is registered with RedactSecrets.
+func (c *Cmd) Secret(key string, prompt string) (string, error) {
+	s, err := c.SecretStore()
+	if err != nil {
+		return "", err
+	}
+
+	v, err := s.Get(key)
+	if !errors.Is(err, ErrSecretNotFound) {
+		c.RedactSecrets(v)
+
+		return v, err
+	}
+
+	v, err = c.NewLineReader().ReadPassword(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if v == "" {
+		return "", nil
+	}
+
+	return v, s.Set(key, v)
+}
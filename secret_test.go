@@ -0,0 +1,121 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestFileSecretStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+	key := bytes.Repeat([]byte{1}, 32)
+
+	fs, err := cli.NewFileSecretStore(path, key)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	_, err = fs.Get("token")
+	if !errors.Is(err, cli.ErrSecretNotFound) {
+		t.Error("expected ErrSecretNotFound, received", err)
+	}
+
+	for _, kv := range [][2]string{{"token", "s3cret"}, {"other", "value"}} {
+		err = fs.Set(kv[0], kv[1])
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if bytes.Contains(b, []byte("s3cret")) {
+		t.Error("expected file to be encrypted")
+	}
+
+	if fi, _ := os.Stat(path); runtime.GOOS != "windows" && fi.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode 0600, received %o", fi.Mode().Perm())
+	}
+
+	err = fs.Delete("other")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	fs, _ = cli.NewFileSecretStore(path, key)
+
+	v, err := fs.Get("token")
+	if err != nil || v != "s3cret" {
+		t.Errorf("expected %q, received %q, %v", "s3cret", v, err)
+	}
+
+	_, err = fs.Get("other")
+	if !errors.Is(err, cli.ErrSecretNotFound) {
+		t.Error("expected ErrSecretNotFound, received", err)
+	}
+
+	fs, _ = cli.NewFileSecretStore(path, bytes.Repeat([]byte{2}, 32))
+
+	_, err = fs.Get("token")
+	if !errors.Is(err, cli.ErrSecretStore) {
+		t.Error("expected ErrSecretStore, received", err)
+	}
+}
+
+func TestCmdSecret(t *testing.T) {
+	fs, _ := cli.NewFileSecretStore(filepath.Join(t.TempDir(), "secrets"), nil)
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetStdin(strings.NewReader("abc123\n"))
+	cmd.SetSecretStore(fs)
+
+	for i := 0; i < 2; i++ {
+		v, err := cmd.Secret("token", "Token: ")
+		if err != nil || v != "abc123" {
+			t.Errorf("expected %q, received %q, %v", "abc123", v, err)
+		}
+	}
+
+	if outbuf.String() != "Token: " {
+		t.Errorf("expected a single prompt, received %q", outbuf)
+	}
+
+	cmd.Println("token is abc123")
+
+	if !strings.HasSuffix(outbuf.String(), "token is ********\n") {
+		t.Errorf("expected secret to be redacted, received %q", outbuf)
+	}
+}
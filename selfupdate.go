@@ -0,0 +1,213 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// SelfUpdatedEnv is the environment variable set to the previous
+// version when SelfUpdate runs the updated executable.
+const SelfUpdatedEnv = "CLI_SELF_UPDATED"
+
+// ErrUnverified is returned by SelfUpdate when a release binary has
+// neither a checksum nor a signature with which to verify it.
+var ErrUnverified = errors.New("release binary cannot be verified")
+
+// ErrSignature is returned by SelfUpdate when the signature of a
+// release binary is missing or invalid.
+var ErrSignature = errors.New("invalid signature")
+
+// ReleaseBinary describes the executable of a release for a platform.
+type ReleaseBinary struct {
+	// URL is where the executable is downloaded from.
+	URL string
+
+	// SHA256 is the SHA-256 checksum of the executable, in
+	// hexadecimal.
+	SHA256 string
+
+	// Signature is the Ed25519 signature of the executable, verified
+	// if SelfUpdateOptions has a PublicKey.
+	Signature []byte
+}
+
+// BinarySource is an UpdateSource which also provides the executables
+// of its releases.
+type BinarySource interface {
+	UpdateSource
+
+	// ReleaseBinary returns the executable of rel for the operating
+	// system and architecture given, as in runtime.GOOS and
+	// runtime.GOARCH.
+	ReleaseBinary(ctx context.Context, rel Release, goos string, goarch string) (ReleaseBinary, error)
+}
+
+// SelfUpdateOptions configures SelfUpdate.
+type SelfUpdateOptions struct {
+	// Source provides the latest release and its executables.
+	Source BinarySource
+
+	// PublicKey, if set, is the Ed25519 key which must have signed the
+	// executable.
+	PublicKey ed25519.PublicKey
+
+	// Executable is the path of the file to replace. The default is
+	// the running executable.
+	Executable string
+
+	// NoReexec disables running the updated executable.
+	NoReexec bool
+}
+
+// AddSelfUpdateCommand adds a "self-update" subcommand which calls
+// SelfUpdate with opts.
+func (c *Cmd) AddSelfUpdateCommand(opts SelfUpdateOptions) *Command {
+	return c.AddCommand("self-update", "update to the latest release", func([]string) error {
+		return c.SelfUpdate(context.Background(), opts)
+	})
+}
+
+// SelfUpdate replaces the running executable with the latest release
+// from opts.Source, if it is newer than the Version of the Cmd. The
+// executable for the current platform is downloaded next to the
+// running one with Download, verified against its checksum and, if
+// opts has a PublicKey, its signature, then renamed over the running
+// one. A release with neither a checksum nor a key to check its
+// signature is refused with ErrUnverified.
+//
+// Once replaced, the updated executable is run with the same arguments
+// and SelfUpdatedEnv set to the previous version, replacing the current
+// process where the platform allows. Run in this way, SelfUpdate only
+// reports the update. The Cmd should be created WithWriteQueue or have
+// no pending output, as exit hooks do not run when the process is
+// replaced.
+func (c *Cmd) SelfUpdate(ctx context.Context, opts SelfUpdateOptions) error {
+	if prev := os.Getenv(SelfUpdatedEnv); prev != "" {
+		c.Printf("updated %s from %s to %s\n", filepath.Base(c.FlagSet.Name()), prev, c.Version())
+
+		return nil
+	}
+
+	rel, err := opts.Source.LatestRelease(ctx)
+	if err != nil {
+		return err
+	}
+
+	cur := c.Version()
+
+	if compareVersions(cur, rel.Version) >= 0 {
+		c.Printf("%s is up to date (%s)\n", filepath.Base(c.FlagSet.Name()), cur)
+
+		return nil
+	}
+
+	bin, err := opts.Source.ReleaseBinary(ctx, rel, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return err
+	}
+
+	if bin.SHA256 == "" && opts.PublicKey == nil {
+		return fmt.Errorf("%w: %s", ErrUnverified, bin.URL)
+	}
+
+	exe := opts.Executable
+	if exe == "" {
+		exe, err = os.Executable()
+		if err == nil {
+			exe, err = filepath.EvalSymlinks(exe)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	fi, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	tmp := exe + ".new"
+
+	err = c.Download(ctx, bin.URL, tmp, DownloadOptions{SHA256: bin.SHA256})
+	if err != nil {
+		return err
+	}
+
+	err = installBinary(tmp, exe, fi.Mode(), bin.Signature, opts.PublicKey)
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return err
+	}
+
+	if opts.NoReexec {
+		c.Printf("updated %s from %s to %s\n", filepath.Base(c.FlagSet.Name()), cur, rel.Version)
+
+		return nil
+	}
+
+	_ = c.Flush()
+
+	return reexec(exe, os.Args, append(os.Environ(), SelfUpdatedEnv+"="+cur))
+}
+
+// installBinary verifies the signature of the executable tmp, if key is
+// set, and renames it over exe with the given mode.
+func installBinary(tmp string, exe string, mode os.FileMode, sig []byte, key ed25519.PublicKey) error {
+	if key != nil {
+		b, err := os.ReadFile(tmp)
+		if err != nil {
+			return err
+		}
+
+		if len(sig) == 0 || !ed25519.Verify(key, b, sig) {
+			return fmt.Errorf("%w: %s", ErrSignature, tmp)
+		}
+	}
+
+	err := os.Chmod(tmp, mode.Perm())
+	if err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		// a running executable cannot be replaced, but can be renamed
+		old := exe + ".old"
+		_ = os.Remove(old)
+
+		err = os.Rename(exe, old)
+		if err != nil {
+			return err
+		}
+	}
+
+	return os.Rename(tmp, exe)
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+// binarySource serves one release from a test server.
+type binarySource struct {
+	version string
+	bin     cli.ReleaseBinary
+}
+
+func (bs binarySource) LatestRelease(context.Context) (cli.Release, error) {
+	return cli.Release{Version: bs.version}, nil
+}
+
+func (bs binarySource) ReleaseBinary(context.Context, cli.Release, string, string) (cli.ReleaseBinary, error) {
+	return bs.bin, nil
+}
+
+func TestSelfUpdate(t *testing.T) {
+	t.Setenv("CI", "false")
+	t.Setenv(cli.SelfUpdatedEnv, "")
+
+	data := []byte("new binary")
+	sum := sha256.Sum256(data)
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		version string
+		bin     cli.ReleaseBinary
+		key     ed25519.PublicKey
+		err     error
+		updated bool
+	}{
+		{
+			name:    "checksum",
+			version: "v1.1.0",
+			bin:     cli.ReleaseBinary{URL: srv.URL, SHA256: hex.EncodeToString(sum[:])},
+			updated: true,
+		},
+		{
+			name:    "signature",
+			version: "v1.1.0",
+			bin:     cli.ReleaseBinary{URL: srv.URL, Signature: ed25519.Sign(priv, data)},
+			key:     pub,
+			updated: true,
+		},
+		{
+			name:    "current",
+			version: "v1.0.0",
+			bin:     cli.ReleaseBinary{URL: srv.URL, SHA256: hex.EncodeToString(sum[:])},
+		},
+		{
+			name:    "bad checksum",
+			version: "v1.1.0",
+			bin:     cli.ReleaseBinary{URL: srv.URL, SHA256: hex.EncodeToString(make([]byte, 32))},
+			err:     cli.ErrChecksum,
+		},
+		{
+			name:    "bad signature",
+			version: "v1.1.0",
+			bin:     cli.ReleaseBinary{URL: srv.URL, Signature: []byte("bogus")},
+			key:     pub,
+			err:     cli.ErrSignature,
+		},
+		{
+			name:    "unverified",
+			version: "v1.1.0",
+			bin:     cli.ReleaseBinary{URL: srv.URL},
+			err:     cli.ErrUnverified,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exe := filepath.Join(t.TempDir(), "tool")
+
+			err := os.WriteFile(exe, []byte("old binary"), 0o755)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			cmd := cli.NewCmd()
+			cmd.SetStdout(new(bytes.Buffer))
+			cmd.SetVersion("v1.0.0")
+
+			err = cmd.SelfUpdate(context.Background(), cli.SelfUpdateOptions{
+				Source:     binarySource{version: test.version, bin: test.bin},
+				PublicKey:  test.key,
+				Executable: exe,
+				NoReexec:   true,
+			})
+			if !errors.Is(err, test.err) {
+				t.Fatalf("expected error %v, received %v", test.err, err)
+			}
+
+			b, err := os.ReadFile(exe)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			if updated := bytes.Equal(b, data); updated != test.updated {
+				t.Errorf("expected updated %t, received %q", test.updated, b)
+			}
+
+			fi, err := os.Stat(exe)
+			if err == nil && fi.Mode().Perm() != 0o755 {
+				t.Errorf("expected mode preserved, received %v", fi.Mode())
+			}
+
+			matches, _ := filepath.Glob(exe + ".new*")
+			if len(matches) > 0 {
+				t.Errorf("unexpected temporary files %v", matches)
+			}
+		})
+	}
+}
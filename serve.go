@@ -0,0 +1,189 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// defaultStatusLines is the scrollback size used by ServeStatus if
+	// snapshots have not been enabled.
+	defaultStatusLines = 100
+
+	// statusInterval is the interval at which the event stream is
+	// checked for changes.
+	statusInterval = 250 * time.Millisecond
+
+	// statusShutdownTimeout limits the wait for open requests when the
+	// status server is shut down.
+	statusShutdownTimeout = time.Second
+)
+
+// statusReport is the JSON document served by ServeStatus.
+type statusReport struct {
+	Live    string  `json:"live"`
+	Lines   []Event `json:"lines"`
+	Exiting bool    `json:"exiting"`
+}
+
+// ServeStatus starts an HTTP server on addr exposing the state of the
+// Cmd, returning the address it is listening on. GET /status returns a
+// JSON document containing the live region, recent output lines and
+// whether the Cmd is shutting down. GET /events streams the same
+// document as server-sent events each time it changes. If metrics have
+// been enabled, GET /metrics serves them in the Prometheus format.
+//
+// Output is recorded as with SetSnapshotSize, enabling it with a
+// default size if needed. The server runs in a goroutine managed by the
+// ExitHandler and is shut down when the exit channel closes.
+func (c *Cmd) ServeStatus(addr string) (net.Addr, error) {
+	if !c.snapshotEnabled() {
+		c.SetSnapshotSize(defaultStatusLines)
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", c.serveStatus)
+	mux.HandleFunc("/events", c.serveStatusEvents)
+
+	if c.metrics != nil {
+		mux.Handle("/metrics", c.metrics)
+	}
+
+	srv := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	c.Add(1)
+
+	go func() {
+		defer c.Done()
+
+		<-c.C
+
+		ctx, cancel := context.WithTimeout(context.Background(), statusShutdownTimeout)
+		defer cancel()
+
+		_ = srv.Shutdown(ctx)
+	}()
+
+	go func() {
+		err := srv.Serve(l)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			c.Eprintln("status server:", err)
+		}
+	}()
+
+	return l.Addr(), nil
+}
+
+// statusJSON returns the current status document.
+func (c *Cmd) statusJSON() []byte {
+	b, _ := json.Marshal(c.statusReport())
+
+	return b
+}
+
+// statusReport returns the current status.
+func (c *Cmd) statusReport() statusReport {
+	s := c.Snapshot()
+
+	return statusReport{
+		Live:    s.Live,
+		Lines:   s.Lines,
+		Exiting: c.exiting(),
+	}
+}
+
+// serveStatus writes the current status document.
+func (c *Cmd) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(c.statusJSON())
+}
+
+// serveStatusEvents streams the status document as server-sent events,
+// sending a new event whenever the document changes. The stream ends
+// when the client disconnects or after the final document has been
+// sent once the exit channel has closed.
+func (c *Cmd) serveStatusEvents(w http.ResponseWriter, r *http.Request) {
+	f, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	t := c.clock().NewTicker(statusInterval)
+	defer t.Stop()
+
+	var last []byte
+
+	for {
+		exiting := c.exiting()
+
+		b := c.statusJSON()
+		if !bytes.Equal(b, last) {
+			_, err := fmt.Fprintf(w, "data: %s\n\n", b)
+			if err != nil {
+				return
+			}
+
+			f.Flush()
+
+			last = b
+		}
+
+		if exiting {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.C:
+		case <-t.C():
+		}
+	}
+}
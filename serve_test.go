@@ -0,0 +1,128 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+type statusDoc struct {
+	Live    string      `json:"live"`
+	Lines   []cli.Event `json:"lines"`
+	Exiting bool        `json:"exiting"`
+}
+
+func TestServeStatus(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+
+	addr, err := cmd.ServeStatus("127.0.0.1:0")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Println("hello")
+	cmd.Lprintf("working\n")
+
+	resp, err := http.Get("http://" + addr.String() + "/status")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	var doc statusDoc
+
+	err = json.NewDecoder(resp.Body).Decode(&doc)
+	resp.Body.Close()
+
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if doc.Live != "working\n" || len(doc.Lines) != 1 || doc.Lines[0].Text != "hello" || doc.Exiting {
+		t.Errorf("unexpected status %+v", doc)
+	}
+
+	resp, err = http.Get("http://" + addr.String() + "/events")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer resp.Body.Close()
+
+	events := bufio.NewScanner(resp.Body)
+
+	doc = readStatusEvent(t, events)
+	if doc.Live != "working\n" || doc.Exiting {
+		t.Errorf("unexpected status %+v", doc)
+	}
+
+	cmd.Exit(nil)
+
+	doc = readStatusEvent(t, events)
+	if !doc.Exiting {
+		t.Errorf("unexpected status %+v", doc)
+	}
+
+	for events.Scan() {
+		if events.Text() != "" {
+			t.Errorf("unexpected data after final event: %q", events.Text())
+		}
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+}
+
+// readStatusEvent reads the next server-sent event from s.
+func readStatusEvent(t *testing.T, s *bufio.Scanner) statusDoc {
+	t.Helper()
+
+	var doc statusDoc
+
+	for s.Scan() {
+		data, ok := strings.CutPrefix(s.Text(), "data: ")
+		if !ok {
+			continue
+		}
+
+		err := json.Unmarshal([]byte(data), &doc)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		return doc
+	}
+
+	t.Fatal("unexpected end of events", s.Err())
+
+	return doc
+}
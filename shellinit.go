@@ -0,0 +1,368 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// completeCommand is the argument with which the shell integration
+// generated by GenShellInit runs the program to obtain completions.
+const completeCommand = "__complete"
+
+// ErrUnsupportedShell is returned by GenShellInit for an unknown shell.
+var ErrUnsupportedShell = errors.New("unsupported shell")
+
+// AddShellAlias adds an alias to the shell integration generated by
+// GenShellInit, which runs the program with args followed by any
+// arguments given to the alias.
+func (c *Cmd) AddShellAlias(name string, args ...string) {
+	if c.shellAliases == nil {
+		c.shellAliases = make(map[string][]string)
+	}
+
+	c.shellAliases[name] = args
+}
+
+// SetShellHook sets arguments with which the shell integration runs the
+// program before each prompt. The name of the shell is appended to args,
+// and the output of the program is evaluated by the shell, allowing it
+// to modify the environment of the shell.
+func (c *Cmd) SetShellHook(args ...string) {
+	c.shellHook = args
+}
+
+// GenShellInit returns a script for the named shell, which may be
+// "bash", "zsh", "fish" or "powershell", defining the aliases added
+// with AddShellAlias, running the hook set with SetShellHook before each
+// prompt and enabling completion of subcommands, flags and flag values.
+// Users evaluate the script from their shell startup file, such as with
+// eval "$(tool shell-init bash)".
+//
+// Completion runs the program with "__complete" followed by the words
+// of the command line, which Run handles by printing the result of
+// Completions.
+func (c *Cmd) GenShellInit(shell string) (string, error) {
+	prog := strings.TrimSuffix(filepath.Base(c.FlagSet.Name()), ".exe")
+
+	si := shellInit{
+		prog:    prog,
+		id:      shellIdent(prog),
+		hook:    c.shellHook,
+		aliases: c.shellAliases,
+	}
+
+	switch shell {
+	case "bash":
+		return si.bash(), nil
+	case "zsh":
+		return si.zsh(), nil
+	case "fish":
+		return si.fish(), nil
+	case "powershell", "pwsh":
+		return si.powershell(), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedShell, shell)
+	}
+}
+
+// shellInit generates shell integration scripts.
+type shellInit struct {
+	prog    string
+	id      string
+	hook    []string
+	aliases map[string][]string
+}
+
+// aliasNames returns the sorted names of the aliases.
+func (si shellInit) aliasNames() []string {
+	names := make([]string, 0, len(si.aliases))
+
+	for name := range si.aliases {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// command returns the program and args quoted with quote.
+func (si shellInit) command(quote func(string) string, args ...string) string {
+	words := []string{quote(si.prog)}
+
+	for _, a := range args {
+		words = append(words, quote(a))
+	}
+
+	return strings.Join(words, " ")
+}
+
+// bash returns the script for bash.
+func (si shellInit) bash() string {
+	var sb strings.Builder
+
+	for _, name := range si.aliasNames() {
+		fmt.Fprintf(&sb, "alias %s=%s\n", name,
+			posixQuote(si.command(posixQuote, si.aliases[name]...)))
+	}
+
+	if si.hook != nil {
+		fmt.Fprintf(&sb, "_%s_hook() { eval \"$(%s)\"; }\n",
+			si.id, si.command(posixQuote, append(si.hook, "bash")...))
+		fmt.Fprintf(&sb, "case \";${PROMPT_COMMAND};\" in\n"+
+			"  *\";_%[1]s_hook;\"*) ;;\n"+
+			"  *) PROMPT_COMMAND=\"_%[1]s_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}\" ;;\n"+
+			"esac\n", si.id)
+	}
+
+	fmt.Fprintf(&sb, "_%s_complete() {\n"+
+		"  local IFS=$'\\n'\n"+
+		"  COMPREPLY=($(%s \"${COMP_WORDS[@]:0:COMP_CWORD+1}\"))\n"+
+		"}\n", si.id, si.command(posixQuote, completeCommand))
+	fmt.Fprintf(&sb, "complete -o default -F _%s_complete %s\n", si.id, posixQuote(si.prog))
+
+	return sb.String()
+}
+
+// zsh returns the script for zsh.
+func (si shellInit) zsh() string {
+	var sb strings.Builder
+
+	for _, name := range si.aliasNames() {
+		fmt.Fprintf(&sb, "alias %s=%s\n", name,
+			posixQuote(si.command(posixQuote, si.aliases[name]...)))
+	}
+
+	if si.hook != nil {
+		fmt.Fprintf(&sb, "_%s_hook() { eval \"$(%s)\"; }\n",
+			si.id, si.command(posixQuote, append(si.hook, "zsh")...))
+		fmt.Fprintf(&sb, "typeset -ag precmd_functions\n"+
+			"if (( ! ${precmd_functions[(I)_%[1]s_hook]} )); then\n"+
+			"  precmd_functions=(_%[1]s_hook $precmd_functions)\n"+
+			"fi\n", si.id)
+	}
+
+	fmt.Fprintf(&sb, "_%s_complete() {\n"+
+		"  local -a cands\n"+
+		"  cands=(\"${(@f)$(%s \"${(@)words[1,CURRENT]}\")}\")\n"+
+		"  compadd -a cands\n"+
+		"}\n", si.id, si.command(posixQuote, completeCommand))
+	fmt.Fprintf(&sb, "(( $+functions[compdef] )) && compdef _%s_complete %s\n",
+		si.id, posixQuote(si.prog))
+
+	return sb.String()
+}
+
+// fish returns the script for fish.
+func (si shellInit) fish() string {
+	var sb strings.Builder
+
+	for _, name := range si.aliasNames() {
+		fmt.Fprintf(&sb, "function %s --wraps %s\n    %s $argv\nend\n", name,
+			fishQuote(si.prog), si.command(fishQuote, si.aliases[name]...))
+	}
+
+	if si.hook != nil {
+		fmt.Fprintf(&sb, "function __%s_hook --on-event fish_prompt\n"+
+			"    %s | source\n"+
+			"end\n", si.id, si.command(fishQuote, append(si.hook, "fish")...))
+	}
+
+	// a quoted variable always expands to one argument, even if the
+	// current token is empty
+	fmt.Fprintf(&sb, "function __%s_complete\n"+
+		"    set -l cur (commandline -ct)\n"+
+		"    %s (commandline -opc) \"$cur\"\n"+
+		"end\n", si.id, si.command(fishQuote, completeCommand))
+	fmt.Fprintf(&sb, "complete -c %s -a '(__%s_complete)'\n", fishQuote(si.prog), si.id)
+
+	return sb.String()
+}
+
+// powershell returns the script for PowerShell.
+func (si shellInit) powershell() string {
+	var sb strings.Builder
+
+	for _, name := range si.aliasNames() {
+		fmt.Fprintf(&sb, "function global:%s { & %s @args }\n",
+			name, si.command(psQuote, si.aliases[name]...))
+	}
+
+	if si.hook != nil {
+		fmt.Fprintf(&sb, "$global:__%[1]s_prompt = $function:prompt\n"+
+			"function global:prompt {\n"+
+			"    & %[2]s | Out-String | Invoke-Expression\n"+
+			"    & $global:__%[1]s_prompt\n"+
+			"}\n", si.id, si.command(psQuote, append(si.hook, "powershell")...))
+	}
+
+	fmt.Fprintf(&sb, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n"+
+		"    param($word, $ast, $cursor)\n"+
+		"    $words = @($ast.CommandElements | ForEach-Object { $_.ToString() })\n"+
+		"    if ($word -eq '') { $words += '' }\n"+
+		"    & %s @words | ForEach-Object {\n"+
+		"        [System.Management.Automation.CompletionResult]::new($_)\n"+
+		"    }\n"+
+		"}\n", psQuote(si.prog), si.command(psQuote, completeCommand))
+
+	return sb.String()
+}
+
+// shellIdent returns s with characters which are not valid in a shell
+// function name replaced by underscores.
+func shellIdent(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') {
+			return r
+		}
+
+		return '_'
+	}, s)
+}
+
+// isPlainWord reports whether s needs no quoting in any shell.
+func isPlainWord(s string) bool {
+	return s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyz"+
+		"ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./:=,+@%") == ""
+}
+
+// posixQuote quotes s for a POSIX shell if needed.
+func posixQuote(s string) string {
+	if isPlainWord(s) {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// fishQuote quotes s for fish if needed.
+func fishQuote(s string) string {
+	if isPlainWord(s) {
+		return s
+	}
+
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+}
+
+// psQuote quotes s for PowerShell if needed.
+func psQuote(s string) string {
+	if isPlainWord(s) && !strings.ContainsAny(s, "@,") {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Completions returns the completions of the last of words, which hold
+// a command line beginning with the name of the program. Subcommands,
+// flags of the FlagSet of the selected command, and values of flags
+// given to SetFlagValues or SetFlagCompleter are completed.
+func (c *Cmd) Completions(words []string) []string {
+	if len(words) < 2 {
+		return nil
+	}
+
+	partial := words[len(words)-1]
+	prior := words[1 : len(words)-1]
+
+	fs, cmds := c.FlagSet, c.commands
+
+	var pending string
+
+	for i := 0; i < len(prior); i++ {
+		w := prior[i]
+
+		if strings.HasPrefix(w, "-") && w != "-" && w != "--" {
+			name, _, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+
+			if f := fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+				if i+1 < len(prior) {
+					i++
+				} else {
+					pending = name
+				}
+			}
+
+			continue
+		}
+
+		if cmd, ok := cmds[w]; ok {
+			cmd.inheritFlags()
+			fs, cmds = cmd.FlagSet, cmd.commands
+		}
+	}
+
+	switch {
+	case pending != "":
+		return c.CompleteFlag(pending, partial)
+	case strings.HasPrefix(partial, "-") && strings.Contains(partial, "="):
+		name, v, _ := strings.Cut(partial, "=")
+
+		cands := c.CompleteFlag(strings.TrimLeft(name, "-"), v)
+		for i := range cands {
+			cands[i] = name + "=" + cands[i]
+		}
+
+		return cands
+	case strings.HasPrefix(partial, "-"):
+		return completeFlagNames(fs, partial)
+	default:
+		var cands []string
+
+		for name := range cmds {
+			if strings.HasPrefix(name, partial) {
+				cands = append(cands, name)
+			}
+		}
+
+		sort.Strings(cands)
+
+		return cands
+	}
+}
+
+// completeFlagNames returns the flags of fs beginning with partial,
+// using the same number of leading dashes as partial.
+func completeFlagNames(fs *flag.FlagSet, partial string) []string {
+	dashes := "-"
+	if strings.HasPrefix(partial, "--") {
+		dashes = "--"
+	}
+
+	var cands []string
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Name, partial[len(dashes):]) {
+			cands = append(cands, dashes+f.Name)
+		}
+	})
+
+	return cands
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestGenShellInit(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.AddShellAlias("j", "jump")
+	cmd.SetShellHook("hook")
+
+	for shell, exp := range map[string][]string{
+		"bash":       {"alias j='cli.test jump'\n", "$(cli.test hook bash)", "complete -o default -F _cli_test_complete cli.test\n"},
+		"zsh":        {"alias j='cli.test jump'\n", "$(cli.test hook zsh)", "compdef _cli_test_complete cli.test\n"},
+		"fish":       {"function j --wraps cli.test\n", "cli.test hook fish | source\n", "complete -c cli.test -a '(__cli_test_complete)'\n"},
+		"powershell": {"function global:j { & cli.test jump @args }\n", "& cli.test hook powershell", "-CommandName cli.test"},
+	} {
+		script, err := cmd.GenShellInit(shell)
+		if err != nil {
+			t.Fatal(shell, "unexpected error", err)
+		}
+
+		for _, s := range exp {
+			if !strings.Contains(script, s) {
+				t.Errorf("%s: expected script to contain %q, received:\n%s", shell, s, script)
+			}
+		}
+	}
+
+	_, err := cmd.GenShellInit("csh")
+	if !errors.Is(err, cli.ErrUnsupportedShell) {
+		t.Error("expected ErrUnsupportedShell, received", err)
+	}
+}
+
+func TestCompletions(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.FlagSet.Bool("verbose", false, "")
+	cmd.FlagSet.String("format", "", "")
+	cmd.SetFlagValues("format", "json", "text", "table")
+
+	remote := cmd.AddCommand("remote", "", nil)
+	remote.PersistentFlags.String("name", "", "")
+	remote.AddCommand("add", "", func([]string) error { return nil })
+	remote.AddCommand("remove", "", func([]string) error { return nil })
+	cmd.AddCommand("run", "", func([]string) error { return nil })
+
+	for _, tc := range []struct {
+		words []string
+		exp   []string
+	}{
+		{[]string{"tool", ""}, []string{"remote", "run"}},
+		{[]string{"tool", "r"}, []string{"remote", "run"}},
+		{[]string{"tool", "-v"}, []string{"-verbose"}},
+		{[]string{"tool", "--f"}, []string{"--format"}},
+		{[]string{"tool", "-format", "t"}, []string{"table", "text"}},
+		{[]string{"tool", "--format=j"}, []string{"--format=json"}},
+		{[]string{"tool", "-format", "json", "-verbose", "rem"}, []string{"remote"}},
+		{[]string{"tool", "remote", "re"}, []string{"remove"}},
+		{[]string{"tool", "remote", "add", "-"}, []string{"-name"}},
+		{[]string{"tool"}, nil},
+	} {
+		cands := cmd.Completions(tc.words)
+		if !reflect.DeepEqual(cands, tc.exp) {
+			t.Errorf("%q: expected %q, received %q", tc.words, tc.exp, cands)
+		}
+	}
+}
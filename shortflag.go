@@ -0,0 +1,115 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"flag"
+	"strings"
+)
+
+// boolFlag is implemented by flag values which do not take an argument,
+// see flag.Value.
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// expandShortFlags returns args with combined single letter flags such
+// as "-abc" split into "-a -b -c". An argument is only split if it does
+// not name a flag itself and each letter names a flag, all but the last
+// of which must be boolean. Arguments are examined up to "--" or the
+// first positional argument, or with interspersed parsing, up to "--"
+// or a positional argument for which stop returns true.
+func (c *Cmd) expandShortFlags(fs *flag.FlagSet, args []string, stop func(string) bool) []string {
+	out := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			if arg == "--" || !c.interspersed || stop(arg) {
+				return append(out, args[i:]...)
+			}
+
+			out = append(out, arg)
+
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		hasValue := strings.Contains(name, "=")
+		name, _, _ = strings.Cut(name, "=")
+
+		if f := fs.Lookup(name); f != nil {
+			out = append(out, arg)
+
+			// the following argument is the value of the flag
+			if !hasValue && !isBoolFlag(f) && i+1 < len(args) {
+				i++
+				out = append(out, args[i])
+			}
+
+			continue
+		}
+
+		split, last := splitShortFlags(fs, arg)
+		out = append(out, split...)
+
+		if last != nil && !isBoolFlag(last) && i+1 < len(args) {
+			i++
+			out = append(out, args[i])
+		}
+	}
+
+	return out
+}
+
+// splitShortFlags splits arg into single letter flags if possible,
+// returning the last flag. Otherwise arg is returned unchanged.
+func splitShortFlags(fs *flag.FlagSet, arg string) ([]string, *flag.Flag) {
+	if strings.HasPrefix(arg, "--") || len(arg) < 3 {
+		return []string{arg}, nil
+	}
+
+	letters := []rune(arg[1:])
+	split := make([]string, 0, len(letters))
+
+	var f *flag.Flag
+
+	for i, l := range letters {
+		f = fs.Lookup(string(l))
+		if f == nil || (i < len(letters)-1 && !isBoolFlag(f)) {
+			return []string{arg}, nil
+		}
+
+		split = append(split, "-"+string(l))
+	}
+
+	return split, f
+}
+
+// isBoolFlag reports whether f does not take an argument.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(boolFlag)
+
+	return ok && b.IsBoolFlag()
+}
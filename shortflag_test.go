@@ -0,0 +1,87 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestCombinedShortFlags(t *testing.T) {
+	tests := []struct {
+		args  []string
+		a, b  bool
+		o     string
+		rest  []string
+		mixed bool
+	}{
+		{[]string{"-ab", "x"}, true, true, "", []string{"x"}, false},
+		{[]string{"-abo", "out", "x"}, true, true, "out", []string{"x"}, false},
+		{[]string{"-o", "-ab", "x"}, false, false, "-ab", []string{"x"}, false},
+		{[]string{"x", "-ab"}, false, false, "", []string{"x", "-ab"}, false},
+		{[]string{"-a", "--", "-b"}, true, false, "", []string{"-b"}, false},
+		{[]string{"x", "-ba", "y"}, true, true, "", []string{"x", "y"}, true},
+		{[]string{"x", "--", "-ab"}, false, false, "", []string{"x", "-ab"}, true},
+		{[]string{"-ab=c"}, false, false, "", nil, false},
+	}
+
+	for _, tc := range tests {
+		var opts []cli.Option
+		if tc.mixed {
+			opts = append(opts, cli.WithInterspersedFlags())
+		}
+
+		cmd := cli.NewCmd(opts...)
+		cmd.FlagSet.Init("test", flag.ContinueOnError)
+		cmd.FlagSet.SetOutput(io.Discard)
+
+		a := cmd.FlagSet.Bool("a", false, "")
+		b := cmd.FlagSet.Bool("b", false, "")
+		o := cmd.FlagSet.String("o", "", "")
+
+		err := cmd.Parse(tc.args)
+		if tc.rest == nil {
+			if err == nil {
+				t.Errorf("%q: expected error, received nil", tc.args)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tc.args, err)
+
+			continue
+		}
+
+		if *a != tc.a || *b != tc.b || *o != tc.o ||
+			!reflect.DeepEqual(cmd.FlagSet.Args(), tc.rest) {
+			t.Errorf("%q: unexpected result %v %v %q %q",
+				tc.args, *a, *b, *o, cmd.FlagSet.Args())
+		}
+	}
+}
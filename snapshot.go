@@ -0,0 +1,163 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot is a copy of the output state of a TermPrinter, for use by
+// an alternate user interface such as a status page.
+type Snapshot struct {
+	// Live is the content of the live region, if any.
+	Live string
+
+	// Lines holds the most recent lines of printed output, oldest
+	// first, with Type set to EventPrint or EventError, or to
+	// EventProgress for lines of a live region which was left in place
+	// by later output.
+	Lines []Event
+}
+
+// snapshotState records output for Snapshot.
+type snapshotState struct {
+	m       sync.RWMutex
+	size    int
+	enabled atomic.Bool
+	live    string
+	lines   []Event
+	next    int
+	partial [2]string
+}
+
+// SetSnapshotSize enables recording of output for Snapshot, keeping up
+// to n lines of scrollback. A zero or negative value disables
+// recording and discards the recorded output.
+func (tp *TermPrinter) SetSnapshotSize(n int) {
+	ss := &tp.snapshot
+
+	ss.m.Lock()
+	defer ss.m.Unlock()
+
+	ss.size = max(n, 0)
+	ss.enabled.Store(ss.size > 0)
+	ss.live = ""
+	ss.lines = nil
+	ss.next = 0
+	ss.partial = [2]string{}
+}
+
+// Snapshot returns the current content of the live region and the most
+// recent lines of scrollback. Recording must first be enabled with
+// SetSnapshotSize, otherwise the Snapshot is empty.
+func (tp *TermPrinter) Snapshot() Snapshot {
+	ss := &tp.snapshot
+
+	ss.m.RLock()
+	defer ss.m.RUnlock()
+
+	s := Snapshot{
+		Live:  ss.live,
+		Lines: make([]Event, 0, len(ss.lines)),
+	}
+
+	s.Lines = append(s.Lines, ss.lines[ss.next:]...)
+	s.Lines = append(s.Lines, ss.lines[:ss.next]...)
+
+	return s
+}
+
+// snapshotEnabled reports whether output is being recorded, without
+// taking the lock, as it is checked on every write.
+func (tp *TermPrinter) snapshotEnabled() bool {
+	return tp.snapshot.enabled.Load()
+}
+
+// record adds output b on stream s to the snapshot state. Content for
+// the live region replaces the previous content, while other output is
+// split into lines and added to the scrollback. As on a terminal, the
+// live region is moved into the scrollback when other output follows
+// it.
+func (tp *TermPrinter) record(s Stream, b []byte, live bool) {
+	ss := &tp.snapshot
+
+	ss.m.Lock()
+	defer ss.m.Unlock()
+
+	if ss.size == 0 {
+		return
+	}
+
+	now := tp.now()
+
+	if live {
+		ss.live = string(b)
+
+		return
+	}
+
+	if ss.live != "" {
+		for _, l := range strings.SplitAfter(ss.live, "\n") {
+			if l != "" {
+				ss.add(Event{Time: now, Type: EventProgress, Stream: Stdout.String(),
+					Text: strings.TrimSuffix(l, "\n")})
+			}
+		}
+
+		ss.live = ""
+	}
+
+	typ := EventPrint
+	if s == Stderr {
+		typ = EventError
+	}
+
+	text := ss.partial[s] + string(b)
+
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+
+		ss.add(Event{Time: now, Type: typ, Stream: s.String(), Text: text[:i]})
+		text = text[i+1:]
+	}
+
+	ss.partial[s] = text
+}
+
+// add appends e to the ring of lines, replacing the oldest line once
+// the ring is full.
+func (ss *snapshotState) add(e Event) {
+	if len(ss.lines) < ss.size {
+		ss.lines = append(ss.lines, e)
+
+		return
+	}
+
+	ss.lines[ss.next] = e
+	ss.next = (ss.next + 1) % ss.size
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestSnapshot(t *testing.T) {
+	p := cli.NewTermPrinter()
+	p.SetStdout(new(bytes.Buffer))
+	p.SetStderr(new(bytes.Buffer))
+
+	p.Println("ignored")
+
+	if s := p.Snapshot(); s.Live != "" || len(s.Lines) != 0 {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+
+	p.SetSnapshotSize(3)
+
+	p.Print("one\ntw")
+	p.Print("o\n")
+	p.Lprintf("working %d\n", 1)
+	p.Lprintf("working %d\n", 2)
+
+	s := p.Snapshot()
+	if s.Live != "working 2\n" {
+		t.Errorf("unexpected live content %q", s.Live)
+	}
+
+	checkLines(t, s.Lines, "print:one", "print:two")
+
+	p.Eprintln("failed")
+	p.Println("four")
+
+	s = p.Snapshot()
+	if s.Live != "" {
+		t.Errorf("unexpected live content %q", s.Live)
+	}
+
+	checkLines(t, s.Lines, "progress:working 2", "error:failed", "print:four")
+
+	p.SetSnapshotSize(0)
+
+	if s := p.Snapshot(); s.Live != "" || len(s.Lines) != 0 {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+}
+
+func checkLines(t *testing.T, lines []cli.Event, exp ...string) {
+	t.Helper()
+
+	if len(lines) != len(exp) {
+		t.Fatalf("expected %d lines, received %+v", len(exp), lines)
+	}
+
+	for i, l := range lines {
+		if s := l.Type + ":" + l.Text; s != exp[i] {
+			t.Errorf("line %d: expected %q, received %q", i, exp[i], s)
+		}
+	}
+}
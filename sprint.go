@@ -0,0 +1,76 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sprint operates in the manner of fmt.Sprint, returning the string
+// which Print would write to Stdout, so that output formatting can be
+// tested without setting a writer.
+func (tp *TermPrinter) Sprint(v ...interface{}) string {
+	return tp.render(fmt.Sprint(v...))
+}
+
+// Sprintf operates in the manner of fmt.Sprintf, returning the string
+// which Printf would write to Stdout.
+func (tp *TermPrinter) Sprintf(f string, v ...interface{}) string {
+	return tp.render(fmt.Sprintf(f, v...))
+}
+
+// Sprintln operates in the manner of fmt.Sprintln, returning the string
+// which Println would write to Stdout.
+func (tp *TermPrinter) Sprintln(v ...interface{}) string {
+	return tp.render(fmt.Sprintln(v...))
+}
+
+// Lsprintf returns the content which Lprintf would draw in the live
+// region, truncated to fit the terminal if Stdout is a terminal. The
+// control sequences which clear the previous frame are not included.
+func (tp *TermPrinter) Lsprintf(f string, v ...interface{}) string {
+	s := tp.redactSecrets(fmt.Sprintf(f, v...))
+
+	if tp.outIsTerm && !tp.ciEnabled() {
+		w, h := tp.termSize()
+		s = string(clampLines([]byte(s), w, h-1))
+	}
+
+	return tp.sanitize(s)
+}
+
+// render applies the processing of writeMessage to s.
+func (tp *TermPrinter) render(s string) string {
+	return tp.sanitize(tp.filterLines(tp.redactSecrets(s)))
+}
+
+// sanitize replaces invalid UTF-8 sequences in s if UTF-8 output is
+// enabled, as the writers of the TermPrinter do.
+func (tp *TermPrinter) sanitize(s string) string {
+	if !tp.utf8 {
+		return s
+	}
+
+	return strings.ToValidUTF8(s, string(replacementChar))
+}
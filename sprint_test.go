@@ -0,0 +1,80 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"unicode/utf8"
+
+	"kreklow.us/go/cli"
+)
+
+func TestSprint(t *testing.T) {
+	p := cli.NewTermPrinter()
+	p.SetUTF8Output(true)
+
+	if s := p.Sprint("a", 1, "b\xff"); s != "a1b�" {
+		t.Errorf("unexpected Sprint %q", s)
+	}
+
+	if s := p.Sprintf("%d-%s", 2, "\xffc"); s != "2-�c" {
+		t.Errorf("unexpected Sprintf %q", s)
+	}
+
+	if s := p.Sprintln("x", "y"); s != "x y\n" {
+		t.Errorf("unexpected Sprintln %q", s)
+	}
+
+	if s := p.Lsprintf("%s\n", "live"); s != "live\n" {
+		t.Errorf("unexpected Lsprintf %q", s)
+	}
+}
+
+func FuzzSprintf(f *testing.F) {
+	f.Add("plain text")
+	f.Add("bad \xff\xfe bytes")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		outbuf := new(bytes.Buffer)
+
+		p := cli.NewTermPrinter()
+		p.SetStdout(outbuf)
+		p.SetUTF8Output(true)
+
+		exp := p.Sprintf("%s", s)
+
+		_, err := p.Printf("%s", s)
+		if err != nil {
+			t.Fatal("unexpected error", err)
+		}
+
+		if outbuf.String() != exp {
+			t.Errorf("Sprintf returned %q, Printf wrote %q", exp, outbuf)
+		}
+
+		if !utf8.ValidString(exp) {
+			t.Errorf("invalid UTF-8 in %q", exp)
+		}
+	})
+}
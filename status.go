@@ -0,0 +1,131 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Status displays a live line of overall progress for a Cmd.
+//
+// When the exit channel of the Cmd closes, the status line switches to
+// a shutdown message. Once all goroutines are done, Wait replaces the
+// status line with a final summary.
+type Status struct {
+	cmd *Cmd
+
+	m        sync.Mutex
+	msg      string
+	summary  string
+	exiting  bool
+	finished bool
+
+	done chan bool
+}
+
+// NewStatus returns a new Status for c and starts watching for Exit.
+func (c *Cmd) NewStatus() *Status {
+	s := &Status{
+		cmd:  c,
+		done: make(chan bool),
+	}
+
+	go func() {
+		select {
+		case <-c.C:
+			s.shutdown()
+		case <-s.done:
+		}
+	}()
+
+	return s
+}
+
+// Update sets the current status message in the manner of fmt.Printf.
+// Updates received after Exit is called are recorded but not displayed.
+func (s *Status) Update(f string, v ...interface{}) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.msg = fmt.Sprintf(f, v...)
+
+	if !s.exiting && !s.finished {
+		s.cmd.Lprintf("%s\n", s.msg)
+	}
+}
+
+// SetSummary sets the message printed by Wait when the Cmd exits
+// without error, in the manner of fmt.Printf. If no summary is set, the
+// last status message is used.
+func (s *Status) SetSummary(f string, v ...interface{}) {
+	s.m.Lock()
+	s.summary = fmt.Sprintf(f, v...)
+	s.m.Unlock()
+}
+
+// Wait calls Wait on the Cmd, then replaces the status line with the
+// summary message, or the error if one was passed to Exit. The return
+// value is the return value of the Cmd's Wait.
+func (s *Status) Wait() error {
+	err := s.cmd.Wait()
+
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if !s.finished {
+		close(s.done)
+	}
+
+	s.finished = true
+
+	switch {
+	case err != nil:
+		s.cmd.FinalizeLive(fmt.Sprintf("failed: %v", err))
+	case s.summary != "":
+		s.cmd.FinalizeLive(s.summary)
+	default:
+		s.cmd.FinalizeLive(s.msg)
+	}
+
+	return err
+}
+
+// shutdown replaces the status line with the shutdown message.
+func (s *Status) shutdown() {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.finished {
+		return
+	}
+
+	s.exiting = true
+
+	if atomic.LoadInt64(&s.cmd.timeout) > 0 {
+		s.cmd.lprintf(true, "shutting down (press Ctrl-C again to force)...\n")
+	} else {
+		s.cmd.lprintf(true, "shutting down...\n")
+	}
+}
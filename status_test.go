@@ -0,0 +1,112 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func ExampleStatus() {
+	cmd := cli.NewCmd()
+	status := cmd.NewStatus()
+
+	cmd.Add(1)
+
+	go func() {
+		defer cmd.Done()
+
+		for i := 1; i <= 3; i++ {
+			status.Update("processed %d/3", i)
+		}
+
+		status.SetSummary("processed 3 items")
+	}()
+
+	err := status.Wait()
+	if err != nil {
+		cmd.Eprintln(err)
+	}
+
+	// Output:
+	// processed 1/3
+	// processed 2/3
+	// processed 3/3
+	// processed 3 items
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("Summary", testStatusSummary)
+	t.Run("Error", testStatusError)
+}
+
+func testStatusSummary(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	status := cmd.NewStatus()
+
+	cmd.Add(1)
+	status.Update("working %d", 1)
+	cmd.Done()
+
+	err := status.Wait()
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if outbuf.String() != "working 1\nworking 1\n" {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testStatusError(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+
+	status := cmd.NewStatus()
+
+	cmd.Add(1)
+	status.Update("working")
+	cmd.Exit(errTest)
+
+	time.Sleep(50 * time.Millisecond)
+	status.Update("ignored")
+	cmd.Done()
+
+	err := status.Wait()
+	if err == nil {
+		t.Error("expected error, received nil")
+	}
+
+	if outbuf.String() != "working\nshutting down...\nfailed: testing error\n" {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
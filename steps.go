@@ -0,0 +1,183 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"sync"
+	"time"
+)
+
+// spinnerFrames are the frames of the step spinner.
+//
+//nolint:gochecknoglobals // read-only animation frames
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// spinnerInterval is the time between spinner frames.
+const spinnerInterval = 100 * time.Millisecond
+
+// Steps runs a sequence of named steps, displaying the status of each.
+//
+// On a terminal, the running step is shown with a spinner which is
+// replaced by a check mark or cross when the step completes. Otherwise
+// a line is printed as each step starts and finishes.
+//
+// By default, once a step fails, later steps are skipped. Use
+// SetContinue to run every step regardless.
+type Steps struct {
+	tp *TermPrinter
+
+	m        sync.Mutex
+	cont     bool
+	err      error
+	failures int
+}
+
+// Steps returns a new Steps printing to tp.
+func (tp *TermPrinter) Steps() *Steps {
+	return &Steps{tp: tp}
+}
+
+// SetContinue sets whether steps continue to run after a step fails.
+func (s *Steps) SetContinue(cont bool) {
+	s.m.Lock()
+	s.cont = cont
+	s.m.Unlock()
+}
+
+// Run runs fn as a step named name. If a previous step has failed and
+// SetContinue has not been enabled, fn is not called. The return value
+// is the error returned by fn, or the error of the failed step if fn
+// was skipped.
+func (s *Steps) Run(name string, fn func() error) error {
+	if err := s.skip(); err != nil {
+		s.tp.Printf("- %s (skipped)\n", name)
+
+		return err
+	}
+
+	e := ProgressEvent{ID: s.tp.progressID(), Label: name}
+	s.tp.reportProgress(progressStart, e)
+
+	var err error
+
+	if s.tp.outIsTerm && !s.tp.ciEnabled() {
+		err = s.runLive(name, fn)
+	} else {
+		s.tp.Printf("  %s...\n", name)
+
+		err = fn()
+	}
+
+	if err != nil {
+		s.tp.Printf("%s %s: %v\n", s.tp.Colorize(Red, "✗"), name, err)
+		s.fail(err)
+	} else {
+		s.tp.Printf("%s %s\n", s.tp.Colorize(Green, "✓"), name)
+	}
+
+	e.Err = err
+	s.tp.reportProgress(progressFinish, e)
+
+	return err
+}
+
+// skip returns the error of the first failed step if later steps
+// should be skipped.
+func (s *Steps) skip() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	if s.cont {
+		return nil
+	}
+
+	return s.err
+}
+
+// fail records a failed step.
+func (s *Steps) fail(err error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	s.failures++
+
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// Err returns the error of the first failed step, or nil.
+func (s *Steps) Err() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.err
+}
+
+// Failures returns the number of steps which failed.
+func (s *Steps) Failures() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+
+	return s.failures
+}
+
+// runLive calls fn while animating a spinner in the live region.
+func (s *Steps) runLive(name string, fn func() error) error {
+	done := make(chan bool)
+	stopped := make(chan bool)
+
+	go func() {
+		defer close(stopped)
+
+		tick, stop := s.tp.frames(spinnerInterval)
+		defer stop()
+
+		for i := 0; ; i++ {
+			s.tp.lprintf(false, "%s %s\n", spinnerFrames[i%len(spinnerFrames)], name)
+
+			select {
+			case <-tick:
+			case <-done:
+				return
+			}
+
+			if s.tp.animationIdle() {
+				select {
+				case <-s.tp.idleWait():
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	err := fn()
+
+	close(done)
+	<-stopped
+
+	s.tp.clearLiveLines()
+
+	return err
+}
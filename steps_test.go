@@ -0,0 +1,139 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	expect "github.com/Netflix/go-expect"
+	"kreklow.us/go/cli"
+)
+
+func TestSteps(t *testing.T) {
+	t.Run("Abort", testStepsAbort)
+	t.Run("Continue", testStepsContinue)
+	t.Run("Console", testStepsConsole)
+}
+
+func testStepsAbort(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+
+	steps := p.Steps()
+
+	_ = steps.Run("Compiling", func() error { return nil })
+
+	err := steps.Run("Uploading", func() error { return errTest })
+	if !errors.Is(err, errTest) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = steps.Run("Notifying", func() error {
+		t.Error("unexpected call to skipped step")
+
+		return nil
+	})
+	if !errors.Is(err, errTest) || !errors.Is(steps.Err(), errTest) {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "  Compiling...\n✓ Compiling\n" +
+		"  Uploading...\n✗ Uploading: testing error\n" +
+		"- Notifying (skipped)\n"
+	if outbuf.String() != exp {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testStepsContinue(t *testing.T) {
+	p := cli.NewTermPrinter()
+	p.SetStdout(new(bytes.Buffer))
+
+	steps := p.Steps()
+	steps.SetContinue(true)
+
+	count := 0
+
+	for i := 0; i < 3; i++ {
+		_ = steps.Run("step", func() error {
+			count++
+
+			return errTest
+		})
+	}
+
+	if count != 3 || steps.Failures() != 3 {
+		t.Error("expected 3 failed steps, got", count, steps.Failures())
+	}
+}
+
+func testStepsConsole(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	var outstr string
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		outstr, err = cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetColorMode(cli.ColorNever)
+
+	_ = p.Steps().Run("Compiling", func() error {
+		time.Sleep(150 * time.Millisecond)
+
+		return nil
+	})
+
+	p.Print("END")
+
+	wg.Wait()
+
+	if !strings.HasPrefix(outstr, "| Compiling\r\n\x1b[1A\x1b[2K/ Compiling\r\n") ||
+		!strings.HasSuffix(outstr, "\x1b[1A\x1b[2K✓ Compiling\r\nEND") {
+		t.Errorf("unexpected output %q", outstr)
+	}
+}
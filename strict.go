@@ -0,0 +1,194 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownConfigKey is returned in strict mode when the config file
+// sets a name which is not a flag.
+var ErrUnknownConfigKey = errors.New("unknown config key")
+
+// ErrUnknownEnv is returned in strict mode when an environment variable
+// with the prefix of the application does not correspond to a flag.
+var ErrUnknownEnv = errors.New("unknown environment variable")
+
+// WithStrictConfig makes unknown names in the config file an error,
+// rather than ignoring them, so that a misspelled setting is not
+// silently lost. If envPrefix is not empty, environment variables
+// beginning with it, such as "MYTOOL_" in "MYTOOL_LOG_LEVEL", must
+// also name a flag, with underscores in place of dashes. The errors
+// suggest similar names where there are any.
+//
+// The checks are made by Parse and OnConfigReload, and by
+// ValidateConfig, which also reports invalid values.
+func WithStrictConfig(envPrefix string) Option {
+	return func(c *Cmd) {
+		c.strictConfig = true
+		c.envPrefix = envPrefix
+	}
+}
+
+// ValidateConfig checks the config file and environment in the manner
+// of WithStrictConfig, and checks that each value in the config file is
+// valid for its flag, without changing any flags. All of the problems
+// found are returned, joined with errors.Join.
+func (c *Cmd) ValidateConfig() error {
+	path := c.ConfigFile()
+	if path == "" {
+		return ErrNoHome
+	}
+
+	entries, err := readConfig(path)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, e := range entries {
+		f := c.FlagSet.Lookup(e.name)
+		if f == nil {
+			err = c.unknownConfigKey(e.name)
+		} else {
+			err = checkFlagValue(f, e.value)
+			if err == nil {
+				err = c.validateFlag(e.name, e.value)
+			}
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s:%d: %w", ErrConfig, path, e.line, err))
+		}
+	}
+
+	errs = append(errs, c.checkEnv()...)
+
+	return errors.Join(errs...)
+}
+
+// AddConfigValidateCommand adds a "config validate" subcommand which
+// calls ValidateConfig, for checking configuration in CI. It is added
+// to an existing "config" command, if there is one.
+func (c *Cmd) AddConfigValidateCommand() *Command {
+	group, ok := c.commands["config"]
+	if !ok {
+		group = c.AddCommand("config", "manage configuration", nil)
+	}
+
+	return group.AddCommand("validate", "check the config file and environment", func([]string) error {
+		err := c.ValidateConfig()
+		if err != nil {
+			return err
+		}
+
+		c.Printf("%s is valid\n", c.ConfigFile())
+
+		return nil
+	})
+}
+
+// strictEntries returns an error for the first entry which is not a
+// flag, in strict mode.
+func (c *Cmd) strictEntries(path string, entries []configEntry) error {
+	if !c.strictConfig {
+		return nil
+	}
+
+	for _, e := range entries {
+		if c.FlagSet.Lookup(e.name) == nil {
+			return fmt.Errorf("%w: %s:%d: %w", ErrConfig, path, e.line, c.unknownConfigKey(e.name))
+		}
+	}
+
+	return nil
+}
+
+// strictEnv returns the first error found by checkEnv, in strict mode.
+func (c *Cmd) strictEnv() error {
+	if !c.strictConfig {
+		return nil
+	}
+
+	if errs := c.checkEnv(); len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// unknownConfigKey returns an error for the unknown config key name,
+// suggesting similar flag names.
+func (c *Cmd) unknownConfigKey(name string) error {
+	var names []*Command
+
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		names = append(names, &Command{Name: f.Name})
+	})
+
+	return unknownName(ErrUnknownConfigKey, name, names)
+}
+
+// checkEnv returns an error for each environment variable with the
+// prefix which does not name a flag, sorted by name.
+func (c *Cmd) checkEnv() []error {
+	if c.envPrefix == "" {
+		return nil
+	}
+
+	var names []*Command
+
+	c.FlagSet.VisitAll(func(f *flag.Flag) {
+		names = append(names, &Command{Name: c.flagEnv(f.Name)})
+	})
+
+	var errs []error
+
+	for _, kv := range os.Environ() {
+		k, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(k, c.envPrefix) || k == c.envPrefix {
+			continue
+		}
+
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(k, c.envPrefix)), "_", "-")
+		if c.FlagSet.Lookup(name) == nil {
+			errs = append(errs, unknownName(ErrUnknownEnv, k, names))
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return errs
+}
+
+// flagEnv returns the environment variable corresponding to the named
+// flag.
+func (c *Cmd) flagEnv(name string) string {
+	return c.envPrefix + strings.ReplaceAll(strings.ToUpper(name), "-", "_")
+}
@@ -0,0 +1,122 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestStrictConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	err := os.WriteFile(path, []byte("name = x\nlevle = 3\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd := cli.NewCmd(cli.WithStrictConfig(""))
+	cmd.SetConfigFile(path)
+	cmd.FlagSet.String("name", "", "name")
+	cmd.FlagSet.Int("level", 0, "level")
+
+	err = cmd.Parse(nil)
+	if !errors.Is(err, cli.ErrConfig) || !errors.Is(err, cli.ErrUnknownConfigKey) {
+		t.Fatal("expected ErrUnknownConfigKey, received", err)
+	}
+
+	if !strings.HasSuffix(err.Error(), ":2: unknown config key 'levle'; did you mean 'level'?") {
+		t.Errorf("unexpected error %q", err)
+	}
+}
+
+func TestStrictConfigEnv(t *testing.T) {
+	t.Setenv("TOOL_LOG_LEVEL", "debug")
+	t.Setenv("TOOL_LOG_LEVLE", "debug")
+
+	cmd := cli.NewCmd(cli.WithStrictConfig("TOOL_"))
+	cmd.FlagSet.Init("tool", flag.ContinueOnError)
+	cmd.FlagSet.String("log-level", "info", "log level")
+
+	err := cmd.Parse(nil)
+	if !errors.Is(err, cli.ErrUnknownEnv) {
+		t.Fatal("expected ErrUnknownEnv, received", err)
+	}
+
+	expect := "unknown environment variable 'TOOL_LOG_LEVLE'; did you mean 'TOOL_LOG_LEVEL'?"
+	if err.Error() != expect {
+		t.Errorf("expected %q, received %q", expect, err)
+	}
+}
+
+func TestConfigValidateCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.SetConfigFile(path)
+	cmd.FlagSet.Int("level", 0, "level")
+	cmd.SetFlagValues("mode", "fast", "safe")
+	cmd.FlagSet.String("mode", "safe", "mode")
+	cmd.AddConfigValidateCommand()
+
+	err := os.WriteFile(path, []byte("level = 3\nmode = fast\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cmd.Dispatch([]string{"config", "validate"})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if outbuf.String() != path+" is valid\n" {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+
+	err = os.WriteFile(path, []byte("level = high\nmode = slow\nother = 1\n"), 0o600)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	err = cmd.Dispatch([]string{"config", "validate"})
+
+	for _, target := range []error{cli.ErrConfig, cli.ErrInvalidValue, cli.ErrUnknownConfigKey} {
+		if !errors.Is(err, target) {
+			t.Errorf("expected %v, received %v", target, err)
+		}
+	}
+
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 3 {
+		t.Errorf("expected three problems, received %q", err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxSuggestDistance is the largest edit distance between an unknown
+// command and a subcommand name for the name to be suggested.
+const maxSuggestDistance = 2
+
+// suggestCommands returns the names of the commands in cmds which are
+// similar to name, being within a small edit distance of it or
+// beginning with it.
+func suggestCommands(name string, cmds []*Command) []string {
+	var s []string
+
+	for _, cmd := range cmds {
+		if editDistance(name, cmd.Name) <= maxSuggestDistance ||
+			(name != "" && strings.HasPrefix(cmd.Name, name)) {
+			s = append(s, cmd.Name)
+		}
+	}
+
+	return s
+}
+
+// unknownCommand returns an error reporting that name is not one of
+// cmds, suggesting similar names if there are any.
+func unknownCommand(name string, cmds []*Command) error {
+	return unknownName(ErrUnknownCommand, name, cmds)
+}
+
+// unknownName returns err reporting that name is not one of cmds,
+// suggesting similar names if there are any.
+func unknownName(err error, name string, cmds []*Command) error {
+	s := suggestCommands(name, cmds)
+	if len(s) == 0 {
+		return fmt.Errorf("%w '%s'", err, name)
+	}
+
+	return fmt.Errorf("%w '%s'; did you mean '%s'?",
+		err, name, strings.Join(s, "' or '"))
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
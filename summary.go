@@ -0,0 +1,128 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Summary describes a completed run of a Cmd, as printed by
+// PrintSummary.
+type Summary struct {
+	// Reason is the exit reason recorded by Run, such as
+	// ExitCompleted, or derived from the error passed to Exit if Run
+	// was not used.
+	Reason string `json:"reason"`
+
+	// ExitCode is the exit status returned by Run.
+	ExitCode int `json:"exit_code"`
+
+	// Elapsed is the wall time since the Cmd was created.
+	Elapsed time.Duration `json:"elapsed_ns"`
+
+	// PeakMemory is the peak memory use of the process in bytes, or
+	// zero if unknown.
+	PeakMemory int64 `json:"peak_memory"`
+
+	// Warnings and Errors are the numbers of warnings and errors
+	// printed, as returned by Counts.
+	Warnings int64 `json:"warnings"`
+	Errors   int64 `json:"errors"`
+}
+
+// WithSummary makes Run print the summary with PrintSummary once the
+// Cmd has exited.
+func WithSummary() Option {
+	return func(c *Cmd) {
+		c.autoSummary = true
+	}
+}
+
+// Summary returns a summary of the run. It should be called after Wait,
+// or after Run returns.
+func (c *Cmd) Summary() Summary {
+	counts := c.Counts()
+
+	s := Summary{
+		Reason:     c.exitReason,
+		ExitCode:   c.exitCode,
+		Elapsed:    c.clock().Now().Sub(c.start),
+		PeakMemory: peakMemory(),
+		Warnings:   counts.Warnings,
+		Errors:     counts.Errors,
+	}
+
+	if s.Reason == "" {
+		s.Reason, s.ExitCode = ExitCompleted, exitCode(c.ExitHandler.err)
+
+		if c.ExitHandler.err != nil {
+			s.Reason = ExitFailed
+		}
+	}
+
+	if c.deterministic {
+		s.Elapsed, s.PeakMemory = 0, 0
+	}
+
+	return s
+}
+
+// PrintSummary prints the Summary to Stderr as a footer, such as
+// "completed in 1.2s, peak memory 24.0 MiB, 1 warning, 0 errors". If
+// the --output flag selects json, the summary is printed as a JSON
+// object instead.
+func (c *Cmd) PrintSummary() {
+	s := c.Summary()
+
+	if c.OutputFormat() == FormatJSON {
+		b, _ := json.Marshal(s)
+		c.Eprintf("%s\n", b)
+
+		return
+	}
+
+	msg := fmt.Sprintf("%s in %s", s.Reason, s.Elapsed.Round(time.Millisecond))
+
+	if s.ExitCode != 0 {
+		msg += fmt.Sprintf(" (exit status %d)", s.ExitCode)
+	}
+
+	if s.PeakMemory > 0 {
+		msg += ", peak memory " + c.FormatBytes(s.PeakMemory)
+	}
+
+	msg += ", " + plural(s.Warnings, "warning") + ", " + plural(s.Errors, "error")
+
+	c.Eprintln(msg)
+}
+
+// plural returns n followed by noun, adding an s unless n is one.
+func plural(n int64, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
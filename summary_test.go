@@ -0,0 +1,116 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
+)
+
+func TestPrintSummary(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd(cli.WithSummary())
+	cmd.FlagSet.Init("test", flag.ContinueOnError)
+	cmd.SetStderr(errbuf)
+	cmd.SetDeterministic(true)
+
+	code := cmd.Run(nil, func(context.Context, []string) error {
+		return errTest
+	})
+
+	if code != 1 {
+		t.Errorf("expected code 1, received %d", code)
+	}
+
+	expect := "error in 0s (exit status 1), 0 warnings, 1 error\n"
+	if !strings.HasSuffix(errbuf.String(), expect) {
+		t.Errorf("expected summary %q, received %q", expect, errbuf)
+	}
+}
+
+func TestPrintSummaryJSON(t *testing.T) {
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd(cli.WithSummary())
+	cmd.FlagSet.Init("test", flag.ContinueOnError)
+	cmd.SetStderr(errbuf)
+	cmd.AddOutputFlag()
+
+	cmd.Run([]string{"-output", "json"}, func(context.Context, []string) error {
+		return nil
+	})
+
+	var s cli.Summary
+
+	err := json.Unmarshal(errbuf.Bytes(), &s)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if s.Reason != cli.ExitCompleted || s.ExitCode != 0 || s.Elapsed <= 0 || s.Errors != 0 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+}
+
+func TestSummaryWithoutRun(t *testing.T) {
+	cmd := cli.NewCmd()
+	cmd.Add(1)
+	cmd.Exit(errTest)
+	cmd.Done()
+
+	_ = cmd.Wait()
+
+	s := cmd.Summary()
+	if s.Reason != cli.ExitFailed || s.ExitCode != 1 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+}
+
+func TestSummaryClock(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Unix(0, 0))
+
+	cmd := cli.NewCmd()
+	cmd.SetClock(clk)
+
+	clk.Advance(90 * time.Second)
+
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+
+	_ = cmd.Wait()
+
+	s := cmd.Summary()
+	if s.Elapsed != 90*time.Second {
+		t.Error("expected 1m30s elapsed, received", s.Elapsed)
+	}
+}
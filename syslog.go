@@ -0,0 +1,135 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Priorities of system log messages, as defined by syslog.
+const (
+	priorityCrit    = 2
+	priorityErr     = 3
+	priorityWarning = 4
+	priorityInfo    = 6
+	priorityDebug   = 7
+)
+
+// systemLogger sends messages to the system log.
+type systemLogger interface {
+	log(priority int, msg string) error
+}
+
+// LogToSystem redirects Stderr to the system log, using the systemd
+// journal if it is available and syslog otherwise, with messages tagged
+// with the given name. This suits tools which run as services. Each
+// line is sent as a separate message, with a priority chosen from its
+// prefix: "fatal:" and "panic:" are critical, "error:" is an error,
+// "warning:" and "warn:" are warnings, "debug:" is debug, and all other
+// lines are informational. ErrNotSupported is returned on platforms
+// without a system log.
+func (c *Cmd) LogToSystem(tag string) error {
+	l, err := dialSystemLog(tag)
+	if err != nil {
+		return err
+	}
+
+	c.SetStderr(&systemLogWriter{l: l})
+
+	return nil
+}
+
+// AutoSystemLog calls LogToSystem if Stderr is not a terminal and the
+// environment variable env is set to a true value, as understood by
+// strconv.ParseBool. A service can then opt in to the system log from
+// its unit file, while interactive use is unaffected.
+func (c *Cmd) AutoSystemLog(tag string, env string) error {
+	if c.errIsTerm {
+		return nil
+	}
+
+	if on, _ := strconv.ParseBool(os.Getenv(env)); !on {
+		return nil
+	}
+
+	return c.LogToSystem(tag)
+}
+
+// systemLogWriter sends each line written to the system log.
+type systemLogWriter struct {
+	m       sync.Mutex
+	l       systemLogger
+	partial []byte
+}
+
+// Write sends each complete line in b to the system log, keeping any
+// partial line until it is completed by a later write.
+func (sw *systemLogWriter) Write(b []byte) (int, error) {
+	sw.m.Lock()
+	defer sw.m.Unlock()
+
+	sw.partial = append(sw.partial, b...)
+
+	for {
+		i := bytes.IndexByte(sw.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := string(sw.partial[:i])
+		sw.partial = sw.partial[i+1:]
+
+		if line == "" {
+			continue
+		}
+
+		err := sw.l.log(linePriority(line), line)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// linePriority returns the priority of a line from its prefix.
+func linePriority(line string) int {
+	l := strings.ToLower(line)
+
+	switch {
+	case strings.HasPrefix(l, "fatal:"), strings.HasPrefix(l, "panic:"):
+		return priorityCrit
+	case strings.HasPrefix(l, "error:"):
+		return priorityErr
+	case strings.HasPrefix(l, "warning:"), strings.HasPrefix(l, "warn:"):
+		return priorityWarning
+	case strings.HasPrefix(l, "debug:"):
+		return priorityDebug
+	default:
+		return priorityInfo
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//go:build unix && !plan9
+
+package cli
+
+import (
+	"fmt"
+	"log/syslog"
+	"net"
+	"strings"
+)
+
+// journalSocket is the path of the systemd journal socket.
+const journalSocket = "/run/systemd/journal/socket"
+
+// dialSystemLog connects to the systemd journal, or to syslog if the
+// journal is not available.
+func dialSystemLog(tag string) (systemLogger, error) {
+	conn, err := net.Dial("unixgram", journalSocket)
+	if err == nil {
+		return &journalLogger{conn: conn, tag: tag}, nil
+	}
+
+	w, err := syslog.New(syslog.LOG_USER|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return syslogLogger{w: w}, nil
+}
+
+// journalLogger sends messages to the systemd journal using its native
+// protocol.
+type journalLogger struct {
+	conn net.Conn
+	tag  string
+}
+
+// log sends msg with the given priority.
+func (jl *journalLogger) log(priority int, msg string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "PRIORITY=%d\n", priority)
+	fmt.Fprintf(&b, "SYSLOG_IDENTIFIER=%s\n", jl.tag)
+	fmt.Fprintf(&b, "MESSAGE=%s\n", msg)
+
+	_, err := jl.conn.Write([]byte(b.String()))
+
+	return err
+}
+
+// syslogLogger sends messages to syslog.
+type syslogLogger struct {
+	w *syslog.Writer
+}
+
+// log sends msg with the given priority.
+func (sl syslogLogger) log(priority int, msg string) error {
+	switch priority {
+	case priorityCrit:
+		return sl.w.Crit(msg)
+	case priorityErr:
+		return sl.w.Err(msg)
+	case priorityWarning:
+		return sl.w.Warning(msg)
+	case priorityDebug:
+		return sl.w.Debug(msg)
+	default:
+		return sl.w.Info(msg)
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// columnGap is the space between table columns.
+const columnGap = "  "
+
+// Column describes a column of a Table.
+type Column struct {
+	// Name is the column heading.
+	Name string
+
+	// Priority determines which columns are reduced first when the
+	// table is too wide for the terminal. Columns with lower priority
+	// are reduced first, columns with equal priority are reduced from
+	// right to left.
+	Priority int
+
+	// MinWidth is the narrowest width a column may be truncated to
+	// rather than dropped. A zero value never truncates the column.
+	MinWidth int
+}
+
+// Table formats rows of text into aligned columns.
+//
+// When a table is wider than the available width, columns are reduced
+// in order of priority, so the most important information remains
+// legible. A column is truncated if that alone makes the table fit
+// without going below its minimum width, otherwise it is dropped.
+type Table struct {
+	cols []Column
+	rows [][]string
+}
+
+// NewTable returns a new Table with the given columns.
+func NewTable(cols ...Column) *Table {
+	return &Table{cols: cols}
+}
+
+// AddRow adds a row of cells to the table. Missing cells are left
+// empty and extra cells are ignored.
+func (t *Table) AddRow(cells ...string) {
+	row := make([]string, len(t.cols))
+	copy(row, cells)
+
+	t.rows = append(t.rows, row)
+}
+
+// Render returns the table formatted to fit within width. A zero or
+// negative width is unlimited.
+func (t *Table) Render(width int) string {
+	widths := t.fit(width)
+
+	var sb strings.Builder
+
+	writeRow(&sb, widths, t.headings())
+
+	for _, row := range t.rows {
+		writeRow(&sb, widths, row)
+	}
+
+	return sb.String()
+}
+
+// MarshalJSON encodes the table as a list of objects, one per row, with
+// the cells keyed by column name.
+func (t *Table) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('[')
+
+	for i, row := range t.rows {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		buf.WriteByte('{')
+
+		for j, c := range t.cols {
+			if j > 0 {
+				buf.WriteByte(',')
+			}
+
+			k, _ := json.Marshal(c.Name)
+			v, _ := json.Marshal(row[j])
+
+			buf.Write(k)
+			buf.WriteByte(':')
+			buf.Write(v)
+		}
+
+		buf.WriteByte('}')
+	}
+
+	buf.WriteByte(']')
+
+	return buf.Bytes(), nil
+}
+
+// PrintTable prints t to Stdout, fitted to the width of the terminal.
+func (tp *TermPrinter) PrintTable(t *Table) (int, error) {
+	w, _ := tp.termSize()
+
+	return tp.Print(t.Render(w))
+}
+
+// headings returns the column names as a row.
+func (t *Table) headings() []string {
+	row := make([]string, len(t.cols))
+
+	for i, c := range t.cols {
+		row[i] = c.Name
+	}
+
+	return row
+}
+
+// fit returns the width of each column, where a negative width is a
+// dropped column.
+func (t *Table) fit(width int) []int {
+	widths := make([]int, len(t.cols))
+
+	for i, c := range t.cols {
+		widths[i] = textWidth(c.Name)
+
+		for _, row := range t.rows {
+			widths[i] = max(widths[i], textWidth(row[i]))
+		}
+	}
+
+	if width <= 0 {
+		return widths
+	}
+
+	order := make([]int, len(t.cols))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := t.cols[order[a]].Priority, t.cols[order[b]].Priority
+		if pa != pb {
+			return pa < pb
+		}
+
+		return order[a] > order[b]
+	})
+
+	// truncate each column if that is enough to fit, otherwise drop it
+	for n, i := range order {
+		excess := totalWidth(widths) - width
+		if excess <= 0 {
+			break
+		}
+
+		if m := t.cols[i].MinWidth; m > 0 && widths[i]-excess >= m {
+			widths[i] -= excess
+
+			break
+		}
+
+		// always leave at least one column
+		if n == len(order)-1 {
+			widths[i] = max(1, widths[i]-excess)
+
+			break
+		}
+
+		widths[i] = -1
+	}
+
+	return widths
+}
+
+// writeRow writes the cells of row padded or truncated to widths.
+func writeRow(sb *strings.Builder, widths []int, row []string) {
+	var line strings.Builder
+
+	first := true
+
+	for i, w := range widths {
+		if w < 0 {
+			continue
+		}
+
+		if !first {
+			line.WriteString(columnGap)
+		}
+
+		first = false
+
+		cell := truncate(row[i], w)
+		line.WriteString(cell)
+		line.WriteString(strings.Repeat(" ", w-textWidth(cell)))
+	}
+
+	sb.WriteString(strings.TrimRight(line.String(), " "))
+	sb.WriteByte('\n')
+}
+
+// totalWidth returns the width of a row with the given column widths.
+func totalWidth(widths []int) int {
+	n := 0
+	total := 0
+
+	for _, w := range widths {
+		if w < 0 {
+			continue
+		}
+
+		n++
+		total += w
+	}
+
+	if n > 1 {
+		total += (n - 1) * len(columnGap)
+	}
+
+	return total
+}
+
+// textWidth returns the number of terminal columns used by s.
+func textWidth(s string) int {
+	return VisibleLength(s)
+}
+
+// truncate shortens s to width w, marking the truncation with an
+// ellipsis.
+func truncate(s string, w int) string {
+	if textWidth(s) <= w {
+		return s
+	}
+
+	if w < 1 {
+		return ""
+	}
+
+	return truncateWidth(s, w-1) + "…"
+}
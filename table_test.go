@@ -0,0 +1,103 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func ExampleTable() {
+	t := cli.NewTable(
+		cli.Column{Name: "ID", Priority: 3},
+		cli.Column{Name: "IMAGE", Priority: 1, MinWidth: 8},
+		cli.Column{Name: "STATUS", Priority: 2},
+		cli.Column{Name: "PORTS", Priority: 0},
+	)
+
+	t.AddRow("a1b2c3", "registry.example.com/app:1.2", "Up 2 hours", "0.0.0.0:80->80/tcp")
+	t.AddRow("d4e5f6", "postgres:16", "Up 3 days")
+
+	fmt.Print(t.Render(0))
+	fmt.Println()
+	fmt.Print(t.Render(40))
+
+	// Output:
+	// ID      IMAGE                         STATUS      PORTS
+	// a1b2c3  registry.example.com/app:1.2  Up 2 hours  0.0.0.0:80->80/tcp
+	// d4e5f6  postgres:16                   Up 3 days
+	//
+	// ID      IMAGE                 STATUS
+	// a1b2c3  registry.example.co…  Up 2 hours
+	// d4e5f6  postgres:16           Up 3 days
+}
+
+func TestTable(t *testing.T) {
+	tbl := cli.NewTable(
+		cli.Column{Name: "NAME", Priority: 1},
+		cli.Column{Name: "DESCRIPTION", MinWidth: 5},
+	)
+
+	tbl.AddRow("alpha", "the first letter")
+	tbl.AddRow("beta", "the second letter", "ignored")
+
+	tests := []struct {
+		width int
+		exp   string
+	}{
+		{30, "NAME   DESCRIPTION\nalpha  the first letter\nbeta   the second letter\n"},
+		{20, "NAME   DESCRIPTION\nalpha  the first le…\nbeta   the second l…\n"},
+		{10, "NAME\nalpha\nbeta\n"},
+		{2, "N…\na…\nb…\n"},
+	}
+
+	for _, tc := range tests {
+		s := tbl.Render(tc.width)
+		if s != tc.exp {
+			t.Errorf("unexpected output at width %d: %q", tc.width, s)
+		}
+	}
+}
+
+func TestTableWide(t *testing.T) {
+	tbl := cli.NewTable(
+		cli.Column{Name: "NAME", Priority: 1},
+		cli.Column{Name: "CITY", MinWidth: 3},
+	)
+
+	tbl.AddRow("山田", "東京都")
+	tbl.AddRow("José", "Zürich")
+	tbl.AddRow("👍🏽 ok", "x")
+
+	exp := "NAME   CITY\n山田   東京都\nJosé   Zürich\n👍🏽 ok  x\n"
+	if s := tbl.Render(0); s != exp {
+		t.Errorf("expected %q, received %q", exp, s)
+	}
+
+	exp = "NAME   CITY\n山田   東…\nJosé   Zür…\n👍🏽 ok  x\n"
+	if s := tbl.Render(11); s != exp {
+		t.Errorf("expected %q, received %q", exp, s)
+	}
+}
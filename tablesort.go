@@ -0,0 +1,206 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownColumn is returned when a sort or filter refers to a column
+// which does not exist in the table.
+var ErrUnknownColumn = errors.New("unknown column")
+
+// ErrInvalidFilter is returned when a filter expression cannot be
+// parsed.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// SortBy sorts the rows of the table by the named column. Cells which
+// are both numbers are compared numerically, otherwise cells are
+// compared as strings. A leading "-" on the name sorts in descending
+// order. The column name is not case sensitive.
+func (t *Table) SortBy(name string) error {
+	return t.sortBy(name, localeC)
+}
+
+// sortBy implements SortBy, parsing numbers with the separators of loc.
+func (t *Table) sortBy(name string, loc Locale) error {
+	desc := strings.HasPrefix(name, "-")
+
+	i, err := t.column(strings.TrimPrefix(name, "-"))
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(t.rows, func(a, b int) bool {
+		if desc {
+			return loc.cellLess(t.rows[b][i], t.rows[a][i])
+		}
+
+		return loc.cellLess(t.rows[a][i], t.rows[b][i])
+	})
+
+	return nil
+}
+
+// Filter removes the rows of the table which do not match expr. The
+// expression is a comma separated list of conditions, all of which must
+// match. Each condition is one of:
+//
+//	column=value   cell equals value
+//	column!=value  cell does not equal value
+//	column~value   cell contains value
+//
+// Column names are not case sensitive, values are.
+func (t *Table) Filter(expr string) error {
+	type cond struct {
+		col int
+		op  string
+		val string
+	}
+
+	var conds []cond
+
+	for _, s := range strings.Split(expr, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		i := strings.IndexAny(s, "!=~")
+		if i < 1 {
+			return fmt.Errorf("%w: %q", ErrInvalidFilter, s)
+		}
+
+		c := cond{op: s[i : i+1], val: s[i+1:]}
+
+		if c.op == "!" {
+			if !strings.HasPrefix(s[i:], "!=") {
+				return fmt.Errorf("%w: %q", ErrInvalidFilter, s)
+			}
+
+			c.op = "!="
+			c.val = s[i+2:]
+		}
+
+		col, err := t.column(strings.TrimSpace(s[:i]))
+		if err != nil {
+			return err
+		}
+
+		c.col = col
+		conds = append(conds, c)
+	}
+
+	rows := t.rows[:0]
+
+	for _, row := range t.rows {
+		match := true
+
+		for _, c := range conds {
+			switch c.op {
+			case "=":
+				match = row[c.col] == c.val
+			case "!=":
+				match = row[c.col] != c.val
+			case "~":
+				match = strings.Contains(row[c.col], c.val)
+			}
+
+			if !match {
+				break
+			}
+		}
+
+		if match {
+			rows = append(rows, row)
+		}
+	}
+
+	t.rows = rows
+
+	return nil
+}
+
+// column returns the index of the named column.
+func (t *Table) column(name string) (int, error) {
+	for i, c := range t.cols {
+		if strings.EqualFold(c.Name, name) {
+			return i, nil
+		}
+	}
+
+	return -1, fmt.Errorf("%w: %q", ErrUnknownColumn, name)
+}
+
+// cellLess compares two cells, numerically if possible.
+func (loc Locale) cellLess(a string, b string) bool {
+	fa, errA := loc.parseNumber(a)
+	fb, errB := loc.parseNumber(b)
+
+	if errA == nil && errB == nil {
+		return fa < fb
+	}
+
+	return a < b
+}
+
+// AddTableFlags adds the --sort-by and --filter flags to the FlagSet,
+// which are applied to tables printed with PrintTable.
+func (c *Cmd) AddTableFlags() {
+	c.sortBy = c.FlagSet.String("sort-by", "",
+		"sort table output by `column`, prefix with - to reverse")
+	c.filter = c.FlagSet.String("filter", "",
+		"filter table output by `expression`, such as name~foo,state=up")
+}
+
+// PrintTable applies the --sort-by and --filter flags, if added with
+// AddTableFlags, then prints t to Stdout fitted to the width of the
+// terminal. Unlike SortBy, sorting recognizes numbers formatted for the
+// locale, such as those returned by FormatInt.
+func (c *Cmd) PrintTable(t *Table) (int, error) {
+	err := c.applyTableFlags(t)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.TermPrinter.PrintTable(t)
+}
+
+// applyTableFlags applies the --filter and --sort-by flags to t.
+func (c *Cmd) applyTableFlags(t *Table) error {
+	if c.filter != nil && *c.filter != "" {
+		err := t.Filter(*c.filter)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.sortBy != nil && *c.sortBy != "" {
+		return t.sortBy(*c.sortBy, c.numericLocale())
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func newSortTable() *cli.Table {
+	t := cli.NewTable(
+		cli.Column{Name: "NAME"},
+		cli.Column{Name: "SIZE"},
+		cli.Column{Name: "STATE"},
+	)
+
+	t.AddRow("web", "10", "up")
+	t.AddRow("db", "9", "up")
+	t.AddRow("cache", "100", "down")
+	t.AddRow("queue", "25", "up")
+
+	return t
+}
+
+func TestTableSortFilter(t *testing.T) {
+	t.Run("Cmd", testTableCmd)
+	t.Run("Filter", testTableFilter)
+	t.Run("Errors", testTableErrors)
+}
+
+func testTableCmd(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(outbuf)
+	cmd.AddTableFlags()
+
+	err := cmd.FlagSet.Parse([]string{"-sort-by", "-size", "-filter", "state=up"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = cmd.PrintTable(newSortTable())
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "NAME   SIZE  STATE\nqueue  25    up\nweb    10    up\ndb     9     up\n"
+	if outbuf.String() != exp {
+		t.Error("unexpected output", outbuf.String())
+	}
+}
+
+func testTableFilter(t *testing.T) {
+	tbl := newSortTable()
+
+	err := tbl.Filter("name~e, state!=down")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	err = tbl.SortBy("name")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	exp := "NAME   SIZE  STATE\nqueue  25    up\nweb    10    up\n"
+	if s := tbl.Render(0); s != exp {
+		t.Error("unexpected output", s)
+	}
+}
+
+func testTableErrors(t *testing.T) {
+	tbl := newSortTable()
+
+	err := tbl.SortBy("color")
+	if !errors.Is(err, cli.ErrUnknownColumn) {
+		t.Error("unexpected error:", err)
+	}
+
+	err = tbl.Filter("color=red")
+	if !errors.Is(err, cli.ErrUnknownColumn) {
+		t.Error("unexpected error:", err)
+	}
+
+	for _, expr := range []string{"name", "=web", "name!web"} {
+		err = tbl.Filter(expr)
+		if !errors.Is(err, cli.ErrInvalidFilter) {
+			t.Error("unexpected error:", err)
+		}
+	}
+
+	cmd := cli.NewCmd()
+	cmd.AddTableFlags()
+
+	err = cmd.FlagSet.Parse([]string{"-filter", "bogus"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	_, err = cmd.PrintTable(tbl)
+	if !errors.Is(err, cli.ErrInvalidFilter) {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limits of telemetry reporting.
+const (
+	maxTelemetryQueue = 100
+	telemetryTimeout  = 2 * time.Second
+)
+
+// TelemetryRecord describes a single run of an application.
+type TelemetryRecord struct {
+	Time     time.Time     `json:"time"`
+	Command  string        `json:"command"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exit_code"`
+}
+
+// TelemetryUploader sends telemetry records to a collection service.
+type TelemetryUploader interface {
+	Upload(ctx context.Context, records []TelemetryRecord) error
+}
+
+// Telemetry records anonymous usage of an application, if the user has
+// consented, and passes the records to an uploader. Only the command
+// name, duration and exit status are recorded. Records which cannot be
+// uploaded are queued in the StateDir of the application and sent with
+// the next upload.
+//
+// Telemetry is disabled unless the user has consented with SetConsent
+// or AskConsent, or has enabled it with the flag added by EnableFlag.
+// The DO_NOT_TRACK environment variable, or the variable named with
+// SetEnv set to a false value, disables it regardless.
+type Telemetry struct {
+	m        sync.Mutex
+	c        *Cmd
+	uploader TelemetryUploader
+	env      string
+	flag     *optBool
+	records  []TelemetryRecord
+}
+
+// Telemetry returns the telemetry subsystem of the Cmd.
+func (c *Cmd) Telemetry() *Telemetry {
+	if c.telemetry == nil {
+		c.telemetry = &Telemetry{c: c}
+	}
+
+	return c.telemetry
+}
+
+// SetUploader sets the uploader which receives telemetry records.
+// Nothing is recorded until an uploader has been set.
+func (t *Telemetry) SetUploader(u TelemetryUploader) {
+	t.m.Lock()
+	t.uploader = u
+	t.m.Unlock()
+}
+
+// SetEnv sets the name of an environment variable which disables
+// telemetry when set to a false value, such as "MYTOOL_TELEMETRY=0".
+func (t *Telemetry) SetEnv(name string) {
+	t.m.Lock()
+	t.env = name
+	t.m.Unlock()
+}
+
+// EnableFlag adds a boolean flag with the given name to the FlagSet of
+// the Cmd, which enables or disables telemetry for a single run,
+// overriding the saved consent.
+func (t *Telemetry) EnableFlag(name string) {
+	t.m.Lock()
+	t.flag = new(optBool)
+	t.m.Unlock()
+
+	t.c.FlagSet.Var(t.flag, name, "send anonymous usage statistics")
+}
+
+// SetConsent saves the decision of the user to enable or disable
+// telemetry in the ConfigDir of the application.
+func (t *Telemetry) SetConsent(enabled bool) error {
+	dir, err := ConfigDir(t.app())
+	if err != nil {
+		return err
+	}
+
+	v := "disabled\n"
+	if enabled {
+		v = "enabled\n"
+	}
+
+	return writeFileAtomic(filepath.Join(dir, "telemetry"), strings.NewReader(v))
+}
+
+// Consent returns the saved decision of the user, and whether the user
+// has made one.
+func (t *Telemetry) Consent() (bool, bool) {
+	dir, err := configDirPath(t.app())
+	if err != nil {
+		return false, false
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "telemetry"))
+	if err != nil {
+		return false, false
+	}
+
+	return strings.TrimSpace(string(b)) == "enabled", true
+}
+
+// AskConsent asks the user whether to enable telemetry, printing
+// question followed by a "[y/N]" prompt, then saves the answer. The user
+// is only asked if no decision has been saved and Stdin is a terminal.
+func (t *Telemetry) AskConsent(question string) error {
+	if _, ok := t.Consent(); ok {
+		return nil
+	}
+
+	r := t.c.NewLineReader()
+	r.SetExitOnInterrupt(false)
+
+	if !r.term {
+		return nil
+	}
+
+	t.c.Println(question)
+
+	v, err := r.ReadLine("[y/N] ")
+	if err != nil {
+		return err
+	}
+
+	v = strings.ToLower(strings.TrimSpace(v))
+
+	return t.SetConsent(v == "y" || v == "yes")
+}
+
+// Enabled reports whether telemetry is enabled for this run.
+func (t *Telemetry) Enabled() bool {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.uploader == nil {
+		return false
+	}
+
+	if on, err := strconv.ParseBool(os.Getenv("DO_NOT_TRACK")); err == nil && on {
+		return false
+	}
+
+	if t.env != "" {
+		if on, err := strconv.ParseBool(os.Getenv(t.env)); err == nil && !on {
+			return false
+		}
+	}
+
+	if t.flag != nil && t.flag.set {
+		return t.flag.v
+	}
+
+	on, _ := t.Consent()
+
+	return on
+}
+
+// Record adds a record of a run to the queue if telemetry is enabled.
+// Run records each run automatically.
+func (t *Telemetry) Record(command string, d time.Duration, code int) {
+	if !t.Enabled() {
+		return
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	t.records = append(t.records, TelemetryRecord{
+		Time:     time.Now().UTC().Truncate(time.Second),
+		Command:  command,
+		Duration: d,
+		ExitCode: code,
+	})
+}
+
+// Flush uploads the queued records along with any saved by earlier
+// runs. If the upload fails, the records are saved for the next run.
+func (t *Telemetry) Flush(ctx context.Context) error {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if len(t.records) == 0 || t.uploader == nil {
+		return nil
+	}
+
+	path := t.queuePath()
+	records := t.loadQueue(path)
+	records = append(records, t.records...)
+	t.records = nil
+
+	err := t.uploader.Upload(ctx, records)
+	if err != nil {
+		if len(records) > maxTelemetryQueue {
+			records = records[len(records)-maxTelemetryQueue:]
+		}
+
+		b, _ := json.Marshal(records)
+		_ = writeFileAtomic(path, bytes.NewReader(b))
+
+		return err
+	}
+
+	_ = os.Remove(path)
+
+	return nil
+}
+
+// flush uploads the queued records with a short timeout, ignoring
+// errors.
+func (t *Telemetry) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), telemetryTimeout)
+	defer cancel()
+
+	_ = t.Flush(ctx)
+}
+
+// queuePath returns the path of the saved queue.
+func (t *Telemetry) queuePath() string {
+	dir, err := StateDir(t.app())
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "telemetry-queue.json")
+}
+
+// loadQueue returns the records saved at path.
+func (t *Telemetry) loadQueue(path string) []TelemetryRecord {
+	var records []TelemetryRecord
+
+	b, err := os.ReadFile(path)
+	if err == nil {
+		_ = json.Unmarshal(b, &records)
+	}
+
+	return records
+}
+
+// app returns the name of the application.
+func (t *Telemetry) app() string {
+	return filepath.Base(t.c.FlagSet.Name())
+}
+
+// optBool is a boolean flag value which records whether it was set.
+type optBool struct {
+	set bool
+	v   bool
+}
+
+// String returns the value of the flag.
+func (ob *optBool) String() string {
+	if ob == nil {
+		return "false"
+	}
+
+	return strconv.FormatBool(ob.v)
+}
+
+// Set parses s as the value of the flag.
+func (ob *optBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+
+	ob.set, ob.v = true, v
+
+	return nil
+}
+
+// IsBoolFlag allows the flag to be given without a value.
+func (ob *optBool) IsBoolFlag() bool {
+	return true
+}
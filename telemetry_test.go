@@ -0,0 +1,115 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+// recordUploader collects uploaded telemetry records.
+type recordUploader struct {
+	m       sync.Mutex
+	records []cli.TelemetryRecord
+	err     error
+}
+
+func (ru *recordUploader) Upload(_ context.Context, records []cli.TelemetryRecord) error {
+	ru.m.Lock()
+	defer ru.m.Unlock()
+
+	if ru.err != nil {
+		return ru.err
+	}
+
+	ru.records = append(ru.records, records...)
+
+	return nil
+}
+
+func TestTelemetry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("DO_NOT_TRACK", "")
+	t.Setenv("TEST_TELEMETRY", "")
+
+	up := new(recordUploader)
+
+	run := func(args ...string) {
+		t.Helper()
+
+		cmd := cli.NewCmd()
+		cmd.SetStderr(new(bytes.Buffer))
+		cmd.AddCommand("sync", "", func([]string) error { return nil })
+
+		tm := cmd.Telemetry()
+		tm.SetUploader(up)
+		tm.SetEnv("TEST_TELEMETRY")
+		tm.EnableFlag("telemetry")
+
+		cmd.Run(args, func(_ context.Context, args []string) error {
+			return cmd.Dispatch(args)
+		})
+	}
+
+	// no consent yet
+	run("sync")
+
+	// enabled by the flag, then failing to upload
+	up.err = errTest
+	run("-telemetry", "sync")
+
+	up.err = nil
+
+	err := cli.NewCmd().Telemetry().SetConsent(true)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	// consent overridden by the environment
+	t.Setenv("TEST_TELEMETRY", "0")
+	run("sync")
+
+	// consented, sending the queued record as well
+	t.Setenv("TEST_TELEMETRY", "")
+	run("nosuch")
+
+	if len(up.records) != 2 {
+		t.Fatal("expected two records, received", up.records)
+	}
+
+	for i, exp := range []struct {
+		cmd  string
+		code int
+	}{{"cli.test sync", 0}, {"cli.test", 1}} {
+		r := up.records[i]
+
+		if r.Command != exp.cmd || r.ExitCode != exp.code {
+			t.Errorf("record %d: unexpected %+v", i, r)
+		}
+	}
+}
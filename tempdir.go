@@ -0,0 +1,74 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"os"
+)
+
+// TempDirOption configures a directory created by TempDir.
+type TempDirOption func(*tempDirConfig)
+
+// tempDirConfig holds the options of TempDir.
+type tempDirConfig struct {
+	keepOnFailure bool
+}
+
+// KeepOnFailure keeps the directory created by TempDir if the
+// application exits with an error, printing its path to Stderr so it
+// can be inspected.
+func KeepOnFailure() TempDirOption {
+	return func(cfg *tempDirConfig) {
+		cfg.keepOnFailure = true
+	}
+}
+
+// TempDir creates a new temporary directory in the manner of
+// os.MkdirTemp, which is removed with its contents when the application
+// shuts down. Removal happens in an exit hook, so the directory is also
+// removed on a forced exit by timeout or signal, and after a crash
+// report is written.
+func (c *Cmd) TempDir(pattern string, opts ...TempDirOption) (string, error) {
+	var cfg tempDirConfig
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+
+	c.OnExit(func(err error) {
+		if err != nil && cfg.keepOnFailure {
+			c.Eprintf("keeping temporary directory %s\n", dir)
+
+			return
+		}
+
+		_ = os.RemoveAll(dir)
+	})
+
+	return dir, nil
+}
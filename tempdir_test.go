@@ -0,0 +1,77 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestTempDir(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		opts []cli.TempDirOption
+		keep bool
+	}{
+		{name: "Success"},
+		{name: "Failure", err: errTest},
+		{name: "KeepOnSuccess", opts: []cli.TempDirOption{cli.KeepOnFailure()}},
+		{name: "KeepOnFailure", err: errTest, opts: []cli.TempDirOption{cli.KeepOnFailure()}, keep: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errbuf := new(bytes.Buffer)
+
+			cmd := cli.NewCmd()
+			cmd.SetStderr(errbuf)
+
+			dir, err := cmd.TempDir("clitest-*", tc.opts...)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			t.Cleanup(func() { os.RemoveAll(dir) })
+
+			err = os.WriteFile(dir+"/file", []byte("data"), 0o600)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+
+			cmd.Exit(tc.err)
+			_ = cmd.Wait()
+
+			_, err = os.Stat(dir)
+			if tc.keep != (err == nil) {
+				t.Errorf("expected kept %v, received %v", tc.keep, err)
+			}
+
+			if tc.keep && !strings.Contains(errbuf.String(), dir) {
+				t.Errorf("expected path to be printed, received %q", errbuf)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import "sync"
+
+// termRestorers holds the functions which undo changes made to the
+// terminal, such as entering raw mode.
+type termRestorers struct {
+	m    sync.Mutex
+	fns  map[int]func()
+	next int
+}
+
+// GuardTerminal registers restore, a function which undoes a change
+// made to the terminal such as entering raw mode or hiding the cursor,
+// so that the terminal is left usable however the process exits. The
+// returned function calls restore and removes it, and should be used
+// in place of restore. Until then, restore is called when a timeout or
+// signal forces an exit, when a panic is handled by RecoverCrash, and
+// by Wait. Each restore function is called at most once.
+//
+// The package guards the terminal changes it makes, so GuardTerminal
+// is only needed for changes made by the application.
+func (e *ExitHandler) GuardTerminal(restore func()) func() {
+	once := new(sync.Once)
+	guarded := func() { once.Do(restore) }
+
+	e.term.m.Lock()
+	defer e.term.m.Unlock()
+
+	if e.term.fns == nil {
+		e.term.fns = make(map[int]func())
+	}
+
+	id := e.term.next
+	e.term.next++
+	e.term.fns[id] = guarded
+
+	return func() {
+		e.term.m.Lock()
+		delete(e.term.fns, id)
+		e.term.m.Unlock()
+
+		guarded()
+	}
+}
+
+// guardTerminal calls GuardTerminal if e is not nil, otherwise it
+// returns restore.
+func (e *ExitHandler) guardTerminal(restore func()) func() {
+	if e == nil {
+		return restore
+	}
+
+	return e.GuardTerminal(restore)
+}
+
+// restoreTerminal calls the registered restore functions, latest first.
+func (e *ExitHandler) restoreTerminal() {
+	e.term.m.Lock()
+	fns := e.term.fns
+	e.term.fns = nil
+	next := e.term.next
+	e.term.m.Unlock()
+
+	for id := next - 1; id >= 0 && len(fns) > 0; id-- {
+		if fn, ok := fns[id]; ok {
+			delete(fns, id)
+			fn()
+		}
+	}
+}
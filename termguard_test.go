@@ -0,0 +1,95 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+)
+
+func TestGuardTerminal(t *testing.T) {
+	cmd := cli.NewCmd()
+
+	var calls []string
+
+	restore := func(name string) func() {
+		return func() { calls = append(calls, name) }
+	}
+
+	cmd.GuardTerminal(restore("raw"))
+	release := cmd.GuardTerminal(restore("cursor"))
+	cmd.GuardTerminal(restore("alt screen"))
+
+	release()
+	release()
+
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+
+	_ = cmd.Wait()
+	_ = cmd.Wait()
+
+	expect := []string{"cursor", "alt screen", "raw"}
+	if !reflect.DeepEqual(calls, expect) {
+		t.Errorf("expected %q, received %q", expect, calls)
+	}
+}
+
+func TestGuardTerminalForcedExit(t *testing.T) {
+	if os.Getenv("CLI_TEST_GUARD_TERMINAL") != "" {
+		cmd := cli.NewCmd()
+		cmd.SetTimeout(10 * time.Millisecond)
+		cmd.GuardTerminal(func() { os.Stderr.WriteString("terminal restored\n") })
+
+		cmd.Add(1)
+		cmd.Exit(nil)
+
+		// never calls Done, so the exit is forced
+		_ = cmd.Wait()
+
+		return
+	}
+
+	errbuf := new(bytes.Buffer)
+
+	proc := exec.Command(os.Args[0], "-test.run=^TestGuardTerminalForcedExit$")
+	proc.Env = append(os.Environ(), "CLI_TEST_GUARD_TERMINAL=1")
+	proc.Stderr = errbuf
+
+	err := proc.Run()
+	if err == nil {
+		t.Fatal("expected forced exit")
+	}
+
+	if !strings.HasPrefix(errbuf.String(), "terminal restored\nexit forced by timeout\n") {
+		t.Errorf("expected terminal restored before exit, received %q", errbuf)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+)
+
+// Tracer creates spans, allowing Run to be traced without depending on
+// a particular tracing library. An adapter for OpenTelemetry or similar
+// is a few lines of code.
+type Tracer interface {
+	// Start starts a span with the given name as a child of any span
+	// in ctx, returning a context containing the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a unit of work started by a Tracer.
+type Span interface {
+	// SetAttribute records a key and value on the span.
+	SetAttribute(key string, value any)
+
+	// End completes the span.
+	End()
+}
+
+// WithTracer sets the Tracer used by Run.
+func WithTracer(t Tracer) Option {
+	return func(c *Cmd) {
+		c.trace = t
+	}
+}
+
+// tracer returns the Tracer of the Cmd, or one which does nothing.
+func (c *Cmd) tracer() Tracer {
+	if c.trace == nil {
+		return noopTracer{}
+	}
+
+	return c.trace
+}
+
+// noopTracer is a Tracer which creates spans that do nothing.
+type noopTracer struct{}
+
+// Start returns ctx and a span which does nothing.
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+// noopSpan is a Span which does nothing.
+type noopSpan struct{}
+
+// SetAttribute does nothing.
+func (noopSpan) SetAttribute(string, any) {}
+
+// End does nothing.
+func (noopSpan) End() {}
@@ -0,0 +1,285 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TranscriptFormat is the file format of a transcript.
+type TranscriptFormat int
+
+// Transcript formats accepted by RecordTranscript.
+const (
+	// TranscriptText records each line of output with the number of
+	// seconds since recording began and the stream it was written to.
+	// Terminal control sequences are removed.
+	TranscriptText TranscriptFormat = iota
+
+	// TranscriptAsciinema records the output in the asciinema v2
+	// format, including terminal control sequences, so the session can
+	// be replayed with "asciinema play".
+	TranscriptAsciinema
+)
+
+// Default terminal size written to an asciinema transcript if the size
+// of the terminal is unknown.
+const (
+	defaultTranscriptWidth  = 80
+	defaultTranscriptHeight = 24
+)
+
+// TranscriptOptions configures the transcript written by
+// RecordTranscript.
+type TranscriptOptions struct {
+	// Format is the file format. Defaults to TranscriptText.
+	Format TranscriptFormat
+
+	// Input also records the lines read by ReadLine from a LineReader
+	// created with the NewLineReader method of the Cmd. Passwords are
+	// never recorded.
+	Input bool
+}
+
+// RecordTranscript writes a timestamped transcript of all output written
+// to Stdout and Stderr, including the live region, to the file at path,
+// which is replaced if it exists. Output to the terminal is unchanged.
+// Any transcript already being recorded is closed. The transcript is
+// closed when the Cmd exits. Errors writing the file are ignored, so a
+// full disk does not interrupt the program.
+//
+// The file is created with mode 0600 since a transcript may contain
+// sensitive information, although secrets registered with
+// RedactSecrets are masked.
+func (c *Cmd) RecordTranscript(path string, opts TranscriptOptions) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	t := &transcript{f: f, opts: opts, now: c.now, start: c.now()}
+
+	if opts.Format == TranscriptAsciinema {
+		t.writeHeader(c.asciinemaHeader(t.start))
+	}
+
+	if old := c.transcript.Swap(t); old != nil {
+		old.close()
+	} else {
+		c.OnExit(func(error) {
+			if t := c.transcript.Swap(nil); t != nil {
+				t.close()
+			}
+		})
+	}
+
+	return nil
+}
+
+// asciinemaHeader returns the header of an asciinema transcript started
+// at start.
+func (c *Cmd) asciinemaHeader(start time.Time) map[string]interface{} {
+	w, h := c.termSize()
+	if w == 0 {
+		w = defaultTranscriptWidth
+	}
+
+	if h == 0 {
+		h = defaultTranscriptHeight
+	}
+
+	return map[string]interface{}{
+		"version":   2,
+		"width":     w,
+		"height":    h,
+		"timestamp": start.Unix(),
+		"title":     filepath.Base(c.FlagSet.Name()),
+		"env": map[string]string{
+			"TERM":  os.Getenv("TERM"),
+			"SHELL": os.Getenv("SHELL"),
+		},
+	}
+}
+
+// recordTranscript passes output written to stream s to the transcript,
+// if one is being recorded.
+func (tp *TermPrinter) recordTranscript(s Stream, b []byte) {
+	if t := tp.transcript.Load(); t != nil {
+		t.output(s, b)
+	}
+}
+
+// recordInput passes a line of input to the transcript, if one is being
+// recorded with input.
+func (tp *TermPrinter) recordInput(line string) {
+	if t := tp.transcript.Load(); t != nil && t.opts.Input {
+		t.input(tp.redactSecrets(line) + "\n")
+	}
+}
+
+// transcript is a transcript file being recorded.
+type transcript struct {
+	m     sync.Mutex
+	f     *os.File
+	opts  TranscriptOptions
+	now   func() time.Time
+	start time.Time
+
+	// partial holds the incomplete last line of each stream in text
+	// format, and partialAt the time it began
+	partial   [2][]byte
+	partialAt [2]time.Duration
+}
+
+// output records b written to stream s.
+func (t *transcript) output(s Stream, b []byte) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.f == nil {
+		return
+	}
+
+	if t.opts.Format == TranscriptAsciinema {
+		t.writeEvent("o", string(b))
+
+		return
+	}
+
+	t.writeLines(int(s), b)
+}
+
+// input records a line of input.
+func (t *transcript) input(line string) {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.f == nil {
+		return
+	}
+
+	if t.opts.Format == TranscriptAsciinema {
+		t.writeEvent("i", line)
+
+		return
+	}
+
+	t.writeLines(len(t.partial), []byte(line))
+}
+
+// writeHeader writes the header line of an asciinema transcript.
+func (t *transcript) writeHeader(header map[string]interface{}) {
+	b, err := json.Marshal(header)
+	if err == nil {
+		_, _ = t.f.Write(append(b, '\n'))
+	}
+}
+
+// writeEvent writes an asciinema event of type typ.
+func (t *transcript) writeEvent(typ string, data string) {
+	secs := t.now().Sub(t.start).Seconds()
+
+	b, err := json.Marshal([]interface{}{secs, typ, data})
+	if err == nil {
+		_, _ = t.f.Write(append(b, '\n'))
+	}
+}
+
+// writeLines writes the complete lines of b in text format, labelled
+// with the tag at index i. An incomplete last line is kept until it is
+// completed or the transcript is closed.
+func (t *transcript) writeLines(i int, b []byte) {
+	elapsed := t.now().Sub(t.start)
+
+	for len(b) > 0 {
+		line, rest, nl := bytes.Cut(b, []byte("\n"))
+		b = rest
+
+		if i < len(t.partial) {
+			if len(t.partial[i]) == 0 {
+				t.partialAt[i] = elapsed
+			}
+
+			if !nl {
+				t.partial[i] = append(t.partial[i], line...)
+
+				return
+			}
+
+			line = append(t.partial[i], line...)
+			t.partial[i] = t.partial[i][:0]
+			elapsed = t.partialAt[i]
+		}
+
+		t.writeLine(i, elapsed, line)
+	}
+}
+
+// writeLine writes a line in text format with control sequences
+// removed, skipping lines which only held control sequences.
+func (t *transcript) writeLine(i int, elapsed time.Duration, line []byte) {
+	text := stripEscapes(string(bytes.TrimSuffix(line, []byte("\r"))))
+	if text == "" && len(line) > 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(t.f, "%9.3f %-3s %s\n", elapsed.Seconds(), transcriptTag(i), text)
+}
+
+// transcriptTag returns the label in text format of the stream with
+// index i, where the index after the last stream is input.
+func transcriptTag(i int) string {
+	switch i {
+	case int(Stdout):
+		return "out"
+	case int(Stderr):
+		return "err"
+	default:
+		return "in"
+	}
+}
+
+// close writes any incomplete lines and closes the file.
+func (t *transcript) close() {
+	t.m.Lock()
+	defer t.m.Unlock()
+
+	if t.f == nil {
+		return
+	}
+
+	for i, p := range t.partial {
+		if len(p) > 0 {
+			t.writeLine(i, t.partialAt[i], p)
+		}
+	}
+
+	_ = t.f.Close()
+	t.f = nil
+}
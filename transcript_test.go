@@ -0,0 +1,147 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestTranscriptText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.txt")
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.SetStderr(new(bytes.Buffer))
+	cmd.SetStdin(strings.NewReader("yes\n"))
+	cmd.SetDeterministic(true)
+
+	err := cmd.RecordTranscript(path, cli.TranscriptOptions{Input: true})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Println("hello")
+	cmd.Eprint("partial ")
+	cmd.Printf("continue? ")
+
+	_, err = cmd.NewLineReader().ReadLine("")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Eprintln("line")
+	cmd.Print("\x1b[32mdone\x1b[0m")
+
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+	_ = cmd.Wait()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	exp := "    0.000 out hello\n" +
+		"    0.000 in  yes\n" +
+		"    0.000 err partial line\n" +
+		"    0.000 out continue? done\n"
+	if string(b) != exp {
+		t.Errorf("unexpected transcript %q", b)
+	}
+
+	if fi, _ := os.Stat(path); runtime.GOOS != "windows" && fi.Mode().Perm()&0o077 != 0 {
+		t.Errorf("expected private file, mode %o", fi.Mode().Perm())
+	}
+}
+
+func TestTranscriptAsciinema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.cast")
+
+	cmd := cli.NewCmd()
+	cmd.SetStdout(new(bytes.Buffer))
+	cmd.SetTermSize(100, 30)
+
+	err := cmd.RecordTranscript(path, cli.TranscriptOptions{Format: cli.TranscriptAsciinema})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	cmd.Println("hello")
+
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+	_ = cmd.Wait()
+
+	cmd.Println("after close")
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+
+	var header struct {
+		Version int `json:"version"`
+		Width   int `json:"width"`
+		Height  int `json:"height"`
+	}
+
+	if !s.Scan() || json.Unmarshal(s.Bytes(), &header) != nil {
+		t.Fatalf("invalid header %q", s.Text())
+	}
+
+	if header.Version != 2 || header.Width != 100 || header.Height != 30 {
+		t.Errorf("unexpected header %+v", header)
+	}
+
+	var events [][]interface{}
+
+	for s.Scan() {
+		var ev []interface{}
+
+		err = json.Unmarshal(s.Bytes(), &ev)
+		if err != nil {
+			t.Fatalf("invalid event %q", s.Text())
+		}
+
+		events = append(events, ev)
+	}
+
+	if len(events) != 1 || events[0][1] != "o" || events[0][2] != "hello\n" {
+		t.Errorf("unexpected events %v", events)
+	}
+}
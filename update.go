@@ -0,0 +1,332 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for update checks.
+const (
+	defaultUpdateInterval = 24 * time.Hour
+	updateTimeout         = 5 * time.Second
+	updateNoticeWait      = time.Second
+)
+
+// ErrUpdateCheck is returned by an UpdateSource when the latest release
+// cannot be determined.
+var ErrUpdateCheck = errors.New("update check failed")
+
+// Release describes a released version of an application.
+type Release struct {
+	// Version is the version of the release, such as "v1.2.3".
+	Version string `json:"version"`
+
+	// URL is where the release may be found, if known.
+	URL string `json:"url,omitempty"`
+}
+
+// UpdateSource provides the latest release of an application.
+type UpdateSource interface {
+	LatestRelease(ctx context.Context) (Release, error)
+}
+
+// GitHubSource is an UpdateSource which reads the latest release of a
+// GitHub repository.
+type GitHubSource struct {
+	// Repo is the repository, in the form "owner/name".
+	Repo string
+
+	// APIURL is the base URL of the GitHub API. The default is
+	// "https://api.github.com".
+	APIURL string
+}
+
+// LatestRelease returns the tag and page of the latest release.
+func (gs GitHubSource) LatestRelease(ctx context.Context) (Release, error) {
+	api := gs.APIURL
+	if api == "" {
+		api = "https://api.github.com"
+	}
+
+	var rel struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+
+	err := getJSON(ctx, strings.TrimSuffix(api, "/")+"/repos/"+gs.Repo+"/releases/latest", &rel)
+	if err != nil {
+		return Release{}, err
+	}
+
+	return Release{Version: rel.TagName, URL: rel.HTMLURL}, nil
+}
+
+// URLSource is an UpdateSource which reads a JSON document describing
+// the latest release, in the form of a Release, from a URL.
+type URLSource struct {
+	URL string
+}
+
+// LatestRelease returns the release described at the URL.
+func (us URLSource) LatestRelease(ctx context.Context) (Release, error) {
+	var rel Release
+
+	err := getJSON(ctx, us.URL, &rel)
+
+	return rel, err
+}
+
+// getJSON decodes the JSON document at url into v.
+func getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s: %s", ErrUpdateCheck, url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// updateState is cached between runs to limit the frequency of checks.
+type updateState struct {
+	Checked time.Time `json:"checked"`
+	Latest  Release   `json:"latest"`
+}
+
+// updateCheck holds the state of an update check in progress.
+type updateCheck struct {
+	interval time.Duration
+	state    updateState
+	done     chan struct{}
+}
+
+// SetUpdateInterval sets the minimum time between update checks. The
+// default is 24 hours.
+func (c *Cmd) SetUpdateInterval(d time.Duration) {
+	c.updateInterval = d
+}
+
+// CheckForUpdates enables an update notice, which Run prints to Stderr
+// after the command finishes when src reports a newer release than the
+// Version of the Cmd. The result of each check is cached in the user
+// cache directory, and src is queried in the background at most once
+// per update interval. Nothing is printed if Stderr is not a terminal,
+// when running in CI, or if the version is unknown.
+func (c *Cmd) CheckForUpdates(src UpdateSource) {
+	interval := c.updateInterval
+	if interval <= 0 {
+		interval = defaultUpdateInterval
+	}
+
+	uc := &updateCheck{interval: interval, done: make(chan struct{})}
+	c.update = uc
+
+	path := c.updateStatePath()
+
+	b, err := os.ReadFile(path)
+	if err == nil {
+		_ = json.Unmarshal(b, &uc.state)
+	}
+
+	if c.clock().Now().Sub(uc.state.Checked) < interval {
+		close(uc.done)
+
+		return
+	}
+
+	go func() {
+		defer close(uc.done)
+
+		ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+		defer cancel()
+
+		rel, err := src.LatestRelease(ctx)
+		if err != nil {
+			return
+		}
+
+		uc.state = updateState{Checked: c.clock().Now(), Latest: rel}
+
+		b, err := json.Marshal(uc.state)
+		if err != nil {
+			return
+		}
+
+		_ = os.MkdirAll(filepath.Dir(path), 0o700)
+		_ = writeFileAtomic(path, bytes.NewReader(b))
+	}()
+}
+
+// updateStatePath returns the path of the cached update state.
+func (c *Cmd) updateStatePath() string {
+	dir, err := CacheDir(filepath.Base(c.FlagSet.Name()))
+	if err != nil {
+		dir = filepath.Join(os.TempDir(), filepath.Base(c.FlagSet.Name()))
+	}
+
+	return filepath.Join(dir, "update.json")
+}
+
+// PrintUpdateNotice prints a notice to Stderr if CheckForUpdates found a
+// newer release. It waits briefly for a check in progress to complete.
+// Run calls PrintUpdateNotice after the command finishes.
+func (c *Cmd) PrintUpdateNotice() {
+	uc := c.update
+	if uc == nil || !c.errIsTerm || c.ciEnabled() {
+		return
+	}
+
+	t := c.clock().NewTimer(updateNoticeWait)
+	defer t.Stop()
+
+	select {
+	case <-uc.done:
+	case <-t.C():
+		return
+	}
+
+	cur, latest := c.Version(), uc.state.Latest
+
+	if latest.Version == "" || compareVersions(cur, latest.Version) >= 0 {
+		return
+	}
+
+	lines := []string{
+		fmt.Sprintf("A new version of %s is available: %s -> %s",
+			filepath.Base(c.FlagSet.Name()), cur, latest.Version),
+	}
+
+	if latest.URL != "" {
+		lines = append(lines, latest.URL)
+	}
+
+	c.printPanel(lines)
+}
+
+// printPanel prints lines to Stderr within a border.
+func (tp *TermPrinter) printPanel(lines []string) {
+	var w int
+
+	for _, l := range lines {
+		w = max(w, textWidth(l))
+	}
+
+	var sb strings.Builder
+
+	border := "+" + strings.Repeat("-", w+2) + "+\n"
+
+	sb.WriteString(border)
+
+	for _, l := range lines {
+		sb.WriteString("| " + padRight(l, w) + " |\n")
+	}
+
+	sb.WriteString(border)
+
+	tp.Eprint(sb.String())
+}
+
+// compareVersions compares versions of the form "v1.2.3", returning a
+// negative number if a is older than b, a positive number if a is newer
+// and zero if they are equal. A pre-release such as "v1.2.3-rc1" is
+// older than the release itself. An unparseable version, such as that
+// of a development build, is never older.
+func compareVersions(a string, b string) int {
+	na, prea, ok := parseVersion(a)
+	if !ok {
+		return 0
+	}
+
+	nb, preb, ok := parseVersion(b)
+	if !ok {
+		return 0
+	}
+
+	for i := range na {
+		if na[i] != nb[i] {
+			return na[i] - nb[i]
+		}
+	}
+
+	switch {
+	case prea && !preb:
+		return -1
+	case preb && !prea:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// parseVersion returns the major, minor and patch numbers of v, and
+// whether v is a pre-release.
+func parseVersion(v string) ([3]int, bool, bool) {
+	var n [3]int
+
+	v = strings.TrimPrefix(v, "v")
+
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+
+	v, _, pre := strings.Cut(v, "-")
+
+	f := strings.Split(v, ".")
+	if len(f) > len(n) {
+		return n, false, false
+	}
+
+	for i, s := range f {
+		num, err := strconv.Atoi(s)
+		if err != nil {
+			return n, false, false
+		}
+
+		n[i] = num
+	}
+
+	return n, pre, true
+}
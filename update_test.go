@@ -0,0 +1,75 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestCheckForUpdates(t *testing.T) {
+	t.Setenv("CI", "false")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var hits atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+
+		_, _ = w.Write([]byte(`{"version":"v1.1.0","url":"https://example.com/v1.1.0"}`))
+	}))
+	defer srv.Close()
+
+	cons := newTestConsole(t)
+
+	for i := 0; i < 2; i++ {
+		cmd := cli.NewCmd()
+		cmd.SetStdout(cons.Tty())
+		cmd.SetStderr(cons.Tty())
+		cmd.SetVersion("v1.1.0-rc1")
+		cmd.CheckForUpdates(cli.URLSource{URL: srv.URL})
+
+		code := cmd.Run(nil, func(context.Context, []string) error {
+			return nil
+		})
+		if code != 0 {
+			t.Fatal("unexpected exit status", code)
+		}
+
+		for _, s := range []string{"v1.1.0-rc1 -> v1.1.0", "https://example.com/v1.1.0"} {
+			_, err := cons.ExpectString(s)
+			if err != nil {
+				t.Fatal("unexpected error", err)
+			}
+		}
+	}
+
+	if hits.Load() != 1 {
+		t.Error("expected one request, received", hits.Load())
+	}
+}
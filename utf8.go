@@ -0,0 +1,137 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+)
+
+// replacementChar replaces invalid UTF-8 sequences.
+//
+//nolint:gochecknoglobals // read-only replacement bytes
+var replacementChar = []byte(string(utf8.RuneError))
+
+// WithUTF8 enables UTF-8 output on the TermPrinter, see SetUTF8Output,
+// and makes ExpectPipedStdin replace invalid UTF-8 in the input.
+func WithUTF8() Option {
+	return func(c *Cmd) {
+		c.SetUTF8Output(true)
+		c.utf8In = true
+	}
+}
+
+// SetUTF8Output enables replacement of invalid UTF-8 sequences in all
+// output with the Unicode replacement character, so that binary or
+// mis-encoded data does not garble the terminal. On Windows, the
+// console code pages are also switched to UTF-8, so that non-ASCII
+// output renders correctly.
+func (tp *TermPrinter) SetUTF8Output(enable bool) {
+	tp.utf8 = enable
+
+	if enable {
+		setConsoleUTF8()
+	}
+}
+
+// utf8Writer replaces invalid UTF-8 sequences before passing data to w.
+type utf8Writer struct {
+	w io.Writer
+}
+
+// Write writes b to the embedded io.Writer, replacing invalid UTF-8
+// sequences. The returned count is relative to b.
+func (uw utf8Writer) Write(b []byte) (int, error) {
+	if utf8.Valid(b) {
+		return uw.w.Write(b)
+	}
+
+	_, err := uw.w.Write(bytes.ToValidUTF8(b, replacementChar))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+// NewUTF8Reader returns a reader which replaces invalid UTF-8 sequences
+// read from r with the Unicode replacement character. Sequences split
+// across reads of r are reassembled.
+func NewUTF8Reader(r io.Reader) io.Reader {
+	return &utf8Reader{r: r}
+}
+
+// utf8Reader is the implementation of NewUTF8Reader.
+type utf8Reader struct {
+	r   io.Reader
+	raw []byte
+	out []byte
+	err error
+}
+
+// Read reads valid UTF-8 into p.
+func (ur *utf8Reader) Read(p []byte) (int, error) {
+	for len(ur.out) == 0 {
+		if ur.err != nil {
+			return 0, ur.err
+		}
+
+		var buf [4096]byte
+
+		n, err := ur.r.Read(buf[:])
+		ur.raw = append(ur.raw, buf[:n]...)
+		ur.err = err
+
+		// hold back an incomplete sequence until more is read
+		keep := 0
+		if err == nil {
+			keep = partialRune(ur.raw)
+		}
+
+		end := len(ur.raw) - keep
+		ur.out = bytes.ToValidUTF8(ur.raw[:end], replacementChar)
+		ur.raw = append(ur.raw[:0], ur.raw[end:]...)
+	}
+
+	n := copy(p, ur.out)
+	ur.out = ur.out[n:]
+
+	return n, nil
+}
+
+// partialRune returns the length of the incomplete UTF-8 sequence at
+// the end of b, if any.
+func partialRune(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if utf8.FullRune(b[i:]) {
+				return 0
+			}
+
+			return len(b) - i
+		}
+	}
+
+	return 0
+}
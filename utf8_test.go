@@ -0,0 +1,93 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"kreklow.us/go/cli"
+)
+
+func TestUTF8Output(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	tp := cli.NewTermPrinter()
+	tp.SetStdout(buf)
+
+	tp.Print("ok \xff")
+	tp.SetUTF8Output(true)
+
+	n, err := tp.Print("héllo \xff\xfe wörld")
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if n != 16 {
+		t.Errorf("expected 16 bytes, received %d", n)
+	}
+
+	if buf.String() != "ok \xffhéllo � wörld" {
+		t.Errorf("unexpected output %q", buf)
+	}
+}
+
+func TestUTF8Reader(t *testing.T) {
+	in := "héllo \xff wörld ✓"
+
+	b, err := io.ReadAll(cli.NewUTF8Reader(iotest.OneByteReader(strings.NewReader(in))))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if string(b) != "héllo � wörld ✓" {
+		t.Errorf("unexpected result %q", b)
+	}
+
+	// an incomplete sequence at the end of the input is replaced
+	b, err = io.ReadAll(cli.NewUTF8Reader(strings.NewReader("ab\xe2\x9c")))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if string(b) != "ab�" {
+		t.Errorf("unexpected result %q", b)
+	}
+}
+
+func TestUTF8Stdin(t *testing.T) {
+	cmd := cli.NewCmd(cli.WithUTF8())
+	cmd.SetStdin(strings.NewReader("a\xffb"))
+
+	b, err := io.ReadAll(cmd.ExpectPipedStdin())
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	if string(b) != "a�b" {
+		t.Errorf("unexpected result %q", b)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Watch mode settings.
+const (
+	// watchQuitKey quits RunWatch when pressed.
+	watchQuitKey = 'q'
+
+	// maxWatchBackoff limits the growth of the interval after repeated
+	// errors, as a multiple of the interval.
+	maxWatchBackoff = 32
+)
+
+// outputCapture collects the messages printed to Stdout while a watch
+// iteration runs.
+type outputCapture struct {
+	m  sync.Mutex
+	sb strings.Builder
+}
+
+// write appends msg to the captured output.
+func (oc *outputCapture) write(msg string) {
+	oc.m.Lock()
+	oc.sb.WriteString(msg)
+	oc.m.Unlock()
+}
+
+// String returns the captured output.
+func (oc *outputCapture) String() string {
+	oc.m.Lock()
+	defer oc.m.Unlock()
+
+	return oc.sb.String()
+}
+
+// RunWatch calls fn repeatedly, waiting interval between calls, in the
+// manner of watch(1). Messages printed to Stdout while fn runs are
+// collected, then replace the live region along with a header showing
+// the interval and time, so each call redraws the display. If fn fails,
+// its error is shown in place of the output, and the wait doubles after
+// each consecutive failure, up to 32 times interval.
+//
+// The context passed to fn is canceled when the exit channel closes,
+// after which RunWatch leaves the last display in place and returns.
+// If Stdin and Stdout are terminals, pressing q calls Exit, so the
+// program shuts down as on a signal. RunWatch should not be combined
+// with EnablePauseKey.
+func (c *Cmd) RunWatch(interval time.Duration, fn func(ctx context.Context) error) error {
+	c.Add(1)
+	defer c.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-c.C
+		cancel()
+	}()
+
+	hint := ""
+
+	if stop := c.watchQuitKey(); stop != nil {
+		defer stop()
+
+		hint = fmt.Sprintf("  (press %c to quit)", watchQuitKey)
+	}
+
+	defer c.FinalizeLive("")
+
+	wait := interval
+
+	for {
+		// the output of a call interrupted by Exit is incomplete
+		out, err := c.watchOnce(ctx, fn)
+		if c.exiting() {
+			return nil
+		}
+
+		var sb strings.Builder
+
+		fmt.Fprintf(&sb, "Every %s: %s%s\n\n", interval,
+			c.clock().Now().Format(time.TimeOnly), hint)
+
+		if err != nil {
+			wait = min(wait*2, interval*maxWatchBackoff)
+
+			fmt.Fprintf(&sb, "%s %v\nretrying in %s\n", c.Colorize(Red, "error:"), err, wait)
+		} else {
+			wait = interval
+
+			sb.WriteString(out)
+		}
+
+		c.Lprintf("%s", sb.String())
+
+		t := c.clock().NewTimer(wait)
+
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+
+			return nil
+		}
+	}
+}
+
+// watchOnce calls fn, returning the messages it printed to Stdout and
+// its error.
+func (c *Cmd) watchOnce(ctx context.Context, fn func(ctx context.Context) error) (string, error) {
+	oc := new(outputCapture)
+
+	c.capture.Store(oc)
+	defer c.capture.Store(nil)
+
+	err := fn(ctx)
+
+	return oc.String(), err
+}
+
+// watchQuitKey listens for the quit key if Stdin and Stdout are
+// terminals, returning a function which stops listening, or nil if the
+// key is not available.
+func (c *Cmd) watchQuitKey() func() {
+	f, ok := c.stdinReader().(*os.File)
+	if !ok || !isTerminal(f) || !c.outIsTerm {
+		return nil
+	}
+
+	stop, err := listenKeys(f, func(b byte) {
+		if b == watchQuitKey {
+			c.Exit(nil)
+		}
+	})
+	if err != nil {
+		return nil
+	}
+
+	return c.GuardTerminal(stop)
+}
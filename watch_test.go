@@ -0,0 +1,114 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kreklow.us/go/cli"
+	"kreklow.us/go/cli/clitest"
+)
+
+func TestRunWatch(t *testing.T) {
+	clk := clitest.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	buf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd()
+	cmd.SetClock(clk)
+	cmd.SetStdout(buf)
+
+	var calls atomic.Int64
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- cmd.RunWatch(time.Second, func(context.Context) error {
+			n := calls.Add(1)
+
+			switch n {
+			case 2, 3:
+				return errTest
+			case 4:
+				cmd.Exit(nil)
+			}
+
+			cmd.Printf("run %d\n", n)
+
+			return nil
+		})
+	}()
+
+	// successful runs wait the interval, failures double the wait
+	for _, tc := range []struct {
+		advance time.Duration
+		calls   int64
+	}{
+		{0, 1},
+		{time.Second, 2},
+		{time.Second, 2},
+		{time.Second, 3},
+		{3 * time.Second, 3},
+	} {
+		clk.Advance(tc.advance)
+		clk.BlockUntil(1)
+
+		if n := calls.Load(); n != tc.calls {
+			t.Fatalf("after %s: expected %d calls, received %d", tc.advance, tc.calls, n)
+		}
+	}
+
+	clk.Advance(time.Second)
+
+	if err := <-done; err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if n := calls.Load(); n != 4 {
+		t.Error("expected 4 calls, received", n)
+	}
+
+	out := buf.String()
+
+	for _, exp := range []string{
+		"Every 1s: 03:04:05\n\nrun 1\n",
+		"error: testing error\nretrying in 2s\n",
+		"error: testing error\nretrying in 4s\n",
+	} {
+		if !strings.Contains(out, exp) {
+			t.Errorf("expected %q in output %q", exp, out)
+		}
+	}
+
+	if strings.Contains(out, "run 4") {
+		t.Errorf("unexpected output after exit: %q", out)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+	variationText   = '\ufe0e'
+	variationEmoji  = '\ufe0f'
+)
+
+// DisplayWidth returns the number of terminal columns used to display
+// s. Width is measured per grapheme cluster, so combining characters,
+// variation selectors and joined emoji sequences do not add columns,
+// and East Asian wide characters and emoji count as two columns.
+func DisplayWidth(s string) int {
+	var w int
+
+	for s != "" {
+		var c string
+
+		c, s = nextCluster(s)
+		w += clusterWidth(c)
+	}
+
+	return w
+}
+
+// nextCluster splits the first grapheme cluster from s. This is an
+// approximation of the Unicode rules sufficient for terminal output: a
+// cluster is a base character followed by any combining marks,
+// variation selectors, emoji modifiers and zero width joined characters,
+// or a pair of regional indicators forming a flag.
+func nextCluster(s string) (string, string) {
+	r, n := utf8.DecodeRuneInString(s)
+
+	if isRegional(r) {
+		if r2, n2 := utf8.DecodeRuneInString(s[n:]); isRegional(r2) {
+			return s[:n+n2], s[n+n2:]
+		}
+	}
+
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+
+		switch {
+		case r == zeroWidthJoiner:
+			n += size
+
+			if n < len(s) {
+				_, size = utf8.DecodeRuneInString(s[n:])
+				n += size
+			}
+		case isExtending(r):
+			n += size
+		default:
+			return s[:n], s[n:]
+		}
+	}
+
+	return s, ""
+}
+
+// clusterWidth returns the number of columns used by the cluster c.
+func clusterWidth(c string) int {
+	r, _ := utf8.DecodeRuneInString(c)
+
+	switch {
+	case r < ' ' || r == 0x7f || (r >= 0x80 && r < 0xa0) || isExtending(r):
+		return 0
+	case isRegional(r) && len(c) > utf8.RuneLen(r):
+		return 2
+	case strings.ContainsRune(c, variationEmoji):
+		return 2
+	case strings.ContainsRune(c, variationText):
+		return 1
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// isExtending reports whether r extends the preceding character rather
+// than starting a new cluster.
+func isExtending(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) ||
+		(r >= 0xfe00 && r <= 0xfe0f) || // variation selectors
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // emoji skin tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) || // tag characters
+		r == '\u200b' || r == '\u200c'
+}
+
+// isRegional reports whether r is a regional indicator, pairs of which
+// form flag emoji.
+func isRegional(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isWide reports whether r is an East Asian wide or fullwidth
+// character, or an emoji displayed with two columns by default.
+func isWide(r rune) bool {
+	if r < wideRanges[0].lo {
+		return false
+	}
+
+	i := sort.Search(len(wideRanges), func(i int) bool {
+		return wideRanges[i].hi >= r
+	})
+
+	return i < len(wideRanges) && wideRanges[i].lo <= r
+}
+
+// wideRanges lists the ranges of wide characters in ascending order,
+// derived from the East Asian Width property of Unicode.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a},
+	{0x23e9, 0x23ec}, {0x23f0, 0x23f0}, {0x23f3, 0x23f3},
+	{0x25fd, 0x25fe}, {0x2614, 0x2615}, {0x2648, 0x2653},
+	{0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5},
+	{0x26ce, 0x26ce}, {0x26d4, 0x26d4}, {0x26ea, 0x26ea},
+	{0x26f2, 0x26f3}, {0x26f5, 0x26f5}, {0x26fa, 0x26fa},
+	{0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e},
+	{0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27b0, 0x27b0}, {0x27bf, 0x27bf}, {0x2b1b, 0x2b1c},
+	{0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff},
+	{0xa000, 0xa4cf}, {0xa960, 0xa97f}, {0xac00, 0xd7a3},
+	{0xf900, 0xfaff}, {0xfe10, 0xfe19}, {0xfe30, 0xfe6f},
+	{0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b2ff}, {0x1f004, 0x1f004},
+	{0x1f0cf, 0x1f0cf}, {0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a},
+	{0x1f200, 0x1f202}, {0x1f210, 0x1f23b}, {0x1f240, 0x1f248},
+	{0x1f250, 0x1f251}, {0x1f260, 0x1f265}, {0x1f300, 0x1f64f},
+	{0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb}, {0x1f90c, 0x1f9ff},
+	{0x1fa70, 0x1faff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// padRight pads s with spaces to a display width of w.
+func padRight(s string, w int) string {
+	n := VisibleLength(s)
+	if n >= w {
+		return s
+	}
+
+	return s + strings.Repeat(" ", w-n)
+}
+
+// truncateWidth shortens s to a display width of at most w, without
+// splitting grapheme clusters. Escape sequences are kept, and if s is
+// shortened after one, the colors are reset at the end.
+func truncateWidth(s string, w int) string {
+	var (
+		n, end  int
+		escaped bool
+	)
+
+	for rest := s; rest != ""; {
+		if seq, after, ok := cutEscape(rest); ok {
+			end += len(seq)
+			rest = after
+			escaped = true
+
+			continue
+		}
+
+		var c string
+
+		c, rest = nextCluster(rest)
+
+		cw := clusterWidth(c)
+		if n+cw > w {
+			break
+		}
+
+		n += cw
+		end += len(c)
+	}
+
+	if escaped && end < len(s) {
+		return s[:end] + "\x1b[0m"
+	}
+
+	return s[:end]
+}
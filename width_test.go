@@ -0,0 +1,59 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in  string
+		exp int
+	}{
+		{"", 0},
+		{"hello", 5},
+		{"héllo", 5},
+		{"héllo", 5},
+		{"日本語", 6},
+		{"ｈｉ", 4},
+		{"한국어", 6},
+		{"✓ ok", 4},
+		{"✅", 2},
+		{"👍🏽", 2},
+		{"👨‍👩‍👧", 2},
+		{"🇺🇸", 2},
+		{"❤️", 2},
+		{"☺︎", 1},
+		{"a\tb", 2},
+	}
+
+	for _, tc := range tests {
+		w := cli.DisplayWidth(tc.in)
+		if w != tc.exp {
+			t.Errorf("%q: expected %d, received %d", tc.in, tc.exp, w)
+		}
+	}
+}
@@ -29,22 +29,54 @@ import (
 	"os"
 	"sync"
 	"sync/atomic"
-
-	"github.com/mattn/go-isatty"
 )
 
-// lockingWriter is a simple mutex-protected writer.
+// lockingWriter is a simple mutex-protected writer. The mutex may be
+// shared with another lockingWriter, see linkLocks. If a write queue is
+// running, writes are passed to it instead, see StartWriteQueue.
 type lockingWriter struct {
-	m sync.Mutex
+	m *sync.Mutex
 	w io.Writer
+
+	queue  atomic.Pointer[writeQueue]
+	tap    func([]byte)
+	fail   func(error)
+	broken atomic.Bool
+}
+
+// newLockingWriter returns a lockingWriter for w with its own mutex.
+func newLockingWriter(w io.Writer) *lockingWriter {
+	return &lockingWriter{m: new(sync.Mutex), w: w}
 }
 
-// Write passes the provided data to the embedded io.Writer.
-func (lw *lockingWriter) Write(b []byte) (n int, err error) {
+// Write passes the provided data to the write queue if it is running,
+// otherwise to the embedded io.Writer.
+func (lw *lockingWriter) Write(b []byte) (int, error) {
+	if lw.broken.Load() {
+		return len(b), nil
+	}
+
+	if lw.tap != nil {
+		lw.tap(b)
+	}
+
+	if q := lw.queue.Load(); q != nil && q.enqueue(lw, b) {
+		return len(b), nil
+	}
+
+	return lw.write(b)
+}
+
+// write passes the provided data to the embedded io.Writer.
+func (lw *lockingWriter) write(b []byte) (n int, err error) {
 	lw.m.Lock()
 	n, err = lw.w.Write(b)
 	lw.m.Unlock()
 
+	if err != nil && lw.fail != nil {
+		lw.fail(err)
+	}
+
 	return
 }
 
@@ -63,124 +95,450 @@ func (lw *lockingWriter) Write(b []byte) (n int, err error) {
 // If TermPrinter is not created with NewTermPrinter, SetStdout and
 // SetStderr must be called before use.
 type TermPrinter struct {
+	dropped   int64 // guarantee 64 bit alignment on 32 bit platforms
+	writes    int64
+	fraction  uint64
 	livecount uint32
 
 	outIsTerm bool
 	errIsTerm bool
 
-	out io.Writer
-	err io.Writer
+	// liveEnabled caches whether either stream is a terminal, so that
+	// printing to pipes and files can skip coordinating with the live
+	// region
+	liveEnabled bool
+
+	outFd uintptr
+
+	outColor ColorMode
+	errColor ColorMode
+
+	width  int
+	height int
+
+	out *lockingWriter
+	err *lockingWriter
+
+	shareLock bool
+
+	utf8   bool
+	debug  bool
+	locale string
+
+	deterministic bool
+	clk           Clock
 
-	livebuf bytes.Buffer
+	estimator func() RateEstimator
+
+	queueM       sync.Mutex
+	queue        *writeQueue
+	backpressure Backpressure
+
+	// frame serializes each update of the live region, and each
+	// printed message, with the write which produces it
+	frame sync.Mutex
+
+	exitFunc func(error)
+
+	live       liveRenderer
+	dedup      dedupState
+	hooks      outputHooks
+	filters    lineFilters
+	redact     redactState
+	events     eventState
+	ci         ciState
+	snapshot   snapshotState
+	transcript atomic.Pointer[transcript]
+	counts     outputCounts
+	brokenPipe brokenPipeState
+	reporters  progressReporters
+	idle       idleState
+	capture    atomic.Pointer[outputCapture]
 }
 
 // NewTermPrinter returns a TermPrinter set to output to os.Stdout and
 // os.Stderr.
 func NewTermPrinter() *TermPrinter {
-	return &TermPrinter{
-		out: &lockingWriter{w: os.Stdout},
-		err: &lockingWriter{w: os.Stderr},
+	tp := &TermPrinter{
+		ci: ciState{interval: -1},
 	}
+
+	tp.setProgressFraction(noProgress)
+
+	tp.out = tp.newLockingWriter(os.Stdout, Stdout)
+	tp.err = tp.newLockingWriter(os.Stderr, Stderr)
+
+	tp.linkLocks()
+
+	return tp
 }
 
 // SetStdout sets the destination for calls to Print, Printf, Println
 // and Lprintf.
 func (tp *TermPrinter) SetStdout(w io.Writer) {
-	tp.out = &lockingWriter{w: w}
+	tp.out = tp.newLockingWriter(w, Stdout)
 	tp.outIsTerm = false
 
+	tp.linkLocks()
+
 	if f, ok := w.(*os.File); ok {
-		tp.outIsTerm = isatty.IsTerminal(f.Fd())
+		tp.outFd = f.Fd()
+		tp.outIsTerm = isTerminal(f)
+	}
+
+	tp.liveEnabled = tp.outIsTerm || tp.errIsTerm
+
+	tp.ci.m.Lock()
+	tp.ci.enabled = tp.outIsTerm && IsCI()
+	tp.ci.m.Unlock()
+}
+
+// SetTermSize sets a fixed terminal width and height used when drawing
+// the live region, overriding the size detected from Stdout. Zero
+// values restore detection.
+func (tp *TermPrinter) SetTermSize(width int, height int) {
+	tp.width = width
+	tp.height = height
+}
+
+// termSize returns the terminal width and height, or zero values if
+// unknown.
+func (tp *TermPrinter) termSize() (int, int) {
+	w, h := tp.width, tp.height
+
+	if tp.deterministic {
+		if w == 0 {
+			w = deterministicWidth
+		}
+
+		if h == 0 {
+			h = deterministicHeight
+		}
+	}
+
+	if (w == 0 || h == 0) && tp.outIsTerm {
+		tw, th := termSize(tp.outFd)
+
+		if w == 0 {
+			w = tw
+		}
+
+		if h == 0 {
+			h = th
+		}
 	}
+
+	return w, h
 }
 
 // SetStderr sets the destination for calls to EPrint, EPrintf and
 // EPrintln.
 func (tp *TermPrinter) SetStderr(w io.Writer) {
-	tp.err = &lockingWriter{w: w}
+	tp.err = tp.newLockingWriter(w, Stderr)
 	tp.errIsTerm = false
 
 	if f, ok := w.(*os.File); ok {
-		tp.errIsTerm = isatty.IsTerminal(f.Fd())
+		tp.errIsTerm = isTerminal(f)
 	}
+
+	tp.liveEnabled = tp.outIsTerm || tp.errIsTerm
+
+	tp.linkLocks()
 }
 
-// Print operates in the manner of fmt.Print, writing to Stdout.
-func (tp *TermPrinter) Print(v ...interface{}) (int, error) {
-	if tp.outIsTerm {
-		tp.resetLiveLines()
+// ShareLock makes Stdout and Stderr share a single lock, so that writes
+// to the two streams are serialized. This happens automatically when
+// both refer to the same file, such as a terminal, but may be needed
+// when the destinations are merged in some other way.
+func (tp *TermPrinter) ShareLock() {
+	tp.shareLock = true
+	tp.linkLocks()
+}
+
+// linkLocks shares the lock of Stdout with Stderr if ShareLock has been
+// called or both refer to the same file, otherwise it ensures they have
+// separate locks. Nothing is done until both streams have been set.
+func (tp *TermPrinter) linkLocks() {
+	if tp.out == nil || tp.err == nil {
+		return
 	}
 
-	return fmt.Fprint(tp.out, v...)
+	switch {
+	case tp.shareLock || sameFile(tp.out.w, tp.err.w):
+		tp.err.m = tp.out.m
+	case tp.err.m == tp.out.m:
+		tp.err.m = new(sync.Mutex)
+	}
 }
 
-// Printf operates in the manner of fmt.Printf, writing to Stdout.
-func (tp *TermPrinter) Printf(f string, v ...interface{}) (int, error) {
-	if tp.outIsTerm {
-		tp.resetLiveLines()
+// sameFile reports whether a and b are both files referring to the same
+// underlying file.
+func sameFile(a io.Writer, b io.Writer) bool {
+	fa, ok := a.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fb, ok := b.(*os.File)
+	if !ok {
+		return false
+	}
+
+	if fa.Fd() == fb.Fd() {
+		return true
 	}
 
-	return fmt.Fprintf(tp.out, f, v...)
+	sa, err := fa.Stat()
+	if err != nil {
+		return false
+	}
+
+	sb, err := fb.Stat()
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(sa, sb)
+}
+
+// Print operates in the manner of fmt.Print, writing to Stdout.
+func (tp *TermPrinter) Print(v ...interface{}) (int, error) {
+	return tp.writeMessage(Stdout, fmt.Sprint(v...))
+}
+
+// Printf operates in the manner of fmt.Printf, writing to Stdout.
+func (tp *TermPrinter) Printf(f string, v ...interface{}) (int, error) {
+	return tp.writeMessage(Stdout, fmt.Sprintf(f, v...))
 }
 
 // Println operates in the manner of fmt.Println, writing to Stdout.
 func (tp *TermPrinter) Println(v ...interface{}) (int, error) {
-	if tp.outIsTerm {
-		tp.resetLiveLines()
-	}
-
-	return fmt.Fprintln(tp.out, v...)
+	return tp.writeMessage(Stdout, fmt.Sprintln(v...))
 }
 
 // Lprintf implements a "live update" version of fmt.Printf. If Stdout
 // appears to be a terminal, the previously output line(s) will be
 // cleared before the new line(s) are written.
 //
+// If the output is taller than the terminal, it is truncated to fit
+// and the final line is replaced with a count of the omitted lines,
+// since lines scrolled out of view cannot be cleared.
+//
 // While Lprintf is safe for concurrent use with Print* and Eprint*,
 // concurrent use of Lprintf will conflict, overwriting the previous
 // output.
+//
+// In CI environments the live region is not redrawn, see SetCIInterval.
 func (tp *TermPrinter) Lprintf(f string, v ...interface{}) (int, error) {
+	tp.markActive()
+
+	return tp.lprintf(false, f, v...)
+}
+
+// lprintf implements Lprintf. If force is true, the update is not
+// dropped in CI environments.
+func (tp *TermPrinter) lprintf(force bool, f string, v ...interface{}) (int, error) {
+	s := tp.redactSecrets(fmt.Sprintf(f, v...))
+
 	if !tp.outIsTerm {
-		return fmt.Fprintf(tp.out, f, v...)
+		return io.WriteString(tp.liveWriter(), s)
 	}
 
-	tp.clearLiveLines()
-	tp.livebuf.Reset()
+	if tp.ciEnabled() {
+		if tp.ciThrottle(force) {
+			return 0, nil
+		}
+
+		return io.WriteString(tp.liveWriter(), s)
+	}
 
-	fmt.Fprintf(&tp.livebuf, f, v...)
+	tp.frame.Lock()
+	defer tp.frame.Unlock()
 
-	b := tp.livebuf.Bytes()
+	if tp.live.paused {
+		tp.live.pending = s
+		tp.live.hasPending = true
 
-	atomic.StoreUint32(&tp.livecount, uint32(bytes.Count(b, []byte{'\n'})))
+		return len(s), nil
+	}
 
-	return tp.out.Write(b)
+	return tp.drawLive(s)
+}
+
+// drawLive replaces the live region with s. The frame lock must be
+// held.
+func (tp *TermPrinter) drawLive(s string) (int, error) {
+	frame := getFrame()
+	defer putFrame(frame)
+
+	cleared := tp.appendClear(frame)
+	start := frame.Len()
+
+	frame.WriteString(s)
+
+	w, h := tp.termSize()
+	content := clampLines(frame.Bytes()[start:], w, h-1)
+
+	if len(content) != frame.Len()-start {
+		frame.Truncate(start)
+		frame.Write(content)
+		content = frame.Bytes()[start:]
+	}
+
+	atomic.StoreUint32(&tp.livecount, uint32(countRows(content, w)))
+
+	lw := tp.liveWriter()
+	if hw, ok := lw.(*hookWriter); ok {
+		hw.notify(content)
+		lw = hw.w
+	}
+
+	// the clear and the new content are written together, so the
+	// terminal never shows a partially drawn frame
+	_, err := lw.Write(frame.Bytes())
+	if err != nil {
+		if cleared {
+			panic(err)
+		}
+
+		return 0, err
+	}
+
+	return len(content), nil
 }
 
 // Eprint operates in the manner of fmt.Print, writing to Stderr.
 func (tp *TermPrinter) Eprint(v ...interface{}) (int, error) {
-	if tp.errIsTerm {
-		tp.resetLiveLines()
+	if tp.dedupEnabled() {
+		return tp.dedupWrite(fmt.Sprint(v...))
 	}
 
-	return fmt.Fprint(tp.err, v...)
+	return tp.writeMessage(Stderr, fmt.Sprint(v...))
 }
 
 // Eprintf operates in the manner of fmt.Printf, writing to Stderr.
 func (tp *TermPrinter) Eprintf(f string, v ...interface{}) (int, error) {
-	if tp.errIsTerm {
-		tp.resetLiveLines()
+	if tp.dedupEnabled() {
+		return tp.dedupWrite(fmt.Sprintf(f, v...))
 	}
 
-	return fmt.Fprintf(tp.err, f, v...)
+	return tp.writeMessage(Stderr, fmt.Sprintf(f, v...))
 }
 
 // Eprintln operates in the manner of fmt.Println, writing to Stderr.
 func (tp *TermPrinter) Eprintln(v ...interface{}) (int, error) {
-	if tp.errIsTerm {
+	if tp.dedupEnabled() {
+		return tp.dedupWrite(fmt.Sprintln(v...))
+	}
+
+	return tp.writeMessage(Stderr, fmt.Sprintln(v...))
+}
+
+// writeMessage writes a printed message to stream s. The message is
+// written with a single call while holding the frame lock, so it cannot
+// land inside a redraw of the live region, and the live region is left
+// in place above it. Secrets are masked, then messages to Stdout are
+// passed through the line filters.
+func (tp *TermPrinter) writeMessage(s Stream, msg string) (int, error) {
+	tp.markActive()
+
+	msg = tp.redactSecrets(msg)
+
+	if s == Stdout {
+		msg = tp.filterLines(msg)
+		if msg == "" {
+			return 0, nil
+		}
+
+		// collected for the next frame of RunWatch
+		if oc := tp.capture.Load(); oc != nil {
+			oc.write(msg)
+
+			return len(msg), nil
+		}
+	}
+
+	// without a terminal there is no live region to keep in place, and
+	// the stream lock alone keeps the message in one piece
+	if !tp.liveEnabled {
+		return io.WriteString(tp.writer(s), msg)
+	}
+
+	tp.frame.Lock()
+	defer tp.frame.Unlock()
+
+	if (s == Stdout && tp.outIsTerm) || (s == Stderr && tp.errIsTerm) {
 		tp.resetLiveLines()
 	}
 
-	return fmt.Fprintln(tp.err, v...)
+	return io.WriteString(tp.writer(s), msg)
+}
+
+// countRows returns the number of terminal rows the cursor moves down
+// when writing b to a terminal of width w. Lines longer than w are
+// counted as wrapping onto multiple rows.
+func countRows(b []byte, w int) int {
+	rows := 0
+
+	for {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			return rows
+		}
+
+		rows += lineRows(b[:i], w)
+		b = b[i+1:]
+	}
+}
+
+// lineRows returns the number of rows occupied by line on a terminal
+// of width w.
+func lineRows(line []byte, w int) int {
+	n := VisibleLength(string(line))
+	if w <= 0 || n <= w {
+		return 1
+	}
+
+	return (n + w - 1) / w
+}
+
+// clampLines truncates b to fit within limit rows of a terminal of
+// width w, replacing the final row with a count of the omitted lines.
+// If limit is less than 1, b is returned unchanged.
+func clampLines(b []byte, w int, limit int) []byte {
+	if limit < 1 || countRows(b, w) <= limit {
+		return b
+	}
+
+	var (
+		rows int
+		end  int
+	)
+
+	for {
+		i := bytes.IndexByte(b[end:], '\n')
+		if i < 0 {
+			break
+		}
+
+		r := lineRows(b[end:end+i], w)
+		if rows+r > limit-1 {
+			break
+		}
+
+		rows += r
+		end += i + 1
+	}
+
+	more := bytes.Count(b[end:], []byte{'\n'})
+	if b[len(b)-1] != '\n' {
+		more++
+	}
+
+	out := make([]byte, end, end+32)
+	copy(out, b[:end])
+
+	return fmt.Appendf(out, "... %d more lines\n", more)
 }
 
 func (tp *TermPrinter) resetLiveLines() {
@@ -190,15 +548,62 @@ func (tp *TermPrinter) resetLiveLines() {
 //nolint:gochecknoglobals // improves performance of clearLiveLines
 var clearline = []byte("\x1b[1A\x1b[2K")
 
-func (tp *TermPrinter) clearLiveLines() {
-	ll := atomic.LoadUint32(&tp.livecount)
+// framePool holds buffers used to build live region frames.
+//
+//nolint:gochecknoglobals // shared pool of frame buffers
+var framePool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getFrame returns an empty buffer from the pool.
+func getFrame() *bytes.Buffer {
+	b, _ := framePool.Get().(*bytes.Buffer)
+	b.Reset()
+
+	return b
+}
+
+// putFrame returns b to the pool, unless it has grown unusually large.
+func putFrame(b *bytes.Buffer) {
+	if b.Cap() > maxFrameSize {
+		return
+	}
+
+	framePool.Put(b)
+}
+
+// maxFrameSize is the capacity above which frame buffers are not
+// returned to the pool.
+const maxFrameSize = 64 << 10
+
+// appendClear appends the sequences to clear the live region to b and
+// resets the live line count, reporting whether any were appended.
+func (tp *TermPrinter) appendClear(b *bytes.Buffer) bool {
+	ll := atomic.SwapUint32(&tp.livecount, 0)
 
 	for l := uint32(0); l < ll; l++ {
-		_, err := tp.out.Write(clearline)
-		if err != nil {
-			panic(err)
-		}
+		b.Write(clearline)
+	}
+
+	return ll > 0
+}
+
+// clearLiveLines clears the live region with a single write.
+func (tp *TermPrinter) clearLiveLines() {
+	frame := getFrame()
+	defer putFrame(frame)
+
+	tp.frame.Lock()
+	defer tp.frame.Unlock()
+
+	if !tp.appendClear(frame) {
+		return
 	}
 
-	tp.resetLiveLines()
+	_, err := tp.out.Write(frame.Bytes())
+	if err != nil {
+		panic(err)
+	}
 }
@@ -24,9 +24,12 @@ package cli_test
 
 import (
 	"bytes"
+	"io"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	expect "github.com/Netflix/go-expect"
 	"kreklow.us/go/cli"
@@ -35,6 +38,8 @@ import (
 func TestLprintf(t *testing.T) {
 	t.Run("Buffer", testLprintfBuffer)
 	t.Run("Console", testLprintfConsole)
+	t.Run("Clamp", testLprintfClamp)
+	t.Run("Concurrent", testLprintfConcurrent)
 }
 
 func testLprintfBuffer(t *testing.T) {
@@ -57,6 +62,8 @@ func testLprintfBuffer(t *testing.T) {
 }
 
 func testLprintfConsole(t *testing.T) {
+	t.Setenv("CI", "false")
+
 	cons, err := expect.NewConsole()
 	if err != nil {
 		t.Fatal("unexpected error", err)
@@ -114,6 +121,124 @@ func testLprintfConsole(t *testing.T) {
 	t.Error("expected panic, got", err)
 }
 
+func testLprintfClamp(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	var outstr string
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		outstr, err = cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetTermSize(20, 4)
+
+	p.Lprintf("1\n2\n3\n4\n5\n")
+	p.Lprintf("%s\n", strings.Repeat("x", 30))
+	p.Lprintf("END")
+
+	wg.Wait()
+
+	clear := "\x1b[1A\x1b[2K"
+
+	if outstr != "1\r\n2\r\n... 3 more lines\r\n"+
+		strings.Repeat(clear, 3)+strings.Repeat("x", 30)+"\r\n"+
+		strings.Repeat(clear, 2)+"END" {
+		t.Errorf("unexpected output %q", outstr)
+	}
+}
+
+func testLprintfConcurrent(t *testing.T) {
+	t.Setenv("CI", "false")
+
+	cons := newTestConsole(t)
+
+	var outstr string
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		var err error
+
+		outstr, err = cons.ExpectString("END")
+		if err != nil {
+			t.Error("unexpected error", err)
+		}
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetStderr(cons.Tty())
+
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 200; i++ {
+			p.Lprintf("live %d\nlive %d\n", i, i)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 200; i++ {
+			p.Eprintln("message", i)
+		}
+	}()
+
+	wg.Wait()
+	p.Print("END")
+	<-done
+
+	// replay the output, removing a line for each clear, to check that
+	// no message was erased by a redraw of the live region
+	var screen []string
+
+	clear := "\x1b[1A\x1b[2K"
+
+	for i, part := range strings.Split(outstr, clear) {
+		if i > 0 && len(screen) > 0 {
+			screen = screen[:len(screen)-1]
+		}
+
+		lines := strings.Split(part, "\r\n")
+		screen = append(screen, lines[:len(lines)-1]...)
+	}
+
+	n := 0
+
+	for _, l := range screen {
+		if strings.HasPrefix(l, "message ") {
+			n++
+		}
+	}
+
+	if n != 200 {
+		t.Errorf("expected 200 messages, found %d", n)
+	}
+}
+
 func writeLprintf(p *cli.TermPrinter) {
 	p.Print("print 1\n")
 	p.Eprintf("print %d\n", 2)
@@ -125,3 +250,150 @@ func writeLprintf(p *cli.TermPrinter) {
 	p.Printf("print %d\n", 8)
 	p.Eprintln("print 9")
 }
+
+func BenchmarkLprintf(b *testing.B) {
+	b.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		b.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	go func() {
+		_, _ = io.Copy(io.Discard, cons)
+	}()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(cons.Tty())
+	p.SetTermSize(80, 24)
+
+	b.Run("OneLine", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			p.Lprintf("progress %d%%\n", i%100)
+		}
+	})
+
+	b.Run("TenLines", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			p.Lprintf("%s", strings.Repeat("worker: busy\n", 10))
+		}
+	})
+}
+
+func BenchmarkPrintln(b *testing.B) {
+	b.Setenv("CI", "false")
+
+	cons, err := expect.NewConsole()
+	if err != nil {
+		b.Fatal("unexpected error", err)
+	}
+
+	defer cons.Close()
+
+	go func() {
+		_, _ = io.Copy(io.Discard, cons)
+	}()
+
+	// both cases print the same messages to io.Discard, but only the
+	// first takes the fast path which skips the live region, since in
+	// the second Stderr is a terminal and the frame lock must be taken
+	b.Run("FastPath", func(b *testing.B) {
+		p := cli.NewTermPrinter()
+		p.SetStdout(io.Discard)
+		p.SetStderr(io.Discard)
+
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				p.Println("processed item")
+			}
+		})
+	})
+
+	b.Run("FrameLock", func(b *testing.B) {
+		p := cli.NewTermPrinter()
+		p.SetStdout(io.Discard)
+		p.SetStderr(cons.Tty())
+
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				p.Println("processed item")
+			}
+		})
+	})
+}
+
+// overlapWriter records whether calls to Write ever overlap.
+type overlapWriter struct {
+	active  atomic.Int32
+	overlap atomic.Bool
+}
+
+func (w *overlapWriter) Write(b []byte) (int, error) {
+	if w.active.Add(1) > 1 {
+		w.overlap.Store(true)
+	}
+
+	time.Sleep(time.Millisecond)
+	w.active.Add(-1)
+
+	return len(b), nil
+}
+
+func TestShareLock(t *testing.T) {
+	w := new(overlapWriter)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(w)
+	p.SetStderr(w)
+	p.ShareLock()
+
+	wg := new(sync.WaitGroup)
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 20; i++ {
+			p.Lprintf("out %d\n", i)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 20; i++ {
+			p.Eprintln("err", i)
+		}
+	}()
+
+	wg.Wait()
+
+	if w.overlap.Load() {
+		t.Error("writes to Stdout and Stderr overlapped")
+	}
+}
+
+func TestZeroTermPrinter(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+	errbuf := new(bytes.Buffer)
+
+	p := new(cli.TermPrinter)
+	p.SetStdout(outbuf)
+	p.ShareLock()
+	p.SetStderr(errbuf)
+
+	p.Println("out")
+	p.Eprintln("err")
+
+	if outbuf.String() != "out\n" || errbuf.String() != "err\n" {
+		t.Errorf("unexpected output %q %q", outbuf, errbuf)
+	}
+}
@@ -0,0 +1,377 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// Backpressure determines what happens to writes when the write queue
+// is full.
+type Backpressure int32
+
+// Backpressure policies.
+const (
+	// BackpressureBlock makes writers wait for space in the queue, so
+	// no output is lost.
+	BackpressureBlock Backpressure = iota
+
+	// BackpressureDropOldest discards the oldest pending write to make
+	// space, so writers never wait.
+	BackpressureDropOldest
+
+	// BackpressureSample keeps one in every ten writes made while the
+	// queue is full, discarding the oldest pending write to make space,
+	// and discards the others.
+	BackpressureSample
+)
+
+// sampleRate is the fraction of writes kept by BackpressureSample.
+const sampleRate = 10
+
+// writeQueue passes writes from many goroutines to a single goroutine
+// which performs them, so callers do not wait on each other or on slow
+// writers. Consecutive writes to the same lockingWriter are combined.
+type writeQueue struct {
+	// queued counts writes added to c, finished counts those written
+	// or discarded, allowing flush to wait for earlier writes
+	queued  int64 // guarantee 64 bit alignment on 32 bit platforms
+	sampled int64
+	policy  int32
+	dropped *int64
+
+	c    chan queuedWrite
+	done chan bool
+
+	// m guards closed, preventing sends on c once it is closed
+	m      sync.RWMutex
+	closed bool
+
+	fm       sync.Mutex
+	fc       *sync.Cond
+	finished int64
+	err      error
+}
+
+// queuedWrite is an entry in a writeQueue.
+type queuedWrite struct {
+	lw *lockingWriter
+	b  []byte
+}
+
+// newWriteQueue returns a running writeQueue holding up to size
+// pending writes, counting discarded lines in dropped.
+func newWriteQueue(size int, policy Backpressure, dropped *int64) *writeQueue {
+	if size < 1 {
+		size = 1
+	}
+
+	q := &writeQueue{
+		policy:  int32(policy),
+		dropped: dropped,
+		c:       make(chan queuedWrite, size),
+		done:    make(chan bool),
+	}
+
+	q.fc = sync.NewCond(&q.fm)
+
+	go q.run()
+
+	return q
+}
+
+// enqueue adds a copy of b to the queue for lw, applying the
+// backpressure policy if the queue is full. It returns false if the
+// queue is closed.
+func (q *writeQueue) enqueue(lw *lockingWriter, b []byte) bool {
+	q.m.RLock()
+	defer q.m.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	w := queuedWrite{lw: lw, b: append([]byte(nil), b...)}
+
+	atomic.AddInt64(&q.queued, 1)
+
+	policy := Backpressure(atomic.LoadInt32(&q.policy))
+	if policy == BackpressureBlock {
+		q.c <- w
+
+		return true
+	}
+
+	for first := true; ; first = false {
+		select {
+		case q.c <- w:
+			return true
+		default:
+		}
+
+		if first && policy == BackpressureSample &&
+			atomic.AddInt64(&q.sampled, 1)%sampleRate != 0 {
+			q.discard(w)
+
+			return true
+		}
+
+		select {
+		case old := <-q.c:
+			q.discard(old)
+		default:
+		}
+	}
+}
+
+// discard counts the lines of w as dropped and w as finished.
+func (q *writeQueue) discard(w queuedWrite) {
+	n := int64(bytes.Count(w.b, []byte("\n")))
+	if n == 0 {
+		n = 1
+	}
+
+	atomic.AddInt64(q.dropped, n)
+
+	q.finish(1, nil)
+}
+
+// finish records that n writes have been written or discarded, and the
+// error if it is the first since the previous flush.
+func (q *writeQueue) finish(n int64, err error) {
+	q.fm.Lock()
+
+	q.finished += n
+
+	if q.err == nil {
+		q.err = err
+	}
+
+	q.fc.Broadcast()
+	q.fm.Unlock()
+}
+
+// flush waits for the writes queued before it, returning the first
+// error since the previous flush.
+func (q *writeQueue) flush() error {
+	target := atomic.LoadInt64(&q.queued)
+
+	q.fm.Lock()
+	defer q.fm.Unlock()
+
+	for q.finished < target {
+		q.fc.Wait()
+	}
+
+	err := q.err
+	q.err = nil
+
+	return err
+}
+
+// close waits for the pending writes and stops the queue, returning the
+// first error since the previous flush.
+func (q *writeQueue) close() error {
+	q.m.Lock()
+
+	if q.closed {
+		q.m.Unlock()
+
+		return nil
+	}
+
+	q.closed = true
+	close(q.c)
+
+	q.m.Unlock()
+
+	<-q.done
+
+	return q.flush()
+}
+
+// run performs the queued writes until the queue is closed. Entries
+// already waiting are combined into as few writes as possible.
+func (q *writeQueue) run() {
+	defer close(q.done)
+
+	var (
+		pending *lockingWriter
+		buf     []byte
+		n       int64
+	)
+
+	write := func() {
+		if pending == nil {
+			return
+		}
+
+		_, err := pending.write(buf)
+
+		q.finish(n, err)
+
+		pending = nil
+		buf = buf[:0]
+		n = 0
+	}
+
+	for w := range q.c {
+		for ok := true; ok; {
+			if w.lw != pending {
+				write()
+
+				pending = w.lw
+			}
+
+			buf = append(buf, w.b...)
+			n++
+
+			select {
+			case w, ok = <-q.c:
+			default:
+				ok = false
+			}
+		}
+
+		write()
+	}
+}
+
+// newLockingWriter returns a lockingWriter for stream s writing to w,
+// which uses the write queue of the TermPrinter, if it is running, and
+// passes its output to the transcript, if one is being recorded. Each
+// write is counted for EnableHeartbeat.
+func (tp *TermPrinter) newLockingWriter(w io.Writer, s Stream) *lockingWriter {
+	lw := newLockingWriter(w)
+	lw.tap = func(b []byte) {
+		atomic.AddInt64(&tp.writes, 1)
+		tp.recordTranscript(s, b)
+	}
+	lw.fail = func(err error) {
+		tp.writeFailed(lw, s, err)
+	}
+
+	tp.queueM.Lock()
+	lw.queue.Store(tp.queue)
+	tp.queueM.Unlock()
+
+	return lw
+}
+
+// StartWriteQueue makes writes to Stdout and Stderr return without
+// waiting, passing them to a single goroutine which writes them in
+// order. Up to size writes may be pending, after which the policy set
+// with SetBackpressure applies. This raises throughput when many
+// goroutines print, or when the output is slow, at the cost of
+// reporting write errors late: they are returned by Flush and
+// StopWriteQueue rather than by the Print functions.
+//
+// Pending output is lost if the program exits without calling Flush or
+// StopWriteQueue. A Cmd created WithWriteQueue stops the queue when it
+// exits.
+func (tp *TermPrinter) StartWriteQueue(size int) {
+	_ = tp.StopWriteQueue()
+
+	tp.queueM.Lock()
+	q := newWriteQueue(size, tp.backpressure, &tp.dropped)
+	tp.queue = q
+	tp.out.queue.Store(q)
+	tp.err.queue.Store(q)
+	tp.queueM.Unlock()
+}
+
+// SetBackpressure sets the policy applied when the write queue is full.
+// The default is BackpressureBlock. The policy applies to the running
+// write queue, if any, and to those started later.
+func (tp *TermPrinter) SetBackpressure(p Backpressure) {
+	tp.queueM.Lock()
+	defer tp.queueM.Unlock()
+
+	tp.backpressure = p
+
+	if tp.queue != nil {
+		atomic.StoreInt32(&tp.queue.policy, int32(p))
+	}
+}
+
+// DroppedLines returns the number of lines of output discarded by the
+// backpressure policy of the write queue. A write without a newline is
+// counted as one line.
+func (tp *TermPrinter) DroppedLines() int64 {
+	return atomic.LoadInt64(&tp.dropped)
+}
+
+// Flush waits until the writes queued before it have been performed,
+// returning the first write error since the previous call to Flush.
+// Flush does nothing if the write queue is not running.
+func (tp *TermPrinter) Flush() error {
+	tp.queueM.Lock()
+	q := tp.queue
+	tp.queueM.Unlock()
+
+	if q == nil {
+		return nil
+	}
+
+	return q.flush()
+}
+
+// StopWriteQueue performs all pending writes and stops the write queue
+// started with StartWriteQueue, returning the first write error since
+// the previous call to Flush. Subsequent writes are performed directly.
+func (tp *TermPrinter) StopWriteQueue() error {
+	tp.queueM.Lock()
+	q := tp.queue
+	tp.queue = nil
+	tp.queueM.Unlock()
+
+	if q == nil {
+		return nil
+	}
+
+	return q.close()
+}
+
+// WithWriteQueue starts a write queue of the given size, as with
+// StartWriteQueue, which is stopped when the Cmd exits so that no output
+// is lost. If output was dropped by the backpressure policy, the number
+// of dropped lines is printed to Stderr.
+func WithWriteQueue(size int) Option {
+	return func(c *Cmd) {
+		c.StartWriteQueue(size)
+
+		c.OnExit(func(error) {
+			err := c.StopWriteQueue()
+			if err != nil {
+				_, _ = c.Eprintln("unable to write output:", err)
+			}
+
+			if n := c.DroppedLines(); n > 0 {
+				_, _ = c.Eprintf("%d lines of output dropped\n", n)
+			}
+		})
+	}
+}
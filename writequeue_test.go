@@ -0,0 +1,287 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"kreklow.us/go/cli"
+)
+
+func TestWriteQueue(t *testing.T) {
+	t.Run("Order", testWriteQueueOrder)
+	t.Run("Error", testWriteQueueError)
+	t.Run("Cmd", testWriteQueueCmd)
+	t.Run("DropOldest", testWriteQueueDropOldest)
+	t.Run("Sample", testWriteQueueSample)
+}
+
+func testWriteQueueOrder(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(outbuf)
+	p.StartWriteQueue(4)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				p.Printf("%d:%d\n", i, j)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	err := p.Flush()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	next := make(map[string]int)
+
+	lines := strings.Split(strings.TrimSuffix(outbuf.String(), "\n"), "\n")
+	if len(lines) != 400 {
+		t.Fatal("expected 400 lines, received", len(lines))
+	}
+
+	for _, l := range lines {
+		var i, j int
+
+		_, err = fmt.Sscanf(l, "%d:%d", &i, &j)
+		if err != nil {
+			t.Fatalf("unexpected line %q", l)
+		}
+
+		key := fmt.Sprint(i)
+		if next[key] != j {
+			t.Fatalf("expected %s:%d, received %q", key, next[key], l)
+		}
+
+		next[key]++
+	}
+
+	err = p.StopWriteQueue()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	p.Println("direct")
+
+	if !strings.HasSuffix(outbuf.String(), ":99\ndirect\n") {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+}
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errTest
+}
+
+func testWriteQueueError(t *testing.T) {
+	p := cli.NewTermPrinter()
+	p.SetStdout(failWriter{})
+	p.StartWriteQueue(1)
+
+	_, err := p.Println("lost")
+	if err != nil {
+		t.Error("unexpected error from Println", err)
+	}
+
+	err = p.Flush()
+	if !errors.Is(err, errTest) {
+		t.Error("expected errTest from Flush, received", err)
+	}
+
+	err = p.Flush()
+	if err != nil {
+		t.Error("expected error to be cleared, received", err)
+	}
+
+	p.Println("lost")
+
+	err = p.StopWriteQueue()
+	if !errors.Is(err, errTest) {
+		t.Error("expected errTest from StopWriteQueue, received", err)
+	}
+}
+
+func testWriteQueueCmd(t *testing.T) {
+	outbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd(cli.WithWriteQueue(16))
+	cmd.SetStdout(outbuf)
+
+	cmd.Add(1)
+
+	go func() {
+		defer cmd.Done()
+
+		cmd.Println("before exit")
+		cmd.Exit(nil)
+	}()
+
+	err := cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if outbuf.String() != "before exit\n" {
+		t.Errorf("unexpected output %q", outbuf)
+	}
+}
+
+// gateWriter blocks each write until a value is received on release,
+// signaling entered when a write begins.
+type gateWriter struct {
+	buf     bytes.Buffer
+	entered chan bool
+	release chan bool
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan bool, 100), release: make(chan bool)}
+}
+
+func (w *gateWriter) Write(b []byte) (int, error) {
+	w.entered <- true
+	<-w.release
+
+	return w.buf.Write(b)
+}
+
+// fillWriteQueue prints "1", waits for it to block in the writer, then
+// prints the numbers from 2 to n.
+func fillWriteQueue(p *cli.TermPrinter, w *gateWriter, n int) {
+	p.Println(1)
+	<-w.entered
+
+	for i := 2; i <= n; i++ {
+		p.Println(i)
+	}
+
+	close(w.release)
+}
+
+func testWriteQueueDropOldest(t *testing.T) {
+	w := newGateWriter()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(w)
+	p.SetBackpressure(cli.BackpressureDropOldest)
+	p.StartWriteQueue(1)
+
+	fillWriteQueue(p, w, 11)
+
+	err := p.StopWriteQueue()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	if w.buf.String() != "1\n11\n" {
+		t.Errorf("unexpected output %q", w.buf.String())
+	}
+
+	if n := p.DroppedLines(); n != 9 {
+		t.Error("expected 9 dropped lines, received", n)
+	}
+}
+
+func testWriteQueueSample(t *testing.T) {
+	w := newGateWriter()
+	errbuf := new(bytes.Buffer)
+
+	cmd := cli.NewCmd(cli.WithWriteQueue(1))
+	cmd.SetStdout(w)
+	cmd.SetStderr(errbuf)
+	cmd.SetBackpressure(cli.BackpressureSample)
+
+	fillWriteQueue(cmd.TermPrinter, w, 22)
+
+	cmd.Add(1)
+	cmd.Exit(nil)
+	cmd.Done()
+
+	err := cmd.Wait()
+	if err != nil {
+		t.Error("unexpected error", err)
+	}
+
+	// the 10th and 20th writes made while full, 12 and 22, each
+	// replaced the pending write
+	if w.buf.String() != "1\n22\n" {
+		t.Errorf("unexpected output %q", w.buf.String())
+	}
+
+	if errbuf.String() != "20 lines of output dropped\n" {
+		t.Errorf("unexpected notice %q", errbuf)
+	}
+}
+
+func BenchmarkPrintParallel(b *testing.B) {
+	b.Run("Locked", func(b *testing.B) { benchmarkPrintParallel(b, 0) })
+	b.Run("Queued", func(b *testing.B) { benchmarkPrintParallel(b, 1024) })
+}
+
+func benchmarkPrintParallel(b *testing.B, size int) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	defer f.Close()
+
+	p := cli.NewTermPrinter()
+	p.SetStdout(f)
+
+	if size > 0 {
+		p.StartWriteQueue(size)
+	}
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.Printf("request %d handled in %s\n", 42, "1.5ms")
+		}
+	})
+
+	err = p.StopWriteQueue()
+	if err != nil {
+		b.Error(err)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2024 Collin Kreklow
+//
+// Permission is hereby granted, free of charge, to any person obtaining
+// a copy of this software and associated documentation files (the
+// "Software"), to deal in the Software without restriction, including
+// without limitation the rights to use, copy, modify, merge, publish,
+// distribute, sublicense, and/or sell copies of the Software, and to
+// permit persons to whom the Software is furnished to do so, subject to
+// the following conditions:
+//
+// The above copyright notice and this permission notice shall be
+// included in all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND,
+// EXPRESS OR IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF
+// MERCHANTABILITY, FITNESS FOR A PARTICULAR PURPOSE AND
+// NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR COPYRIGHT HOLDERS
+// BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER IN AN
+// ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlTimestamp matches strings which YAML reads as a date or time.
+var yamlTimestamp = regexp.MustCompile(`^[0-9]{4}-[0-9]{1,2}-[0-9]{1,2}(?:$|[Tt \t])`)
+
+// yamlNode is a decoded JSON value with object keys kept in order.
+type yamlNode struct {
+	scalar interface{} // string, json.Number, bool or nil
+	keys   []string
+	vals   []*yamlNode
+	isMap  bool
+	isList bool
+}
+
+// MarshalYAML returns the YAML encoding of v. The value is first
+// encoded in the manner of encoding/json, so JSON struct tags and
+// json.Marshaler implementations apply, and the order of object keys
+// is preserved.
+func MarshalYAML(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	n, err := decodeYAMLNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+
+	switch {
+	case n.isMap && len(n.keys) > 0:
+		writeYAMLMap(buf, n, 0)
+	case n.isList && len(n.vals) > 0:
+		writeYAMLList(buf, n, 0)
+	default:
+		buf.WriteString(yamlScalar(n))
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeYAMLNode reads the next value from dec.
+func decodeYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(yamlNode)
+
+	switch tok {
+	case json.Delim('{'):
+		n.isMap = true
+
+		for dec.More() {
+			k, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			key, _ := k.(string)
+			n.keys = append(n.keys, key)
+			n.vals = append(n.vals, v)
+		}
+
+		_, err = dec.Token()
+	case json.Delim('['):
+		n.isList = true
+
+		for dec.More() {
+			v, err := decodeYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			n.vals = append(n.vals, v)
+		}
+
+		_, err = dec.Token()
+	default:
+		n.scalar = tok
+	}
+
+	return n, err
+}
+
+// writeYAMLMap writes the keys and values of a map node.
+func writeYAMLMap(buf *bytes.Buffer, n *yamlNode, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	for i, k := range n.keys {
+		buf.WriteString(pad)
+		buf.WriteString(yamlString(k))
+		buf.WriteByte(':')
+		writeYAMLValue(buf, n.vals[i], indent+1, true)
+	}
+}
+
+// writeYAMLList writes the items of a list node.
+func writeYAMLList(buf *bytes.Buffer, n *yamlNode, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	for _, v := range n.vals {
+		buf.WriteString(pad)
+		buf.WriteByte('-')
+
+		if v.isMap && len(v.keys) > 0 {
+			// the first key shares the line with the dash
+			sub := new(bytes.Buffer)
+			writeYAMLMap(sub, v, indent+1)
+			buf.WriteByte(' ')
+			buf.Write(sub.Bytes()[len(pad)+2:])
+
+			continue
+		}
+
+		writeYAMLValue(buf, v, indent+1, false)
+	}
+}
+
+// writeYAMLValue writes a value following a key or list dash. Nested
+// lists under a map key are not indented, as is conventional.
+func writeYAMLValue(buf *bytes.Buffer, v *yamlNode, indent int, inMap bool) {
+	switch {
+	case v.isMap && len(v.keys) > 0:
+		buf.WriteByte('\n')
+		writeYAMLMap(buf, v, indent)
+	case v.isList && len(v.vals) > 0:
+		buf.WriteByte('\n')
+
+		if inMap {
+			indent--
+		}
+
+		writeYAMLList(buf, v, indent)
+	default:
+		buf.WriteByte(' ')
+		buf.WriteString(yamlScalar(v))
+		buf.WriteByte('\n')
+	}
+}
+
+// yamlScalar returns the YAML representation of a scalar or empty
+// collection node.
+func yamlScalar(n *yamlNode) string {
+	switch {
+	case n.isMap:
+		return "{}"
+	case n.isList:
+		return "[]"
+	}
+
+	switch v := n.scalar.(type) {
+	case string:
+		return yamlString(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return "null"
+	}
+}
+
+// yamlString returns s, quoted if it would otherwise be interpreted as
+// a different type or contains special characters.
+func yamlString(s string) string {
+	if yamlNeedsQuote(s) {
+		b, _ := json.Marshal(s)
+
+		return string(b)
+	}
+
+	return s
+}
+
+// yamlNeedsQuote reports whether s must be quoted to be read back as
+// the same string.
+func yamlNeedsQuote(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~",
+		".inf", ".nan":
+		return true
+	}
+
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+
+	// hexadecimal, octal and binary integers, which may be out of range
+	_, err := strconv.ParseInt(s, 0, 64)
+	if err == nil || errors.Is(err, strconv.ErrRange) {
+		return true
+	}
+
+	if yamlTimestamp.MatchString(s) {
+		return true
+	}
+
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+
+	if strings.HasSuffix(s, ":") || strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") {
+		return true
+	}
+
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+
+	return false
+}